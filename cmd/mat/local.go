@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/secret"
+	"github.com/multi-agent-testing/backend/internal/service"
+	"github.com/multi-agent-testing/backend/internal/suite"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// newLocalBackend 按配置文件初始化与服务端相同的服务, 任务保存在内存中
+// 启用数据库时可使用数据集、模板及数据库中的模型配置, 返回的closeFn用于释放资源
+func newLocalBackend(configPath, profile string) (*suite.LocalBackend, func(), error) {
+	cfg, err := config.Load(configPath, profile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := cfg.ResolveModelKeys(); err != nil {
+		return nil, nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid config: %w", err)
+	}
+	// 标准输出用于报告, 日志固定输出到标准错误
+	if err := logger.Init(cfg.Log.Level, cfg.Log.Format, "stderr"); err != nil {
+		return nil, nil, fmt.Errorf("failed to init logger: %w", err)
+	}
+
+	multiModelService := service.NewMultiModelService(cfg)
+	var (
+		db          *gorm.DB
+		datasetRepo *repository.DatasetRepository
+		templates   *service.TemplateService
+	)
+	if cfg.Database.Enabled {
+		db, err = repository.NewDB(&cfg.Database)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open database: %w", err)
+		}
+		datasetRepo = repository.NewDatasetRepository(db)
+		templates = service.NewTemplateService(repository.NewPromptTemplateRepository(db))
+		loadModelConfigs(cfg, db, multiModelService)
+	}
+
+	jobs := service.NewJobService(repository.NewMemoryJobStore(), multiModelService, datasetRepo)
+	closeFn := func() {
+		jobs.Shutdown(context.Background())
+		if db != nil {
+			repository.Close(db)
+		}
+		logger.Sync()
+	}
+	return suite.NewLocalBackend(jobs, templates), closeFn, nil
+}
+
+// loadModelConfigs 加载数据库中的模型配置, 与服务端一致地覆盖配置文件
+func loadModelConfigs(cfg *config.Config, db *gorm.DB, multiModelService *service.MultiModelService) {
+	keyring, err := secret.Load(cfg.Security.MasterKeyEnv, cfg.Security.MasterKeyFile)
+	if err != nil {
+		logger.Warn("Failed to load master key, stored API keys are unavailable", zap.Error(err))
+	}
+	modelConfigs := service.NewModelConfigService(repository.NewModelConfigRepository(db), multiModelService, keyring)
+	if err := modelConfigs.Load(context.Background()); err != nil {
+		logger.Warn("Failed to load model configs", zap.Error(err))
+	}
+}
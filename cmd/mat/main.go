@@ -0,0 +1,199 @@
+// mat 执行声明式YAML测试套件, 可连接运行中的服务端或在进程内使用相同的服务代码执行
+//
+//	mat run [flags] suite.yaml...
+//	mat validate suite.yaml...
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/suite"
+)
+
+// 进程退出码, 与server regression子命令一致
+const (
+	exitError  = 1 // 执行出错
+	exitUsage  = 2 // 参数错误
+	exitFailed = 3 // 存在未通过的用例
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(exitUsage)
+	}
+
+	switch os.Args[1] {
+	case "run":
+		os.Exit(runSuites(os.Args[2:]))
+	case "validate":
+		os.Exit(validateSuites(os.Args[2:]))
+	case "-h", "-help", "--help", "help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(exitUsage)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage:
+  mat run [flags] suite.yaml...    run test suites, exit 3 when any case fails
+  mat validate suite.yaml...       check test suite files
+
+Run "mat run -h" for flags.`)
+}
+
+// runSuites 执行run子命令, 返回进程退出码
+func runSuites(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	serverURL := fs.String("server", "", "服务端地址, 如http://localhost:8080, 为空时在进程内执行")
+	configPath := fs.String("config", "configs/config.yaml", "进程内执行使用的配置文件路径")
+	profile := fs.String("profile", "", "配置profile, 未指定时读取APP_PROFILE环境变量")
+	format := fs.String("format", "table", "输出格式: table/json/junit")
+	output := fs.String("o", "", "报告输出文件, 为空时输出到标准输出")
+	junitPath := fs.String("junit", "", "额外写入JUnit XML报告的文件")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: mat run [flags] suite.yaml...")
+		fs.PrintDefaults()
+		return exitUsage
+	}
+	write, ok := writers[*format]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown format %q, expected table, json or junit\n", *format)
+		return exitUsage
+	}
+
+	suites, err := loadSuites(fs.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	var backend suite.Backend
+	if *serverURL != "" {
+		backend = suite.NewRemoteBackend(*serverURL)
+	} else {
+		if *profile == "" {
+			*profile = os.Getenv(config.ProfileEnv)
+		}
+		local, closeFn, err := newLocalBackend(*configPath, *profile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		defer closeFn()
+		backend = local
+	}
+
+	// 中断时取消正在执行的任务
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	reports := make([]*suite.Report, 0, len(suites))
+	for _, s := range suites {
+		fmt.Fprintf(os.Stderr, "Running suite %s (%s)\n", s.Name, describeSuite(s))
+		report, err := suite.Run(ctx, s, backend)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Suite %s failed to run: %v\n", s.Name, err)
+			return exitError
+		}
+		reports = append(reports, report)
+	}
+
+	if err := writeReport(*output, reports, write); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+		return exitError
+	}
+	if *junitPath != "" {
+		if err := writeReport(*junitPath, reports, suite.WriteJUnit); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write JUnit report: %v\n", err)
+			return exitError
+		}
+	}
+
+	for _, r := range reports {
+		if r.HasFailures() {
+			return exitFailed
+		}
+	}
+	return 0
+}
+
+// validateSuites 执行validate子命令, 逐个校验套件文件
+func validateSuites(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: mat validate suite.yaml...")
+		return exitUsage
+	}
+	code := 0
+	for _, path := range args {
+		s, err := suite.Load(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = exitError
+			continue
+		}
+		fmt.Printf("Suite %s (%s) is valid\n", s.Name, path)
+	}
+	return code
+}
+
+// describeSuite 描述套件的用例来源, 数据集用例在执行端加载, 数量此时未知
+func describeSuite(s *suite.Suite) string {
+	if s.DatasetID == 0 {
+		return fmt.Sprintf("%d cases", len(s.Cases))
+	}
+	desc := fmt.Sprintf("dataset %d", s.DatasetID)
+	if s.DatasetVersion != 0 {
+		desc += fmt.Sprintf(" v%d", s.DatasetVersion)
+	}
+	return desc
+}
+
+// writers 按输出格式索引的报告输出函数
+var writers = map[string]func(io.Writer, []*suite.Report) error{
+	"table": suite.WriteTable,
+	"json":  suite.WriteJSON,
+	"junit": suite.WriteJUnit,
+}
+
+// loadSuites 加载全部套件文件, 任一文件不合法时不执行
+func loadSuites(paths []string) ([]*suite.Suite, error) {
+	suites := make([]*suite.Suite, 0, len(paths))
+	for _, path := range paths {
+		s, err := suite.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		suites = append(suites, s)
+	}
+	return suites, nil
+}
+
+// writeReport 将报告写入文件, path为空时写入标准输出
+func writeReport(path string, reports []*suite.Report, write func(io.Writer, []*suite.Report) error) error {
+	if path == "" {
+		return write(os.Stdout, reports)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f, reports); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
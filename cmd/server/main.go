@@ -2,32 +2,57 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/multi-agent-testing/backend/internal/api/router"
 	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/repository"
 	"github.com/multi-agent-testing/backend/pkg/logger"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 var (
-	configPath = flag.String("config", "configs/config.yaml", "配置文件路径")
+	configPath  = flag.String("config", "configs/config.yaml", "配置文件路径")
+	profile     = flag.String("profile", "", "配置profile, 叠加同目录下的config.<profile>.yaml, 未指定时读取APP_PROFILE环境变量")
+	checkConfig = flag.Bool("check-config", false, "校验配置文件后退出")
 )
 
 func main() {
 	flag.Parse()
+	if *profile == "" {
+		*profile = os.Getenv(config.ProfileEnv)
+	}
+
+	// 输出生效的配置, 配置不完整时也可执行
+	if flag.Arg(0) == "show-config" {
+		os.Exit(runShowConfig(flag.Args()[1:]))
+	}
 
 	// 1. 加载配置
-	cfg, err := config.Load(*configPath)
+	// 子命令不调用模型, 仅启动服务及校验配置时要求模型api_key中的引用可解析
+	cfg, err := config.Load(*configPath, *profile)
+	if err == nil && (*checkConfig || flag.NArg() == 0) {
+		err = cfg.ResolveModelKeys()
+	}
+	if err == nil && (*checkConfig || flag.NArg() == 0) {
+		err = cfg.Validate()
+	}
 	if err != nil {
-		fmt.Printf("Failed to load config: %v\n", err)
+		printConfigError(err)
 		os.Exit(1)
 	}
+	if *checkConfig {
+		fmt.Printf("Config %s is valid\n", strings.Join(config.Files(), " + "))
+		return
+	}
 
 	// 2. 初始化日志
 	if err := logger.Init(cfg.Log.Level, cfg.Log.Format, cfg.Log.Output); err != nil {
@@ -36,13 +61,33 @@ func main() {
 	}
 	defer logger.Sync()
 
+	// 子命令
+	switch flag.Arg(0) {
+	case "migrate":
+		os.Exit(runMigrate(cfg, flag.Args()[1:]))
+	case "rotate-keys":
+		os.Exit(runRotateKeys(cfg))
+	case "regression":
+		os.Exit(runRegression(cfg, flag.Args()[1:]))
+	case "report":
+		os.Exit(runReport(cfg, flag.Args()[1:]))
+	}
+
 	logger.Info("Starting Multi-Agent Testing Platform",
 		zap.String("version", "0.1.0"),
 		zap.String("mode", cfg.Server.Mode),
+		zap.String("profile", *profile),
+		zap.Strings("config_files", config.Files()),
 	)
 
-	// 3. 初始化数据库 (TODO)
-	// initDB(cfg)
+	// 3. 初始化数据库
+	db, err := initDB(cfg)
+	if err != nil {
+		logger.Fatal("Failed to init database", zap.Error(err))
+	}
+	if db != nil {
+		defer repository.Close(db)
+	}
 
 	// 4. 初始化aggo客户端 (TODO)
 	// initAggoClients(cfg)
@@ -53,23 +98,91 @@ func main() {
 	)
 
 	// 6. 注册路由
-	router.Setup(h, cfg)
+	router.Setup(h, cfg, db)
 
-	// 7. 启动服务器
-	go func() {
-		logger.Info("Server starting", zap.String("addr", cfg.Server.GetAddr()))
-		h.Spin()
-	}()
+	// 配置热加载: 日志级别立即生效, 模型提供者由路由中注册的回调更新
+	config.OnChange(func(old, new *config.Config) {
+		if old.Log.Level == new.Log.Level {
+			return
+		}
+		if err := logger.SetLevel(new.Log.Level); err != nil {
+			logger.Error("Failed to change log level", zap.Error(err))
+			return
+		}
+		logger.Info("Log level changed", zap.String("from", old.Log.Level), zap.String("to", new.Log.Level))
+	})
+	if err := config.Watch(); err != nil {
+		logger.Error("Config hot reload is unavailable", zap.Error(err))
+	}
 
-	// 8. 优雅关闭
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	// 7. 启动服务器, 收到SIGINT/SIGTERM时优雅关闭
+	// Hertz默认收到SIGTERM时立即退出, 不执行OnShutdown回调, 任务来不及保存进度
+	h.SetCustomSignalWaiter(func(errCh chan error) error {
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		select {
+		case sig := <-quit:
+			logger.Info("Shutting down server...", zap.String("signal", sig.String()))
+			return nil
+		case err := <-errCh:
+			return err
+		}
+	})
+	logger.Info("Server starting", zap.String("addr", cfg.Server.GetAddr()))
+	h.Spin()
 
-	logger.Info("Shutting down server...")
-	if err := h.Shutdown(context.Background()); err != nil {
-		logger.Error("Server forced to shutdown", zap.Error(err))
+	logger.Info("Server exited")
+}
+
+// printConfigError 输出配置错误, 校验错误逐项输出
+func printConfigError(err error) {
+	var verr *config.ValidationError
+	if !errors.As(err, &verr) {
+		fmt.Printf("Failed to load config: %v\n", err)
+		return
 	}
+	fmt.Printf("Invalid config %s:\n", strings.Join(config.Files(), " + "))
+	for _, p := range verr.Problems {
+		fmt.Printf("  - %s\n", p)
+	}
+}
 
-	logger.Info("Server exited")
-}
\ No newline at end of file
+// initDB 初始化数据库, 未启用时返回nil
+func initDB(cfg *config.Config) (*gorm.DB, error) {
+	if !cfg.Database.Enabled {
+		logger.Info("Database is disabled, persistence features are unavailable")
+		return nil, nil
+	}
+
+	db, err := repository.NewDB(&cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := repository.NewMigrator(db, &cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Database.AutoMigrate {
+		applied, err := migrator.Up(context.Background(), 0)
+		for _, m := range applied {
+			logger.Info("Migration applied", zap.Int64("version", m.Version), zap.String("name", m.Name))
+		}
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		pending, err := migrator.Pending(context.Background(), 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(pending) > 0 {
+			logger.Warn("Database has pending migrations, run the migrate subcommand",
+				zap.Int("pending_count", len(pending)),
+			)
+		}
+	}
+
+	logger.Info("Database initialized", zap.String("type", cfg.Database.Type))
+	return db, nil
+}
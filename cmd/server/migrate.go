@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/repository/migrations"
+)
+
+const migrateUsage = `Usage: server [-config path] migrate <status|up|down> [-steps N] [-dry-run]
+
+  status    列出全部迁移及执行状态
+  up        执行待执行的迁移, 默认全部
+  down      回滚最近执行的迁移, 默认1个
+`
+
+// runMigrate 执行migrate子命令, 返回进程退出码
+func runMigrate(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+	action := args[0]
+
+	fs := flag.NewFlagSet("migrate "+action, flag.ContinueOnError)
+	steps := fs.Int("steps", 0, "执行或回滚的迁移数量")
+	dryRun := fs.Bool("dry-run", false, "仅打印将要执行的SQL")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	db, err := repository.NewDB(&cfg.Database)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open database: %v\n", err)
+		return 1
+	}
+	defer repository.Close(db)
+
+	migrator, err := repository.NewMigrator(db, &cfg.Database)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load migrations: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	switch action {
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to get migration status: %v\n", err)
+			return 1
+		}
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = "applied at " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-32s %s\n", s.Version, s.Name, appliedAt)
+		}
+		return 0
+
+	case "up":
+		if *dryRun {
+			pending, err := migrator.Pending(ctx, *steps)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to list pending migrations: %v\n", err)
+				return 1
+			}
+			printScripts(pending, true)
+			return 0
+		}
+		applied, err := migrator.Up(ctx, *steps)
+		for _, m := range applied {
+			fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+		return 0
+
+	case "down":
+		if *steps <= 0 {
+			*steps = 1
+		}
+		if *dryRun {
+			rollback, err := migrator.Applied(ctx, *steps)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to list applied migrations: %v\n", err)
+				return 1
+			}
+			printScripts(rollback, false)
+			return 0
+		}
+		reverted, err := migrator.Down(ctx, *steps)
+		for _, m := range reverted {
+			fmt.Printf("Reverted %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		if len(reverted) == 0 {
+			fmt.Println("No applied migrations")
+		}
+		return 0
+
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+}
+
+// printScripts 打印迁移SQL
+func printScripts(list []migrations.Migration, up bool) {
+	if len(list) == 0 {
+		fmt.Println("-- nothing to do")
+		return
+	}
+	for _, m := range list {
+		script, direction := m.Down, "down"
+		if up {
+			script, direction = m.Up, "up"
+		}
+		fmt.Printf("-- %04d_%s (%s)\n", m.Version, m.Name, direction)
+		for _, stmt := range migrations.SplitStatements(script) {
+			fmt.Println(stmt)
+		}
+		fmt.Println()
+	}
+}
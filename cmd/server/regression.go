@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/service"
+)
+
+// exitRegressed 回归检查未通过时的退出码, 与执行出错(1)及参数错误(2)区分
+const exitRegressed = 3
+
+// runRegression 执行regression子命令, 对比批量任务与基线任务, 返回进程退出码
+// 检查通过时返回0, 未通过时返回exitRegressed, 可用于CI门禁
+func runRegression(cfg *config.Config, args []string) int {
+	fs := flag.NewFlagSet("regression", flag.ContinueOnError)
+	runID := fs.String("run", "", "本次批量任务ID")
+	baselineID := fs.String("baseline", "", "基线批量任务ID")
+	modelName := fs.String("model", "", "只对比本次运行中的该模型, 需同时指定-baseline-model")
+	baselineModel := fs.String("baseline-model", "", "基线运行中对应的模型")
+	scoreThreshold := fs.Float64("score-threshold", 0, "归一化分数变化超过该值视为改进或退化")
+	maxRegressed := fs.Int("max-regressed", 0, "每个模型允许退化的用例数")
+	maxPassRateDrop := fs.Float64("max-pass-rate-drop", 0, "允许的通过率下降(0-1)")
+	maxLatencyIncrease := fs.Float64("max-latency-increase", 0, "允许的平均响应时间增幅比例, 0表示不检查")
+	maxCostIncrease := fs.Float64("max-cost-increase", 0, "允许的总费用增幅比例, 0表示不检查")
+	asJSON := fs.Bool("json", false, "以JSON格式输出报告")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *runID == "" || *baselineID == "" {
+		fmt.Fprintln(os.Stderr, "Usage: server [-config path] regression -run <job id> -baseline <job id> [flags]")
+		fs.PrintDefaults()
+		return 2
+	}
+	if !cfg.Database.Enabled || cfg.Job.Store == "memory" {
+		fmt.Fprintln(os.Stderr, "Regression requires jobs stored in the database, enable database and set job.store to database")
+		return 1
+	}
+
+	// 仅覆盖命令行中显式指定的阈值, 其余使用配置文件
+	thresholds := &model.RegressionThresholds{}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "score-threshold":
+			thresholds.ScoreThreshold = scoreThreshold
+		case "max-regressed":
+			thresholds.MaxRegressed = maxRegressed
+		case "max-pass-rate-drop":
+			thresholds.MaxPassRateDrop = maxPassRateDrop
+		case "max-latency-increase":
+			thresholds.MaxLatencyIncrease = maxLatencyIncrease
+		case "max-cost-increase":
+			thresholds.MaxCostIncrease = maxCostIncrease
+		}
+	})
+
+	db, err := repository.NewDB(&cfg.Database)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open database: %v\n", err)
+		return 1
+	}
+	defer repository.Close(db)
+
+	regression := service.NewRegressionService(repository.NewGormJobStore(db), cfg.Regression)
+	report, err := regression.Compare(context.Background(), &model.RegressionRequest{
+		RunID:         *runID,
+		BaselineID:    *baselineID,
+		Model:         *modelName,
+		BaselineModel: *baselineModel,
+		Thresholds:    thresholds,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to compare runs: %v\n", err)
+		return 1
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to encode report: %v\n", err)
+			return 1
+		}
+	} else if err := printRegression(report); err != nil {
+		return 1
+	}
+
+	if report.Verdict != model.VerdictPass {
+		return exitRegressed
+	}
+	return 0
+}
+
+// printRegression 以表格输出回归报告, 列出退化的用例
+func printRegression(report *model.RegressionReport) error {
+	fmt.Printf("Run %s vs baseline %s\n\n", report.RunID, report.BaselineID)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tBASELINE\tIMPROVED\tREGRESSED\tUNCHANGED\tMISSING\tPASS RATE\tLATENCY\tCOST")
+	for _, m := range report.Models {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%s -> %s\t%dms -> %dms\t%s\n",
+			m.Model, m.BaselineModel, m.Improved, m.Regressed, m.Unchanged, m.Missing,
+			formatRatio(m.BaselinePassRate), formatRatio(m.PassRate),
+			m.BaselineAvgResponseTime, m.AvgResponseTime, formatChange(m.CostChange))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, m := range report.Models {
+		for _, c := range m.Cases {
+			if c.Status != model.RegressionRegressed {
+				continue
+			}
+			label := fmt.Sprintf("case %d", c.CaseIndex+1)
+			if c.CaseID != "" {
+				label += " (" + c.CaseID + ")"
+			}
+			fmt.Printf("  regressed: %s %s: %s\n", m.Model, label, c.Reason)
+		}
+	}
+
+	fmt.Printf("\nVerdict: %s\n", report.Verdict)
+	for _, reason := range report.Reasons {
+		fmt.Printf("  - %s\n", reason)
+	}
+	return nil
+}
+
+// formatRatio 以百分比输出比例, 为空时输出"-"
+func formatRatio(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", *v*100)
+}
+
+// formatChange 以带符号的百分比输出变化比例, 为空时输出"-"
+func formatChange(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f%%", *v*100)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/report"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/service"
+)
+
+// runReport 执行report子命令, 将一次或多次运行的结果导出为报告, 返回进程退出码
+func runReport(cfg *config.Config, args []string) int {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	format := fs.String("format", report.FormatHTML, "报告格式: json/"+strings.Join(report.Formats, "/"))
+	output := fs.String("o", "", "输出文件, 为空时输出到标准输出")
+	title := fs.String("title", "", "报告标题, 为空时使用运行列表")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: server [-config path] report [flags] <run_type>:<run_id>...")
+		fmt.Fprintln(os.Stderr, "  run_type is record or job, e.g. report -format html -o report.html job:<id> record:12")
+		fs.PrintDefaults()
+		return 2
+	}
+	if *format != "json" && !report.ValidFormat(*format) {
+		fmt.Fprintf(os.Stderr, "Unknown format %q\n", *format)
+		return 2
+	}
+	refs, err := service.ParseRunRefs(fs.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if !cfg.Database.Enabled {
+		fmt.Fprintln(os.Stderr, "Report requires the database to be enabled")
+		return 1
+	}
+
+	db, err := repository.NewDB(&cfg.Database)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open database: %v\n", err)
+		return 1
+	}
+	defer repository.Close(db)
+
+	reports := service.NewReportService(repository.NewTestRecordRepository(db), repository.NewGormJobStore(db))
+	result, err := reports.Build(context.Background(), refs, *title)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to build report: %v\n", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create %s: %v\n", *output, err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	if *format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(result)
+	} else {
+		err = report.Write(w, result, *format)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+		return 1
+	}
+	if *output != "" {
+		fmt.Fprintf(os.Stderr, "Report written to %s\n", *output)
+	}
+	return 0
+}
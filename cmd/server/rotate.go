@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/secret"
+	"github.com/multi-agent-testing/backend/internal/service"
+)
+
+// runRotateKeys 执行rotate-keys子命令, 使用当前主密钥重新加密数据库中的API Key, 返回进程退出码
+func runRotateKeys(cfg *config.Config) int {
+	keyring, err := secret.Load(cfg.Security.MasterKeyEnv, cfg.Security.MasterKeyFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load master key: %v\n", err)
+		return 1
+	}
+	if keyring == nil {
+		fmt.Fprintf(os.Stderr, "Master key is not configured, set %s or security.master_key_file\n", cfg.Security.MasterKeyEnv)
+		return 1
+	}
+
+	db, err := repository.NewDB(&cfg.Database)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open database: %v\n", err)
+		return 1
+	}
+	defer repository.Close(db)
+
+	rotated, err := service.RotateAPIKeys(context.Background(), repository.NewModelConfigRepository(db), keyring)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to rotate API keys after %d updates: %v\n", rotated, err)
+		return 1
+	}
+	fmt.Printf("Rotated %d API keys to master key %s\n", rotated, keyring.PrimaryID())
+	return 0
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/multi-agent-testing/backend/internal/config"
+)
+
+// runShowConfig 执行show-config子命令, 输出合并后生效的配置及每项的来源, 敏感字段脱敏
+// 不校验配置, 便于排查配置错误
+func runShowConfig(args []string) int {
+	fs := flag.NewFlagSet("show-config", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "以JSON格式输出")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	settings, err := config.Inspect(*configPath, *profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		return 1
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(settings); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to encode config: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Printf("# layers: %s < env\n", strings.Join(config.Files(), " < "))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, s := range settings {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, s.Value, s.Source)
+	}
+	if err := w.Flush(); err != nil {
+		return 1
+	}
+	return 0
+}
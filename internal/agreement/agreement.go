@@ -0,0 +1,82 @@
+// Package agreement 计算多名标注者之间的一致性(Krippendorff's alpha及两两一致率)
+package agreement
+
+// Metric 两个取值之间的差异, 相同取值的差异为0
+type Metric func(a, b float64) float64
+
+// Interval 区间尺度差异, 适用于数值评分
+func Interval(a, b float64) float64 {
+	d := a - b
+	return d * d
+}
+
+// Nominal 名义尺度差异, 适用于标签及是/否类取值
+func Nominal(a, b float64) float64 {
+	if a == b {
+		return 0
+	}
+	return 1
+}
+
+// Result 一致性统计
+type Result struct {
+	Alpha            *float64 // Krippendorff's alpha, 可配对取值不足或全部取值相同时为空
+	PercentAgreement *float64 // 同一单元内取值相同的标注者对占比
+	Units            int      // 参与计算(至少两个取值)的单元数
+	Values           int      // 参与计算的取值数
+	Pairs            int      // 同一单元内的标注者对数
+}
+
+// Compute 计算一致性, units为各单元(被标注的对象)中各标注者给出的取值
+// 取值少于两个的单元无法配对, 不参与计算
+func Compute(units [][]float64, metric Metric) Result {
+	var (
+		result   Result
+		pooled   []float64
+		observed float64
+		agreed   int
+	)
+	for _, values := range units {
+		m := len(values)
+		if m < 2 {
+			continue
+		}
+		result.Units++
+		pooled = append(pooled, values...)
+
+		var sum float64
+		for i := 0; i < m; i++ {
+			for j := i + 1; j < m; j++ {
+				d := metric(values[i], values[j])
+				sum += 2 * d
+				result.Pairs++
+				if d == 0 {
+					agreed++
+				}
+			}
+		}
+		observed += sum / float64(m-1)
+	}
+
+	n := len(pooled)
+	result.Values = n
+	if result.Pairs == 0 {
+		return result
+	}
+	percent := float64(agreed) / float64(result.Pairs)
+	result.PercentAgreement = &percent
+
+	var expected float64
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			expected += 2 * metric(pooled[i], pooled[j])
+		}
+	}
+	expected /= float64(n) * float64(n-1)
+	if expected == 0 {
+		return result
+	}
+	alpha := 1 - observed/float64(n)/expected
+	result.Alpha = &alpha
+	return result
+}
@@ -0,0 +1,139 @@
+package agreement
+
+import (
+	"math"
+	"testing"
+)
+
+// reliabilityData Krippendorff(2011) "Computing Krippendorff's Alpha-Reliability"中4名标注者对12个单元的示例数据
+// 按单元列出已有的取值, 最后一个单元只有一个取值; 文中给出nominal alpha=0.743, interval alpha=0.849
+var reliabilityData = [][]float64{
+	{1, 1, 1},
+	{2, 2, 3, 2},
+	{3, 3, 3, 3},
+	{3, 3, 3, 3},
+	{2, 2, 2, 2},
+	{1, 2, 3, 4},
+	{4, 4, 4, 4},
+	{1, 1, 2, 1},
+	{2, 2, 2, 2},
+	{5, 5, 5},
+	{1, 1},
+	{3},
+}
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name        string
+		units       [][]float64
+		metric      Metric
+		wantAlpha   *float64
+		wantPercent *float64
+		wantUnits   int
+		wantValues  int
+		wantPairs   int
+	}{
+		{
+			name:        "published nominal",
+			units:       reliabilityData,
+			metric:      Nominal,
+			wantAlpha:   ptr(0.743),
+			wantPercent: ptr(43.0 / 55),
+			wantUnits:   11,
+			wantValues:  40,
+			wantPairs:   55,
+		},
+		{
+			name:        "published interval",
+			units:       reliabilityData,
+			metric:      Interval,
+			wantAlpha:   ptr(0.849),
+			wantPercent: ptr(43.0 / 55),
+			wantUnits:   11,
+			wantValues:  40,
+			wantPairs:   55,
+		},
+		{
+			name:        "systematic disagreement",
+			units:       [][]float64{{0, 1}, {1, 0}},
+			metric:      Nominal,
+			wantAlpha:   ptr(-0.5),
+			wantPercent: ptr(0.0),
+			wantUnits:   2,
+			wantValues:  4,
+			wantPairs:   2,
+		},
+		{
+			name:      "single rater",
+			units:     [][]float64{{1}, {2}, {3}},
+			metric:    Interval,
+			wantUnits: 0,
+		},
+		{
+			name:        "all values identical",
+			units:       [][]float64{{4, 4}, {4, 4, 4}},
+			metric:      Interval,
+			wantPercent: ptr(1.0),
+			wantUnits:   2,
+			wantValues:  5,
+			wantPairs:   4,
+		},
+		{
+			name:        "units with one rating are skipped",
+			units:       [][]float64{{1, 1}, {2, 2}, {5}, {}},
+			metric:      Nominal,
+			wantAlpha:   ptr(1.0),
+			wantPercent: ptr(1.0),
+			wantUnits:   2,
+			wantValues:  4,
+			wantPairs:   2,
+		},
+		{
+			name:      "no units",
+			metric:    Nominal,
+			wantUnits: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compute(tt.units, tt.metric)
+			if !near(got.Alpha, tt.wantAlpha, 5e-4) {
+				t.Fatalf("Alpha = %v, want %v", deref(got.Alpha), deref(tt.wantAlpha))
+			}
+			if !near(got.PercentAgreement, tt.wantPercent, 1e-9) {
+				t.Fatalf("PercentAgreement = %v, want %v", deref(got.PercentAgreement), deref(tt.wantPercent))
+			}
+			if got.Units != tt.wantUnits || got.Values != tt.wantValues || got.Pairs != tt.wantPairs {
+				t.Fatalf("units/values/pairs = %d/%d/%d, want %d/%d/%d",
+					got.Units, got.Values, got.Pairs, tt.wantUnits, tt.wantValues, tt.wantPairs)
+			}
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	if Nominal(2, 2) != 0 || Nominal(1, 2) != 1 || Nominal(1, 5) != 1 {
+		t.Fatal("Nominal should be 0 for equal values and 1 otherwise")
+	}
+	if Interval(3, 3) != 0 || Interval(1, 3) != 4 || Interval(3, 1) != 4 {
+		t.Fatal("Interval should be the squared difference")
+	}
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func deref(p *float64) interface{} {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+func near(got, want *float64, tolerance float64) bool {
+	if got == nil || want == nil {
+		return got == want
+	}
+	return math.Abs(*got-*want) <= tolerance
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/service"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// AnnotationHandler 人工标注处理器
+type AnnotationHandler struct {
+	service *service.AnnotationService
+}
+
+// NewAnnotationHandler 创建人工标注处理器
+func NewAnnotationHandler(service *service.AnnotationService) *AnnotationHandler {
+	return &AnnotationHandler{
+		service: service,
+	}
+}
+
+// SaveAnnotation 保存标注, 同一标注者对同一响应重复提交时覆盖原标注
+func (h *AnnotationHandler) SaveAnnotation(ctx context.Context, c *app.RequestContext) {
+	var req model.AnnotationRequest
+	if err := c.BindJSON(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	annotation, err := h.service.Save(ctx, &req)
+	if err != nil {
+		writeAnnotationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(annotation))
+}
+
+// ListAnnotations 按条件分页查询标注
+// 支持run_type、run_id、provider、model、annotator、tag过滤
+func (h *AnnotationHandler) ListAnnotations(ctx context.Context, c *app.RequestContext) {
+	page, pageSize, offset := parsePagination(c)
+
+	filter, ok := parseAnnotationFilter(c)
+	if !ok {
+		return
+	}
+	filter.Annotator = c.Query("annotator")
+	filter.Offset, filter.Limit = offset, pageSize
+
+	annotations, total, err := h.service.List(ctx, filter)
+	if err != nil {
+		logger.Error("Failed to list annotations", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(model.PageResult{
+		Items:    annotations,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}))
+}
+
+// GetAnnotation 获取标注详情
+func (h *AnnotationHandler) GetAnnotation(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	annotation, err := h.service.Get(ctx, id)
+	if err != nil {
+		writeRepositoryError(c, "Annotation", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(annotation))
+}
+
+// DeleteAnnotation 删除标注
+func (h *AnnotationHandler) DeleteAnnotation(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(ctx, id); err != nil {
+		writeRepositoryError(c, "Annotation", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(nil))
+}
+
+// GetAgreement 计算标注者之间的一致性
+// 支持run_type、run_id、provider、model、tag过滤
+func (h *AnnotationHandler) GetAgreement(ctx context.Context, c *app.RequestContext) {
+	filter, ok := parseAnnotationFilter(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Agreement(ctx, filter)
+	if err != nil {
+		logger.Error("Failed to compute annotation agreement", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(result))
+}
+
+// ExportAnnotations 导出运行结果及其标注, format为json(默认)或csv
+func (h *AnnotationHandler) ExportAnnotations(ctx context.Context, c *app.RequestContext) {
+	runType, runID := c.Query("run_type"), c.Query("run_id")
+	if runType == "" || runID == "" {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "run_type and run_id are required"))
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid format parameter"))
+		return
+	}
+
+	export, err := h.service.Export(ctx, runType, runID)
+	if err != nil {
+		writeAnnotationError(c, err)
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, model.NewSuccessResponse(export))
+		return
+	}
+	var buf bytes.Buffer
+	if err := service.WriteAnnotationCSV(&buf, export); err != nil {
+		logger.Error("Failed to write annotation export", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, err.Error()))
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=annotations-%s-%s.csv", runType, runID))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// parseAnnotationFilter 解析标注列表及一致性统计共用的过滤参数, 失败时直接输出错误
+func parseAnnotationFilter(c *app.RequestContext) (repository.AnnotationFilter, bool) {
+	filter := repository.AnnotationFilter{
+		RunType:  c.Query("run_type"),
+		RunID:    c.Query("run_id"),
+		Provider: c.Query("provider"),
+		Model:    c.Query("model"),
+		Tag:      c.Query("tag"),
+	}
+	switch filter.RunType {
+	case "", model.RunTypeRecord, model.RunTypeJob:
+	default:
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid run_type parameter"))
+		return filter, false
+	}
+	return filter, true
+}
+
+// writeAnnotationError 输出标注操作错误, 被标注的运行不存在时返回404
+func writeAnnotationError(c *app.RequestContext, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidAnnotation):
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+	case errors.Is(err, repository.ErrDuplicate):
+		c.JSON(http.StatusConflict, model.NewErrorResponse(409, "Annotation is being saved concurrently, please retry"))
+	default:
+		writeRepositoryError(c, "Run", err)
+	}
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/service"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ArenaHandler 盲测处理器, 对战由测试接口的blind模式创建
+type ArenaHandler struct {
+	service *service.ArenaService
+}
+
+// NewArenaHandler 创建盲测处理器
+func NewArenaHandler(service *service.ArenaService) *ArenaHandler {
+	return &ArenaHandler{
+		service: service,
+	}
+}
+
+// GetBattle 获取对战, 投票前不包含模型身份
+func (h *ArenaHandler) GetBattle(ctx context.Context, c *app.RequestContext) {
+	session, err := h.service.Get(ctx, c.Param("id"))
+	if err != nil {
+		writeArenaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(session))
+}
+
+// Vote 提交投票并揭晓模型身份
+func (h *ArenaHandler) Vote(ctx context.Context, c *app.RequestContext) {
+	var req model.ArenaVoteRequest
+	if err := c.BindJSON(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	session, err := h.service.Vote(ctx, c.Param("id"), &req)
+	if err != nil {
+		writeArenaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(session))
+}
+
+// writeArenaError 输出盲测操作错误
+func writeArenaError(c *app.RequestContext, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidArena):
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+	case errors.Is(err, service.ErrArenaModelFailed):
+		c.JSON(http.StatusBadGateway, model.NewErrorResponse(502, err.Error()))
+	case errors.Is(err, repository.ErrConflict):
+		c.JSON(http.StatusConflict, model.NewErrorResponse(409, "Battle already voted"))
+	default:
+		writeRepositoryError(c, "Battle", err)
+	}
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/service"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ComparisonHandler 成对对比及排行榜处理器
+type ComparisonHandler struct {
+	service *service.ComparisonService
+}
+
+// NewComparisonHandler 创建成对对比处理器
+func NewComparisonHandler(service *service.ComparisonService) *ComparisonHandler {
+	return &ComparisonHandler{
+		service: service,
+	}
+}
+
+// Compare 调用各模型并由评审模型两两对比输出
+func (h *ComparisonHandler) Compare(ctx context.Context, c *app.RequestContext) {
+	var req model.CompareRequest
+	if err := c.BindJSON(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	result, err := h.service.Compare(ctx, &req)
+	if err != nil {
+		writeComparisonError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(result))
+}
+
+// Vote 保存人工投票
+func (h *ComparisonHandler) Vote(ctx context.Context, c *app.RequestContext) {
+	var req model.VoteRequest
+	if err := c.BindJSON(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	comparison, err := h.service.Vote(ctx, &req)
+	if err != nil {
+		writeComparisonError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(comparison))
+}
+
+// ListComparisons 按条件分页查询成对对比
+// 支持tag、dataset_id、source、provider、model、start、end过滤
+func (h *ComparisonHandler) ListComparisons(ctx context.Context, c *app.RequestContext) {
+	page, pageSize, offset := parsePagination(c)
+
+	filter, ok := parseComparisonFilter(c)
+	if !ok {
+		return
+	}
+	filter.Provider = c.Query("provider")
+	filter.Model = c.Query("model")
+	filter.Offset, filter.Limit = offset, pageSize
+
+	comparisons, total, err := h.service.List(ctx, filter)
+	if err != nil {
+		logger.Error("Failed to list comparisons", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(model.PageResult{
+		Items:    comparisons,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}))
+}
+
+// GetComparison 获取成对对比详情
+func (h *ComparisonHandler) GetComparison(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	comparison, err := h.service.Get(ctx, id)
+	if err != nil {
+		writeRepositoryError(c, "Comparison", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(comparison))
+}
+
+// DeleteComparison 删除成对对比, 排行榜随之更新
+func (h *ComparisonHandler) DeleteComparison(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(ctx, id); err != nil {
+		writeRepositoryError(c, "Comparison", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(nil))
+}
+
+// GetLeaderboard 根据成对对比计算排行榜
+// 支持tag、dataset_id、source、start、end过滤, method为bradley_terry(默认)或elo, rounds为自助法重采样次数
+func (h *ComparisonHandler) GetLeaderboard(ctx context.Context, c *app.RequestContext) {
+	filter, ok := parseComparisonFilter(c)
+	if !ok {
+		return
+	}
+	rounds := 0
+	if v := c.Query("rounds"); v != "" {
+		var err error
+		if rounds, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid rounds parameter"))
+			return
+		}
+	}
+
+	board, err := h.service.Leaderboard(ctx, filter, c.Query("method"), rounds)
+	if err != nil {
+		writeComparisonError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(board))
+}
+
+// parseComparisonFilter 解析排行榜及对比列表共用的过滤参数, 失败时直接输出错误
+func parseComparisonFilter(c *app.RequestContext) (repository.ComparisonFilter, bool) {
+	filter := repository.ComparisonFilter{
+		Tag:    c.Query("tag"),
+		Source: c.Query("source"),
+	}
+	switch filter.Source {
+	case "", model.ComparisonSourceJudge, model.ComparisonSourceHuman, model.ComparisonSourceArena:
+	default:
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid source parameter"))
+		return filter, false
+	}
+
+	var err error
+	if v := c.Query("dataset_id"); v != "" {
+		if filter.DatasetID, err = strconv.ParseUint(v, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid dataset_id parameter"))
+			return filter, false
+		}
+	}
+	if filter.StartTime, err = parseTimeQuery(c.Query("start"), false); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+		return filter, false
+	}
+	if filter.EndTime, err = parseTimeQuery(c.Query("end"), true); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+		return filter, false
+	}
+	return filter, true
+}
+
+// writeComparisonError 输出成对对比操作错误
+func writeComparisonError(c *app.RequestContext, err error) {
+	if errors.Is(err, service.ErrInvalidComparison) {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+		return
+	}
+	writeRepositoryError(c, "Comparison", err)
+}
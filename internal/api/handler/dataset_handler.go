@@ -0,0 +1,403 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/multi-agent-testing/backend/internal/dataset"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/service"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// DatasetHandler 数据集处理器
+type DatasetHandler struct {
+	service *service.DatasetService
+}
+
+// NewDatasetHandler 创建数据集处理器
+func NewDatasetHandler(service *service.DatasetService) *DatasetHandler {
+	return &DatasetHandler{
+		service: service,
+	}
+}
+
+// CreateDataset 创建数据集
+func (h *DatasetHandler) CreateDataset(ctx context.Context, c *app.RequestContext) {
+	var req model.SaveDatasetRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	ds, err := h.service.Create(ctx, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(ds))
+}
+
+// UploadDataset 上传文件导入数据集
+// 表单字段: file, name, description, format(为空时按扩展名推断), mapping(字段映射JSON),
+// dry_run(只校验), skip_invalid(跳过不合法的行); 存在不合法的行时返回400及逐行问题
+func (h *DatasetHandler) UploadDataset(ctx context.Context, c *app.RequestContext) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Dataset file is required"))
+		return
+	}
+	req := &service.ImportDatasetRequest{
+		Name:        string(c.FormValue("name")),
+		Description: string(c.FormValue("description")),
+		Filename:    fileHeader.Filename,
+	}
+	if req.Options, err = parseImportOptions(c); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+		return
+	}
+	for name, target := range map[string]*bool{"dry_run": &req.DryRun, "skip_invalid": &req.SkipInvalid} {
+		if v := string(c.FormValue(name)); v != "" {
+			if *target, err = strconv.ParseBool(v); err != nil {
+				c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid "+name+" field"))
+				return
+			}
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Failed to open dataset file"))
+		return
+	}
+	defer file.Close()
+
+	imported, err := h.service.Import(ctx, req, file)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDataset) {
+			c.JSON(http.StatusBadRequest, &model.Response{Code: 400, Message: err.Error(), Data: imported})
+			return
+		}
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(imported))
+}
+
+// parseImportOptions 解析上传数据集文件时的format及mapping表单字段
+func parseImportOptions(c *app.RequestContext) (dataset.Options, error) {
+	opts := dataset.Options{Format: string(c.FormValue("format"))}
+	if opts.Format != "" && !slices.Contains(dataset.Formats, opts.Format) {
+		return opts, fmt.Errorf("format must be one of %s", strings.Join(dataset.Formats, ", "))
+	}
+	if v := c.FormValue("mapping"); len(v) > 0 {
+		if err := json.Unmarshal(v, &opts.Mapping); err != nil {
+			return opts, errors.New("Invalid mapping field")
+		}
+	}
+	return opts, nil
+}
+
+// ListDatasets 分页查询数据集
+func (h *DatasetHandler) ListDatasets(ctx context.Context, c *app.RequestContext) {
+	page, pageSize, offset := parsePagination(c)
+
+	datasets, total, err := h.service.List(ctx, offset, pageSize)
+	if err != nil {
+		logger.Error("Failed to list datasets", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(model.PageResult{
+		Items:    datasets,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}))
+}
+
+// GetDataset 获取数据集详情, 用例为工作副本, 支持split、tag过滤
+func (h *DatasetHandler) GetDataset(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	filter, ok := parseCaseFilter(c)
+	if !ok {
+		return
+	}
+
+	detail, err := h.service.Get(ctx, id, filter)
+	if err != nil {
+		writeRepositoryError(c, "Dataset", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(detail))
+}
+
+// parseCaseFilter 解析用例过滤参数, 失败时直接输出错误
+func parseCaseFilter(c *app.RequestContext) (repository.CaseFilter, bool) {
+	filter := repository.CaseFilter{Split: c.Query("split")}
+	if filter.Split != "" && !slices.Contains(model.Splits, filter.Split) {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid split parameter"))
+		return filter, false
+	}
+	if tag := c.Query("tag"); tag != "" {
+		filter.Tags = []string{tag}
+	}
+	return filter, true
+}
+
+// DeleteDataset 删除数据集
+func (h *DatasetHandler) DeleteDataset(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(ctx, id); err != nil {
+		writeRepositoryError(c, "Dataset", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(nil))
+}
+
+// AppendCases 向数据集追加用例
+func (h *DatasetHandler) AppendCases(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	var req model.AppendCasesRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	ds, err := h.service.AppendCases(ctx, id, &req)
+	if err != nil {
+		writeDatasetError(c, "Dataset", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(ds))
+}
+
+// UpdateCase 修改数据集用例, 请求体为完整的用例
+func (h *DatasetHandler) UpdateCase(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	caseID, ok := parseCaseIDParam(c)
+	if !ok {
+		return
+	}
+	var req model.TestCase
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	row, err := h.service.UpdateCase(ctx, id, caseID, req)
+	if err != nil {
+		writeDatasetError(c, "Dataset case", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(row))
+}
+
+// DeleteCase 删除数据集用例
+func (h *DatasetHandler) DeleteCase(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	caseID, ok := parseCaseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteCase(ctx, id, caseID); err != nil {
+		writeRepositoryError(c, "Dataset case", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(nil))
+}
+
+// parseCaseIDParam 解析路径中的用例ID, 失败时直接输出错误
+func parseCaseIDParam(c *app.RequestContext) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("case_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid case id"))
+		return 0, false
+	}
+	return id, true
+}
+
+// TagCases 批量添加或移除用例标签
+func (h *DatasetHandler) TagCases(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	var req model.TagCasesRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	changed, err := h.service.TagCases(ctx, id, &req)
+	if err != nil {
+		writeDatasetError(c, "Dataset", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(changed))
+}
+
+// SplitDataset 按比例随机划分数据集用例
+func (h *DatasetHandler) SplitDataset(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	var req model.SplitDatasetRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	counts, err := h.service.Split(ctx, id, &req)
+	if err != nil {
+		writeDatasetError(c, "Dataset", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(counts))
+}
+
+// FreezeDataset 冻结数据集的当前用例为新版本
+func (h *DatasetHandler) FreezeDataset(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	var req model.FreezeDatasetRequest
+	if len(c.Request.Body()) > 0 {
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+			return
+		}
+	}
+
+	version, err := h.service.Freeze(ctx, id, req.Note)
+	if err != nil {
+		writeDatasetError(c, "Dataset", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(version))
+}
+
+// ListVersions 列出数据集的全部版本
+func (h *DatasetHandler) ListVersions(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	versions, err := h.service.ListVersions(ctx, id)
+	if err != nil {
+		writeRepositoryError(c, "Dataset", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(versions))
+}
+
+// GetVersion 获取数据集版本及其用例快照, 支持split、tag过滤
+func (h *DatasetHandler) GetVersion(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	version, err := parseVersion(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid version"))
+		return
+	}
+	filter, ok := parseCaseFilter(c)
+	if !ok {
+		return
+	}
+
+	detail, err := h.service.GetVersion(ctx, id, version, filter)
+	if err != nil {
+		writeRepositoryError(c, "Dataset version", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(detail))
+}
+
+// ListRuns 分页查询使用数据集的批量任务, 参数version只查询使用指定版本的任务
+func (h *DatasetHandler) ListRuns(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	version := 0
+	if v := c.Query("version"); v != "" {
+		var err error
+		if version, err = parseVersion(v); err != nil {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid version parameter"))
+			return
+		}
+	}
+	page, pageSize, offset := parsePagination(c)
+
+	jobs, total, err := h.service.ListRuns(ctx, id, version, offset, pageSize)
+	if err != nil {
+		logger.Error("Failed to list dataset runs", zap.Uint64("dataset_id", id), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(model.PageResult{
+		Items:    jobs,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}))
+}
+
+// writeDatasetError 输出数据集修改错误, 请求不合法或无需冻结时返回400
+func writeDatasetError(c *app.RequestContext, resource string, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidDatasetChange), errors.Is(err, service.ErrDatasetUnchanged):
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+	case errors.Is(err, repository.ErrDuplicate):
+		c.JSON(http.StatusConflict, model.NewErrorResponse(409, "Dataset is being frozen concurrently, please retry"))
+	default:
+		writeRepositoryError(c, resource, err)
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/service"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// parsePagination 解析分页参数, 返回页码、每页数量和偏移量
+func parsePagination(c *app.RequestContext) (page, pageSize, offset int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err = strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultPageSize)))
+	if err != nil || pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	return page, pageSize, (page - 1) * pageSize
+}
+
+// parseIDParam 解析路径中的数字ID, 失败时直接输出错误
+func parseIDParam(c *app.RequestContext) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid id"))
+		return 0, false
+	}
+	return id, true
+}
+
+// writeRepositoryError 输出仓储操作错误
+func writeRepositoryError(c *app.RequestContext, resource string, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, model.NewErrorResponse(404, resource+" not found"))
+		return
+	}
+	logger.Error("Repository operation failed", zap.String("resource", resource), zap.Error(err))
+	c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, err.Error()))
+}
+
+// writeRequestError 输出测试请求预处理错误, 引用的模板不存在时返回404, 请求不合法时返回400, 读取模板等服务端错误返回500
+func writeRequestError(c *app.RequestContext, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, model.NewErrorResponse(404, "Template not found"))
+	case errors.Is(err, service.ErrInvalidRequest), errors.Is(err, service.ErrInvalidJob):
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+	default:
+		logger.Error("Failed to prepare request", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, err.Error()))
+	}
+}
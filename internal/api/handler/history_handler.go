@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/service"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// HistoryHandler 历史记录处理器
+type HistoryHandler struct {
+	service *service.HistoryService
+}
+
+// NewHistoryHandler 创建历史记录处理器
+func NewHistoryHandler(service *service.HistoryService) *HistoryHandler {
+	return &HistoryHandler{
+		service: service,
+	}
+}
+
+// GetHistory 按条件分页查询历史记录
+// 支持model、provider、start、end(RFC3339或2006-01-02)、success、template_id、template_version过滤
+func (h *HistoryHandler) GetHistory(ctx context.Context, c *app.RequestContext) {
+	page, pageSize, offset := parsePagination(c)
+
+	filter := repository.HistoryFilter{
+		Model:    c.Query("model"),
+		Provider: c.Query("provider"),
+		Offset:   offset,
+		Limit:    pageSize,
+	}
+
+	var err error
+	if filter.StartTime, err = parseTimeQuery(c.Query("start"), false); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+		return
+	}
+	if filter.EndTime, err = parseTimeQuery(c.Query("end"), true); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+		return
+	}
+	if v := c.Query("success"); v != "" {
+		success, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid success parameter"))
+			return
+		}
+		filter.Success = &success
+	}
+	if v := c.Query("template_id"); v != "" {
+		if filter.TemplateID, err = strconv.ParseUint(v, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid template_id parameter"))
+			return
+		}
+	}
+	if v := c.Query("template_version"); v != "" {
+		if filter.TemplateVersion, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid template_version parameter"))
+			return
+		}
+	}
+
+	records, total, err := h.service.List(ctx, filter)
+	if err != nil {
+		logger.Error("Failed to list history", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(model.PageResult{
+		Items:    records,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}))
+}
+
+// GetHistoryDetail 获取历史记录详情
+func (h *HistoryHandler) GetHistoryDetail(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	record, err := h.service.Get(ctx, id)
+	if err != nil {
+		writeRepositoryError(c, "Record", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(record))
+}
+
+// DeleteHistory 删除历史记录
+func (h *HistoryHandler) DeleteHistory(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(ctx, id); err != nil {
+		writeRepositoryError(c, "Record", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(nil))
+}
+
+// parseTimeQuery 解析时间参数, 仅有日期的结束时间包含当天
+func parseTimeQuery(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time: %s", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/multi-agent-testing/backend/internal/dataset"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/service"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// JobHandler 异步任务处理器
+type JobHandler struct {
+	service *service.JobService
+}
+
+// NewJobHandler 创建异步任务处理器
+func NewJobHandler(service *service.JobService) *JobHandler {
+	return &JobHandler{
+		service: service,
+	}
+}
+
+// SubmitJob 提交异步测试任务
+func (h *JobHandler) SubmitJob(ctx context.Context, c *app.RequestContext) {
+	var req model.TestRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	if msg := validateTestRequest(&req); msg != "" {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, msg))
+		return
+	}
+
+	job, err := h.service.SubmitTest(ctx, &req)
+	if err != nil {
+		logger.Error("Failed to submit job", zap.Error(err))
+		writeRequestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, model.NewSuccessResponse(job))
+}
+
+// SubmitBatch 提交批量测试任务
+// 支持JSON请求体, 或multipart表单上传数据集文件(file)并附带models字段
+func (h *JobHandler) SubmitBatch(ctx context.Context, c *app.RequestContext) {
+	var req model.BatchRequest
+
+	if strings.HasPrefix(string(c.ContentType()), "multipart/form-data") {
+		if msg := bindBatchForm(c, &req); msg != "" {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, msg))
+			return
+		}
+	} else if err := c.BindJSON(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	job, err := h.service.SubmitBatch(ctx, &req)
+	if err != nil {
+		logger.Error("Failed to submit batch job", zap.Error(err))
+		writeRequestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, model.NewSuccessResponse(job))
+}
+
+// bindBatchForm 从multipart表单解析批量测试请求, 返回错误提示
+func bindBatchForm(c *app.RequestContext, req *model.BatchRequest) string {
+	if err := json.Unmarshal(c.FormValue("models"), &req.Models); err != nil {
+		return "Invalid models field"
+	}
+	if v := string(c.FormValue("concurrency")); v != "" {
+		concurrency, err := strconv.Atoi(v)
+		if err != nil {
+			return "Invalid concurrency field"
+		}
+		req.Concurrency = concurrency
+	}
+	if v := c.FormValue("assertions"); len(v) > 0 {
+		if err := json.Unmarshal(v, &req.Assertions); err != nil {
+			return "Invalid assertions field"
+		}
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return "Dataset file is required"
+	}
+	opts, err := parseImportOptions(c)
+	if err != nil {
+		return err.Error()
+	}
+	if opts.Format == "" {
+		if opts.Format, err = dataset.DetectFormat(fileHeader.Filename); err != nil {
+			return err.Error()
+		}
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "Failed to open dataset file"
+	}
+	defer file.Close()
+
+	result, err := dataset.Import(file, opts)
+	if err != nil {
+		return err.Error()
+	}
+	if err := result.Err(); err != nil {
+		return err.Error()
+	}
+	req.Cases = result.Cases
+	return ""
+}
+
+// SubmitConversation 提交多轮对话测试任务
+func (h *JobHandler) SubmitConversation(ctx context.Context, c *app.RequestContext) {
+	var req model.ConversationRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	job, err := h.service.SubmitConversation(ctx, &req)
+	if err != nil {
+		logger.Error("Failed to submit conversation job", zap.Error(err))
+		writeRequestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, model.NewSuccessResponse(job))
+}
+
+// SubmitSimulation 提交模拟用户评测任务
+func (h *JobHandler) SubmitSimulation(ctx context.Context, c *app.RequestContext) {
+	var req model.SimulationRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	job, err := h.service.SubmitSimulation(ctx, &req)
+	if err != nil {
+		logger.Error("Failed to submit simulation job", zap.Error(err))
+		writeRequestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, model.NewSuccessResponse(job))
+}
+
+// ResumeJob 从检查点恢复批量任务
+func (h *JobHandler) ResumeJob(ctx context.Context, c *app.RequestContext) {
+	job, err := h.service.Resume(ctx, c.Param("id"))
+	if err != nil {
+		writeJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, model.NewSuccessResponse(job))
+}
+
+// GetJob 查询任务状态及部分结果
+func (h *JobHandler) GetJob(ctx context.Context, c *app.RequestContext) {
+	job, err := h.service.Get(ctx, c.Param("id"))
+	if err != nil {
+		writeJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(job))
+}
+
+// ListJobs 分页查询任务, 支持status、dataset_id、dataset_version过滤
+func (h *JobHandler) ListJobs(ctx context.Context, c *app.RequestContext) {
+	page, pageSize, offset := parsePagination(c)
+
+	filter := repository.JobFilter{Status: c.Query("status")}
+	var err error
+	if v := c.Query("dataset_id"); v != "" {
+		if filter.DatasetID, err = strconv.ParseUint(v, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid dataset_id parameter"))
+			return
+		}
+	}
+	if v := c.Query("dataset_version"); v != "" {
+		if filter.DatasetVersion, err = parseVersion(v); err != nil {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid dataset_version parameter"))
+			return
+		}
+	}
+
+	jobs, total, err := h.service.List(ctx, filter, offset, pageSize)
+	if err != nil {
+		logger.Error("Failed to list jobs", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(model.PageResult{
+		Items:    jobs,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}))
+}
+
+// CancelJob 取消任务
+func (h *JobHandler) CancelJob(ctx context.Context, c *app.RequestContext) {
+	job, err := h.service.Cancel(ctx, c.Param("id"))
+	if err != nil {
+		writeJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(job))
+}
+
+// writeJobError 输出任务相关错误
+func writeJobError(c *app.RequestContext, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, model.NewErrorResponse(404, "Job not found"))
+	case errors.Is(err, service.ErrJobFinished), errors.Is(err, service.ErrJobNotResumable), errors.Is(err, service.ErrJobRunning):
+		c.JSON(http.StatusConflict, model.NewErrorResponse(409, err.Error()))
+	default:
+		logger.Error("Job operation failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, err.Error()))
+	}
+}
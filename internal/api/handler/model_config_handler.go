@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/service"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ModelConfigHandler 模型配置处理器
+type ModelConfigHandler struct {
+	service *service.ModelConfigService
+}
+
+// NewModelConfigHandler 创建模型配置处理器
+func NewModelConfigHandler(service *service.ModelConfigService) *ModelConfigHandler {
+	return &ModelConfigHandler{
+		service: service,
+	}
+}
+
+// ListConfigs 列出模型配置, 支持provider过滤
+func (h *ModelConfigHandler) ListConfigs(ctx context.Context, c *app.RequestContext) {
+	configs, err := h.service.List(ctx, c.Query("provider"))
+	if err != nil {
+		logger.Error("Failed to list model configs", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(configs))
+}
+
+// GetConfig 获取模型配置
+func (h *ModelConfigHandler) GetConfig(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	cfg, err := h.service.Get(ctx, id)
+	if err != nil {
+		writeModelConfigError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(cfg))
+}
+
+// SaveConfig 创建模型配置
+func (h *ModelConfigHandler) SaveConfig(ctx context.Context, c *app.RequestContext) {
+	var req model.SaveModelConfigRequest
+	if err := c.BindJSON(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	cfg, err := h.service.Create(ctx, &req)
+	if err != nil {
+		writeModelConfigError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(cfg))
+}
+
+// UpdateConfig 更新模型配置
+func (h *ModelConfigHandler) UpdateConfig(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req model.SaveModelConfigRequest
+	if err := c.BindJSON(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	cfg, err := h.service.Update(ctx, id, &req)
+	if err != nil {
+		writeModelConfigError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(cfg))
+}
+
+// EnableConfig 启用模型配置
+func (h *ModelConfigHandler) EnableConfig(ctx context.Context, c *app.RequestContext) {
+	h.setEnabled(ctx, c, true)
+}
+
+// DisableConfig 禁用模型配置
+func (h *ModelConfigHandler) DisableConfig(ctx context.Context, c *app.RequestContext) {
+	h.setEnabled(ctx, c, false)
+}
+
+// setEnabled 修改模型配置的启用状态
+func (h *ModelConfigHandler) setEnabled(ctx context.Context, c *app.RequestContext, enabled bool) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	cfg, err := h.service.SetEnabled(ctx, id, enabled)
+	if err != nil {
+		writeModelConfigError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(cfg))
+}
+
+// DeleteConfig 删除模型配置
+func (h *ModelConfigHandler) DeleteConfig(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(ctx, id); err != nil {
+		writeModelConfigError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(nil))
+}
+
+// writeModelConfigError 输出模型配置操作错误
+func writeModelConfigError(c *app.RequestContext, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidModelConfig):
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+	case errors.Is(err, repository.ErrDuplicate):
+		c.JSON(http.StatusConflict, model.NewErrorResponse(409, "Model config already exists"))
+	default:
+		writeRepositoryError(c, "Model config", err)
+	}
+}
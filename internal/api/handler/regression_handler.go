@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/service"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// RegressionHandler 回归对比处理器
+type RegressionHandler struct {
+	service *service.RegressionService
+}
+
+// NewRegressionHandler 创建回归对比处理器
+func NewRegressionHandler(service *service.RegressionService) *RegressionHandler {
+	return &RegressionHandler{
+		service: service,
+	}
+}
+
+// CompareRuns 将批量任务与基线任务逐用例对比, verdict为fail时仍返回200
+func (h *RegressionHandler) CompareRuns(ctx context.Context, c *app.RequestContext) {
+	var req model.RegressionRequest
+	if err := c.BindJSON(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	report, err := h.service.Compare(ctx, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRegression) {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+			return
+		}
+		writeRepositoryError(c, "Job", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(report))
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/report"
+	"github.com/multi-agent-testing/backend/internal/service"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ReportHandler 结果报告处理器
+type ReportHandler struct {
+	service *service.ReportService
+}
+
+// NewReportHandler 创建结果报告处理器
+func NewReportHandler(service *service.ReportService) *ReportHandler {
+	return &ReportHandler{
+		service: service,
+	}
+}
+
+// ExportReport 导出一次或多次运行的结果报告
+// runs为逗号分隔的<run_type>:<run_id>, format为json/csv/jsonl/markdown/html, 默认json
+// html报告在浏览器中直接展示, 其余格式作为附件下载
+func (h *ReportHandler) ExportReport(ctx context.Context, c *app.RequestContext) {
+	runs := c.Query("runs")
+	if runs == "" {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "runs is required"))
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && !report.ValidFormat(format) {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid format parameter"))
+		return
+	}
+
+	refs, err := service.ParseRunRefs(strings.Split(runs, ","))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+		return
+	}
+	result, err := h.service.Build(ctx, refs, c.Query("title"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidReport) {
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+			return
+		}
+		writeRepositoryError(c, "Run", err)
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, model.NewSuccessResponse(result))
+		return
+	}
+	var buf bytes.Buffer
+	if err := report.Write(&buf, result, format); err != nil {
+		logger.Error("Failed to write report", zap.String("format", format), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, err.Error()))
+		return
+	}
+	disposition := "attachment"
+	if format == report.FormatHTML {
+		disposition = "inline"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("%s; filename=report.%s", disposition, report.FileExtension(format)))
+	c.Data(http.StatusOK, report.ContentType(format), buf.Bytes())
+}
@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/service"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// TemplateHandler 提示词模板处理器
+type TemplateHandler struct {
+	service *service.TemplateService
+}
+
+// NewTemplateHandler 创建提示词模板处理器
+func NewTemplateHandler(service *service.TemplateService) *TemplateHandler {
+	return &TemplateHandler{
+		service: service,
+	}
+}
+
+// GetTemplates 分页查询模板, 支持name模糊匹配
+func (h *TemplateHandler) GetTemplates(ctx context.Context, c *app.RequestContext) {
+	page, pageSize, offset := parsePagination(c)
+
+	templates, total, err := h.service.List(ctx, c.Query("name"), offset, pageSize)
+	if err != nil {
+		logger.Error("Failed to list templates", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(model.PageResult{
+		Items:    templates,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}))
+}
+
+// GetTemplate 获取模板详情
+func (h *TemplateHandler) GetTemplate(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	tpl, err := h.service.Get(ctx, id)
+	if err != nil {
+		writeTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(tpl))
+}
+
+// SaveTemplate 创建模板
+func (h *TemplateHandler) SaveTemplate(ctx context.Context, c *app.RequestContext) {
+	var req model.SaveTemplateRequest
+	if err := c.BindJSON(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	tpl, err := h.service.Create(ctx, &req)
+	if err != nil {
+		writeTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(tpl))
+}
+
+// UpdateTemplate 更新模板
+func (h *TemplateHandler) UpdateTemplate(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req model.SaveTemplateRequest
+	if err := c.BindJSON(&req); err != nil {
+		logger.Error("Failed to bind request", zap.Error(err))
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	tpl, err := h.service.Update(ctx, id, &req)
+	if err != nil {
+		writeTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(tpl))
+}
+
+// DeleteTemplate 删除模板
+func (h *TemplateHandler) DeleteTemplate(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(ctx, id); err != nil {
+		writeTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(nil))
+}
+
+// RenderTemplate 使用变量渲染模板, 返回渲染后的提示词
+func (h *TemplateHandler) RenderTemplate(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	// 允许不带请求体, 仅使用默认值渲染
+	var req model.RenderTemplateRequest
+	if len(c.Request.Body()) > 0 {
+		if err := c.BindJSON(&req); err != nil {
+			logger.Error("Failed to bind request", zap.Error(err))
+			c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+			return
+		}
+	}
+
+	prompts, err := h.service.Render(ctx, id, req.Version, req.Variables)
+	if err != nil {
+		writeTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(prompts))
+}
+
+// ListVersions 列出模板的全部版本
+func (h *TemplateHandler) ListVersions(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	versions, err := h.service.ListVersions(ctx, id)
+	if err != nil {
+		writeTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(versions))
+}
+
+// GetVersion 获取模板的指定版本
+func (h *TemplateHandler) GetVersion(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	version, err := parseVersion(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid version"))
+		return
+	}
+
+	v, err := h.service.GetVersion(ctx, id, version)
+	if err != nil {
+		writeRepositoryError(c, "Template version", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(v))
+}
+
+// DiffVersions 比较模板两个版本的字段差异, 参数from、to为版本号
+func (h *TemplateHandler) DiffVersions(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	from, err := parseVersion(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid from parameter"))
+		return
+	}
+	to, err := parseVersion(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid to parameter"))
+		return
+	}
+
+	diff, err := h.service.Diff(ctx, id, from, to)
+	if err != nil {
+		writeRepositoryError(c, "Template version", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(diff))
+}
+
+// RollbackTemplate 回滚模板到指定版本
+func (h *TemplateHandler) RollbackTemplate(ctx context.Context, c *app.RequestContext) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req model.RollbackTemplateRequest
+	if err := c.BindJSON(&req); err != nil || req.Version <= 0 {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Invalid request body"))
+		return
+	}
+
+	tpl, err := h.service.Rollback(ctx, id, req.Version, req.Note)
+	if err != nil {
+		writeTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(tpl))
+}
+
+// parseVersion 解析版本号, 版本号从1开始
+func parseVersion(value string) (int, error) {
+	version, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if version < 1 {
+		return 0, fmt.Errorf("invalid version: %d", version)
+	}
+	return version, nil
+}
+
+// writeTemplateError 输出模板操作错误, 校验与渲染错误返回400
+func writeTemplateError(c *app.RequestContext, err error) {
+	if errors.Is(err, service.ErrInvalidTemplate) || errors.Is(err, service.ErrRenderTemplate) {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, err.Error()))
+		return
+	}
+	writeRepositoryError(c, "Template", err)
+}
@@ -14,12 +14,14 @@ import (
 // TestHandler 测试处理器
 type TestHandler struct {
 	service *service.MultiModelService
+	arena   *service.ArenaService // 未启用数据库时为nil, 不支持盲测
 }
 
-// NewTestHandler 创建测试处理器
-func NewTestHandler(service *service.MultiModelService) *TestHandler {
+// NewTestHandler 创建测试处理器, arena为nil时不支持盲测
+func NewTestHandler(service *service.MultiModelService, arena *service.ArenaService) *TestHandler {
 	return &TestHandler{
 		service: service,
+		arena:   arena,
 	}
 }
 
@@ -35,21 +37,29 @@ func (h *TestHandler) ExecuteTest(ctx context.Context, c *app.RequestContext) {
 	}
 
 	// 验证请求
-	if req.Prompts.User == "" {
-		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "User prompt is required"))
-		return
-	}
-
-	if len(req.Models) == 0 {
-		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "At least one model is required"))
+	if msg := validateTestRequest(&req); msg != "" {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, msg))
 		return
 	}
 
 	logger.Info("Received test request",
 		zap.Int("model_count", len(req.Models)),
+		zap.Uint64("template_id", req.TemplateID),
 		//zap.String("user_prompt", req.Prompts.User),
 	)
 
+	// 渲染模板并校验, 失败时不调用任何模型
+	if err := h.service.PrepareRequest(ctx, &req); err != nil {
+		writeRequestError(c, err)
+		return
+	}
+
+	// 盲测模式返回匿名输出
+	if req.Blind {
+		h.startArena(ctx, c, &req)
+		return
+	}
+
 	// 执行测试
 	result, err := h.service.ExecuteTest(ctx, &req)
 	if err != nil {
@@ -62,6 +72,35 @@ func (h *TestHandler) ExecuteTest(ctx context.Context, c *app.RequestContext) {
 	c.JSON(http.StatusOK, model.NewSuccessResponse(result))
 }
 
+// startArena 创建盲测对战
+func (h *TestHandler) startArena(ctx context.Context, c *app.RequestContext, req *model.TestRequest) {
+	if h.arena == nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(400, "Blind mode requires database to be enabled"))
+		return
+	}
+
+	session, err := h.arena.Start(ctx, req)
+	if err != nil {
+		writeArenaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(session))
+}
+
+// validateTestRequest 校验测试请求, 返回错误提示
+func validateTestRequest(req *model.TestRequest) string {
+	if req.Prompts.User == "" && req.TemplateID == 0 {
+		return "User prompt or template_id is required"
+	}
+
+	if len(req.Models) == 0 {
+		return "At least one model is required"
+	}
+
+	return ""
+}
+
 // GetModelList 获取可用模型列表
 func (h *TestHandler) GetModelList(ctx context.Context, c *app.RequestContext) {
 	models := h.service.GetAvailableModels()
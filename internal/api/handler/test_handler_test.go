@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/repository/repotest"
+	"github.com/multi-agent-testing/backend/internal/service"
+)
+
+func TestExecuteTestPrepareErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		closeDB    bool // 关闭数据库以模拟读取模板失败
+		wantStatus int
+	}{
+		{"invalid request", `{"prompts": {"user": "hi"}, "variables": {"x": 1}, "models": [{"name": "gpt-4.1", "provider": "openai"}]}`, false, http.StatusBadRequest},
+		{"template not found", `{"template_id": 42, "models": [{"name": "gpt-4.1", "provider": "openai"}]}`, false, http.StatusNotFound},
+		{"storage failure", `{"template_id": 42, "models": [{"name": "gpt-4.1", "provider": "openai"}]}`, true, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := repotest.OpenSQLite(t)
+			if tt.closeDB {
+				sqlDB, err := db.DB()
+				if err != nil {
+					t.Fatal(err)
+				}
+				_ = sqlDB.Close()
+			}
+			svc := service.NewMultiModelService(&config.Config{})
+			svc.EnableTemplates(service.NewTemplateService(repository.NewPromptTemplateRepository(db)))
+			h := NewTestHandler(svc, nil)
+
+			c := ut.CreateUtRequestContext(http.MethodPost, "/api/v1/test/execute",
+				&ut.Body{Body: bytes.NewBufferString(tt.body), Len: len(tt.body)},
+				ut.Header{Key: "Content-Type", Value: "application/json"})
+			h.ExecuteTest(context.Background(), c)
+			if got := c.Response.StatusCode(); got != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", got, tt.wantStatus, c.Response.Body())
+			}
+		})
+	}
+}
+
+func TestExecuteTestBlindRejectsEvaluation(t *testing.T) {
+	models := `"models": [{"name": "gpt-4.1", "provider": "openai"}, {"name": "deepseek-chat", "provider": "deepseek"}]`
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"expected", `{"prompts": {"user": "hi"}, "blind": true, "expected": "hello", ` + models + `}`},
+		{"metrics", `{"prompts": {"user": "hi"}, "blind": true, "metrics": {"tolerance": 0.01}, ` + models + `}`},
+		{"assertions", `{"prompts": {"user": "hi"}, "blind": true, "assertions": [{"type": "contains", "value": "hello"}], ` + models + `}`},
+	}
+	cfg := &config.Config{Models: map[string]config.ModelConfig{
+		"openai":   {ApiKey: "sk-test", Enabled: true},
+		"deepseek": {ApiKey: "sk-test", Enabled: true},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := service.NewMultiModelService(cfg)
+			arena := service.NewArenaService(repository.NewArenaRepository(repotest.OpenSQLite(t)), svc)
+			h := NewTestHandler(svc, arena)
+
+			c := ut.CreateUtRequestContext(http.MethodPost, "/api/v1/test/execute",
+				&ut.Body{Body: bytes.NewBufferString(tt.body), Len: len(tt.body)},
+				ut.Header{Key: "Content-Type", Value: "application/json"})
+			h.ExecuteTest(context.Background(), c)
+			if got := c.Response.StatusCode(); got != http.StatusBadRequest || !strings.Contains(string(c.Response.Body()), "blind mode") {
+				t.Fatalf("status = %d, want 400 from blind mode: %s", got, c.Response.Body())
+			}
+		})
+	}
+}
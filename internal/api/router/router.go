@@ -7,9 +7,12 @@ import (
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/multi-agent-testing/backend/internal/api/handler"
 	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/secret"
 	"github.com/multi-agent-testing/backend/internal/service"
 	"github.com/multi-agent-testing/backend/pkg/logger"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // CORS 中间件
@@ -30,16 +33,86 @@ func CORS() app.HandlerFunc {
 	}
 }
 
-// Setup 设置路由
-func Setup(h *server.Hertz, cfg *config.Config) {
+// newJobStore 根据配置选择任务存储
+func newJobStore(cfg *config.Config, db *gorm.DB) repository.JobStore {
+	switch cfg.Job.Store {
+	case "memory":
+		return repository.NewMemoryJobStore()
+	case "database":
+		if db == nil {
+			logger.Warn("Database is not enabled, falling back to memory job store")
+			return repository.NewMemoryJobStore()
+		}
+		return repository.NewGormJobStore(db)
+	default:
+		if db != nil {
+			return repository.NewGormJobStore(db)
+		}
+		return repository.NewMemoryJobStore()
+	}
+}
+
+// Setup 设置路由, db为nil时不启用持久化
+func Setup(h *server.Hertz, cfg *config.Config, db *gorm.DB) {
 	// 添加全局CORS中间件
 	h.Use(CORS())
 
 	// 初始化服务
 	multiModelService := service.NewMultiModelService(cfg)
+	config.OnChange(func(old, new *config.Config) {
+		multiModelService.ReloadConfig(new)
+	})
+	var (
+		datasetRepo        *repository.DatasetRepository
+		historyService     *service.HistoryService
+		templateService    *service.TemplateService
+		modelConfigService *service.ModelConfigService
+		comparisonService  *service.ComparisonService
+		arenaService       *service.ArenaService
+		annotationRepo     *repository.AnnotationRepository
+		recordRepo         *repository.TestRecordRepository
+	)
+	if db != nil {
+		datasetRepo = repository.NewDatasetRepository(db)
+		recordRepo = repository.NewTestRecordRepository(db)
+		historyService = service.NewHistoryService(recordRepo)
+		multiModelService.EnableHistory(historyService)
+		templateService = service.NewTemplateService(repository.NewPromptTemplateRepository(db))
+		multiModelService.EnableTemplates(templateService)
+		comparisonService = service.NewComparisonService(repository.NewComparisonRepository(db), multiModelService)
+		arenaService = service.NewArenaService(repository.NewArenaRepository(db), multiModelService)
+		annotationRepo = repository.NewAnnotationRepository(db)
+		// 数据库中的模型配置覆盖配置文件
+		keyring, err := secret.Load(cfg.Security.MasterKeyEnv, cfg.Security.MasterKeyFile)
+		if err != nil {
+			logger.Error("Failed to load master key, API keys cannot be stored", zap.Error(err))
+		} else if keyring == nil {
+			logger.Warn("Master key is not configured, API keys cannot be stored in model configs")
+		}
+		modelConfigService = service.NewModelConfigService(repository.NewModelConfigRepository(db), multiModelService, keyring)
+		if err := modelConfigService.Load(context.Background()); err != nil {
+			logger.Error("Failed to load model configs", zap.Error(err))
+		}
+	}
+	jobStore := newJobStore(cfg, db)
+	jobService := service.NewJobService(jobStore, multiModelService, datasetRepo)
+	if err := jobService.Recover(context.Background()); err != nil {
+		logger.Error("Failed to recover jobs", zap.Error(err))
+	}
+	h.OnShutdown = append(h.OnShutdown, func(ctx context.Context) {
+		jobService.Shutdown(ctx)
+	})
+
+	regressionService := service.NewRegressionService(jobStore, cfg.Regression)
+	config.OnChange(func(old, new *config.Config) {
+		regressionService.ReloadConfig(new.Regression)
+	})
 
 	// 初始化处理器
-	testHandler := handler.NewTestHandler(multiModelService)
+	testHandler := handler.NewTestHandler(multiModelService, arenaService)
+	jobHandler := handler.NewJobHandler(jobService)
+	regressionHandler := handler.NewRegressionHandler(regressionService)
+	reportHandler := handler.NewReportHandler(service.NewReportService(recordRepo, jobStore))
 
 	// API分组
 	api := h.Group("/api/v1")
@@ -59,28 +132,140 @@ func Setup(h *server.Hertz, cfg *config.Config) {
 		// testGroup.GET("/stream", testHandler.StreamTest)
 	}
 
+	// 异步任务相关路由
+	jobGroup := api.Group("/jobs")
+	{
+		jobGroup.POST("", jobHandler.SubmitJob)
+		jobGroup.POST("/batch", jobHandler.SubmitBatch)
+		jobGroup.POST("/conversation", jobHandler.SubmitConversation)
+		jobGroup.POST("/simulation", jobHandler.SubmitSimulation)
+		jobGroup.GET("", jobHandler.ListJobs)
+		jobGroup.GET("/:id", jobHandler.GetJob)
+		jobGroup.DELETE("/:id", jobHandler.CancelJob)
+		jobGroup.POST("/:id/resume", jobHandler.ResumeJob)
+	}
+
+	// 回归对比路由, 对比两个已结束的批量任务
+	api.POST("/regressions", regressionHandler.CompareRuns)
+
+	// 结果报告路由, 未启用数据库时只支持任务
+	api.GET("/reports", reportHandler.ExportReport)
+
+	// 数据集相关路由(需启用数据库)
+	if datasetRepo != nil {
+		datasetHandler := handler.NewDatasetHandler(service.NewDatasetService(datasetRepo, jobStore))
+		datasetGroup := api.Group("/datasets")
+		{
+			datasetGroup.POST("", datasetHandler.CreateDataset)
+			datasetGroup.POST("/upload", datasetHandler.UploadDataset)
+			datasetGroup.GET("", datasetHandler.ListDatasets)
+			datasetGroup.GET("/:id", datasetHandler.GetDataset)
+			datasetGroup.DELETE("/:id", datasetHandler.DeleteDataset)
+			datasetGroup.POST("/:id/cases", datasetHandler.AppendCases)
+			datasetGroup.PUT("/:id/cases/:case_id", datasetHandler.UpdateCase)
+			datasetGroup.DELETE("/:id/cases/:case_id", datasetHandler.DeleteCase)
+			datasetGroup.POST("/:id/tags", datasetHandler.TagCases)
+			datasetGroup.POST("/:id/split", datasetHandler.SplitDataset)
+			datasetGroup.POST("/:id/versions", datasetHandler.FreezeDataset)
+			datasetGroup.GET("/:id/versions", datasetHandler.ListVersions)
+			datasetGroup.GET("/:id/versions/:version", datasetHandler.GetVersion)
+			datasetGroup.GET("/:id/runs", datasetHandler.ListRuns)
+		}
+	}
+
 	// 模型相关路由
 	modelGroup := api.Group("/models")
 	{
 		modelGroup.GET("/list", testHandler.GetModelList)
-		// TODO: 实现模型配置相关接口
-		// modelGroup.POST("/config", modelHandler.SaveConfig)
 	}
 
-	// TODO: 提示词模板相关路由
-	// templateGroup := api.Group("/prompt")
-	// {
-	// 	templateGroup.GET("/templates", promptHandler.GetTemplates)
-	// 	templateGroup.POST("/templates", promptHandler.SaveTemplate)
-	// }
+	// 模型配置管理路由(需启用数据库)
+	if modelConfigService != nil {
+		modelConfigHandler := handler.NewModelConfigHandler(modelConfigService)
+		configGroup := modelGroup.Group("/config")
+		{
+			configGroup.GET("", modelConfigHandler.ListConfigs)
+			configGroup.POST("", modelConfigHandler.SaveConfig)
+			configGroup.GET("/:id", modelConfigHandler.GetConfig)
+			configGroup.PUT("/:id", modelConfigHandler.UpdateConfig)
+			configGroup.DELETE("/:id", modelConfigHandler.DeleteConfig)
+			configGroup.POST("/:id/enable", modelConfigHandler.EnableConfig)
+			configGroup.POST("/:id/disable", modelConfigHandler.DisableConfig)
+		}
+	}
+
+	// 提示词模板相关路由(需启用数据库)
+	if templateService != nil {
+		templateHandler := handler.NewTemplateHandler(templateService)
+		templateGroup := api.Group("/prompt")
+		{
+			templateGroup.GET("/templates", templateHandler.GetTemplates)
+			templateGroup.POST("/templates", templateHandler.SaveTemplate)
+			templateGroup.GET("/templates/:id", templateHandler.GetTemplate)
+			templateGroup.PUT("/templates/:id", templateHandler.UpdateTemplate)
+			templateGroup.DELETE("/templates/:id", templateHandler.DeleteTemplate)
+			templateGroup.POST("/templates/:id/render", templateHandler.RenderTemplate)
+			templateGroup.GET("/templates/:id/versions", templateHandler.ListVersions)
+			templateGroup.GET("/templates/:id/versions/:version", templateHandler.GetVersion)
+			templateGroup.GET("/templates/:id/diff", templateHandler.DiffVersions)
+			templateGroup.POST("/templates/:id/rollback", templateHandler.RollbackTemplate)
+		}
+	}
+
+	// 成对对比及排行榜路由(需启用数据库)
+	if comparisonService != nil {
+		comparisonHandler := handler.NewComparisonHandler(comparisonService)
+		comparisonGroup := api.Group("/comparisons")
+		{
+			comparisonGroup.POST("", comparisonHandler.Compare)
+			comparisonGroup.POST("/votes", comparisonHandler.Vote)
+			comparisonGroup.GET("", comparisonHandler.ListComparisons)
+			comparisonGroup.GET("/:id", comparisonHandler.GetComparison)
+			comparisonGroup.DELETE("/:id", comparisonHandler.DeleteComparison)
+		}
+		api.GET("/leaderboard", comparisonHandler.GetLeaderboard)
+	}
+
+	// 盲测路由(需启用数据库), 对战由/test/execute的blind模式创建
+	if arenaService != nil {
+		arenaHandler := handler.NewArenaHandler(arenaService)
+		arenaGroup := api.Group("/arena")
+		{
+			arenaGroup.GET("/:id", arenaHandler.GetBattle)
+			arenaGroup.POST("/:id/vote", arenaHandler.Vote)
+		}
+	}
+
+	// 人工标注路由(需启用数据库), 标注对象为历史记录或任务中的模型响应
+	if annotationRepo != nil {
+		annotationService := service.NewAnnotationService(annotationRepo, recordRepo, jobStore, cfg.Annotation)
+		config.OnChange(func(old, new *config.Config) {
+			annotationService.ReloadConfig(new.Annotation)
+		})
+		annotationHandler := handler.NewAnnotationHandler(annotationService)
+		annotationGroup := api.Group("/annotations")
+		{
+			annotationGroup.POST("", annotationHandler.SaveAnnotation)
+			annotationGroup.GET("", annotationHandler.ListAnnotations)
+			annotationGroup.GET("/agreement", annotationHandler.GetAgreement)
+			annotationGroup.GET("/export", annotationHandler.ExportAnnotations)
+			annotationGroup.GET("/:id", annotationHandler.GetAnnotation)
+			annotationGroup.DELETE("/:id", annotationHandler.DeleteAnnotation)
+		}
+	}
 
-	// TODO: 历史记录相关路由
-	// historyGroup := api.Group("/history")
-	// {
-	// 	historyGroup.GET("", historyHandler.GetHistory)
-	// }
+	// 历史记录相关路由(需启用数据库)
+	if historyService != nil {
+		historyHandler := handler.NewHistoryHandler(historyService)
+		historyGroup := api.Group("/history")
+		{
+			historyGroup.GET("", historyHandler.GetHistory)
+			historyGroup.GET("/:id", historyHandler.GetHistoryDetail)
+			historyGroup.DELETE("/:id", historyHandler.DeleteHistory)
+		}
+	}
 
 	logger.Info("Routes registered successfully",
-		zap.Int("route_count", 3),
+		zap.Int("route_count", len(h.Routes())),
 	)
-}
\ No newline at end of file
+}
@@ -0,0 +1,231 @@
+package assertion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/tidwall/gjson"
+)
+
+// ErrInvalidAssertion 断言定义不合法
+var ErrInvalidAssertion = errors.New("invalid assertion")
+
+// Validate 校验断言定义, 返回第一个不合法的断言
+func Validate(assertions []model.Assertion) error {
+	for i, a := range assertions {
+		if err := validate(a); err != nil {
+			return fmt.Errorf("%w: assertion %d (%s): %v", ErrInvalidAssertion, i+1, a.Type, err)
+		}
+	}
+	return nil
+}
+
+// validate 校验单个断言
+func validate(a model.Assertion) error {
+	switch a.Type {
+	case model.AssertContains, model.AssertNotContains, model.AssertStartsWith:
+		if _, ok := a.Value.(string); !ok || a.Value == "" {
+			return errors.New("value must be a non-empty string")
+		}
+	case model.AssertRegex:
+		if _, err := compile(a); err != nil {
+			return err
+		}
+	case model.AssertJSONPath:
+		if a.Path == "" {
+			return errors.New("path is required")
+		}
+	case model.AssertIsJSON:
+	case model.AssertLength:
+		if a.Min == nil && a.Max == nil {
+			return errors.New("min or max is required")
+		}
+		if a.Min != nil && a.Max != nil && *a.Min > *a.Max {
+			return errors.New("min must not be greater than max")
+		}
+	case model.AssertLatency, model.AssertCost:
+		if a.Max == nil || *a.Max < 0 {
+			return errors.New("max is required and must not be negative")
+		}
+	case "":
+		return errors.New("type is required")
+	default:
+		return errors.New("unknown type")
+	}
+	return nil
+}
+
+// compile 编译regex断言的正则表达式
+func compile(a model.Assertion) (*regexp.Regexp, error) {
+	pattern, ok := a.Value.(string)
+	if !ok || pattern == "" {
+		return nil, errors.New("value must be a non-empty regular expression")
+	}
+	if a.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %v", err)
+	}
+	return re, nil
+}
+
+// Evaluate 对模型响应执行断言, 填充resp.Assertions及总体结论resp.Passed
+// 调用失败的响应全部断言视为未通过
+func Evaluate(assertions []model.Assertion, resp *model.ModelResponse) {
+	if len(assertions) == 0 {
+		return
+	}
+
+	passed := resp.Success
+	results := make([]model.AssertionResult, 0, len(assertions))
+	for _, a := range assertions {
+		result := model.AssertionResult{Type: a.Type, Name: a.Name}
+		if result.Name == "" {
+			result.Name = a.Type
+		}
+		if resp.Success {
+			result.Message = check(a, resp)
+		} else {
+			result.Message = "model call failed"
+		}
+		result.Passed = result.Message == ""
+		passed = passed && result.Passed
+		results = append(results, result)
+	}
+
+	resp.Assertions = results
+	resp.Passed = &passed
+}
+
+// check 执行单个断言, 通过时返回空字符串, 否则返回原因
+func check(a model.Assertion, resp *model.ModelResponse) string {
+	content := resp.Content
+	value, _ := a.Value.(string)
+	fold := func(s string) string {
+		if a.IgnoreCase {
+			return strings.ToLower(s)
+		}
+		return s
+	}
+
+	switch a.Type {
+	case model.AssertContains:
+		if !strings.Contains(fold(content), fold(value)) {
+			return fmt.Sprintf("output does not contain %q", value)
+		}
+	case model.AssertNotContains:
+		if strings.Contains(fold(content), fold(value)) {
+			return fmt.Sprintf("output contains %q", value)
+		}
+	case model.AssertStartsWith:
+		if !strings.HasPrefix(fold(strings.TrimSpace(content)), fold(value)) {
+			return fmt.Sprintf("output does not start with %q", value)
+		}
+	case model.AssertRegex:
+		re, err := compile(a)
+		if err != nil {
+			return err.Error()
+		}
+		if !re.MatchString(content) {
+			return fmt.Sprintf("output does not match %s", re)
+		}
+	case model.AssertIsJSON:
+		if !json.Valid([]byte(stripFence(content))) {
+			return "output is not valid JSON"
+		}
+	case model.AssertJSONPath:
+		return checkJSONPath(a, content)
+	case model.AssertLength:
+		n := float64(utf8.RuneCountInString(content))
+		if a.Min != nil && n < *a.Min {
+			return fmt.Sprintf("output length %.0f is less than %g", n, *a.Min)
+		}
+		if a.Max != nil && n > *a.Max {
+			return fmt.Sprintf("output length %.0f is greater than %g", n, *a.Max)
+		}
+	case model.AssertLatency:
+		if float64(resp.ResponseTime) > *a.Max {
+			return fmt.Sprintf("response time %dms exceeds %gms", resp.ResponseTime, *a.Max)
+		}
+	case model.AssertCost:
+		if resp.Cost == nil {
+			return "cost is unknown, configure pricing for this model"
+		}
+		if *resp.Cost > *a.Max {
+			return fmt.Sprintf("cost %g exceeds %g", *resp.Cost, *a.Max)
+		}
+	default:
+		return fmt.Sprintf("unknown assertion type %q", a.Type)
+	}
+	return ""
+}
+
+// checkJSONPath 校验JSON输出中path处的值, 未指定value时仅要求路径存在
+func checkJSONPath(a model.Assertion, content string) string {
+	content = stripFence(content)
+	if !json.Valid([]byte(content)) {
+		return "output is not valid JSON"
+	}
+	result := gjson.Get(content, a.Path)
+	if !result.Exists() {
+		return fmt.Sprintf("path %s not found", a.Path)
+	}
+	if a.Value == nil {
+		return ""
+	}
+
+	var actual interface{}
+	if err := json.Unmarshal([]byte(result.Raw), &actual); err != nil {
+		return fmt.Sprintf("path %s: %v", a.Path, err)
+	}
+	expected, err := normalize(a.Value)
+	if err != nil {
+		return fmt.Sprintf("invalid expected value: %v", err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		return fmt.Sprintf("path %s is %s, expected %s", a.Path, result.Raw, mustMarshal(expected))
+	}
+	return ""
+}
+
+// normalize 经JSON编解码统一期望值的类型, 如整数统一为float64
+func normalize(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	err = json.Unmarshal(data, &out)
+	return out, err
+}
+
+// mustMarshal 编码为JSON字符串, 用于错误信息
+func mustMarshal(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// stripFence 去除模型常用的Markdown代码块包裹, 如```json ... ```
+func stripFence(content string) string {
+	s := strings.TrimSpace(content)
+	if !strings.HasPrefix(s, "```") || !strings.HasSuffix(s, "```") || len(s) < 6 {
+		return s
+	}
+	s = strings.TrimSuffix(s[3:], "```")
+	// 首行为语言标识时一并去除
+	if i := strings.IndexByte(s, '\n'); i >= 0 && !strings.ContainsAny(s[:i], "{[\"") {
+		s = s[i+1:]
+	}
+	return strings.TrimSpace(s)
+}
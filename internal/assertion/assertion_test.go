@@ -0,0 +1,163 @@
+package assertion
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+)
+
+// ptr 返回数值指针, 用于min/max及cost
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		assertion model.Assertion
+		resp      model.ModelResponse
+		want      bool
+	}{
+		{"contains", model.Assertion{Type: model.AssertContains, Value: "Paris"}, model.ModelResponse{Content: "It is Paris."}, true},
+		{"contains missing", model.Assertion{Type: model.AssertContains, Value: "Paris"}, model.ModelResponse{Content: "It is paris."}, false},
+		{"contains ignore case", model.Assertion{Type: model.AssertContains, Value: "PARIS", IgnoreCase: true}, model.ModelResponse{Content: "It is paris."}, true},
+		{"contains cjk", model.Assertion{Type: model.AssertContains, Value: "巴黎"}, model.ModelResponse{Content: "答案是巴黎。"}, true},
+		{"not contains", model.Assertion{Type: model.AssertNotContains, Value: "sorry"}, model.ModelResponse{Content: "Here you go"}, true},
+		{"not contains present", model.Assertion{Type: model.AssertNotContains, Value: "sorry", IgnoreCase: true}, model.ModelResponse{Content: "Sorry, I can't"}, false},
+		{"starts with", model.Assertion{Type: model.AssertStartsWith, Value: "Yes"}, model.ModelResponse{Content: "  Yes, it is"}, true},
+		{"starts with other", model.Assertion{Type: model.AssertStartsWith, Value: "Yes"}, model.ModelResponse{Content: "No"}, false},
+		{"regex", model.Assertion{Type: model.AssertRegex, Value: `^\d{3}-\d{4}$`}, model.ModelResponse{Content: "555-1234"}, true},
+		{"regex no match", model.Assertion{Type: model.AssertRegex, Value: `^\d+$`}, model.ModelResponse{Content: "12a"}, false},
+		{"regex ignore case", model.Assertion{Type: model.AssertRegex, Value: `^ok$`, IgnoreCase: true}, model.ModelResponse{Content: "OK"}, true},
+		{"is json", model.Assertion{Type: model.AssertIsJSON}, model.ModelResponse{Content: `{"a": 1}`}, true},
+		{"is json fenced", model.Assertion{Type: model.AssertIsJSON}, model.ModelResponse{Content: "```json\n[1, 2]\n```"}, true},
+		{"is json invalid", model.Assertion{Type: model.AssertIsJSON}, model.ModelResponse{Content: `{"a": }`}, false},
+		{"json path exists", model.Assertion{Type: model.AssertJSONPath, Path: "data.items.0.name"}, model.ModelResponse{Content: `{"data": {"items": [{"name": "go"}]}}`}, true},
+		{"json path missing", model.Assertion{Type: model.AssertJSONPath, Path: "data.items.1"}, model.ModelResponse{Content: `{"data": {"items": [{"name": "go"}]}}`}, false},
+		{"json path value", model.Assertion{Type: model.AssertJSONPath, Path: "answer", Value: 42}, model.ModelResponse{Content: "```json\n{\"answer\": 42}\n```"}, true},
+		{"json path value differs", model.Assertion{Type: model.AssertJSONPath, Path: "answer", Value: "42"}, model.ModelResponse{Content: `{"answer": 42}`}, false},
+		{"json path object value", model.Assertion{Type: model.AssertJSONPath, Path: "item", Value: map[string]interface{}{"n": 1}}, model.ModelResponse{Content: `{"item": {"n": 1}}`}, true},
+		{"json path not json", model.Assertion{Type: model.AssertJSONPath, Path: "a"}, model.ModelResponse{Content: "a: 1"}, false},
+		{"length in range", model.Assertion{Type: model.AssertLength, Min: ptr(2), Max: ptr(4)}, model.ModelResponse{Content: "你好世界"}, true},
+		{"length too short", model.Assertion{Type: model.AssertLength, Min: ptr(5)}, model.ModelResponse{Content: "你好世界"}, false},
+		{"length too long", model.Assertion{Type: model.AssertLength, Max: ptr(3)}, model.ModelResponse{Content: "abcd"}, false},
+		{"latency", model.Assertion{Type: model.AssertLatency, Max: ptr(1000)}, model.ModelResponse{ResponseTime: 1000}, true},
+		{"latency exceeded", model.Assertion{Type: model.AssertLatency, Max: ptr(1000)}, model.ModelResponse{ResponseTime: 1001}, false},
+		{"cost", model.Assertion{Type: model.AssertCost, Max: ptr(0.01)}, model.ModelResponse{Cost: ptr(0.005)}, true},
+		{"cost exceeded", model.Assertion{Type: model.AssertCost, Max: ptr(0.01)}, model.ModelResponse{Cost: ptr(0.02)}, false},
+		{"cost unknown", model.Assertion{Type: model.AssertCost, Max: ptr(0.01)}, model.ModelResponse{}, false},
+		{"unknown type", model.Assertion{Type: "similar"}, model.ModelResponse{Content: "x"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.resp.Success = true
+			msg := check(tt.assertion, &tt.resp)
+			if got := msg == ""; got != tt.want {
+				t.Fatalf("check passed = %v, want %v (message %q)", got, tt.want, msg)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	assertions := []model.Assertion{
+		{Type: model.AssertContains, Value: "Paris"},
+		{Type: model.AssertLength, Name: "short", Max: ptr(20)},
+	}
+	tests := []struct {
+		name       string
+		resp       model.ModelResponse
+		wantPassed bool
+		wantEach   []bool
+	}{
+		{"all pass", model.ModelResponse{Success: true, Content: "Paris"}, true, []bool{true, true}},
+		{"one fails", model.ModelResponse{Success: true, Content: "The capital of France is Paris"}, false, []bool{true, false}},
+		{"call failed", model.ModelResponse{Success: false, Content: "Paris"}, false, []bool{false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Evaluate(assertions, &tt.resp)
+			if tt.resp.Passed == nil || *tt.resp.Passed != tt.wantPassed {
+				t.Fatalf("Passed = %v, want %v", tt.resp.Passed, tt.wantPassed)
+			}
+			if len(tt.resp.Assertions) != len(tt.wantEach) {
+				t.Fatalf("got %d assertion results, want %d", len(tt.resp.Assertions), len(tt.wantEach))
+			}
+			for i, want := range tt.wantEach {
+				if got := tt.resp.Assertions[i].Passed; got != want {
+					t.Fatalf("assertion %d passed = %v, want %v", i, got, want)
+				}
+			}
+			if name := tt.resp.Assertions[0].Name; name != model.AssertContains {
+				t.Fatalf("default name = %q, want the type", name)
+			}
+			if name := tt.resp.Assertions[1].Name; name != "short" {
+				t.Fatalf("name = %q, want short", name)
+			}
+		})
+	}
+
+	t.Run("no assertions", func(t *testing.T) {
+		resp := model.ModelResponse{Success: true}
+		Evaluate(nil, &resp)
+		if resp.Passed != nil || resp.Assertions != nil {
+			t.Fatalf("Evaluate without assertions changed the response")
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		assertion model.Assertion
+		wantErr   string
+	}{
+		{"contains", model.Assertion{Type: model.AssertContains, Value: "x"}, ""},
+		{"contains empty", model.Assertion{Type: model.AssertContains, Value: ""}, "non-empty string"},
+		{"contains not string", model.Assertion{Type: model.AssertStartsWith, Value: 1.0}, "non-empty string"},
+		{"regex invalid", model.Assertion{Type: model.AssertRegex, Value: "("}, "invalid regular expression"},
+		{"json path without path", model.Assertion{Type: model.AssertJSONPath}, "path is required"},
+		{"is json", model.Assertion{Type: model.AssertIsJSON}, ""},
+		{"length without bounds", model.Assertion{Type: model.AssertLength}, "min or max"},
+		{"length min above max", model.Assertion{Type: model.AssertLength, Min: ptr(5), Max: ptr(1)}, "greater than max"},
+		{"latency without max", model.Assertion{Type: model.AssertLatency}, "max is required"},
+		{"cost negative", model.Assertion{Type: model.AssertCost, Max: ptr(-1)}, "max is required"},
+		{"missing type", model.Assertion{}, "type is required"},
+		{"unknown type", model.Assertion{Type: "similar"}, "unknown type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate([]model.Assertion{tt.assertion})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidAssertion) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStripFence(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`{"a": 1}`, `{"a": 1}`},
+		{"```json\n{\"a\": 1}\n```", `{"a": 1}`},
+		{"```\n[1]\n```", "[1]"},
+		{"```{\"a\": 1}```", `{"a": 1}`},
+		{"  ```json\n{}\n```  ", "{}"},
+		{"```", "```"},
+	}
+	for _, tt := range tests {
+		if got := stripFence(tt.in); got != tt.want {
+			t.Errorf("stripFence(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
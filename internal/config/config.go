@@ -2,16 +2,24 @@ package config
 
 import (
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig            `mapstructure:"server"`
-	Models   map[string]ModelConfig  `mapstructure:"models"`
-	Database DatabaseConfig          `mapstructure:"database"`
-	Log      LogConfig               `mapstructure:"log"`
+	Server     ServerConfig           `mapstructure:"server"`
+	Models     map[string]ModelConfig `mapstructure:"models"`
+	Database   DatabaseConfig         `mapstructure:"database"`
+	Job        JobConfig              `mapstructure:"job"`
+	Security   SecurityConfig         `mapstructure:"security"`
+	Pricing    []ModelPrice           `mapstructure:"pricing"`
+	Judge      JudgeConfig            `mapstructure:"judge"`
+	Annotation AnnotationConfig       `mapstructure:"annotation"`
+	Regression RegressionConfig       `mapstructure:"regression"`
+	Log        LogConfig              `mapstructure:"log"`
 }
 
 type ServerConfig struct {
@@ -21,14 +29,71 @@ type ServerConfig struct {
 }
 
 type ModelConfig struct {
-	ApiKey  string        `mapstructure:"api_key"`
-	BaseURL string        `mapstructure:"base_url"`
-	Timeout time.Duration `mapstructure:"timeout"`
-	Enabled bool          `mapstructure:"enabled"`
+	ApiKey         string        `mapstructure:"api_key"`
+	BaseURL        string        `mapstructure:"base_url"`
+	Timeout        time.Duration `mapstructure:"timeout"`
+	Enabled        bool          `mapstructure:"enabled"`
+	MaxConcurrency int           `mapstructure:"max_concurrency"` // 最大并发调用数, 0表示不限制
 }
 
+// ModelPrice 模型价格, 单位为每百万token的费用, model为空时作为该提供者的默认价格
+type ModelPrice struct {
+	Provider string  `mapstructure:"provider"`
+	Model    string  `mapstructure:"model"`
+	Input    float64 `mapstructure:"input"`
+	Output   float64 `mapstructure:"output"`
+}
+
+// JudgeConfig 评审模型配置
+type JudgeConfig struct {
+	Provider  string         `mapstructure:"provider"`   // 默认评审模型的提供者, 请求中可覆盖
+	Model     string         `mapstructure:"model"`      // 默认评审模型
+	CacheSize int            `mapstructure:"cache_size"` // 评审结果缓存条数, 0使用默认值, 负数关闭缓存
+	Rubrics   []RubricConfig `mapstructure:"rubrics"`
+}
+
+// RubricConfig 评分标准
+type RubricConfig struct {
+	Name      string  `mapstructure:"name"`
+	Type      string  `mapstructure:"type"`      // score/pass_fail
+	Criteria  string  `mapstructure:"criteria"`  // 评分要求
+	MinScore  float64 `mapstructure:"min_score"` // score类型的分数范围, 默认1-10
+	MaxScore  float64 `mapstructure:"max_score"`
+	PassScore float64 `mapstructure:"pass_score"` // 达到该分数视为通过, 0表示不判定通过
+	Template  string  `mapstructure:"template"`   // 自定义评审提示词模板, 为空时使用内置模板
+}
+
+// AnnotationConfig 人工标注配置
+type AnnotationConfig struct {
+	MinRating float64  `mapstructure:"min_rating"` // 评分范围, 均为0时为1-5
+	MaxRating float64  `mapstructure:"max_rating"`
+	Tags      []string `mapstructure:"tags"` // 允许使用的标签, 为空时不限制
+}
+
+// RatingRange 评分范围, 未配置时为1-5
+func (c AnnotationConfig) RatingRange() (float64, float64) {
+	if c.MinRating == 0 && c.MaxRating == 0 {
+		return 1, 5
+	}
+	return c.MinRating, c.MaxRating
+}
+
+// RegressionConfig 回归对比的默认阈值, 可在请求中覆盖
+type RegressionConfig struct {
+	ScoreThreshold     float64 `mapstructure:"score_threshold"`      // 归一化分数(0-1)变化超过该值视为改进或退化, 0时为0.05
+	MaxRegressed       int     `mapstructure:"max_regressed"`        // 每个模型允许退化的用例数
+	MaxPassRateDrop    float64 `mapstructure:"max_pass_rate_drop"`   // 允许的通过率下降(0-1)
+	MaxLatencyIncrease float64 `mapstructure:"max_latency_increase"` // 允许的平均响应时间增幅比例, 0表示不检查
+	MaxCostIncrease    float64 `mapstructure:"max_cost_increase"`    // 允许的总费用增幅比例, 0表示不检查
+}
+
+// DefaultScoreThreshold 未配置时的分数变化阈值
+const DefaultScoreThreshold = 0.05
+
 type DatabaseConfig struct {
-	Type         string `mapstructure:"type"`
+	Enabled      bool   `mapstructure:"enabled"`
+	Type         string `mapstructure:"type"` // mysql/sqlite
+	Path         string `mapstructure:"path"` // sqlite数据库文件路径, :memory:表示内存数据库
 	Host         string `mapstructure:"host"`
 	Port         int    `mapstructure:"port"`
 	Username     string `mapstructure:"username"`
@@ -39,6 +104,16 @@ type DatabaseConfig struct {
 	Loc          string `mapstructure:"loc"`
 	MaxIdleConns int    `mapstructure:"maxIdleConns"`
 	MaxOpenConns int    `mapstructure:"maxOpenConns"`
+	AutoMigrate  bool   `mapstructure:"autoMigrate"` // 启动时执行待执行的迁移
+}
+
+type JobConfig struct {
+	Store string `mapstructure:"store"` // memory/database, 为空时有数据库则使用database
+}
+
+type SecurityConfig struct {
+	MasterKeyEnv  string `mapstructure:"master_key_env"`  // 存放当前主密钥的环境变量名, 默认MAT_MASTER_KEY
+	MasterKeyFile string `mapstructure:"master_key_file"` // 主密钥文件, 每行一个base64密钥, 用于保存轮换前的旧密钥
 }
 
 type LogConfig struct {
@@ -48,29 +123,94 @@ type LogConfig struct {
 	File   string `mapstructure:"file"`
 }
 
-var globalConfig *Config
+var globalConfig atomic.Pointer[Config]
+
+// Load 加载配置文件, profile非空时在基础配置上叠加同目录下的config.<profile>.yaml
+// 模型api_key中的引用不在此解析, 需要调用模型时使用ResolveModelKeys
+// 合并顺序: 默认值 < 基础配置 < profile配置 < 环境变量
+// 环境变量的键名为配置项的"."替换为"_", 如MODELS_OPENAI_API_KEY覆盖models.openai.api_key
+func Load(configPath, profile string) (*Config, error) {
+	v := newViper()
+	loaded, err := readLayers(v, LayerFiles(configPath, profile))
+	if err != nil {
+		return nil, err
+	}
+	// 先记录配置文件层, 解析或校验失败时Files也能返回出错的文件
+	setLayers(v, loaded)
+
+	cfg, err := decode(v)
+	if err != nil {
+		return nil, err
+	}
 
-// Load 加载配置文件
-func Load(configPath string) (*Config, error) {
-	viper.SetConfigFile(configPath)
-	viper.AutomaticEnv()
+	globalConfig.Store(cfg)
+	return cfg, nil
+}
+
+// newViper 创建设置了默认值及环境变量覆盖的viper实例
+// 结构体中的配置项全部绑定环境变量, 配置文件中未出现的键也可通过环境变量设置
+func newViper() *viper.Viper {
+	v := viper.New()
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	v.SetDefault("security.master_key_env", "MAT_MASTER_KEY")
+	for key := range Flatten(&Config{}) {
+		_ = v.BindEnv(key)
+	}
+	return v
+}
 
-	if err := viper.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+// decode 将viper中的配置解析为Config, 并解析数据库密码中的引用
+func decode(v *viper.Viper) (*Config, error) {
+	cfg, err := unmarshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if problems := resolveSecrets(cfg); len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
 	}
+	return cfg, nil
+}
 
+// unmarshal 将viper中的配置解析为Config, 不解析引用
+func unmarshal(v *viper.Viper) (*Config, error) {
+	// viper.Unmarshal会按结构体字段重新读取models等map类型的键, 导致环境变量覆盖丢失
+	// 因此先取出合并了环境变量的全部配置, 再解析到结构体
+	settings := viper.New()
+	if err := settings.MergeConfigMap(v.AllSettings()); err != nil {
+		return nil, fmt.Errorf("failed to merge config: %w", err)
+	}
 	var cfg Config
-	if err := viper.Unmarshal(&cfg); err != nil {
+	if err := settings.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
-
-	globalConfig = &cfg
 	return &cfg, nil
 }
 
-// Get 获取全局配置
+// Get 获取全局配置, 热加载后返回最新配置
 func Get() *Config {
-	return globalConfig
+	return globalConfig.Load()
+}
+
+// Price 查找模型价格, 优先精确匹配模型, 其次使用提供者的默认价格
+func (c *Config) Price(provider, model string) (ModelPrice, bool) {
+	var fallback *ModelPrice
+	for i := range c.Pricing {
+		p := &c.Pricing[i]
+		if p.Provider != provider {
+			continue
+		}
+		if p.Model == model {
+			return *p, true
+		}
+		if p.Model == "" && fallback == nil {
+			fallback = p
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return ModelPrice{}, false
 }
 
 // GetAddr 获取服务器地址
@@ -90,4 +230,17 @@ func (c *DatabaseConfig) GetDSN() string {
 		c.ParseTime,
 		c.Loc,
 	)
-}
\ No newline at end of file
+}
+
+// IsSQLiteMemory 是否为SQLite内存数据库
+func (c *DatabaseConfig) IsSQLiteMemory() bool {
+	return c.Path == "" || c.Path == ":memory:"
+}
+
+// GetSQLiteDSN 获取SQLite DSN连接串
+func (c *DatabaseConfig) GetSQLiteDSN() string {
+	if c.IsSQLiteMemory() {
+		return ":memory:?_pragma=foreign_keys(1)"
+	}
+	return fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)", c.Path)
+}
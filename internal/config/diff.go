@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// redacted 敏感字段在差异及打印中显示的值
+const redacted = "******"
+
+// secretFields 需要脱敏的字段名
+var secretFields = map[string]bool{"api_key": true, "password": true}
+
+// Change 单个配置项的变化
+type Change struct {
+	Key string
+	Old string
+	New string
+}
+
+// String 格式化输出, 新增或删除的配置项显示为<unset>
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Key, c.Old, c.New)
+}
+
+// Diff 比较两份配置, 返回发生变化的配置项, 敏感字段仅标记变化不输出内容
+func Diff(old, new *Config) []Change {
+	before, after := Flatten(old), Flatten(new)
+
+	keys := make(map[string]bool, len(before)+len(after))
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+
+	changes := []Change{}
+	for k := range keys {
+		o, ok1 := before[k]
+		n, ok2 := after[k]
+		if ok1 && ok2 && o == n {
+			continue
+		}
+		if !ok1 {
+			o = "<unset>"
+		}
+		if !ok2 {
+			n = "<unset>"
+		}
+		if isSecret(k) {
+			if ok1 {
+				o = redacted
+			}
+			if ok2 {
+				n = redacted + "(changed)"
+			}
+		}
+		changes = append(changes, Change{Key: k, Old: o, New: n})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// Flatten 将配置展开为"a.b.c"形式的键值, 键名使用mapstructure标签
+func Flatten(cfg *Config) map[string]string {
+	out := make(map[string]string)
+	if cfg != nil {
+		flatten("", reflect.ValueOf(*cfg), out)
+	}
+	return out
+}
+
+// flatten 递归展开结构体与map
+func flatten(prefix string, v reflect.Value, out map[string]string) {
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := field.Tag.Get("mapstructure")
+			if name == "" || name == "-" {
+				name = strings.ToLower(field.Name)
+			}
+			flatten(join(prefix, name), v.Field(i), out)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			flatten(join(prefix, fmt.Sprint(key.Interface())), v.MapIndex(key), out)
+		}
+	default:
+		out[prefix] = fmt.Sprint(v.Interface())
+	}
+}
+
+// join 拼接键名
+func join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// isSecret 判断键是否为敏感字段
+func isSecret(key string) bool {
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		key = key[i+1:]
+	}
+	return secretFields[key]
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	base := func() *Config {
+		return &Config{
+			Server: ServerConfig{Host: "0.0.0.0", Port: 8081},
+			Models: map[string]ModelConfig{"openai": {ApiKey: "sk-old", Enabled: true}},
+			Log:    LogConfig{Level: "info"},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		change func(*Config)
+		want   []Change
+	}{
+		{"unchanged", func(*Config) {}, []Change{}},
+		{
+			name:   "value changed",
+			change: func(c *Config) { c.Log.Level = "debug" },
+			want:   []Change{{Key: "log.level", Old: "info", New: "debug"}},
+		},
+		{
+			name:   "sorted by key",
+			change: func(c *Config) { c.Server.Port = 9090; c.Log.Level = "warn" },
+			want: []Change{
+				{Key: "log.level", Old: "info", New: "warn"},
+				{Key: "server.port", Old: "8081", New: "9090"},
+			},
+		},
+		{
+			name:   "secret is redacted",
+			change: func(c *Config) { c.Models["openai"] = ModelConfig{ApiKey: "sk-new", Enabled: true} },
+			want:   []Change{{Key: "models.openai.api_key", Old: redacted, New: redacted + "(changed)"}},
+		},
+		{
+			name:   "model added",
+			change: func(c *Config) { c.Models["zhipu"] = ModelConfig{ApiKey: "k"} },
+			want: []Change{
+				{Key: "models.zhipu.api_key", Old: "<unset>", New: redacted + "(changed)"},
+				{Key: "models.zhipu.base_url", Old: "<unset>", New: ""},
+				{Key: "models.zhipu.enabled", Old: "<unset>", New: "false"},
+				{Key: "models.zhipu.max_concurrency", Old: "<unset>", New: "0"},
+				{Key: "models.zhipu.timeout", Old: "<unset>", New: "0s"},
+			},
+		},
+		{
+			name:   "model removed",
+			change: func(c *Config) { c.Models = nil },
+			want: []Change{
+				{Key: "models.openai.api_key", Old: redacted, New: "<unset>"},
+				{Key: "models.openai.base_url", Old: "", New: "<unset>"},
+				{Key: "models.openai.enabled", Old: "true", New: "<unset>"},
+				{Key: "models.openai.max_concurrency", Old: "0", New: "<unset>"},
+				{Key: "models.openai.timeout", Old: "0s", New: "<unset>"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old, updated := base(), base()
+			tt.change(updated)
+			if got := Diff(old, updated); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Diff = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangeString(t *testing.T) {
+	c := Change{Key: "log.level", Old: "info", New: "debug"}
+	if got, want := c.String(), "log.level: info -> debug"; got != want {
+		t.Fatalf("String = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// ProfileEnv 未通过参数指定profile时读取的环境变量
+const ProfileEnv = "APP_PROFILE"
+
+// 配置项来源
+const (
+	SourceDefault = "default"
+	SourceEnv     = "env"
+)
+
+// layer 一个配置文件层及其中出现的键
+type layer struct {
+	file string
+	keys map[string]bool
+}
+
+var (
+	layersMu sync.RWMutex
+	layers   []layer
+	active   = viper.New() // 当前生效配置对应的viper实例, 与layers一同替换
+)
+
+// Setting 生效的配置项, Source为设置该值的层: 配置文件路径、env:变量名或default
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// LayerFiles 返回按合并顺序排列的配置文件: 基础配置在前, profile叠加配置在后
+// profile配置文件与基础配置位于同一目录, 如configs/config.yaml对应configs/config.staging.yaml
+func LayerFiles(configPath, profile string) []string {
+	files := []string{configPath}
+	if profile != "" {
+		ext := filepath.Ext(configPath)
+		files = append(files, strings.TrimSuffix(configPath, ext)+"."+profile+ext)
+	}
+	return files
+}
+
+// Files 返回当前加载的配置文件
+func Files() []string {
+	layersMu.RLock()
+	defer layersMu.RUnlock()
+	files := make([]string, len(layers))
+	for i, l := range layers {
+		files[i] = l.file
+	}
+	return files
+}
+
+// readLayers 按顺序读取配置文件并合并到v, 后面的文件覆盖前面文件中的同名键
+// 仅修改v, 由调用方在新配置可用后通过setLayers生效
+func readLayers(v *viper.Viper, files []string) ([]layer, error) {
+	loaded := make([]layer, 0, len(files))
+	for i, file := range files {
+		fv := viper.New()
+		fv.SetConfigFile(file)
+		if err := fv.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", file, err)
+		}
+		keys := make(map[string]bool)
+		for _, k := range fv.AllKeys() {
+			keys[k] = true
+		}
+		loaded = append(loaded, layer{file: file, keys: keys})
+
+		if i == 0 {
+			v.SetConfigFile(file)
+			if err := v.ReadInConfig(); err != nil {
+				return nil, fmt.Errorf("failed to read config file %s: %w", file, err)
+			}
+			continue
+		}
+		if err := v.MergeConfigMap(fv.AllSettings()); err != nil {
+			return nil, fmt.Errorf("failed to merge config file %s: %w", file, err)
+		}
+	}
+	bindModelEnv(v)
+	return loaded, nil
+}
+
+// bindModelEnv 为配置文件中的每个模型绑定全部字段的环境变量
+// 模型名来自配置文件, newViper无法预先绑定, 未写在文件中的字段也可通过环境变量设置
+func bindModelEnv(v *viper.Viper) {
+	for name := range v.GetStringMap("models") {
+		for key := range Flatten(&Config{Models: map[string]ModelConfig{name: {}}}) {
+			if strings.HasPrefix(key, "models.") {
+				_ = v.BindEnv(key)
+			}
+		}
+	}
+}
+
+// setLayers 替换当前生效的viper实例及配置文件层
+func setLayers(v *viper.Viper, loaded []layer) {
+	layersMu.Lock()
+	defer layersMu.Unlock()
+	active = v
+	layers = loaded
+}
+
+// Inspect 读取配置文件层但不校验, 返回合并后生效的全部配置项及其来源
+// 敏感字段中的${ENV}及file://引用原样输出, 其余敏感值脱敏
+func Inspect(configPath, profile string) ([]Setting, error) {
+	v := newViper()
+	loaded, err := readLayers(v, LayerFiles(configPath, profile))
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := unmarshal(v)
+	if err != nil {
+		return nil, err
+	}
+	setLayers(v, loaded)
+	return Effective(cfg), nil
+}
+
+// Effective 返回配置中全部配置项的值及来源, 敏感字段脱敏
+func Effective(cfg *Config) []Setting {
+	layersMu.RLock()
+	defer layersMu.RUnlock()
+
+	known := make(map[string]bool)
+	for _, k := range active.AllKeys() {
+		known[k] = true
+	}
+
+	values := Flatten(cfg)
+	settings := make([]Setting, 0, len(values))
+	for key, value := range values {
+		if isSecret(key) && value != "" && !isRef(value) {
+			value = redacted
+		}
+		settings = append(settings, Setting{Key: key, Value: value, Source: sourceOf(key, known)})
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings
+}
+
+// sourceOf 查找设置配置项的层, 优先级从高到低为环境变量、后加载的配置文件、默认值
+// 环境变量仅对viper已知的键生效
+func sourceOf(key string, known map[string]bool) string {
+	lower := strings.ToLower(key)
+	name := strings.ToUpper(strings.ReplaceAll(lower, ".", "_"))
+	if _, ok := os.LookupEnv(name); ok && known[lower] {
+		return SourceEnv + ":" + name
+	}
+	for i := len(layers) - 1; i >= 0; i-- {
+		if layers[i].keys[lower] {
+			return layers[i].file
+		}
+	}
+	return SourceDefault
+}
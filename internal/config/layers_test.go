@@ -0,0 +1,165 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const baseConfig = `
+server:
+  host: 0.0.0.0
+  port: 8081
+models:
+  openai:
+    api_key: ${OPENAI_API_KEY}
+    base_url: https://api.openai.com/v1
+    timeout: 60s
+    enabled: true
+database:
+  password: secret
+log:
+  level: info
+`
+
+const stagingConfig = `
+models:
+  openai:
+    timeout: 30s
+log:
+  level: debug
+`
+
+func TestLayerFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		profile string
+		want    []string
+	}{
+		{"no profile", "configs/config.yaml", "", []string{"configs/config.yaml"}},
+		{"profile", "configs/config.yaml", "staging", []string{"configs/config.yaml", "configs/config.staging.yaml"}},
+		{"yml extension", "/etc/mat/app.yml", "prod", []string{"/etc/mat/app.yml", "/etc/mat/app.prod.yml"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LayerFiles(tt.path, tt.profile); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("LayerFiles = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	dir := t.TempDir()
+	base := writeConfig(t, dir, "config.yaml", baseConfig)
+	staging := writeConfig(t, dir, "config.staging.yaml", stagingConfig)
+
+	tests := []struct {
+		name        string
+		profile     string
+		env         map[string]string
+		wantFiles   []string
+		wantPort    int
+		wantLevel   string
+		wantTimeout time.Duration
+		wantErr     bool
+	}{
+		{name: "base only", wantFiles: []string{base}, wantPort: 8081, wantLevel: "info", wantTimeout: 60 * time.Second},
+		{name: "profile overrides base", profile: "staging", wantFiles: []string{base, staging}, wantPort: 8081, wantLevel: "debug", wantTimeout: 30 * time.Second},
+		{
+			name:        "env overrides profile",
+			profile:     "staging",
+			env:         map[string]string{"LOG_LEVEL": "warn", "SERVER_PORT": "9000", "MODELS_OPENAI_TIMEOUT": "10s"},
+			wantFiles:   []string{base, staging},
+			wantPort:    9000,
+			wantLevel:   "warn",
+			wantTimeout: 10 * time.Second,
+		},
+		{name: "missing profile file", profile: "prod", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			cfg, err := Load(base, tt.profile)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if got := Files(); !reflect.DeepEqual(got, tt.wantFiles) {
+				t.Fatalf("Files = %v, want %v", got, tt.wantFiles)
+			}
+			openai := cfg.Models["openai"]
+			if cfg.Server.Port != tt.wantPort || cfg.Log.Level != tt.wantLevel || openai.Timeout != tt.wantTimeout {
+				t.Fatalf("port/level/timeout = %d/%s/%s, want %d/%s/%s",
+					cfg.Server.Port, cfg.Log.Level, openai.Timeout, tt.wantPort, tt.wantLevel, tt.wantTimeout)
+			}
+			// 未被覆盖的键保留基础配置中的值
+			if openai.BaseURL != "https://api.openai.com/v1" || !openai.Enabled || cfg.Server.Host != "0.0.0.0" {
+				t.Fatalf("base values lost after merge: %+v %+v", openai, cfg.Server)
+			}
+		})
+	}
+}
+
+func TestEffectiveSources(t *testing.T) {
+	dir := t.TempDir()
+	base := writeConfig(t, dir, "config.yaml", baseConfig)
+	staging := writeConfig(t, dir, "config.staging.yaml", stagingConfig)
+	t.Setenv("SERVER_PORT", "9000")
+	t.Setenv("JOB_STORE", "memory")
+	t.Setenv("MODELS_OPENAI_MAX_CONCURRENCY", "2")
+
+	settings, err := Inspect(base, "staging")
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	got := make(map[string]Setting, len(settings))
+	for _, s := range settings {
+		got[s.Key] = s
+	}
+
+	tests := []struct {
+		key        string
+		wantValue  string
+		wantSource string
+	}{
+		{"server.host", "0.0.0.0", base},
+		{"server.port", "9000", SourceEnv + ":SERVER_PORT"},
+		{"log.level", "debug", staging},
+		{"models.openai.timeout", "30s", staging},
+		{"models.openai.base_url", "https://api.openai.com/v1", base},
+		{"models.openai.max_concurrency", "2", SourceEnv + ":MODELS_OPENAI_MAX_CONCURRENCY"},
+		{"job.store", "memory", SourceEnv + ":JOB_STORE"},
+		{"log.format", "", SourceDefault},
+		{"security.master_key_env", "MAT_MASTER_KEY", SourceDefault},
+		// 引用原样输出, 明文敏感值脱敏
+		{"models.openai.api_key", "${OPENAI_API_KEY}", base},
+		{"database.password", redacted, base},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			s, ok := got[tt.key]
+			if !ok {
+				t.Fatalf("setting %s not found", tt.key)
+			}
+			if s.Value != tt.wantValue || s.Source != tt.wantSource {
+				t.Fatalf("%s = %q from %q, want %q from %q", tt.key, s.Value, s.Source, tt.wantValue, tt.wantSource)
+			}
+		})
+	}
+	if want := []string{base, staging}; !reflect.DeepEqual(Files(), want) {
+		t.Fatalf("Files = %v, want %v", Files(), want)
+	}
+	if filepath.Dir(base) != filepath.Dir(staging) {
+		t.Fatal("profile file should sit next to the base config")
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// filePrefix 从文件读取敏感配置的前缀, 如file:///run/secrets/openai_key
+const filePrefix = "file://"
+
+// envRef 环境变量引用, 如${OPENAI_API_KEY}
+var envRef = regexp.MustCompile(`^\$\{([A-Za-z_][A-Za-z0-9_]*)\}$`)
+
+// ValidationError 配置校验错误, 包含全部不合法的配置项
+type ValidationError struct {
+	Problems []string
+}
+
+// Error 实现error接口
+func (e *ValidationError) Error() string {
+	return "invalid config: " + strings.Join(e.Problems, "; ")
+}
+
+// resolveSecrets 解析数据库密码中的${ENV}及file://引用, 数据库启用且解析失败时报错
+// 模型api_key仅在需要调用模型时通过ResolveModelKeys解析, migrate等子命令不要求模型密钥可用
+func resolveSecrets(cfg *Config) []string {
+	problems := []string{}
+	value, err := resolveRef(cfg.Database.Password)
+	if err != nil && cfg.Database.Enabled {
+		problems = append(problems, fmt.Sprintf("database.password: %v", err))
+	}
+	cfg.Database.Password = value
+	return problems
+}
+
+// ResolveModelKeys 解析模型api_key中的${ENV}及file://引用, 在创建模型提供者前调用
+// 仅启用的模型解析失败时报错, 未启用的模型解析失败时置空
+func (c *Config) ResolveModelKeys() error {
+	problems := []string{}
+
+	names := make([]string, 0, len(c.Models))
+	for name := range c.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		m := c.Models[name]
+		value, err := resolveRef(m.ApiKey)
+		if err != nil && m.Enabled {
+			problems = append(problems, fmt.Sprintf("models.%s.api_key: %v", name, err))
+		}
+		m.ApiKey = value
+		c.Models[name] = m
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// resolveRef 解析单个引用, 非引用的值原样返回, 解析失败时返回空字符串
+func resolveRef(value string) (string, error) {
+	if m := envRef.FindStringSubmatch(value); m != nil {
+		v, ok := os.LookupEnv(m[1])
+		if !ok || v == "" {
+			return "", fmt.Errorf("environment variable %s is not set", m[1])
+		}
+		return v, nil
+	}
+	if path, ok := strings.CutPrefix(value, filePrefix); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+		v := strings.TrimSpace(string(data))
+		if v == "" {
+			return "", fmt.Errorf("secret file %s is empty", path)
+		}
+		return v, nil
+	}
+	return value, nil
+}
+
+// isRef 判断值是否为${ENV}或file://引用
+func isRef(value string) bool {
+	return envRef.MatchString(value) || strings.HasPrefix(value, filePrefix)
+}
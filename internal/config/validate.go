@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// logLevels 支持的日志级别
+var logLevels = map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+
+// placeholderKeys 示例配置中常见的占位API Key, 视为未配置
+var placeholderKeys = map[string]bool{"xxx": true, "your-api-key": true, "your_api_key": true, "changeme": true}
+
+// Validate 校验配置, 返回全部不合法的字段
+func (c *Config) Validate() error {
+	problems := []string{}
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		add("server.port: must be between 1 and 65535, got %d", c.Server.Port)
+	}
+
+	names := make([]string, 0, len(c.Models))
+	for name := range c.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		m := c.Models[name]
+		if m.Timeout < 0 {
+			add("models.%s.timeout: must not be negative", name)
+		}
+		if m.MaxConcurrency < 0 {
+			add("models.%s.max_concurrency: must not be negative", name)
+		}
+		if m.BaseURL != "" {
+			if err := checkURL(m.BaseURL); err != nil {
+				add("models.%s.base_url: %v", name, err)
+			}
+		}
+		if !m.Enabled {
+			continue
+		}
+		switch {
+		case m.ApiKey == "":
+			add("models.%s.api_key: required when the model is enabled, set it in the config, ${ENV}, file:// or MODELS_%s_API_KEY", name, strings.ToUpper(name))
+		case placeholderKeys[m.ApiKey]:
+			add("models.%s.api_key: %q is a placeholder, set a real key", name, m.ApiKey)
+		}
+		// 超时按秒传给提供者, 不足1秒会被截断为0
+		if m.Timeout >= 0 && m.Timeout < time.Second {
+			add("models.%s.timeout: must be at least 1s with a unit such as 60s, got %s", name, m.Timeout)
+		}
+	}
+
+	for i, p := range c.Pricing {
+		if p.Provider == "" {
+			add("pricing[%d].provider: required", i)
+		}
+		if p.Input < 0 || p.Output < 0 {
+			add("pricing[%d]: input and output must not be negative", i)
+		}
+	}
+
+	if (c.Judge.Provider == "") != (c.Judge.Model == "") {
+		add("judge: provider and model must be set together")
+	}
+	rubrics := make(map[string]bool, len(c.Judge.Rubrics))
+	for i, r := range c.Judge.Rubrics {
+		switch {
+		case r.Name == "":
+			add("judge.rubrics[%d].name: required", i)
+		case rubrics[r.Name]:
+			add("judge.rubrics[%d].name: duplicate rubric %q", i, r.Name)
+		}
+		rubrics[r.Name] = true
+		if r.Type != "" && r.Type != "score" && r.Type != "pass_fail" {
+			add("judge.rubrics[%d].type: must be score or pass_fail, got %q", i, r.Type)
+		}
+		if r.Criteria == "" && r.Template == "" {
+			add("judge.rubrics[%d]: criteria or template is required", i)
+		}
+	}
+
+	if min, max := c.Annotation.RatingRange(); min >= max {
+		add("annotation: min_rating must be less than max_rating")
+	}
+
+	reg := c.Regression
+	if reg.ScoreThreshold < 0 || reg.ScoreThreshold > 1 {
+		add("regression.score_threshold: must be between 0 and 1, got %g", reg.ScoreThreshold)
+	}
+	if reg.MaxRegressed < 0 {
+		add("regression.max_regressed: must not be negative")
+	}
+	if reg.MaxPassRateDrop < 0 || reg.MaxPassRateDrop > 1 {
+		add("regression.max_pass_rate_drop: must be between 0 and 1, got %g", reg.MaxPassRateDrop)
+	}
+	if reg.MaxLatencyIncrease < 0 {
+		add("regression.max_latency_increase: must not be negative")
+	}
+	if reg.MaxCostIncrease < 0 {
+		add("regression.max_cost_increase: must not be negative")
+	}
+
+	if c.Database.Enabled {
+		switch c.Database.Type {
+		case "", "mysql":
+			if c.Database.Host == "" {
+				add("database.host: required for mysql")
+			}
+			if c.Database.Database == "" {
+				add("database.database: required for mysql")
+			}
+		case "sqlite":
+		default:
+			add("database.type: must be mysql or sqlite, got %q", c.Database.Type)
+		}
+	}
+	switch c.Job.Store {
+	case "", "memory", "database":
+	default:
+		add("job.store: must be memory or database, got %q", c.Job.Store)
+	}
+	if !logLevels[c.Log.Level] {
+		add("log.level: must be one of debug/info/warn/error, got %q", c.Log.Level)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// checkURL 校验地址为带主机名的http(s)地址
+func checkURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("malformed URL %q: %v", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("must start with http:// or https://, got %q", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing host in %q", raw)
+	}
+	return nil
+}
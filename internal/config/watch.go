@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ChangeFunc 配置热加载成功后的回调
+type ChangeFunc func(old, new *Config)
+
+var (
+	listenersMu sync.Mutex
+	listeners   []ChangeFunc
+	reloadMu    sync.Mutex
+)
+
+// OnChange 注册配置变化回调, 按注册顺序调用
+func OnChange(fn ChangeFunc) {
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+	listeners = append(listeners, fn)
+}
+
+// restartKeys 修改后需重启才能生效的配置项前缀
+var restartKeys = []string{"server.", "database.", "job.", "judge.cache_size"}
+
+// Watch 监听全部配置文件层的变化, 新配置校验通过后原子替换, 否则保留当前配置
+// 监听文件所在目录而非文件本身, 以支持编辑器替换写入及Kubernetes ConfigMap的符号链接切换
+// ConfigMap更新时替换的是目录中的..data链接, 配置文件本身没有事件, 因此目录中有任何事件时
+// 都检查配置文件解析后的实际路径, 发生变化即重新加载
+func Watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+
+	files := Files()
+	// 配置文件 -> 解析符号链接后的实际路径
+	watched := make(map[string]string, len(files))
+	for _, file := range files {
+		file = filepath.Clean(file)
+		watched[file] = realPath(file)
+		dir := filepath.Dir(file)
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return fmt.Errorf("failed to watch config dir %s: %w", dir, err)
+		}
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if file, ok := changedFile(watched, event); ok {
+					reload(file)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Error("Config watcher error", zap.Error(err))
+			}
+		}
+	}()
+
+	logger.Info("Watching config files for changes", zap.Strings("files", files))
+	return nil
+}
+
+// changedFile 判断事件是否改变了某个配置文件, 返回该文件
+// 文件本身被写入或创建, 或其符号链接指向的实际文件发生变化时视为改变
+func changedFile(watched map[string]string, event fsnotify.Event) (string, bool) {
+	name := filepath.Clean(event.Name)
+	if _, ok := watched[name]; ok && event.Has(fsnotify.Write|fsnotify.Create) {
+		watched[name] = realPath(name)
+		return name, true
+	}
+	for file, resolved := range watched {
+		if filepath.Dir(file) != filepath.Dir(name) {
+			continue
+		}
+		if current := realPath(file); current != "" && current != resolved {
+			watched[file] = current
+			return file, true
+		}
+	}
+	return "", false
+}
+
+// realPath 返回解析符号链接后的路径, 文件不存在时返回空字符串
+func realPath(file string) string {
+	resolved, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		return ""
+	}
+	return resolved
+}
+
+// reload 将全部配置文件层读取到新的viper实例, 解析引用并校验通过后才替换当前配置
+// 读取或校验失败时当前生效的viper实例及配置均保持不变
+func reload(file string) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	v := newViper()
+	loaded, err := readLayers(v, Files())
+	if err != nil {
+		logger.Error("Failed to read config, keeping the running config", zap.String("file", file), zap.Error(err))
+		return
+	}
+	old := Get()
+	cfg, err := decode(v)
+	if err == nil {
+		err = cfg.ResolveModelKeys()
+	}
+	if err == nil {
+		err = cfg.Validate()
+	}
+	attempted := cfg
+	if attempted == nil {
+		// 引用解析失败时decode不返回配置, 重新解析一份用于输出被拒绝的变更
+		if raw, rawErr := unmarshal(v); rawErr == nil {
+			resolveSecrets(raw)
+			_ = raw.ResolveModelKeys()
+			attempted = raw
+		}
+	}
+	changes := []Change{}
+	if attempted != nil {
+		changes = Diff(old, attempted)
+	}
+	if err != nil {
+		logger.Error("Rejected invalid config, keeping the running config",
+			zap.String("file", file),
+			zap.Stringers("diff", changes),
+			zap.Error(err),
+		)
+		return
+	}
+	setLayers(v, loaded)
+	if len(changes) == 0 {
+		return
+	}
+
+	for _, c := range changes {
+		for _, prefix := range restartKeys {
+			if strings.HasPrefix(c.Key, prefix) {
+				logger.Warn("Config change requires restart to take effect", zap.String("key", c.Key))
+			}
+		}
+	}
+
+	globalConfig.Store(cfg)
+	logger.Info("Config reloaded", zap.String("file", file), zap.Stringers("diff", changes))
+
+	listenersMu.Lock()
+	fns := append([]ChangeFunc(nil), listeners...)
+	listenersMu.Unlock()
+	for _, fn := range fns {
+		fn(old, cfg)
+	}
+}
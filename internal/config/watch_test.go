@@ -0,0 +1,141 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const validConfig = `
+server:
+  port: 8081
+log:
+  level: info
+`
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, "config.yaml", validConfig)
+	if _, err := Load(path, ""); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	var calls []*Config
+	OnChange(func(old, new *Config) {
+		calls = append(calls, new)
+	})
+
+	tests := []struct {
+		name      string
+		content   string
+		wantLevel string
+		wantCalls int
+	}{
+		{"invalid value is rejected", "server:\n  port: 0\nlog:\n  level: debug\n", "info", 0},
+		{"unknown log level is rejected", "server:\n  port: 8081\nlog:\n  level: verbose\n", "info", 0},
+		{
+			name:      "unresolved key of an enabled model is rejected",
+			content:   validConfig + "models:\n  openai:\n    api_key: ${MAT_TEST_UNSET_KEY}\n    timeout: 60s\n    enabled: true\n",
+			wantLevel: "info",
+			wantCalls: 0,
+		},
+		{"malformed yaml is rejected", "server: [\n", "info", 0},
+		{"unchanged file is ignored", validConfig, "info", 0},
+		{"valid change is applied", "server:\n  port: 8081\nlog:\n  level: debug\n", "debug", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := Get()
+			calls = nil
+			writeConfig(t, dir, "config.yaml", tt.content)
+			reload(path)
+
+			if got := Get().Log.Level; got != tt.wantLevel {
+				t.Fatalf("log level = %q, want %q", got, tt.wantLevel)
+			}
+			if len(calls) != tt.wantCalls {
+				t.Fatalf("listener called %d times, want %d", len(calls), tt.wantCalls)
+			}
+			if tt.wantCalls == 0 && Get() != before {
+				t.Fatal("rejected reload replaced the running config")
+			}
+			if tt.wantCalls > 0 && calls[0] != Get() {
+				t.Fatal("listener did not receive the new config")
+			}
+		})
+	}
+}
+
+func TestChangedFile(t *testing.T) {
+	// Kubernetes ConfigMap的目录结构: config.yaml -> ..data/config.yaml, ..data -> 带时间戳的目录
+	dir := t.TempDir()
+	for _, version := range []string{"v1", "v2"} {
+		if err := os.Mkdir(filepath.Join(dir, version), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeConfig(t, filepath.Join(dir, version), "config.yaml", validConfig)
+	}
+	data := filepath.Join(dir, "..data")
+	mustSymlink(t, "v1", data)
+	file := filepath.Join(dir, "config.yaml")
+	mustSymlink(t, filepath.Join("..data", "config.yaml"), file)
+	other := writeConfig(t, dir, "other.txt", "x")
+
+	watched := map[string]string{file: realPath(file)}
+	if _, ok := changedFile(watched, fsnotify.Event{Name: other, Op: fsnotify.Write}); ok {
+		t.Fatal("unrelated file should not trigger a reload")
+	}
+
+	// 原子替换..data链接
+	tmp := filepath.Join(dir, "..data_tmp")
+	mustSymlink(t, "v2", tmp)
+	if err := os.Rename(tmp, data); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := changedFile(watched, fsnotify.Event{Name: data, Op: fsnotify.Create})
+	if !ok || got != file {
+		t.Fatalf("changedFile = %q, %v, want %q after the symlink swap", got, ok, file)
+	}
+	if watched[file] != filepath.Join(realDir(t, dir), "v2", "config.yaml") {
+		t.Fatalf("resolved path not updated: %s", watched[file])
+	}
+	if _, ok := changedFile(watched, fsnotify.Event{Name: data, Op: fsnotify.Create}); ok {
+		t.Fatal("second event without a swap should not trigger a reload")
+	}
+
+	if got, ok := changedFile(watched, fsnotify.Event{Name: file, Op: fsnotify.Write}); !ok || got != file {
+		t.Fatalf("write to the config file should trigger a reload, got %q, %v", got, ok)
+	}
+	if _, ok := changedFile(watched, fsnotify.Event{Name: file, Op: fsnotify.Chmod}); ok {
+		t.Fatal("chmod should not trigger a reload")
+	}
+}
+
+// writeConfig 写入配置文件并返回路径
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func mustSymlink(t *testing.T, target, link string) {
+	t.Helper()
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks are not supported: %v", err)
+	}
+}
+
+// realDir 临时目录本身可能位于符号链接下, 如macOS的/var
+func realDir(t *testing.T, dir string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolved
+}
@@ -0,0 +1,130 @@
+package dataset
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+)
+
+// readCSV 解析CSV数据集, 首行为表头, 列名不区分大小写
+// 默认id/system/prompt/expected/tags/split列映射到用例字段, 其余列作为变量
+// prompt列依次查找prompt、user、input, expected列依次查找expected、ideal
+func (imp *importer) readCSV(r io.Reader, mapping Mapping) error {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("failed to read csv header: %w", err)
+	}
+	// Excel等工具导出的UTF-8 CSV以BOM开头, 不去除时第一列的列名无法匹配
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	index := make(map[string]int, len(header))
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+		index[header[i]] = i
+	}
+
+	// 用例字段对应的列, -1表示没有该列
+	column := func(mapped string, defaults ...string) (int, error) {
+		if mapped != "" {
+			i, ok := index[strings.ToLower(mapped)]
+			if !ok {
+				return -1, fmt.Errorf("column %s not found in csv header", mapped)
+			}
+			return i, nil
+		}
+		for _, name := range defaults {
+			if i, ok := index[name]; ok {
+				return i, nil
+			}
+		}
+		return -1, nil
+	}
+	var cols struct{ id, system, prompt, expected, tags, split int }
+	for _, c := range []struct {
+		target   *int
+		mapped   string
+		defaults []string
+	}{
+		{&cols.id, mapping.ID, []string{"id"}},
+		{&cols.system, mapping.System, []string{"system"}},
+		{&cols.prompt, mapping.Prompt, []string{"prompt", "user", "input"}},
+		{&cols.expected, mapping.Expected, []string{"expected", "ideal"}},
+		{&cols.tags, mapping.Tags, []string{"tags"}},
+		{&cols.split, mapping.Split, []string{"split"}},
+	} {
+		if *c.target, err = column(c.mapped, c.defaults...); err != nil {
+			return err
+		}
+	}
+	if cols.prompt < 0 {
+		return errors.New("csv header has no prompt column")
+	}
+
+	// 作为变量的列
+	variables := map[int]string{}
+	if len(mapping.Variables) > 0 {
+		for _, name := range mapping.Variables {
+			i, err := column(name)
+			if err != nil {
+				return err
+			}
+			variables[i] = header[i]
+		}
+	} else {
+		mapped := map[int]bool{cols.id: true, cols.system: true, cols.prompt: true, cols.expected: true, cols.tags: true, cols.split: true}
+		for i, name := range header {
+			if !mapped[i] {
+				variables[i] = name
+			}
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		imp.result.Rows++
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				imp.fail(parseErr.StartLine, "%v", parseErr.Err)
+				continue
+			}
+			return fmt.Errorf("failed to read csv: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		value := func(i int) string {
+			if i < 0 {
+				return ""
+			}
+			return record[i]
+		}
+		tc := model.TestCase{
+			ID:       value(cols.id),
+			System:   value(cols.system),
+			Prompt:   value(cols.prompt),
+			Expected: value(cols.expected),
+			Tags:     splitTags(value(cols.tags)),
+			Split:    value(cols.split),
+		}
+		if len(variables) > 0 {
+			tc.Variables = make(map[string]interface{}, len(variables))
+			for i, name := range variables {
+				tc.Variables[name] = record[i]
+			}
+		}
+		imp.add(line, tc)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package dataset
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/tidwall/gjson"
+)
+
+// readJSONL 解析JSONL数据集, 每行一个用例
+// input为消息列表时按OpenAI Evals样本解析: system消息作为系统提示词, 唯一的user消息作为提示词
+func (imp *importer) readJSONL(r io.Reader, mapping Mapping) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		imp.result.Rows++
+		if !gjson.Valid(text) || !gjson.Parse(text).IsObject() {
+			imp.fail(line, "invalid json object")
+			continue
+		}
+		if tc, ok := imp.jsonlCase(line, gjson.Parse(text), mapping); ok {
+			imp.add(line, tc)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read dataset: %w", err)
+	}
+	return nil
+}
+
+// jsonlCase 按字段映射提取单行用例, 不合法时记录错误并返回false
+func (imp *importer) jsonlCase(line int, row gjson.Result, mapping Mapping) (model.TestCase, bool) {
+	tc := model.TestCase{
+		ID:     field(row, mapping.ID, "id").String(),
+		System: field(row, mapping.System, "system").String(),
+		Split:  field(row, mapping.Split, "split").String(),
+	}
+
+	input := field(row, mapping.Prompt, "prompt", "user", "input")
+	if input.IsArray() {
+		system, user, err := chatInput(input)
+		if err != nil {
+			imp.fail(line, "%v", err)
+			return tc, false
+		}
+		tc.System = strings.Join(nonEmpty(tc.System, system), "\n\n")
+		tc.Prompt = user
+	} else {
+		tc.Prompt = input.String()
+	}
+
+	expected := field(row, mapping.Expected, "expected", "ideal")
+	if expected.IsArray() {
+		answers := expected.Array()
+		if len(answers) > 0 {
+			tc.Expected = answers[0].String()
+		}
+		if len(answers) > 1 {
+			imp.warn(line, "%d expected answers found, only the first one is used", len(answers))
+		}
+	} else {
+		tc.Expected = expected.String()
+	}
+
+	tags := field(row, mapping.Tags, "tags")
+	if tags.IsArray() {
+		for _, tag := range tags.Array() {
+			tc.Tags = append(tc.Tags, tag.String())
+		}
+	} else {
+		tc.Tags = splitTags(tags.String())
+	}
+
+	if len(mapping.Variables) > 0 {
+		tc.Variables = make(map[string]interface{}, len(mapping.Variables))
+		for _, path := range mapping.Variables {
+			if value := row.Get(path); value.Exists() {
+				tc.Variables[variableName(path)] = value.Value()
+			}
+		}
+	} else if vars := field(row, "", "variables", "vars"); vars.Exists() {
+		values, ok := vars.Value().(map[string]interface{})
+		if !ok {
+			imp.fail(line, "variables must be an object")
+			return tc, false
+		}
+		tc.Variables = values
+	}
+
+	if assertions := row.Get("assertions"); assertions.Exists() {
+		if err := json.Unmarshal([]byte(assertions.Raw), &tc.Assertions); err != nil {
+			imp.fail(line, "invalid assertions: %v", err)
+			return tc, false
+		}
+	}
+	return tc, true
+}
+
+// field 读取映射的字段, 未设置映射时依次尝试默认字段名
+func field(row gjson.Result, path string, defaults ...string) gjson.Result {
+	if path != "" {
+		return row.Get(path)
+	}
+	for _, name := range defaults {
+		if value := row.Get(name); value.Exists() && value.String() != "" {
+			return value
+		}
+	}
+	return gjson.Result{}
+}
+
+// chatInput 解析OpenAI Evals的消息列表, 多条system消息以空行连接
+// 暂不支持多轮对话, 包含assistant消息或多条user消息时返回错误
+func chatInput(input gjson.Result) (string, string, error) {
+	var system []string
+	var user []string
+	for _, msg := range input.Array() {
+		content := msg.Get("content").String()
+		switch role := msg.Get("role").String(); role {
+		case "system":
+			system = append(system, content)
+		case "user":
+			user = append(user, content)
+		case "assistant":
+			return "", "", fmt.Errorf("multi-turn conversation input is not supported")
+		default:
+			return "", "", fmt.Errorf("unsupported message role %q", role)
+		}
+	}
+	if len(user) != 1 {
+		return "", "", fmt.Errorf("input must contain exactly one user message, found %d", len(user))
+	}
+	return strings.Join(system, "\n\n"), user[0], nil
+}
+
+// nonEmpty 过滤空字符串
+func nonEmpty(values ...string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,235 @@
+package dataset
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/multi-agent-testing/backend/internal/assertion"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/prompt"
+)
+
+// 支持的数据集格式
+const (
+	FormatJSONL       = "jsonl"
+	FormatCSV         = "csv"
+	FormatOpenAIEvals = "openai_evals" // OpenAI Evals样本, input为消息列表, ideal为期望输出
+	FormatPromptfoo   = "promptfoo"    // promptfoo测试文件, prompts与tests的组合
+)
+
+// Formats 支持的数据集格式
+var Formats = []string{FormatJSONL, FormatCSV, FormatOpenAIEvals, FormatPromptfoo}
+
+// maxCaseIDLength 用例标识的最大长度, 与dataset_cases.case_key一致
+const maxCaseIDLength = 100
+
+// maxReportedIssues Result.Err中列出的最多问题数
+const maxReportedIssues = 5
+
+// DetectFormat 根据文件名推断数据集格式, OpenAI Evals样本为.jsonl文件, 与JSONL同样解析
+func DetectFormat(filename string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	case ".csv":
+		return FormatCSV, nil
+	case ".yaml", ".yml":
+		return FormatPromptfoo, nil
+	default:
+		return "", fmt.Errorf("unsupported dataset file: %s", filename)
+	}
+}
+
+// Mapping 源字段到用例字段的映射, JSONL中为gjson路径(如meta.question), CSV中为列名
+// 未设置的字段使用默认字段名
+type Mapping struct {
+	ID       string `json:"id,omitempty"`
+	Prompt   string `json:"prompt,omitempty"`
+	System   string `json:"system,omitempty"`
+	Expected string `json:"expected,omitempty"`
+	Tags     string `json:"tags,omitempty"`  // 字符串数组或以逗号、分号分隔的字符串
+	Split    string `json:"split,omitempty"` // train/test/dev
+	// Variables 作为变量的字段, 变量名为路径的最后一段
+	// 未设置时JSONL使用variables或vars字段, CSV中未映射的列均作为变量
+	Variables []string `json:"variables,omitempty"`
+}
+
+// Options 导入选项
+type Options struct {
+	Format  string  `json:"format"`
+	Mapping Mapping `json:"mapping"`
+}
+
+// Issue 导入中某一行的问题, Line为文件中的行号(从1开始)
+type Issue struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// Result 导入结果, 不合法的行记录在Errors中且不计入Cases
+type Result struct {
+	Format   string           `json:"format"`
+	Rows     int              `json:"rows"`     // 读取的行数(不含空行及表头)
+	Imported int              `json:"imported"` // 合法的用例数
+	Errors   []Issue          `json:"errors"`
+	Warnings []Issue          `json:"warnings"` // 不影响导入的问题, 如未设置的变量、不支持的断言
+	Cases    []model.TestCase `json:"-"`
+}
+
+// Err 存在不合法的行时返回汇总错误
+func (r *Result) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, maxReportedIssues)
+	for i, issue := range r.Errors {
+		if i == maxReportedIssues {
+			messages = append(messages, fmt.Sprintf("and %d more", len(r.Errors)-maxReportedIssues))
+			break
+		}
+		messages = append(messages, fmt.Sprintf("line %d: %s", issue.Line, issue.Message))
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
+
+// Import 按格式及字段映射解析数据集, 逐行校验并记录不合法的行
+// 只有文件整体无法读取时(如CSV表头错误、YAML语法错误)返回错误
+func Import(r io.Reader, opts Options) (*Result, error) {
+	imp := &importer{
+		result: &Result{Format: opts.Format, Errors: []Issue{}, Warnings: []Issue{}, Cases: []model.TestCase{}},
+		seen:   make(map[string]int),
+	}
+	var err error
+	switch opts.Format {
+	case FormatJSONL, FormatOpenAIEvals:
+		err = imp.readJSONL(r, opts.Mapping)
+	case FormatCSV:
+		err = imp.readCSV(r, opts.Mapping)
+	case FormatPromptfoo:
+		err = imp.readPromptfoo(r)
+	default:
+		return nil, fmt.Errorf("unsupported dataset format: %s", opts.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	imp.result.Imported = len(imp.result.Cases)
+	return imp.result, nil
+}
+
+// Parse 按格式及默认字段名解析数据集, 存在不合法的行时返回错误
+func Parse(format string, r io.Reader) ([]model.TestCase, error) {
+	result, err := Import(r, Options{Format: format})
+	if err != nil {
+		return nil, err
+	}
+	if err := result.Err(); err != nil {
+		return nil, err
+	}
+	return result.Cases, nil
+}
+
+// importer 导入过程中的状态
+type importer struct {
+	result *Result
+	seen   map[string]int // 用例标识 -> 首次出现的行号
+}
+
+// fail 记录不合法的行
+func (imp *importer) fail(line int, format string, args ...interface{}) {
+	imp.result.Errors = append(imp.result.Errors, Issue{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// warn 记录不影响导入的问题
+func (imp *importer) warn(line int, format string, args ...interface{}) {
+	imp.result.Warnings = append(imp.result.Warnings, Issue{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// add 校验用例, 合法时加入结果
+func (imp *importer) add(line int, tc model.TestCase) {
+	if tc.Prompt == "" {
+		imp.fail(line, "prompt is required")
+		return
+	}
+	if utf8.RuneCountInString(tc.ID) > maxCaseIDLength {
+		imp.fail(line, "id exceeds %d characters", maxCaseIDLength)
+		return
+	}
+	if tc.ID != "" {
+		if first, ok := imp.seen[tc.ID]; ok {
+			imp.fail(line, "duplicate id %s, first seen on line %d", tc.ID, first)
+			return
+		}
+	}
+	if tc.Split != "" && !slices.Contains(model.Splits, tc.Split) {
+		imp.fail(line, "unsupported split %s", tc.Split)
+		return
+	}
+	// 没有变量的用例按原文使用, 不解析模板语法
+	var referenced []string
+	if len(tc.Variables) > 0 {
+		var err error
+		if referenced, err = prompt.ReferencedVariables(tc.System, tc.Prompt); err != nil {
+			imp.fail(line, "%v", err)
+			return
+		}
+	}
+	if err := assertion.Validate(tc.Assertions); err != nil {
+		imp.fail(line, "%v", err)
+		return
+	}
+
+	for _, name := range referenced {
+		if _, ok := tc.Variables[name]; !ok {
+			imp.warn(line, "variable %s is not set", name)
+		}
+	}
+	if tc.ID != "" {
+		imp.seen[tc.ID] = line
+	}
+	tc.Tags = normalizeTags(tc.Tags)
+	imp.result.Cases = append(imp.result.Cases, tc)
+}
+
+// splitTags 拆分以逗号或分号分隔的标签
+func splitTags(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' })
+}
+
+// normalizeTags 去除标签首尾空白、空标签及重复标签
+func normalizeTags(tags []string) []string {
+	var result []string
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// variableName 字段路径作为变量名时取最后一段
+func variableName(path string) string {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
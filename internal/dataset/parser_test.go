@@ -0,0 +1,432 @@
+package dataset
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+)
+
+// mustImport 导入数据集, 文件整体无法读取时终止测试
+func mustImport(t *testing.T, format, data string, mapping Mapping) *Result {
+	t.Helper()
+	result, err := Import(strings.NewReader(data), Options{Format: format, Mapping: mapping})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	return result
+}
+
+// issueLines 返回问题所在的行号
+func issueLines(issues []Issue) []int {
+	lines := []int{}
+	for _, issue := range issues {
+		lines = append(lines, issue.Line)
+	}
+	return lines
+}
+
+// checkIssues 校验问题的行号及信息
+func checkIssues(t *testing.T, kind string, issues []Issue, want map[int]string) {
+	t.Helper()
+	if len(issues) != len(want) {
+		t.Fatalf("%s on lines %v, want %d %s: %v", kind, issueLines(issues), len(want), kind, issues)
+	}
+	for _, issue := range issues {
+		msg, ok := want[issue.Line]
+		if !ok || !strings.Contains(issue.Message, msg) {
+			t.Fatalf("%s line %d: %q, want %q", kind, issue.Line, issue.Message, msg)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{"cases.jsonl", FormatJSONL},
+		{"cases.NDJSON", FormatJSONL},
+		{"cases.csv", FormatCSV},
+		{"promptfooconfig.yaml", FormatPromptfoo},
+		{"tests.yml", FormatPromptfoo},
+		{"cases.json", ""},
+	}
+	for _, tt := range tests {
+		got, err := DetectFormat(tt.filename)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("DetectFormat(%q) = %q, want error", tt.filename, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("DetectFormat(%q) = %q, %v, want %q", tt.filename, got, err, tt.want)
+		}
+	}
+}
+
+func TestImportJSONL(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		mapping  Mapping
+		want     []model.TestCase
+		errors   map[int]string
+		warnings map[int]string
+	}{
+		{
+			name: "default fields",
+			data: `{"id": "c1", "system": "Be brief", "prompt": "Capital of {{ country }}?", "vars": {"country": "France"}, "expected": "Paris", "tags": "geo; easy, geo", "split": "test"}`,
+			want: []model.TestCase{{
+				ID: "c1", System: "Be brief", Prompt: "Capital of {{ country }}?", Expected: "Paris",
+				Variables: map[string]interface{}{"country": "France"}, Tags: []string{"geo", "easy"}, Split: "test",
+			}},
+		},
+		{
+			name: "fallback field names",
+			data: `{"user": "hi", "ideal": "hello", "variables": {"x": 1}, "tags": ["a", " b "]}` + "\n" + `{"input": "bye"}`,
+			want: []model.TestCase{
+				{Prompt: "hi", Expected: "hello", Variables: map[string]interface{}{"x": float64(1)}, Tags: []string{"a", "b"}},
+				{Prompt: "bye"},
+			},
+		},
+		{
+			name:    "mapping",
+			data:    `{"uid": 7, "q": {"text": "Translate {{ word }}"}, "a": "Hallo", "meta": {"word": "hello", "lang": "de"}, "bucket": "dev"}`,
+			mapping: Mapping{ID: "uid", Prompt: "q.text", Expected: "a", Split: "bucket", Variables: []string{"meta.word", "meta.missing"}},
+			want: []model.TestCase{{
+				ID: "7", Prompt: "Translate {{ word }}", Expected: "Hallo", Split: "dev",
+				Variables: map[string]interface{}{"word": "hello"},
+			}},
+		},
+		{
+			name: "assertions",
+			data: `{"prompt": "hi", "assertions": [{"type": "contains", "value": "hello"}]}`,
+			want: []model.TestCase{{Prompt: "hi", Assertions: []model.Assertion{{Type: model.AssertContains, Value: "hello"}}}},
+		},
+		{
+			name: "literal template syntax without variables",
+			data: `{"prompt": "Explain {{ user.name }} and {% for x in y %} in Jinja"}`,
+			want: []model.TestCase{{Prompt: "Explain {{ user.name }} and {% for x in y %} in Jinja"}},
+		},
+		{
+			name: "bad rows",
+			data: strings.Join([]string{
+				`{"id": "a", "prompt": "one"}`,
+				`not json`,
+				`[1, 2]`,
+				``,
+				`{"id": "a", "prompt": "dup"}`,
+				`{"id": "b"}`,
+				`{"prompt": "x", "split": "holdout"}`,
+				`{"prompt": "x", "vars": ["a"]}`,
+				`{"prompt": "x", "assertions": {"type": "contains"}}`,
+				`{"prompt": "x", "assertions": [{"type": "similar"}]}`,
+				`{"prompt": "{{ broken", "vars": {"a": 1}}`,
+				`{"id": "` + strings.Repeat("x", maxCaseIDLength+1) + `", "prompt": "x"}`,
+			}, "\n"),
+			want: []model.TestCase{{ID: "a", Prompt: "one"}},
+			errors: map[int]string{
+				2:  "invalid json object",
+				3:  "invalid json object",
+				5:  "duplicate id a, first seen on line 1",
+				6:  "prompt is required",
+				7:  "unsupported split holdout",
+				8:  "variables must be an object",
+				9:  "invalid assertions",
+				10: "unknown type",
+				11: "invalid template",
+				12: "id exceeds",
+			},
+		},
+		{
+			name:     "unset variable",
+			data:     `{"prompt": "{{ a }} {{ b }}", "vars": {"a": 1}}`,
+			want:     []model.TestCase{{Prompt: "{{ a }} {{ b }}", Variables: map[string]interface{}{"a": float64(1)}}},
+			warnings: map[int]string{1: "variable b is not set"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := mustImport(t, FormatJSONL, tt.data, tt.mapping)
+			checkIssues(t, "errors", result.Errors, tt.errors)
+			checkIssues(t, "warnings", result.Warnings, tt.warnings)
+			if !reflect.DeepEqual(result.Cases, tt.want) {
+				t.Fatalf("cases = %+v, want %+v", result.Cases, tt.want)
+			}
+			if result.Imported != len(tt.want) {
+				t.Fatalf("Imported = %d, want %d", result.Imported, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestImportOpenAIEvals(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		want     []model.TestCase
+		errors   map[int]string
+		warnings map[int]string
+	}{
+		{
+			name: "chat input",
+			data: `{"input": [{"role": "system", "content": "Answer tersely"}, {"role": "system", "content": "Use English"}, {"role": "user", "content": "2+2?"}], "ideal": "4"}`,
+			want: []model.TestCase{{System: "Answer tersely\n\nUse English", Prompt: "2+2?", Expected: "4"}},
+		},
+		{
+			name: "system field and system message",
+			data: `{"system": "Base", "input": [{"role": "system", "content": "Extra"}, {"role": "user", "content": "q"}]}`,
+			want: []model.TestCase{{System: "Base\n\nExtra", Prompt: "q"}},
+		},
+		{
+			name:     "multiple ideal answers",
+			data:     `{"input": [{"role": "user", "content": "q"}], "ideal": ["a", "b"]}`,
+			want:     []model.TestCase{{Prompt: "q", Expected: "a"}},
+			warnings: map[int]string{1: "2 expected answers found"},
+		},
+		{
+			name: "unsupported input",
+			data: strings.Join([]string{
+				`{"input": [{"role": "user", "content": "q"}, {"role": "assistant", "content": "a"}, {"role": "user", "content": "q2"}]}`,
+				`{"input": [{"role": "system", "content": "s"}]}`,
+				`{"input": [{"role": "tool", "content": "t"}, {"role": "user", "content": "q"}]}`,
+			}, "\n"),
+			want: []model.TestCase{},
+			errors: map[int]string{
+				1: "multi-turn conversation input is not supported",
+				2: "exactly one user message, found 0",
+				3: `unsupported message role "tool"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := mustImport(t, FormatOpenAIEvals, tt.data, Mapping{})
+			checkIssues(t, "errors", result.Errors, tt.errors)
+			checkIssues(t, "warnings", result.Warnings, tt.warnings)
+			if !reflect.DeepEqual(result.Cases, tt.want) {
+				t.Fatalf("cases = %+v, want %+v", result.Cases, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		mapping Mapping
+		want    []model.TestCase
+		errors  map[int]string
+	}{
+		{
+			name: "default columns and variables",
+			data: "ID,Prompt,Expected,Tags,Split,Country\nc1,Capital of {{ country }}?,Paris,\"geo,easy\",test,France\n",
+			want: []model.TestCase{{
+				ID: "c1", Prompt: "Capital of {{ country }}?", Expected: "Paris", Tags: []string{"geo", "easy"}, Split: "test",
+				Variables: map[string]interface{}{"country": "France"},
+			}},
+		},
+		{
+			name: "utf-8 bom",
+			data: "\ufeffid,prompt\nc1,hello\n",
+			want: []model.TestCase{{ID: "c1", Prompt: "hello"}},
+		},
+		{
+			name: "fallback column names",
+			data: "input,ideal\nq,a\n",
+			want: []model.TestCase{{Prompt: "q", Expected: "a"}},
+		},
+		{
+			name:    "mapping",
+			data:    "question,answer,lang,notes\nhola,hello,es,ignored\n",
+			mapping: Mapping{Prompt: "Question", Expected: "answer", Variables: []string{"lang"}},
+			want: []model.TestCase{{
+				Prompt: "hola", Expected: "hello", Variables: map[string]interface{}{"lang": "es"},
+			}},
+		},
+		{
+			name: "multiline field",
+			data: "id,prompt\na,\"line one\nline two\"\nb,x\nb,y\n",
+			want: []model.TestCase{{ID: "a", Prompt: "line one\nline two"}, {ID: "b", Prompt: "x"}},
+			errors: map[int]string{
+				5: "duplicate id b, first seen on line 4",
+			},
+		},
+		{
+			name: "bad rows",
+			data: "id,prompt,split\na,one,\nb,,\nc,x,holdout\nd,too,many,fields\ne,\"unterminated\n",
+			want: []model.TestCase{{ID: "a", Prompt: "one"}},
+			errors: map[int]string{
+				3: "prompt is required",
+				4: "unsupported split holdout",
+				5: "wrong number of fields",
+				6: "extraneous or missing",
+			},
+		},
+		{
+			name: "empty file",
+			data: "",
+			want: []model.TestCase{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := mustImport(t, FormatCSV, tt.data, tt.mapping)
+			checkIssues(t, "errors", result.Errors, tt.errors)
+			if !reflect.DeepEqual(result.Cases, tt.want) {
+				t.Fatalf("cases = %+v, want %+v", result.Cases, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportCSVInvalidHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		mapping Mapping
+		wantErr string
+	}{
+		{"no prompt column", "id,question\na,b\n", Mapping{}, "no prompt column"},
+		{"bom without prompt column", "\ufeffid,question\na,b\n", Mapping{}, "no prompt column"},
+		{"mapped column missing", "id,prompt\na,b\n", Mapping{Expected: "answer"}, "column answer not found"},
+		{"mapped variable missing", "id,prompt\na,b\n", Mapping{Variables: []string{"lang"}}, "column lang not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Import(strings.NewReader(tt.data), Options{Format: FormatCSV, Mapping: tt.mapping})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Import error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImportPromptfoo(t *testing.T) {
+	data := `prompts:
+  - "Summarize: {{ text }}"
+  - id: chat
+    raw: '[{"role": "system", "content": "You are terse"}, {"role": "user", "content": "Summarize {{ text }}"}]'
+defaultTest:
+  vars:
+    lang: en
+  assert:
+    - type: latency
+      threshold: 5000
+  metadata:
+    tags: [summary]
+tests:
+  - description: short text
+    vars:
+      text: hello world
+    assert:
+      - type: icontains
+        value: Hello
+        metric: greeting
+      - type: contains-all
+        value: [hello, world]
+      - type: llm-rubric
+        value: is concise
+  - description: short text
+    vars:
+      text: again
+    metadata:
+      tags: [dup]
+  - vars:
+      text: no description
+    assert:
+      - type: equals
+        value: "a.b"
+`
+	result := mustImport(t, FormatPromptfoo, data, Mapping{})
+	checkIssues(t, "errors", result.Errors, nil)
+	checkIssues(t, "warnings", result.Warnings, map[int]string{14: "llm-rubric is not supported"})
+	if result.Rows != 3 || result.Imported != 6 {
+		t.Fatalf("Rows = %d, Imported = %d, want 3 and 6", result.Rows, result.Imported)
+	}
+
+	ids := []string{}
+	for _, tc := range result.Cases {
+		ids = append(ids, tc.ID)
+	}
+	wantIDs := []string{
+		"short text/prompt-1", "short text/chat",
+		"short text test-2/prompt-1", "short text test-2/chat",
+		"test-3/prompt-1", "test-3/chat",
+	}
+	if !reflect.DeepEqual(ids, wantIDs) {
+		t.Fatalf("ids = %q, want %q", ids, wantIDs)
+	}
+
+	first, chat := result.Cases[0], result.Cases[1]
+	if first.Prompt != "Summarize: {{ text }}" || first.System != "" {
+		t.Fatalf("text prompt = %q / %q", first.System, first.Prompt)
+	}
+	if chat.System != "You are terse" || chat.Prompt != "Summarize {{ text }}" {
+		t.Fatalf("chat prompt = %q / %q", chat.System, chat.Prompt)
+	}
+	if want := map[string]interface{}{"lang": "en", "text": "hello world"}; !reflect.DeepEqual(first.Variables, want) {
+		t.Fatalf("variables = %v, want %v", first.Variables, want)
+	}
+	max := 5000.0
+	wantAssertions := []model.Assertion{
+		{Type: model.AssertLatency, Max: &max},
+		{Type: model.AssertContains, Name: "greeting", Value: "Hello", IgnoreCase: true},
+		{Type: model.AssertContains, Value: "hello"},
+		{Type: model.AssertContains, Value: "world"},
+	}
+	if !reflect.DeepEqual(first.Assertions, wantAssertions) {
+		t.Fatalf("assertions = %+v, want %+v", first.Assertions, wantAssertions)
+	}
+	if tags := result.Cases[2].Tags; !reflect.DeepEqual(tags, []string{"summary", "dup"}) {
+		t.Fatalf("tags = %v, want default and test tags", tags)
+	}
+	if a := result.Cases[4].Assertions[1]; a.Type != model.AssertRegex || a.Value != `^a\.b$` {
+		t.Fatalf("equals converted to %+v", a)
+	}
+}
+
+func TestImportPromptfooInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"yaml syntax", "prompts: [\n", "invalid promptfoo file"},
+		{"no prompts", "tests:\n  - vars: {a: 1}\n", "no prompts"},
+		{"no tests", "prompts: [hi]\n", "no tests"},
+		{"tests file reference", "prompts: [hi]\ntests: file://tests.csv\n", "tests must be a list"},
+		{"prompt file reference", "prompts: [file://prompt.txt]\ntests:\n  - vars: {}\n", "prompt file references are not supported"},
+		{"chat prompt without user", "prompts:\n  - '[{\"role\": \"system\", \"content\": \"s\"}]'\ntests:\n  - vars: {}\n", "exactly one user message"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Import(strings.NewReader(tt.data), Options{Format: FormatPromptfoo})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Import error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	if _, err := Parse(FormatJSONL, strings.NewReader(`{"prompt": "ok"}`+"\n"+`{}`)); err == nil || !strings.Contains(err.Error(), "line 2: prompt is required") {
+		t.Fatalf("Parse error = %v, want the invalid line", err)
+	}
+	if _, err := Import(strings.NewReader(""), Options{Format: "xlsx"}); err == nil {
+		t.Fatalf("Import accepted an unsupported format")
+	}
+
+	var lines []string
+	for i := 0; i < maxReportedIssues+2; i++ {
+		lines = append(lines, "{}")
+	}
+	_, err := Parse(FormatJSONL, strings.NewReader(strings.Join(lines, "\n")))
+	if err == nil || !strings.HasSuffix(err.Error(), "and 2 more") {
+		t.Fatalf("Parse error = %v, want the issue list truncated", err)
+	}
+}
@@ -0,0 +1,278 @@
+package dataset
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/tidwall/gjson"
+	"gopkg.in/yaml.v3"
+)
+
+// promptfooFile promptfoo测试文件中支持的部分
+type promptfooFile struct {
+	Prompts     []yaml.Node `yaml:"prompts"`
+	DefaultTest yaml.Node   `yaml:"defaultTest"`
+	Tests       yaml.Node   `yaml:"tests"`
+}
+
+// promptfooTest promptfoo中的单个测试
+type promptfooTest struct {
+	Description string                 `yaml:"description"`
+	Vars        map[string]interface{} `yaml:"vars"`
+	Assert      []promptfooAssert      `yaml:"assert"`
+	Metadata    struct {
+		Tags []string `yaml:"tags"`
+	} `yaml:"metadata"`
+}
+
+// promptfooAssert promptfoo断言
+type promptfooAssert struct {
+	Type      string      `yaml:"type"`
+	Value     interface{} `yaml:"value"`
+	Threshold *float64    `yaml:"threshold"`
+	Metric    string      `yaml:"metric"`
+}
+
+// promptfooPrompt 解析后的提示词
+type promptfooPrompt struct {
+	label  string
+	system string
+	user   string
+}
+
+// readPromptfoo 解析promptfoo测试文件, 每个prompt与每个test组合为一个用例
+// prompt可为文本或聊天消息JSON, 变量语法与本平台一致; 不支持file://引用
+// 无法转换的断言(如llm-rubric、javascript)跳过并记录警告
+func (imp *importer) readPromptfoo(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read dataset: %w", err)
+	}
+	var file promptfooFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("invalid promptfoo file: %w", err)
+	}
+
+	prompts := make([]promptfooPrompt, 0, len(file.Prompts))
+	for i := range file.Prompts {
+		p, err := parsePromptfooPrompt(&file.Prompts[i])
+		if err != nil {
+			return fmt.Errorf("line %d: %w", file.Prompts[i].Line, err)
+		}
+		prompts = append(prompts, p)
+	}
+	if len(prompts) == 0 {
+		return errors.New("promptfoo file has no prompts")
+	}
+	if file.Tests.Kind != yaml.SequenceNode {
+		if file.Tests.Kind == 0 {
+			return errors.New("promptfoo file has no tests")
+		}
+		return fmt.Errorf("line %d: tests must be a list, file references are not supported", file.Tests.Line)
+	}
+
+	var defaultTest promptfooTest
+	if file.DefaultTest.Kind != 0 {
+		if err := file.DefaultTest.Decode(&defaultTest); err != nil {
+			return fmt.Errorf("line %d: invalid defaultTest: %w", file.DefaultTest.Line, err)
+		}
+	}
+	defaults, defaultWarnings := convertPromptfooAsserts(defaultTest.Assert)
+	for _, msg := range defaultWarnings {
+		imp.warn(file.DefaultTest.Line, "defaultTest: %s", msg)
+	}
+
+	used := make(map[string]bool, len(file.Tests.Content))
+
+	for i, node := range file.Tests.Content {
+		line := node.Line
+		imp.result.Rows++
+		var test promptfooTest
+		if err := node.Decode(&test); err != nil {
+			imp.fail(line, "invalid test: %v", err)
+			continue
+		}
+
+		assertions, warnings := convertPromptfooAsserts(test.Assert)
+		for _, msg := range warnings {
+			imp.warn(line, "%s", msg)
+		}
+		vars := make(map[string]interface{}, len(defaultTest.Vars)+len(test.Vars))
+		for k, v := range defaultTest.Vars {
+			vars[k] = v
+		}
+		for k, v := range test.Vars {
+			vars[k] = v
+		}
+
+		// 以描述作为用例标识, 描述为空或重复时使用测试序号
+		id := truncateRunes(strings.TrimSpace(test.Description), maxDescriptionID)
+		if id == "" || used[id] {
+			id = strings.TrimSpace(id + fmt.Sprintf(" test-%d", i+1))
+		}
+		used[id] = true
+		for j, p := range prompts {
+			tc := model.TestCase{
+				ID:         id,
+				System:     p.system,
+				Prompt:     p.user,
+				Variables:  vars,
+				Assertions: append(append([]model.Assertion{}, defaults...), assertions...),
+				Tags:       append(append([]string{}, defaultTest.Metadata.Tags...), test.Metadata.Tags...),
+			}
+			if len(prompts) > 1 {
+				label := p.label
+				if label == "" {
+					label = fmt.Sprintf("prompt-%d", j+1)
+				}
+				tc.ID = id + "/" + label
+			}
+			imp.add(line, tc)
+		}
+	}
+	return nil
+}
+
+// maxDescriptionID 描述作为用例标识时保留的最大字符数, 为prompt后缀预留长度
+const maxDescriptionID = 80
+
+// truncateRunes 按字符截断字符串
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// parsePromptfooPrompt 解析prompts中的一项, 可为字符串或含raw字段的对象
+func parsePromptfooPrompt(node *yaml.Node) (promptfooPrompt, error) {
+	var p promptfooPrompt
+	var raw string
+	switch node.Kind {
+	case yaml.ScalarNode:
+		raw = node.Value
+	case yaml.MappingNode:
+		var obj struct {
+			ID    string `yaml:"id"`
+			Label string `yaml:"label"`
+			Raw   string `yaml:"raw"`
+		}
+		if err := node.Decode(&obj); err != nil {
+			return p, err
+		}
+		raw, p.label = obj.Raw, firstNonEmpty(obj.Label, obj.ID)
+		if strings.HasPrefix(obj.ID, "file://") && raw == "" {
+			raw = obj.ID
+		}
+	default:
+		return p, errors.New("prompt must be a string or an object with raw")
+	}
+
+	if strings.HasPrefix(raw, "file://") {
+		return p, fmt.Errorf("prompt file references are not supported: %s", raw)
+	}
+	if raw == "" {
+		return p, errors.New("prompt is empty")
+	}
+	// 聊天格式的提示词为消息列表JSON
+	if trimmed := strings.TrimSpace(raw); strings.HasPrefix(trimmed, "[") && gjson.Valid(trimmed) {
+		system, user, err := chatInput(gjson.Parse(trimmed))
+		if err != nil {
+			return p, err
+		}
+		p.system, p.user = system, user
+		return p, nil
+	}
+	p.user = raw
+	return p, nil
+}
+
+// convertPromptfooAsserts 将promptfoo断言转换为本平台断言, 返回无法转换的断言说明
+func convertPromptfooAsserts(asserts []promptfooAssert) ([]model.Assertion, []string) {
+	var result []model.Assertion
+	var warnings []string
+	for _, a := range asserts {
+		converted, ok := convertPromptfooAssert(a)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("assertion type %s is not supported, skipped", a.Type))
+			continue
+		}
+		for i := range converted {
+			converted[i].Name = a.Metric
+		}
+		result = append(result, converted...)
+	}
+	return result, warnings
+}
+
+// convertPromptfooAssert 转换单个断言, contains-all展开为多个contains断言
+func convertPromptfooAssert(a promptfooAssert) ([]model.Assertion, bool) {
+	value := ""
+	if a.Value != nil {
+		value = fmt.Sprint(a.Value)
+	}
+	switch a.Type {
+	case "equals":
+		return []model.Assertion{{Type: model.AssertRegex, Value: "^" + regexp.QuoteMeta(value) + "$"}}, true
+	case "contains", "icontains":
+		return []model.Assertion{{Type: model.AssertContains, Value: value, IgnoreCase: a.Type == "icontains"}}, true
+	case "not-contains", "not-icontains":
+		return []model.Assertion{{Type: model.AssertNotContains, Value: value, IgnoreCase: a.Type == "not-icontains"}}, true
+	case "starts-with":
+		return []model.Assertion{{Type: model.AssertStartsWith, Value: value}}, true
+	case "regex":
+		return []model.Assertion{{Type: model.AssertRegex, Value: value}}, true
+	case "is-json":
+		return []model.Assertion{{Type: model.AssertIsJSON}}, true
+	case "contains-all", "icontains-all":
+		values := promptfooValues(a.Value)
+		result := make([]model.Assertion, 0, len(values))
+		for _, v := range values {
+			result = append(result, model.Assertion{Type: model.AssertContains, Value: v, IgnoreCase: a.Type == "icontains-all"})
+		}
+		return result, len(result) > 0
+	case "contains-any", "icontains-any":
+		values := promptfooValues(a.Value)
+		if len(values) == 0 {
+			return nil, false
+		}
+		for i := range values {
+			values[i] = regexp.QuoteMeta(values[i])
+		}
+		return []model.Assertion{{Type: model.AssertRegex, Value: strings.Join(values, "|"), IgnoreCase: a.Type == "icontains-any"}}, true
+	case "latency", "cost":
+		if a.Threshold == nil {
+			return nil, false
+		}
+		typ := model.AssertLatency
+		if a.Type == "cost" {
+			typ = model.AssertCost
+		}
+		return []model.Assertion{{Type: typ, Max: a.Threshold}}, true
+	default:
+		return nil, false
+	}
+}
+
+// promptfooValues 读取列表形式的断言值, 也接受逗号分隔的字符串
+func promptfooValues(value interface{}) []string {
+	var values []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+	case string:
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,103 @@
+package judge
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"sync"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+)
+
+// DefaultCacheSize 默认缓存条数
+const DefaultCacheSize = 1000
+
+// Cache 评审结果的LRU缓存, 相同评审模型与提示词直接复用结果
+type Cache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+// cacheEntry 缓存项
+type cacheEntry struct {
+	key    string
+	result model.JudgeResult
+}
+
+// NewCache 创建缓存, size不大于0时返回nil, 表示不缓存
+func NewCache(size int) *Cache {
+	if size <= 0 {
+		return nil
+	}
+	return &Cache{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Key 根据评审模型、评分标准及提示词生成缓存键
+// 分数范围及通过分数影响对输出的解析, 即使未出现在提示词中也需计入
+func Key(provider, modelName string, rubric model.Rubric, prompts model.PromptSet) string {
+	h := sha256.New()
+	parts := []string{
+		provider, modelName, rubric.Name, rubric.Type,
+		strconv.FormatFloat(rubric.MinScore, 'g', -1, 64),
+		strconv.FormatFloat(rubric.MaxScore, 'g', -1, 64),
+		strconv.FormatFloat(rubric.PassScore, 'g', -1, 64),
+		prompts.System, prompts.User,
+	}
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get 查询缓存, 命中时返回结果副本
+func (c *Cache) Get(key string) (model.JudgeResult, bool) {
+	if c == nil {
+		return model.JudgeResult{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[key]
+	if !ok {
+		return model.JudgeResult{}, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).result, true
+}
+
+// Put 写入缓存, 超出容量时淘汰最久未使用的项
+func (c *Cache) Put(key string, result model.JudgeResult) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*cacheEntry).result = result
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.order.PushFront(&cacheEntry{key: key, result: result})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Len 返回缓存条数
+func (c *Cache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,175 @@
+package judge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/prompt"
+)
+
+// 默认分数范围
+const (
+	defaultMinScore = 1
+	defaultMaxScore = 10
+)
+
+// ErrInvalidRubric 评分标准不合法
+var ErrInvalidRubric = errors.New("invalid rubric")
+
+// systemPrompt 评审模型的系统提示词, 约定输出格式以便解析
+const systemPrompt = `You are an impartial judge evaluating the response of an AI assistant.
+Judge only the response against the criteria, do not let its length or style bias you.
+Reply with a single JSON object and nothing else.`
+
+// scoreFormat score类型的输出格式要求
+const scoreFormat = `Respond with JSON: {"score": <number between %g and %g>, "reason": "<one or two sentences>"}`
+
+// passFailFormat pass_fail类型的输出格式要求
+const passFailFormat = `Respond with JSON: {"pass": <true or false>, "reason": "<one or two sentences>"}`
+
+// defaultTemplate 内置评审提示词模板
+const defaultTemplate = `[Criteria]
+{{ criteria }}
+
+[Question]
+{{ prompt }}
+{% if reference %}
+[Reference Answer]
+{{ reference }}
+{% endif %}
+[Response]
+{{ output }}`
+
+// Input 评审的输入
+type Input struct {
+	Prompt    string // 被测模型收到的用户提示词
+	Output    string // 被测模型的输出
+	Reference string // 参考答案, 可为空
+}
+
+// Normalize 填充评分标准的默认值并校验
+func Normalize(r model.Rubric) (model.Rubric, error) {
+	if r.Type == "" {
+		r.Type = model.RubricTypeScore
+	}
+	if r.Name == "" {
+		r.Name = "inline"
+	}
+	if strings.TrimSpace(r.Criteria) == "" && r.Template == "" {
+		return r, fmt.Errorf("%w: %s: criteria or template is required", ErrInvalidRubric, r.Name)
+	}
+
+	switch r.Type {
+	case model.RubricTypeScore:
+		if r.MinScore == 0 && r.MaxScore == 0 {
+			r.MinScore, r.MaxScore = defaultMinScore, defaultMaxScore
+		}
+		if r.MinScore >= r.MaxScore {
+			return r, fmt.Errorf("%w: %s: min_score must be less than max_score", ErrInvalidRubric, r.Name)
+		}
+		if r.PassScore != 0 && (r.PassScore < r.MinScore || r.PassScore > r.MaxScore) {
+			return r, fmt.Errorf("%w: %s: pass_score must be within the score range", ErrInvalidRubric, r.Name)
+		}
+	case model.RubricTypePassFail:
+	default:
+		return r, fmt.Errorf("%w: %s: type must be score or pass_fail", ErrInvalidRubric, r.Name)
+	}
+
+	if r.Template != "" {
+		if err := prompt.Parse(r.Template); err != nil {
+			return r, fmt.Errorf("%w: %s: %v", ErrInvalidRubric, r.Name, err)
+		}
+	}
+	return r, nil
+}
+
+// BuildPrompt 根据评分标准生成评审提示词, rubric需已经过Normalize
+func BuildPrompt(r model.Rubric, in Input) (model.PromptSet, error) {
+	tpl := r.Template
+	if tpl == "" {
+		tpl = defaultTemplate
+	}
+	user, err := prompt.Render(tpl, map[string]interface{}{
+		"prompt":    in.Prompt,
+		"output":    in.Output,
+		"reference": in.Reference,
+		"criteria":  r.Criteria,
+		"min_score": r.MinScore,
+		"max_score": r.MaxScore,
+	})
+	if err != nil {
+		return model.PromptSet{}, fmt.Errorf("failed to render judge prompt: %w", err)
+	}
+
+	format := passFailFormat
+	if r.Type == model.RubricTypeScore {
+		format = fmt.Sprintf(scoreFormat, r.MinScore, r.MaxScore)
+	}
+	return model.PromptSet{
+		System: systemPrompt,
+		User:   strings.TrimSpace(user) + "\n\n" + format,
+	}, nil
+}
+
+// verdict 评审模型输出的JSON
+type verdict struct {
+	Score  *float64 `json:"score"`
+	Pass   *bool    `json:"pass"`
+	Reason string   `json:"reason"`
+}
+
+// Parse 解析评审模型的输出, 填充分数、结论及理由
+func Parse(r model.Rubric, content string, result *model.JudgeResult) error {
+	raw := extractJSON(content)
+	if raw == "" {
+		return fmt.Errorf("judge output is not JSON: %q", truncate(content, 200))
+	}
+	var v verdict
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return fmt.Errorf("invalid judge output: %v", err)
+	}
+	result.Reason = v.Reason
+
+	if r.Type == model.RubricTypePassFail {
+		if v.Pass == nil {
+			return errors.New("judge output is missing pass")
+		}
+		result.Passed = v.Pass
+		return nil
+	}
+
+	if v.Score == nil {
+		return errors.New("judge output is missing score")
+	}
+	if *v.Score < r.MinScore || *v.Score > r.MaxScore {
+		return fmt.Errorf("judge score %g is out of range [%g, %g]", *v.Score, r.MinScore, r.MaxScore)
+	}
+	result.Score = v.Score
+	result.MaxScore = r.MaxScore
+	if r.PassScore != 0 {
+		passed := *v.Score >= r.PassScore
+		result.Passed = &passed
+	}
+	return nil
+}
+
+// extractJSON 提取输出中的JSON对象, 兼容代码块包裹及前后多余的文字
+func extractJSON(content string) string {
+	start := strings.IndexByte(content, '{')
+	end := strings.LastIndexByte(content, '}')
+	if start < 0 || end < start {
+		return ""
+	}
+	return content[start : end+1]
+}
+
+// truncate 截断过长的文本, 用于错误信息
+func truncate(s string, n int) string {
+	if len([]rune(s)) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "..."
+}
@@ -0,0 +1,332 @@
+package judge
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+)
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"plain", `{"score": 7}`, `{"score": 7}`},
+		{"code fence", "```json\n{\"score\": 7, \"reason\": \"ok\"}\n```", `{"score": 7, "reason": "ok"}`},
+		{"surrounding text", `Verdict: {"pass": true} as requested.`, `{"pass": true}`},
+		{"nested object", `{"score": 7, "detail": {"a": 1}} done`, `{"score": 7, "detail": {"a": 1}}`},
+		{"braces in reason", `{"score": 5, "reason": "uses {x} syntax"}`, `{"score": 5, "reason": "uses {x} syntax"}`},
+		{"empty", "", ""},
+		{"no object", "score: 7", ""},
+		{"only closing brace", "} then {", ""},
+		{"unclosed", `{"score": 7`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractJSON(tt.content); got != tt.want {
+				t.Fatalf("extractJSON = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	score := model.Rubric{Name: "quality", Type: model.RubricTypeScore, MinScore: 1, MaxScore: 10}
+	withPass := score
+	withPass.PassScore = 7
+	passFail := model.Rubric{Name: "safe", Type: model.RubricTypePassFail}
+
+	tests := []struct {
+		name       string
+		rubric     model.Rubric
+		content    string
+		wantScore  *float64
+		wantPassed *bool
+		wantReason string
+		wantErr    string
+	}{
+		{name: "score", rubric: score, content: `{"score": 8, "reason": "good"}`, wantScore: ptr(8.0), wantReason: "good"},
+		{name: "fractional score", rubric: score, content: "```json\n{\"score\": 6.5}\n```", wantScore: ptr(6.5)},
+		{name: "score at min", rubric: score, content: `{"score": 1}`, wantScore: ptr(1.0)},
+		{name: "pass score reached", rubric: withPass, content: `{"score": 7}`, wantScore: ptr(7.0), wantPassed: ptr(true)},
+		{name: "pass score missed", rubric: withPass, content: `{"score": 6.9}`, wantScore: ptr(6.9), wantPassed: ptr(false)},
+		{name: "pass", rubric: passFail, content: `Sure. {"pass": true, "reason": "fine"}`, wantPassed: ptr(true), wantReason: "fine"},
+		{name: "fail", rubric: passFail, content: `{"pass": false}`, wantPassed: ptr(false)},
+		{name: "score out of range", rubric: score, content: `{"score": 11}`, wantErr: "out of range"},
+		{name: "score below range", rubric: score, content: `{"score": 0}`, wantErr: "out of range"},
+		{name: "missing score", rubric: score, content: `{"reason": "n/a"}`, wantErr: "missing score"},
+		{name: "missing pass", rubric: passFail, content: `{"score": 3}`, wantErr: "missing pass"},
+		{name: "score as string", rubric: score, content: `{"score": "8"}`, wantErr: "invalid judge output"},
+		{name: "not json", rubric: score, content: "I would give it an 8", wantErr: "not JSON"},
+		{name: "malformed json", rubric: score, content: `{"score": 8,}`, wantErr: "invalid judge output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result model.JudgeResult
+			err := Parse(tt.rubric, tt.content, &result)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !equalPtr(result.Score, tt.wantScore) {
+				t.Fatalf("Score = %v, want %v", deref(result.Score), deref(tt.wantScore))
+			}
+			if !equalPtr(result.Passed, tt.wantPassed) {
+				t.Fatalf("Passed = %v, want %v", deref(result.Passed), deref(tt.wantPassed))
+			}
+			if result.Reason != tt.wantReason {
+				t.Fatalf("Reason = %q, want %q", result.Reason, tt.wantReason)
+			}
+			if tt.wantScore != nil && result.MaxScore != tt.rubric.MaxScore {
+				t.Fatalf("MaxScore = %v, want %v", result.MaxScore, tt.rubric.MaxScore)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		rubric  model.Rubric
+		want    model.Rubric
+		wantErr string
+	}{
+		{
+			name:   "defaults",
+			rubric: model.Rubric{Criteria: "accurate"},
+			want:   model.Rubric{Name: "inline", Type: model.RubricTypeScore, Criteria: "accurate", MinScore: 1, MaxScore: 10},
+		},
+		{
+			name:   "custom range",
+			rubric: model.Rubric{Name: "r", Criteria: "c", MinScore: 0, MaxScore: 5, PassScore: 3},
+			want:   model.Rubric{Name: "r", Type: model.RubricTypeScore, Criteria: "c", MinScore: 0, MaxScore: 5, PassScore: 3},
+		},
+		{
+			name:   "pass fail",
+			rubric: model.Rubric{Type: model.RubricTypePassFail, Template: "Is {{ output }} safe?"},
+			want:   model.Rubric{Name: "inline", Type: model.RubricTypePassFail, Template: "Is {{ output }} safe?"},
+		},
+		{name: "no criteria", rubric: model.Rubric{Criteria: "  "}, wantErr: "criteria or template is required"},
+		{name: "inverted range", rubric: model.Rubric{Criteria: "c", MinScore: 5, MaxScore: 1}, wantErr: "min_score must be less than max_score"},
+		{name: "pass score out of range", rubric: model.Rubric{Criteria: "c", PassScore: 11}, wantErr: "pass_score must be within"},
+		{name: "unknown type", rubric: model.Rubric{Criteria: "c", Type: "rank"}, wantErr: "type must be score or pass_fail"},
+		{name: "invalid template", rubric: model.Rubric{Template: "{{ output"}, wantErr: "invalid rubric"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.rubric)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrInvalidRubric) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Normalize error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Normalize = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildPrompt(t *testing.T) {
+	rubric, err := Normalize(model.Rubric{Criteria: "Be accurate", MinScore: 1, MaxScore: 5})
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	tests := []struct {
+		name     string
+		in       Input
+		want     []string
+		unwanted []string
+	}{
+		{"with reference", Input{Prompt: "2+2?", Output: "4", Reference: "4"}, []string{"Be accurate", "2+2?", "[Reference Answer]", "between 1 and 5"}, nil},
+		{"without reference", Input{Prompt: "2+2?", Output: "4"}, []string{"[Response]\n4"}, []string{"[Reference Answer]"}},
+		{"output not rendered as template", Input{Prompt: "q", Output: "{{ criteria }}"}, []string{"[Response]\n{{ criteria }}"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompts, err := BuildPrompt(rubric, tt.in)
+			if err != nil {
+				t.Fatalf("BuildPrompt: %v", err)
+			}
+			if prompts.System != systemPrompt {
+				t.Fatalf("unexpected system prompt %q", prompts.System)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(prompts.User, s) {
+					t.Fatalf("prompt does not contain %q:\n%s", s, prompts.User)
+				}
+			}
+			for _, s := range tt.unwanted {
+				if strings.Contains(prompts.User, s) {
+					t.Fatalf("prompt contains %q:\n%s", s, prompts.User)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePairwise(t *testing.T) {
+	tests := []struct {
+		content    string
+		want       string
+		wantReason string
+		wantErr    bool
+	}{
+		{`{"winner": "A", "reason": "clearer"}`, model.WinnerA, "clearer", false},
+		{"```json\n{\"winner\": \" b \"}\n```", model.WinnerB, "", false},
+		{`{"winner": "tie"}`, model.WinnerTie, "", false},
+		{`{"winner": "draw"}`, model.WinnerTie, "", false},
+		{`{"winner": "both"}`, "", "", true},
+		{`{"reason": "no winner"}`, "", "", true},
+		{"A is better", "", "", true},
+	}
+	for _, tt := range tests {
+		winner, reason, err := ParsePairwise(tt.content)
+		if (err != nil) != tt.wantErr || winner != tt.want || reason != tt.wantReason {
+			t.Errorf("ParsePairwise(%q) = %q, %q, %v, want %q, %q, error %v", tt.content, winner, reason, err, tt.want, tt.wantReason, tt.wantErr)
+		}
+	}
+}
+
+func TestParseSimulation(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		turns       int
+		wantReached bool
+		wantTurns   int
+		wantTone    *float64
+		wantErr     string
+	}{
+		{name: "reached", content: `{"goal_reached": true, "turns_to_goal": 2, "tone": 8, "reason": "ok"}`, turns: 3, wantReached: true, wantTurns: 2, wantTone: ptr(8.0)},
+		{name: "turns as string", content: `{"goal_reached": true, "turns_to_goal": "3"}`, turns: 3, wantReached: true, wantTurns: 3},
+		{name: "turns beyond conversation", content: `{"goal_reached": true, "turns_to_goal": 5}`, turns: 3, wantReached: true, wantTurns: 0},
+		{name: "turns null", content: `{"goal_reached": true, "turns_to_goal": null}`, turns: 3, wantReached: true, wantTurns: 0},
+		{name: "not reached ignores turns", content: `{"goal_reached": false, "turns_to_goal": 2}`, turns: 3, wantTurns: 0},
+		{name: "missing goal_reached", content: `{"tone": 5}`, turns: 1, wantErr: "no goal_reached"},
+		{name: "tone out of range", content: `{"goal_reached": true, "tone": 11}`, turns: 1, wantErr: "out of range"},
+		{name: "not json", content: "goal reached", turns: 1, wantErr: "not JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := model.SimulationEvaluation{TurnsToGoal: 9}
+			err := ParseSimulation(tt.content, tt.turns, &result)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseSimulation error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSimulation: %v", err)
+			}
+			if result.GoalReached == nil || *result.GoalReached != tt.wantReached {
+				t.Fatalf("GoalReached = %v, want %v", deref(result.GoalReached), tt.wantReached)
+			}
+			if result.TurnsToGoal != tt.wantTurns {
+				t.Fatalf("TurnsToGoal = %d, want %d", result.TurnsToGoal, tt.wantTurns)
+			}
+			if !equalPtr(result.Tone, tt.wantTone) {
+				t.Fatalf("Tone = %v, want %v", deref(result.Tone), deref(tt.wantTone))
+			}
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	rubric := model.Rubric{Name: "quality", Type: model.RubricTypeScore, MinScore: 1, MaxScore: 10, PassScore: 5}
+	prompts := model.PromptSet{System: "s", User: "u"}
+	base := Key("openai", "gpt-4o", rubric, prompts)
+	if base != Key("openai", "gpt-4o", rubric, prompts) {
+		t.Fatalf("Key is not deterministic")
+	}
+
+	changed := map[string]func(r *model.Rubric, p *model.PromptSet) (string, string){
+		"provider":   func(r *model.Rubric, p *model.PromptSet) (string, string) { return "deepseek", "gpt-4o" },
+		"model":      func(r *model.Rubric, p *model.PromptSet) (string, string) { return "openai", "gpt-4o-mini" },
+		"pass score": func(r *model.Rubric, p *model.PromptSet) (string, string) { r.PassScore = 7; return "openai", "gpt-4o" },
+		"min score":  func(r *model.Rubric, p *model.PromptSet) (string, string) { r.MinScore = 0; return "openai", "gpt-4o" },
+		"max score":  func(r *model.Rubric, p *model.PromptSet) (string, string) { r.MaxScore = 5; return "openai", "gpt-4o" },
+		"type": func(r *model.Rubric, p *model.PromptSet) (string, string) {
+			r.Type = model.RubricTypePassFail
+			return "openai", "gpt-4o"
+		},
+		"user": func(r *model.Rubric, p *model.PromptSet) (string, string) { p.User = "u2"; return "openai", "gpt-4o" },
+		// 各部分以分隔符连接, 内容在相邻部分之间移动时键也不同
+		"boundary": func(r *model.Rubric, p *model.PromptSet) (string, string) {
+			p.System, p.User = "su", ""
+			return "openai", "gpt-4o"
+		},
+	}
+	for name, change := range changed {
+		t.Run(name, func(t *testing.T) {
+			r, p := rubric, prompts
+			provider, modelName := change(&r, &p)
+			if Key(provider, modelName, r, p) == base {
+				t.Fatalf("changing %s did not change the key", name)
+			}
+		})
+	}
+}
+
+func TestCache(t *testing.T) {
+	if c := NewCache(0); c != nil {
+		t.Fatalf("NewCache(0) = %v, want nil", c)
+	}
+	var disabled *Cache
+	disabled.Put("a", model.JudgeResult{})
+	if _, ok := disabled.Get("a"); ok || disabled.Len() != 0 {
+		t.Fatalf("nil cache stored a result")
+	}
+
+	c := NewCache(2)
+	c.Put("a", model.JudgeResult{Reason: "a"})
+	c.Put("b", model.JudgeResult{Reason: "b"})
+	c.Get("a") // a成为最近使用, 写入c时淘汰b
+	c.Put("c", model.JudgeResult{Reason: "c"})
+	if _, ok := c.Get("b"); ok {
+		t.Fatalf("least recently used entry was not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if got, ok := c.Get(key); !ok || got.Reason != key {
+			t.Fatalf("Get(%q) = %+v, %v", key, got, ok)
+		}
+	}
+	c.Put("a", model.JudgeResult{Reason: "a2"})
+	if got, _ := c.Get("a"); got.Reason != "a2" || c.Len() != 2 {
+		t.Fatalf("Put did not replace the entry: %+v, len %d", got, c.Len())
+	}
+}
+
+// ptr 返回值的指针
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// deref 返回指针指向的值, nil时返回nil, 用于错误信息
+func deref[T any](p *T) interface{} {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// equalPtr 判断两个指针指向的值是否相等, 均为nil时相等
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
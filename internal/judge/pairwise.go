@@ -0,0 +1,93 @@
+package judge
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/prompt"
+)
+
+// defaultPairwiseCriteria 未指定评分标准时的对比要求
+const defaultPairwiseCriteria = `Choose the response that follows the user's instructions and answers the question better.
+Consider helpfulness, correctness, relevance and level of detail.`
+
+// pairwiseSystemPrompt 成对对比的系统提示词
+const pairwiseSystemPrompt = `You are an impartial judge comparing two responses of AI assistants to the same question.
+Do not let the order in which the responses are presented, their length or the names of the assistants influence your decision.
+Reply with a single JSON object and nothing else.`
+
+// pairwiseFormat 成对对比的输出格式要求
+const pairwiseFormat = `Respond with JSON: {"winner": "A" | "B" | "tie", "reason": "<one or two sentences>"}`
+
+// pairwiseTemplate 成对对比提示词模板
+const pairwiseTemplate = `[Criteria]
+{{ criteria }}
+
+[Question]
+{{ prompt }}
+{% if reference %}
+[Reference Answer]
+{{ reference }}
+{% endif %}
+[Response A]
+{{ output_a }}
+
+[Response B]
+{{ output_b }}`
+
+// PairwiseInput 成对对比的输入, A与B为呈现给评审模型的顺序
+type PairwiseInput struct {
+	Prompt    string
+	OutputA   string
+	OutputB   string
+	Reference string
+}
+
+// BuildPairwisePrompt 生成成对对比提示词, criteria为空时使用默认要求
+func BuildPairwisePrompt(criteria string, in PairwiseInput) (model.PromptSet, error) {
+	if strings.TrimSpace(criteria) == "" {
+		criteria = defaultPairwiseCriteria
+	}
+	user, err := prompt.Render(pairwiseTemplate, map[string]interface{}{
+		"criteria":  criteria,
+		"prompt":    in.Prompt,
+		"reference": in.Reference,
+		"output_a":  in.OutputA,
+		"output_b":  in.OutputB,
+	})
+	if err != nil {
+		return model.PromptSet{}, fmt.Errorf("failed to render pairwise prompt: %w", err)
+	}
+	return model.PromptSet{
+		System: pairwiseSystemPrompt,
+		User:   strings.TrimSpace(user) + "\n\n" + pairwiseFormat,
+	}, nil
+}
+
+// ParsePairwise 解析成对对比输出, 返回model.WinnerA、model.WinnerB或model.WinnerTie及理由
+func ParsePairwise(content string) (string, string, error) {
+	raw := extractJSON(content)
+	if raw == "" {
+		return "", "", fmt.Errorf("judge output is not JSON: %q", truncate(content, 200))
+	}
+	var v struct {
+		Winner string `json:"winner"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return "", "", fmt.Errorf("invalid judge output: %v", err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(v.Winner)) {
+	case "a":
+		return model.WinnerA, v.Reason, nil
+	case "b":
+		return model.WinnerB, v.Reason, nil
+	case "tie", "draw":
+		return model.WinnerTie, v.Reason, nil
+	default:
+		return "", "", fmt.Errorf("invalid winner %q in judge output", v.Winner)
+	}
+}
@@ -0,0 +1,112 @@
+package judge
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/prompt"
+)
+
+// defaultToneCriteria 未指定评分标准时的语气要求
+const defaultToneCriteria = `The assistant is polite, clear and patient, and its tone suits the user.`
+
+// simulationSystemPrompt 模拟对话评估的系统提示词
+const simulationSystemPrompt = `You are an impartial judge evaluating a conversation between a user and an AI assistant.
+Judge only the assistant, the user is simulated.
+Reply with a single JSON object and nothing else.`
+
+// simulationFormat 模拟对话评估的输出格式要求
+const simulationFormat = `Respond with JSON: {"goal_reached": <true or false>, "turns_to_goal": <number of the turn in which the goal was reached, 0 if not reached>, "tone": <number between 1 and 10 rating the assistant's tone>, "reason": "<one or two sentences>"}`
+
+// simulationTemplate 模拟对话评估提示词模板
+const simulationTemplate = `[User Goal]
+{{ goal }}
+
+[User Persona]
+{{ persona }}
+
+[Tone Criteria]
+{{ criteria }}
+
+[Conversation]
+{{ conversation }}`
+
+// SimulationTurn 模拟对话中的一轮
+type SimulationTurn struct {
+	User      string
+	Assistant string // 被测模型调用失败时为空
+}
+
+// SimulationInput 模拟对话评估的输入
+type SimulationInput struct {
+	Persona string
+	Goal    string
+	Turns   []SimulationTurn
+}
+
+// BuildSimulationPrompt 生成模拟对话评估提示词, criteria为空时使用默认语气要求
+func BuildSimulationPrompt(criteria string, in SimulationInput) (model.PromptSet, error) {
+	if strings.TrimSpace(criteria) == "" {
+		criteria = defaultToneCriteria
+	}
+	var conversation strings.Builder
+	for i, turn := range in.Turns {
+		assistant := turn.Assistant
+		if assistant == "" {
+			assistant = "(no response)"
+		}
+		fmt.Fprintf(&conversation, "[Turn %d]\nUser: %s\nAssistant: %s\n\n", i+1, turn.User, assistant)
+	}
+	user, err := prompt.Render(simulationTemplate, map[string]interface{}{
+		"goal":         in.Goal,
+		"persona":      in.Persona,
+		"criteria":     criteria,
+		"conversation": strings.TrimSpace(conversation.String()),
+	})
+	if err != nil {
+		return model.PromptSet{}, fmt.Errorf("failed to render simulation prompt: %w", err)
+	}
+	return model.PromptSet{
+		System: simulationSystemPrompt,
+		User:   strings.TrimSpace(user) + "\n\n" + simulationFormat,
+	}, nil
+}
+
+// ParseSimulation 解析模拟对话评估输出并写入result, turns为对话的轮数, 超出范围的turns_to_goal视为未知
+func ParseSimulation(content string, turns int, result *model.SimulationEvaluation) error {
+	raw := extractJSON(content)
+	if raw == "" {
+		return fmt.Errorf("judge output is not JSON: %q", truncate(content, 200))
+	}
+	var v struct {
+		GoalReached *bool           `json:"goal_reached"`
+		TurnsToGoal json.RawMessage `json:"turns_to_goal"`
+		Tone        *float64        `json:"tone"`
+		Reason      string          `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return fmt.Errorf("invalid judge output: %v", err)
+	}
+	if v.GoalReached == nil {
+		return fmt.Errorf("judge output has no goal_reached: %q", truncate(content, 200))
+	}
+	if v.Tone != nil && (*v.Tone < defaultMinScore || *v.Tone > defaultMaxScore) {
+		return fmt.Errorf("tone %g out of range [%d, %d]", *v.Tone, defaultMinScore, defaultMaxScore)
+	}
+
+	result.GoalReached = v.GoalReached
+	result.Tone = v.Tone
+	result.Reason = v.Reason
+	result.TurnsToGoal = 0
+	if *v.GoalReached {
+		// 部分模型以字符串或null输出轮数
+		n, err := strconv.Atoi(strings.Trim(string(v.TurnsToGoal), `"`))
+		if err == nil && n >= 1 && n <= turns {
+			result.TurnsToGoal = n
+		}
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package metrics
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 指标名称
+const (
+	ExactMatch      = "exact_match"      // 去除首尾空白后完全一致
+	NormalizedMatch = "normalized_match" // 规范化(小写、去标点及冠词、合并空白)后一致
+	TokenF1         = "token_f1"         // 规范化后的词级F1
+	BLEU            = "bleu"             // 句子级BLEU-4, 带平滑
+	RougeL          = "rouge_l"          // 基于最长公共子序列的F1
+	NumericMatch    = "numeric_match"    // 期望为数值时, 输出中最后一个数值在容差内
+)
+
+// Names 全部指标名称, 按输出顺序排列
+var Names = []string{ExactMatch, NormalizedMatch, TokenF1, BLEU, RougeL, NumericMatch}
+
+// defaultTolerance 数值比较的默认绝对容差
+const defaultTolerance = 1e-6
+
+// Options 指标计算选项
+type Options struct {
+	AbsTolerance float64 // 数值比较的绝对容差, 0使用默认值
+	RelTolerance float64 // 数值比较的相对容差, 相对于期望值
+}
+
+// Compute 计算输出相对期望答案的全部指标, 取值范围均为[0, 1]
+// 期望答案不是单个数值时不计算numeric_match
+func Compute(output, expected string, opts Options) map[string]float64 {
+	result := make(map[string]float64, len(Names))
+	result[ExactMatch] = boolScore(strings.TrimSpace(output) == strings.TrimSpace(expected))
+
+	out, ref := Tokenize(output), Tokenize(expected)
+	result[NormalizedMatch] = boolScore(strings.Join(out, " ") == strings.Join(ref, " "))
+	result[TokenF1] = tokenF1(out, ref)
+	result[BLEU] = bleu(out, ref, 4)
+	result[RougeL] = rougeL(out, ref)
+
+	if want, ok := parseNumber(expected); ok {
+		got, found := lastNumber(output)
+		result[NumericMatch] = boolScore(found && withinTolerance(got, want, opts))
+	}
+	return result
+}
+
+// boolScore 布尔值转换为0或1
+func boolScore(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// articles 规范化时去除的英文冠词
+var articles = map[string]bool{"a": true, "an": true, "the": true}
+
+// Tokenize 规范化并分词: 转为小写, 去除标点与英文冠词
+// 字母数字连续序列为一个词, 中日韩文字逐字成词
+func Tokenize(text string) []string {
+	tokens := []string{}
+	var word strings.Builder
+	flush := func() {
+		if word.Len() == 0 {
+			return
+		}
+		if w := word.String(); !articles[w] {
+			tokens = append(tokens, w)
+		}
+		word.Reset()
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// isCJK 判断是否为中日韩文字
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
+
+// tokenF1 词袋重叠的F1
+func tokenF1(out, ref []string) float64 {
+	if len(out) == 0 || len(ref) == 0 {
+		return boolScore(len(out) == len(ref))
+	}
+	counts := make(map[string]int, len(ref))
+	for _, t := range ref {
+		counts[t]++
+	}
+	common := 0
+	for _, t := range out {
+		if counts[t] > 0 {
+			counts[t]--
+			common++
+		}
+	}
+	if common == 0 {
+		return 0
+	}
+	precision := float64(common) / float64(len(out))
+	recall := float64(common) / float64(len(ref))
+	return 2 * precision * recall / (precision + recall)
+}
+
+// bleu 句子级BLEU, 对未匹配的n-gram使用epsilon平滑(Chen & Cherry方法1)
+// 阶数不超过输出及期望的长度, 避免短答案因高阶n-gram不存在而被过度惩罚
+func bleu(out, ref []string, maxN int) float64 {
+	if len(out) == 0 || len(ref) == 0 {
+		return boolScore(len(out) == len(ref))
+	}
+
+	const epsilon = 0.1
+	order := min(maxN, len(out), len(ref))
+	logSum := 0.0
+	for n := 1; n <= order; n++ {
+		total := len(out) - n + 1
+		refCounts := ngrams(ref, n)
+		matched := 0
+		for gram, count := range ngrams(out, n) {
+			matched += min(count, refCounts[gram])
+		}
+		if matched == 0 {
+			if n == 1 {
+				return 0
+			}
+			logSum += math.Log(epsilon / float64(total))
+			continue
+		}
+		logSum += math.Log(float64(matched) / float64(total))
+	}
+
+	// 简短惩罚
+	bp := 1.0
+	if len(out) < len(ref) {
+		bp = math.Exp(1 - float64(len(ref))/float64(len(out)))
+	}
+	return bp * math.Exp(logSum/float64(order))
+}
+
+// ngrams 统计n-gram出现次数
+func ngrams(tokens []string, n int) map[string]int {
+	counts := make(map[string]int)
+	for i := 0; i+n <= len(tokens); i++ {
+		counts[strings.Join(tokens[i:i+n], "\x00")]++
+	}
+	return counts
+}
+
+// rougeL 基于最长公共子序列的F1
+func rougeL(out, ref []string) float64 {
+	if len(out) == 0 || len(ref) == 0 {
+		return boolScore(len(out) == len(ref))
+	}
+	lcs := lcsLength(out, ref)
+	if lcs == 0 {
+		return 0
+	}
+	precision := float64(lcs) / float64(len(out))
+	recall := float64(lcs) / float64(len(ref))
+	return 2 * precision * recall / (precision + recall)
+}
+
+// lcsLength 最长公共子序列长度, 使用滚动数组
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				curr[j] = prev[j-1] + 1
+			} else {
+				curr[j] = max(prev[j], curr[j-1])
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// parseNumber 将期望答案解析为单个数值, 允许千分位逗号、百分号及首尾空白
+func parseNumber(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(s, "%")
+	s = strings.ReplaceAll(s, ",", "")
+	if s == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+// lastNumber 提取文本中的最后一个数值, 模型通常在推理过程之后给出最终答案
+func lastNumber(text string) (float64, bool) {
+	runes := []rune(text)
+	for end := len(runes); end > 0; end-- {
+		if !unicode.IsDigit(runes[end-1]) {
+			continue
+		}
+		start := end
+		for start > 0 && (unicode.IsDigit(runes[start-1]) || runes[start-1] == '.' || runes[start-1] == ',') {
+			start--
+		}
+		if start > 0 && runes[start-1] == '-' {
+			start--
+		}
+		candidate := strings.Trim(string(runes[start:end]), ".,")
+		if v, ok := parseNumber(candidate); ok {
+			return v, true
+		}
+		end = start + 1
+	}
+	return 0, false
+}
+
+// withinTolerance 判断数值是否在容差范围内
+func withinTolerance(got, want float64, opts Options) bool {
+	tol := opts.AbsTolerance
+	if tol == 0 {
+		tol = defaultTolerance
+	}
+	return math.Abs(got-want) <= tol+opts.RelTolerance*math.Abs(want)
+}
@@ -0,0 +1,227 @@
+package metrics
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// approx 判断两个指标值是否近似相等
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-4
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", []string{}},
+		{"  ", []string{}},
+		{"The Quick, brown fox!", []string{"quick", "brown", "fox"}},
+		{"an apple a day", []string{"apple", "day"}},
+		{"北京是首都", []string{"北", "京", "是", "首", "都"}},
+		{"GPT-4o回答了42个问题", []string{"gpt", "4o", "回", "答", "了", "42", "个", "问", "题"}},
+		{"こんにちは world", []string{"こ", "ん", "に", "ち", "は", "world"}},
+	}
+	for _, tt := range tests {
+		if got := Tokenize(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTokenF1(t *testing.T) {
+	tests := []struct {
+		name          string
+		out, expected string
+		want          float64
+	}{
+		{"both empty", "", "", 1},
+		{"empty output", "", "paris", 0},
+		{"empty expected", "paris", "", 0},
+		{"identical", "Paris, France", "paris france", 1},
+		{"partial", "the capital is paris", "paris", 0.5},
+		{"no overlap", "london", "paris", 0},
+		{"repeated tokens counted once each", "paris paris", "paris", 2.0 / 3},
+		{"cjk", "北京", "北京市", 0.8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenF1(Tokenize(tt.out), Tokenize(tt.expected)); !approx(got, tt.want) {
+				t.Fatalf("tokenF1 = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBLEU(t *testing.T) {
+	tests := []struct {
+		name          string
+		out, expected string
+		want          float64
+	}{
+		{"both empty", "", "", 1},
+		{"empty output", "", "the cat sat", 0},
+		{"empty expected", "cat", "", 0},
+		{"identical", "the cat sat on the mat", "The cat sat on the mat.", 1},
+		{"single token match", "paris", "paris", 1},
+		{"no unigram match", "london", "paris", 0},
+		// 输出比期望短时受简短惩罚: exp(1-3/2)
+		{"brevity penalty", "cat sat", "cat sat mat", math.Exp(-0.5)},
+		// 二元组无匹配时以epsilon平滑: sqrt(1 * 0.1/1)
+		{"smoothed bigram", "sat cat", "cat sat", math.Sqrt(0.1)},
+		{"cjk identical", "北京是首都", "北京是首都", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bleu(Tokenize(tt.out), Tokenize(tt.expected), 4)
+			if !approx(got, tt.want) {
+				t.Fatalf("bleu = %v, want %v", got, tt.want)
+			}
+			if got < 0 || got > 1 {
+				t.Fatalf("bleu = %v out of [0, 1]", got)
+			}
+		})
+	}
+}
+
+func TestRougeL(t *testing.T) {
+	tests := []struct {
+		name          string
+		out, expected string
+		want          float64
+	}{
+		{"both empty", "", "", 1},
+		{"empty output", "", "paris", 0},
+		{"identical", "cat sat mat", "cat sat mat", 1},
+		// LCS为cat mat, 长度2, precision 2/3, recall 2/2
+		{"subsequence", "cat dog mat", "cat mat", 0.8},
+		{"reordered", "mat cat", "cat mat", 0.5},
+		{"no overlap", "dog", "cat", 0},
+		// LCS为北京, precision 2/2, recall 2/3
+		{"cjk", "北京", "北京市", 0.8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rougeL(Tokenize(tt.out), Tokenize(tt.expected)); !approx(got, tt.want) {
+				t.Fatalf("rougeL = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   float64
+		wantOK bool
+	}{
+		{"42", 42, true},
+		{" -3.5 ", -3.5, true},
+		{"1,234,567", 1234567, true},
+		{"12.5%", 12.5, true},
+		{"1e3", 1000, true},
+		{"", 0, false},
+		{"%", 0, false},
+		{"forty two", 0, false},
+		{"NaN", 0, false},
+		{"Inf", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseNumber(tt.in)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("parseNumber(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestLastNumber(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		want   float64
+		wantOK bool
+	}{
+		{"empty", "", 0, false},
+		{"no digits", "no answer", 0, false},
+		{"plain", "42", 42, true},
+		{"last of several", "3 apples and 5 pears, so 8", 8, true},
+		{"trailing period", "The answer is 3.", 3, true},
+		{"decimal", "pi is about 3.14", 3.14, true},
+		{"negative", "the temperature is -12 degrees", -12, true},
+		{"negative decimal", "change: -0.75", -0.75, true},
+		{"thousands separator", "Total: 1,234,567 users", 1234567, true},
+		{"thousands with decimal", "costs 1,234.50 dollars", 1234.5, true},
+		{"trailing comma", "we got 7, then stopped", 7, true},
+		{"cjk", "所以答案是42个", 42, true},
+		{"cjk negative", "温度为-5度", -5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := lastNumber(tt.in)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Fatalf("lastNumber(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name          string
+		out, expected string
+		opts          Options
+		want          map[string]float64
+	}{
+		{
+			name: "exact", out: " Paris ", expected: "Paris",
+			want: map[string]float64{ExactMatch: 1, NormalizedMatch: 1, TokenF1: 1, BLEU: 1, RougeL: 1},
+		},
+		{
+			name: "normalized only", out: "the Paris!", expected: "paris",
+			want: map[string]float64{ExactMatch: 0, NormalizedMatch: 1, TokenF1: 1},
+		},
+		{
+			name: "numeric", out: "After adding them up we get 1,000.", expected: "1000",
+			want: map[string]float64{ExactMatch: 0, NumericMatch: 1},
+		},
+		{
+			name: "numeric outside tolerance", out: "about 101", expected: "100",
+			want: map[string]float64{NumericMatch: 0},
+		},
+		{
+			name: "numeric relative tolerance", out: "about 101", expected: "100", opts: Options{RelTolerance: 0.02},
+			want: map[string]float64{NumericMatch: 1},
+		},
+		{
+			name: "numeric without number in output", out: "I don't know", expected: "7",
+			want: map[string]float64{NumericMatch: 0},
+		},
+		{
+			name: "both empty", out: "", expected: "",
+			want: map[string]float64{ExactMatch: 1, NormalizedMatch: 1, TokenF1: 1, BLEU: 1, RougeL: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compute(tt.out, tt.expected, tt.opts)
+			for name, want := range tt.want {
+				if v, ok := got[name]; !ok || !approx(v, want) {
+					t.Fatalf("%s = %v (present %v), want %v", name, v, ok, want)
+				}
+			}
+			for name, v := range got {
+				if v < 0 || v > 1 {
+					t.Fatalf("%s = %v out of [0, 1]", name, v)
+				}
+			}
+		})
+	}
+
+	t.Run("non numeric expected", func(t *testing.T) {
+		if _, ok := Compute("42", "forty two", Options{})[NumericMatch]; ok {
+			t.Fatalf("numeric_match computed for a non-numeric expected answer")
+		}
+	})
+}
@@ -3,6 +3,7 @@ package model
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -16,21 +17,101 @@ func (j JSONField) Value() (driver.Value, error) {
 
 // Scan 实现sql.Scanner接口
 func (j *JSONField) Scan(value interface{}) error {
-	bytes, ok := value.([]byte)
-	if !ok {
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
 		return nil
 	}
 	return json.Unmarshal(bytes, j)
 }
 
+// NewJSONField 将任意结构体转换为JSONField
+func NewJSONField(v interface{}) (JSONField, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var j JSONField
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Decode 将JSONField解码到目标结构体
+func (j JSONField) Decode(v interface{}) error {
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// JSONArray 自定义JSON数组字段类型
+type JSONArray []interface{}
+
+// Value 实现driver.Valuer接口
+func (j JSONArray) Value() (driver.Value, error) {
+	return json.Marshal(j)
+}
+
+// Scan 实现sql.Scanner接口
+func (j *JSONArray) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+	return json.Unmarshal(bytes, j)
+}
+
+// NewJSONArray 将任意切片转换为JSONArray
+func NewJSONArray(v interface{}) (JSONArray, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var j JSONArray
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Decode 将JSONArray解码到目标切片
+func (j JSONArray) Decode(v interface{}) error {
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
 // TestRecord 测试记录表
 type TestRecord struct {
-	ID        uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
-	Prompts   JSONField `gorm:"type:json;not null" json:"prompts"`
-	Models    JSONField `gorm:"type:json;not null" json:"models"`
-	Results   JSONField `gorm:"type:json;not null" json:"results"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID           uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Prompts      JSONField `gorm:"type:json;not null" json:"prompts"`
+	Models       JSONArray `gorm:"type:json;not null" json:"models"`
+	Results      JSONField `gorm:"type:json;not null" json:"results"`
+	ModelNames   string    `gorm:"type:varchar(1000);not null;default:''" json:"-"` // 逗号包裹的模型名称, 用于过滤
+	Providers    string    `gorm:"type:varchar(500);not null;default:''" json:"-"`  // 逗号包裹的提供者, 用于过滤
+	ModelCount   int       `gorm:"not null;default:0" json:"model_count"`
+	SuccessCount int       `gorm:"not null;default:0" json:"success_count"`
+	Success      bool      `gorm:"not null;default:0;index:idx_test_records_success" json:"success"` // 全部模型调用成功
+	Duration     int64     `gorm:"not null;default:0" json:"duration"`                               // 总耗时(毫秒)
+	// 使用模板时记录模板及渲染所用的版本
+	TemplateID      uint64    `gorm:"not null;default:0;index:idx_test_records_template" json:"template_id,omitempty"`
+	TemplateVersion int       `gorm:"not null;default:0;index:idx_test_records_template" json:"template_version,omitempty"`
+	CreatedAt       time.Time `gorm:"autoCreateTime;index:idx_test_records_created_at" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
 // TableName 指定表名
@@ -41,12 +122,14 @@ func (TestRecord) TableName() string {
 // PromptTemplate 提示词模板表
 type PromptTemplate struct {
 	ID           uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name         string    `gorm:"type:varchar(255);not null;index:idx_name" json:"name"`
+	Name         string    `gorm:"type:varchar(255);not null;index:idx_prompt_templates_name" json:"name"`
 	SystemPrompt string    `gorm:"type:text" json:"system_prompt"`
 	UserPrompt   string    `gorm:"type:text" json:"user_prompt"`
-	AIPrompt     string    `gorm:"type:text" json:"ai_prompt"`
+	AIPrompt     string    `gorm:"column:ai_prompt;type:text" json:"ai_prompt"`
 	Description  string    `gorm:"type:varchar(500)" json:"description"`
-	CreatedAt    time.Time `gorm:"autoCreateTime;index:idx_created_at" json:"created_at"`
+	Variables    JSONArray `gorm:"type:json" json:"variables"`        // 变量声明, 元素为TemplateVariable
+	Version      int       `gorm:"not null;default:1" json:"version"` // 当前版本号, 每次保存递增
+	CreatedAt    time.Time `gorm:"autoCreateTime;index:idx_prompt_templates_created_at" json:"created_at"`
 	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
@@ -55,15 +138,78 @@ func (PromptTemplate) TableName() string {
 	return "prompt_templates"
 }
 
+// NewVersion 根据模板当前内容生成版本快照
+func (t *PromptTemplate) NewVersion(note string) *PromptTemplateVersion {
+	return &PromptTemplateVersion{
+		TemplateID:   t.ID,
+		Version:      t.Version,
+		Name:         t.Name,
+		SystemPrompt: t.SystemPrompt,
+		UserPrompt:   t.UserPrompt,
+		AIPrompt:     t.AIPrompt,
+		Description:  t.Description,
+		Variables:    t.Variables,
+		Note:         note,
+	}
+}
+
+// VariableDecls 解析模板变量声明
+func (t *PromptTemplate) VariableDecls() ([]TemplateVariable, error) {
+	decls := []TemplateVariable{}
+	if len(t.Variables) == 0 {
+		return decls, nil
+	}
+	if err := t.Variables.Decode(&decls); err != nil {
+		return nil, err
+	}
+	return decls, nil
+}
+
+// PromptTemplateVersion 提示词模板版本表, 每次保存模板生成一条不可变记录
+// 模板删除后版本仍保留, 以便解释历史测试结果
+type PromptTemplateVersion struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	TemplateID   uint64    `gorm:"not null;uniqueIndex:uk_prompt_template_versions_version" json:"template_id"`
+	Version      int       `gorm:"not null;uniqueIndex:uk_prompt_template_versions_version" json:"version"`
+	Name         string    `gorm:"type:varchar(255);not null" json:"name"`
+	SystemPrompt string    `gorm:"type:text" json:"system_prompt"`
+	UserPrompt   string    `gorm:"type:text" json:"user_prompt"`
+	AIPrompt     string    `gorm:"column:ai_prompt;type:text" json:"ai_prompt"`
+	Description  string    `gorm:"type:varchar(500)" json:"description"`
+	Variables    JSONArray `gorm:"type:json" json:"variables"`
+	Note         string    `gorm:"type:varchar(255)" json:"note"` // 变更说明
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定表名
+func (PromptTemplateVersion) TableName() string {
+	return "prompt_template_versions"
+}
+
+// Template 将版本快照还原为模板
+func (v *PromptTemplateVersion) Template() *PromptTemplate {
+	return &PromptTemplate{
+		ID:           v.TemplateID,
+		Name:         v.Name,
+		SystemPrompt: v.SystemPrompt,
+		UserPrompt:   v.UserPrompt,
+		AIPrompt:     v.AIPrompt,
+		Description:  v.Description,
+		Variables:    v.Variables,
+		Version:      v.Version,
+	}
+}
+
 // ModelConfigEntity 模型配置表
 type ModelConfigEntity struct {
 	ID            uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
-	Provider      string    `gorm:"type:varchar(50);not null;uniqueIndex:uk_provider_model" json:"provider"`
-	ModelName     string    `gorm:"type:varchar(100);not null;uniqueIndex:uk_provider_model" json:"model_name"`
-	ApiKey        string    `gorm:"type:varchar(500)" json:"api_key"`
+	Provider      string    `gorm:"type:varchar(50);not null;uniqueIndex:uk_model_configs_provider_model" json:"provider"`
+	ModelName     string    `gorm:"type:varchar(100);not null;uniqueIndex:uk_model_configs_provider_model" json:"model_name"`
+	ApiKey        string    `gorm:"type:varchar(1024)" json:"-"`               // 使用主密钥加密后保存, 不对外输出
+	ApiKeyHint    string    `gorm:"type:varchar(32)" json:"api_key,omitempty"` // 脱敏后的API Key, 如sk-****abcd
 	BaseURL       string    `gorm:"type:varchar(500)" json:"base_url"`
 	DefaultConfig JSONField `gorm:"type:json" json:"default_config"`
-	Enabled       bool      `gorm:"type:tinyint(1);default:1;index:idx_enabled" json:"enabled"`
+	Enabled       bool      `gorm:"type:tinyint(1);default:1;index:idx_model_configs_enabled" json:"enabled"`
 	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
@@ -71,4 +217,279 @@ type ModelConfigEntity struct {
 // TableName 指定表名
 func (ModelConfigEntity) TableName() string {
 	return "model_configs"
-}
\ No newline at end of file
+}
+
+// 任务类型
+const (
+	JobTypeTest         = "test"
+	JobTypeBatch        = "batch"
+	JobTypeConversation = "conversation"
+	JobTypeSimulation   = "simulation"
+)
+
+// 任务状态
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCanceled  = "canceled"
+)
+
+// Job 异步任务表
+type Job struct {
+	ID             string     `gorm:"type:varchar(64);primaryKey" json:"id"`
+	Type           string     `gorm:"type:varchar(32);not null;index:idx_jobs_type" json:"type"`
+	Status         string     `gorm:"type:varchar(32);not null;index:idx_jobs_status" json:"status"`
+	Total          int        `gorm:"not null;default:0" json:"total"`     // 总任务数
+	Completed      int        `gorm:"not null;default:0" json:"completed"` // 已完成数
+	Request        JSONField  `gorm:"type:json" json:"request"`
+	DatasetID      uint64     `gorm:"not null;default:0;index:idx_jobs_dataset" json:"dataset_id,omitempty"` // 批量任务使用的数据集, 用例来自请求时为0
+	DatasetVersion int        `gorm:"not null;default:0;index:idx_jobs_dataset" json:"dataset_version,omitempty"`
+	Result         JSONField  `gorm:"type:json" json:"result,omitempty"` // 执行结果(运行中为部分结果)
+	Error          string     `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime;index:idx_jobs_created_at" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+}
+
+// TableName 指定表名
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// IsFinished 任务是否已结束
+func (j *Job) IsFinished() bool {
+	return j.Status == JobStatusSucceeded || j.Status == JobStatusFailed || j.Status == JobStatusCanceled
+}
+
+// 数据集用例的划分
+const (
+	SplitTrain = "train"
+	SplitTest  = "test"
+	SplitDev   = "dev"
+)
+
+// Splits 支持的数据划分
+var Splits = []string{SplitTrain, SplitTest, SplitDev}
+
+// Dataset 数据集表, 用例可编辑, 冻结后生成不可变的版本供批量任务引用
+type Dataset struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string    `gorm:"type:varchar(255);not null;index:idx_datasets_name" json:"name"`
+	Description string    `gorm:"type:varchar(500)" json:"description"`
+	CaseCount   int       `gorm:"not null;default:0" json:"case_count"` // 工作副本的用例数
+	Version     int       `gorm:"not null;default:0" json:"version"`    // 最新冻结的版本号, 0表示尚未冻结
+	Modified    bool      `gorm:"not null;default:1" json:"modified"`   // 工作副本在最新版本之后是否有修改
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Dataset) TableName() string {
+	return "datasets"
+}
+
+// DatasetCase 数据集用例表
+type DatasetCase struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	DatasetID  uint64    `gorm:"not null;index:idx_dataset_cases_dataset_id;index:idx_dataset_cases_version" json:"dataset_id"`
+	Version    int       `gorm:"not null;default:0;index:idx_dataset_cases_version" json:"version"` // 0为工作副本, 其余为冻结版本的快照
+	Position   int       `gorm:"not null;default:0" json:"position"`
+	CaseKey    string    `gorm:"type:varchar(100)" json:"case_key"`
+	System     string    `gorm:"type:text" json:"system"`
+	Prompt     string    `gorm:"type:text;not null" json:"prompt"`
+	Variables  JSONField `gorm:"type:json" json:"variables"`
+	Expected   string    `gorm:"type:text" json:"expected"`
+	Assertions JSONArray `gorm:"type:json" json:"assertions,omitempty"`   // 用例断言, 元素为Assertion
+	Tags       JSONArray `gorm:"type:json" json:"tags,omitempty"`         // 用例标签, 元素为字符串
+	Split      string    `gorm:"type:varchar(16)" json:"split,omitempty"` // 数据划分, train/test/dev
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (DatasetCase) TableName() string {
+	return "dataset_cases"
+}
+
+// HasTag 用例是否带有指定标签
+func (c *DatasetCase) HasTag(tag string) bool {
+	for _, t := range c.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// DatasetVersion 数据集版本表, 冻结数据集时生成, 用例快照保存在dataset_cases中
+// 数据集删除后版本仍保留, 以便解释历史批量任务
+type DatasetVersion struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	DatasetID uint64    `gorm:"not null;uniqueIndex:uk_dataset_versions_version" json:"dataset_id"`
+	Version   int       `gorm:"not null;uniqueIndex:uk_dataset_versions_version" json:"version"`
+	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
+	Note      string    `gorm:"type:varchar(255)" json:"note,omitempty"`
+	CaseCount int       `gorm:"not null;default:0" json:"case_count"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定表名
+func (DatasetVersion) TableName() string {
+	return "dataset_versions"
+}
+
+// SetTestCase 以测试用例设置用例内容
+func (c *DatasetCase) SetTestCase(tc TestCase) error {
+	c.CaseKey = tc.ID
+	c.System = tc.System
+	c.Prompt = tc.Prompt
+	c.Variables = tc.Variables
+	c.Expected = tc.Expected
+	c.Split = tc.Split
+	c.Assertions, c.Tags = nil, nil
+	if len(tc.Assertions) > 0 {
+		assertions, err := NewJSONArray(tc.Assertions)
+		if err != nil {
+			return fmt.Errorf("failed to encode assertions: %w", err)
+		}
+		c.Assertions = assertions
+	}
+	if len(tc.Tags) > 0 {
+		tags, err := NewJSONArray(tc.Tags)
+		if err != nil {
+			return fmt.Errorf("failed to encode tags: %w", err)
+		}
+		c.Tags = tags
+	}
+	return nil
+}
+
+// ToTestCase 转换为测试用例
+func (c *DatasetCase) ToTestCase() TestCase {
+	tc := TestCase{
+		ID:        c.CaseKey,
+		System:    c.System,
+		Prompt:    c.Prompt,
+		Variables: c.Variables,
+		Expected:  c.Expected,
+		Split:     c.Split,
+	}
+	if len(c.Assertions) > 0 {
+		// 断言由NewJSONArray编码写入, 解码失败时视为无断言
+		if err := c.Assertions.Decode(&tc.Assertions); err != nil {
+			tc.Assertions = nil
+		}
+	}
+	for _, tag := range c.Tags {
+		if name, ok := tag.(string); ok {
+			tc.Tags = append(tc.Tags, name)
+		}
+	}
+	return tc
+}
+
+// 成对对比的胜者
+const (
+	WinnerA   = "a"
+	WinnerB   = "b"
+	WinnerTie = "tie"
+	// WinnerBothBad 两个输出都不可接受, 计算评分时视为平局
+	WinnerBothBad = "both_bad"
+)
+
+// 成对对比的来源
+const (
+	ComparisonSourceJudge = "judge" // 评审模型
+	ComparisonSourceHuman = "human" // 人工投票
+	ComparisonSourceArena = "arena" // 盲测投票
+)
+
+// Comparison 成对对比表, 记录两个模型对同一提示词输出的胜负, 用于计算排行榜
+type Comparison struct {
+	ID        uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
+	System    string `gorm:"type:text" json:"system,omitempty"`
+	Prompt    string `gorm:"type:text;not null" json:"prompt"`
+	ProviderA string `gorm:"type:varchar(50);not null" json:"provider_a"`
+	ModelA    string `gorm:"type:varchar(100);not null;index:idx_comparisons_model_a" json:"model_a"`
+	OutputA   string `gorm:"type:text" json:"output_a"`
+	ProviderB string `gorm:"type:varchar(50);not null" json:"provider_b"`
+	ModelB    string `gorm:"type:varchar(100);not null;index:idx_comparisons_model_b" json:"model_b"`
+	OutputB   string `gorm:"type:text" json:"output_b"`
+	Winner    string `gorm:"type:varchar(16);not null" json:"winner"` // a/b/tie/both_bad
+	Source    string `gorm:"type:varchar(16);not null;index:idx_comparisons_source" json:"source"`
+	// 评审模型, 人工投票时为空
+	JudgeProvider string `gorm:"type:varchar(50)" json:"judge_provider,omitempty"`
+	JudgeModel    string `gorm:"type:varchar(100)" json:"judge_model,omitempty"`
+	// 交换位置后两次评审是否一致, 不一致时记为平局; 未交换位置时为空
+	Consistent *bool     `json:"consistent,omitempty"`
+	Reason     string    `gorm:"type:text" json:"reason,omitempty"`
+	Tags       JSONArray `gorm:"type:json" json:"tags,omitempty"`
+	TagNames   string    `gorm:"type:varchar(500);not null;default:''" json:"-"` // 逗号包裹的标签, 用于过滤
+	DatasetID  uint64    `gorm:"not null;default:0;index:idx_comparisons_dataset_id" json:"dataset_id,omitempty"`
+	CaseKey    string    `gorm:"type:varchar(100)" json:"case_key,omitempty"`
+	CreatedAt  time.Time `gorm:"autoCreateTime;index:idx_comparisons_created_at" json:"created_at"`
+}
+
+// TableName 指定表名
+func (Comparison) TableName() string {
+	return "comparisons"
+}
+
+// ArenaBattle 盲测对战表, 投票前不向投票者透露模型身份
+type ArenaBattle struct {
+	ID           string     `gorm:"type:varchar(64);primaryKey" json:"id"`
+	System       string     `gorm:"type:text" json:"system,omitempty"`
+	Prompt       string     `gorm:"type:text;not null" json:"prompt"`
+	ProviderA    string     `gorm:"type:varchar(50);not null" json:"provider_a"` // 展示为Model A的模型
+	ModelA       string     `gorm:"type:varchar(100);not null" json:"model_a"`
+	OutputA      string     `gorm:"type:text" json:"output_a"`
+	ProviderB    string     `gorm:"type:varchar(50);not null" json:"provider_b"`
+	ModelB       string     `gorm:"type:varchar(100);not null" json:"model_b"`
+	OutputB      string     `gorm:"type:text" json:"output_b"`
+	Winner       string     `gorm:"type:varchar(16);not null;default:''" json:"winner,omitempty"` // 投票前为空
+	Reason       string     `gorm:"type:text" json:"reason,omitempty"`
+	ComparisonID uint64     `gorm:"not null;default:0" json:"comparison_id,omitempty"` // 投票生成的成对对比
+	CreatedAt    time.Time  `gorm:"autoCreateTime;index:idx_arena_battles_created_at" json:"created_at"`
+	VotedAt      *time.Time `json:"voted_at,omitempty"`
+}
+
+// TableName 指定表名
+func (ArenaBattle) TableName() string {
+	return "arena_battles"
+}
+
+// Voted 是否已投票
+func (b *ArenaBattle) Voted() bool {
+	return b.Winner != ""
+}
+
+// 被标注结果所属的运行类型
+const (
+	RunTypeRecord = "record" // 测试历史记录, run_id为记录ID
+	RunTypeJob    = "job"    // 异步任务, run_id为任务ID
+)
+
+// Annotation 人工标注表, 每位标注者对同一运行中的同一模型响应最多一条标注, 不同提供者下的同名模型分别标注
+type Annotation struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	RunType   string    `gorm:"type:varchar(16);not null;uniqueIndex:uk_annotations_target" json:"run_type"`
+	RunID     string    `gorm:"type:varchar(64);not null;uniqueIndex:uk_annotations_target" json:"run_id"`
+	CaseIndex int       `gorm:"not null;default:0;uniqueIndex:uk_annotations_target" json:"case_index"` // 批量任务中的用例序号, 单次测试为0
+	Provider  string    `gorm:"type:varchar(50);not null;default:'';uniqueIndex:uk_annotations_target" json:"provider"`
+	ModelName string    `gorm:"type:varchar(100);not null;uniqueIndex:uk_annotations_target" json:"model_name"`
+	Annotator string    `gorm:"type:varchar(100);not null;uniqueIndex:uk_annotations_target;index:idx_annotations_annotator" json:"annotator"`
+	Rating    *float64  `json:"rating,omitempty"` // 未评分时为空
+	Tags      JSONArray `gorm:"type:json" json:"tags,omitempty"`
+	TagNames  string    `gorm:"type:varchar(500);not null;default:''" json:"-"` // 逗号包裹的标签, 用于过滤
+	Note      string    `gorm:"type:text" json:"note,omitempty"`
+	Preferred bool      `gorm:"not null;default:0" json:"preferred"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Annotation) TableName() string {
+	return "annotations"
+}
@@ -2,8 +2,16 @@ package model
 
 // TestRequest 多模型测试请求
 type TestRequest struct {
-	Prompts PromptSet  `json:"prompts" binding:"required"`
-	Models  []ModelReq `json:"models" binding:"required,min=1"`
+	Prompts         PromptSet              `json:"prompts"`
+	Models          []ModelReq             `json:"models" binding:"required,min=1"`
+	TemplateID      uint64                 `json:"template_id,omitempty"`      // 使用模板渲染提示词, 替代prompts
+	TemplateVersion int                    `json:"template_version,omitempty"` // 固定使用的模板版本, 为空时使用当前版本
+	Variables       map[string]interface{} `json:"variables,omitempty"`        // 模板变量取值
+	Assertions      []Assertion            `json:"assertions,omitempty"`       // 对每个模型输出执行的断言
+	Judge           *JudgeRequest          `json:"judge,omitempty"`            // 使用评审模型为每个模型输出打分
+	Expected        string                 `json:"expected,omitempty"`         // 期望输出, 设置时计算参考指标, 也作为评审的默认参考答案
+	Metrics         *MetricOptions         `json:"metrics,omitempty"`          // 参考指标选项
+	Blind           bool                   `json:"blind,omitempty"`            // 盲测模式: 需两个模型, 不支持断言、评审、期望输出及参考指标, 以匿名标签随机顺序返回输出, 投票后揭晓模型
 }
 
 type CallProvidersRequest struct {
@@ -30,6 +38,220 @@ type ModelReq struct {
 	Config   map[string]interface{} `json:"config"`                      // 模型参数配置
 }
 
+// TestCase 单个测试用例
+type TestCase struct {
+	ID         string                 `json:"id,omitempty"`         // 用例标识
+	System     string                 `json:"system,omitempty"`     // 系统提示词
+	Prompt     string                 `json:"prompt"`               // 用户提示词, 支持{{变量}}
+	Variables  map[string]interface{} `json:"variables,omitempty"`  // 变量取值
+	Expected   string                 `json:"expected,omitempty"`   // 期望输出
+	Assertions []Assertion            `json:"assertions,omitempty"` // 仅对该用例执行的断言, 与批量请求中的断言合并
+	Tags       []string               `json:"tags,omitempty"`       // 用例标签, 如类别、难度
+	Split      string                 `json:"split,omitempty"`      // 数据划分, train/test/dev
+}
+
+// BatchRequest 批量测试请求, 用例来源为dataset_id或cases
+type BatchRequest struct {
+	DatasetID      uint64         `json:"dataset_id,omitempty"`
+	DatasetVersion int            `json:"dataset_version,omitempty"` // 数据集版本, 为空时使用最新版本, 工作副本有未冻结的修改时先自动冻结
+	Split          string         `json:"split,omitempty"`           // 只运行指定划分的数据集用例
+	Tags           []string       `json:"tags,omitempty"`            // 只运行带有任一标签的数据集用例
+	Cases          []TestCase     `json:"cases,omitempty"`
+	Models         []ModelReq     `json:"models" binding:"required,min=1"`
+	Concurrency    int            `json:"concurrency,omitempty"` // 并发用例数
+	Assertions     []Assertion    `json:"assertions,omitempty"`  // 对每个用例的每个模型输出执行的断言
+	Judge          *JudgeRequest  `json:"judge,omitempty"`       // 使用评审模型打分, 参考答案为用例的expected
+	Metrics        *MetricOptions `json:"metrics,omitempty"`     // 参考指标选项, 对设置了expected的用例计算
+}
+
+// ConversationRequest 多轮对话脚本测试请求, 各模型逐轮执行, 模型自身的回复加入其后续轮次的历史
+type ConversationRequest struct {
+	System     string                 `json:"system,omitempty"`    // 系统提示词, 支持{{变量}}
+	History    []Message              `json:"history,omitempty"`   // 预置的历史消息, 各模型共用, 角色为user或assistant
+	Turns      []ConversationTurn     `json:"turns"`               // 依次发送的用户消息
+	Variables  map[string]interface{} `json:"variables,omitempty"` // 渲染系统提示词及各轮用户消息的变量
+	Models     []ModelReq             `json:"models" binding:"required,min=1"`
+	Assertions []Assertion            `json:"assertions,omitempty"` // 对每一轮输出执行的断言
+	Metrics    *MetricOptions         `json:"metrics,omitempty"`    // 参考指标选项, 对设置了expected的轮次计算
+}
+
+// ConversationTurn 对话脚本中的一轮
+type ConversationTurn struct {
+	User       string      `json:"user"`                 // 用户消息, 支持{{变量}}
+	Expected   string      `json:"expected,omitempty"`   // 本轮期望输出
+	Assertions []Assertion `json:"assertions,omitempty"` // 仅对本轮执行的断言, 与请求中的断言合并
+}
+
+// SimulationRequest 模拟用户评测请求, 由另一个模型扮演用户与各被测模型对话, 结束后由评审模型评估
+type SimulationRequest struct {
+	System    string                 `json:"system,omitempty"`    // 被测模型的系统提示词, 支持{{变量}}
+	User      SimulatedUser          `json:"user"`                // 模拟用户设定
+	MaxTurns  int                    `json:"max_turns,omitempty"` // 最大轮数, 默认5
+	Variables map[string]interface{} `json:"variables,omitempty"` // 渲染系统提示词及模拟用户设定的变量
+	Models    []ModelReq             `json:"models" binding:"required,min=1"`
+	Judge     *JudgeRequest          `json:"judge,omitempty"` // 评审模型, 评分标准的要求作为语气的评分要求, 为空时使用默认评审模型
+}
+
+// SimulatedUser 模拟用户设定
+type SimulatedUser struct {
+	Persona       string `json:"persona"`                  // 用户画像, 支持{{变量}}
+	Goal          string `json:"goal"`                     // 用户希望达成的目标, 支持{{变量}}
+	StopCondition string `json:"stop_condition,omitempty"` // 结束对话的条件, 为空时目标达成或确认无法达成时结束
+	Opening       string `json:"opening,omitempty"`        // 第一条用户消息, 为空时由模拟用户生成
+	Provider      string `json:"provider,omitempty"`       // 扮演用户的模型, 为空时使用默认评审模型
+	Model         string `json:"model,omitempty"`
+}
+
+// MetricOptions 参考指标选项
+type MetricOptions struct {
+	Disabled     bool    `json:"disabled,omitempty"`      // 不计算参考指标
+	Tolerance    float64 `json:"tolerance,omitempty"`     // numeric_match的绝对容差, 默认1e-6
+	RelTolerance float64 `json:"rel_tolerance,omitempty"` // numeric_match的相对容差, 相对于期望值
+}
+
+// 断言类型
+const (
+	AssertContains    = "contains"     // 输出包含value
+	AssertNotContains = "not_contains" // 输出不包含value
+	AssertRegex       = "regex"        // 输出匹配正则value
+	AssertStartsWith  = "starts_with"  // 输出以value开头
+	AssertJSONPath    = "json_path"    // 输出为JSON且path处的值等于value
+	AssertIsJSON      = "is_json"      // 输出为合法JSON
+	AssertLength      = "length"       // 输出字符数在[min, max]之间
+	AssertLatency     = "latency"      // 响应时间不超过max毫秒
+	AssertCost        = "cost"         // 调用费用不超过max
+)
+
+// Assertion 对模型输出的声明式断言
+type Assertion struct {
+	Type       string      `json:"type"`
+	Name       string      `json:"name,omitempty"`        // 断言名称, 为空时使用类型
+	Value      interface{} `json:"value,omitempty"`       // 期望值, json_path可为任意JSON值, 其余为字符串
+	Path       string      `json:"path,omitempty"`        // json_path使用的gjson路径, 如data.items.0.name
+	Min        *float64    `json:"min,omitempty"`         // length下限
+	Max        *float64    `json:"max,omitempty"`         // length上限, latency毫秒上限, cost费用上限
+	IgnoreCase bool        `json:"ignore_case,omitempty"` // contains、not_contains、starts_with、regex忽略大小写
+}
+
+// 评分标准类型
+const (
+	RubricTypeScore    = "score"     // 在分数范围内打分
+	RubricTypePassFail = "pass_fail" // 判定通过或不通过
+)
+
+// JudgeRequest 评审配置, rubric与inline_rubric二选一
+type JudgeRequest struct {
+	Rubric       string  `json:"rubric,omitempty"`        // 配置文件中的评分标准名称
+	InlineRubric *Rubric `json:"inline_rubric,omitempty"` // 请求中直接定义的评分标准
+	Provider     string  `json:"provider,omitempty"`      // 评审模型, 为空时使用配置文件中的默认评审模型
+	Model        string  `json:"model,omitempty"`
+	Reference    string  `json:"reference,omitempty"` // 参考答案, 批量测试中为空时使用用例的expected
+}
+
+// Rubric 评分标准
+type Rubric struct {
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`                // score/pass_fail, 为空时为score
+	Criteria  string  `json:"criteria"`            // 评分要求
+	MinScore  float64 `json:"min_score,omitempty"` // score类型的分数范围, 默认1-10
+	MaxScore  float64 `json:"max_score,omitempty"`
+	PassScore float64 `json:"pass_score,omitempty"` // 达到该分数视为通过, 0表示不判定通过
+	Template  string  `json:"template,omitempty"`   // 自定义评审提示词模板, 可用变量prompt、output、reference、criteria、min_score、max_score
+}
+
+// CompareRequest 成对对比请求, 各模型对同一提示词的输出两两由评审模型比较
+type CompareRequest struct {
+	Prompts   PromptSet     `json:"prompts"`
+	Models    []ModelReq    `json:"models"`               // 至少两个模型, 名称不可重复
+	Judge     *JudgeRequest `json:"judge,omitempty"`      // 评审模型及参考答案, 指定评分标准时使用其criteria作为对比要求
+	NoSwap    bool          `json:"no_swap,omitempty"`    // 不交换位置复评, 默认交换以降低位置偏差
+	Tags      []string      `json:"tags,omitempty"`       // 标签, 用于排行榜过滤
+	DatasetID uint64        `json:"dataset_id,omitempty"` // 提示词所属数据集
+	CaseKey   string        `json:"case_key,omitempty"`   // 提示词所属用例
+}
+
+// VoteRequest 人工投票请求, 记录对两个模型输出的人工判断
+type VoteRequest struct {
+	Prompts   PromptSet `json:"prompts"`
+	ModelA    ModelReq  `json:"model_a"`
+	OutputA   string    `json:"output_a"`
+	ModelB    ModelReq  `json:"model_b"`
+	OutputB   string    `json:"output_b"`
+	Winner    string    `json:"winner"` // a/b/tie/both_bad
+	Reason    string    `json:"reason,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	DatasetID uint64    `json:"dataset_id,omitempty"`
+	CaseKey   string    `json:"case_key,omitempty"`
+}
+
+// ArenaVoteRequest 盲测投票请求
+type ArenaVoteRequest struct {
+	Winner string `json:"winner"` // a/b/tie/both_bad
+	Reason string `json:"reason,omitempty"`
+}
+
+// AnnotationRequest 人工标注请求, 同一标注者重复提交时覆盖原标注
+type AnnotationRequest struct {
+	RunType   string   `json:"run_type"`             // record/job
+	RunID     string   `json:"run_id"`               // 历史记录ID或任务ID
+	CaseIndex int      `json:"case_index,omitempty"` // 批量任务中的用例序号
+	Provider  string   `json:"provider,omitempty"`   // 为空时按模型名称匹配响应, 运行中有多个同名模型时必填
+	ModelName string   `json:"model_name"`
+	Annotator string   `json:"annotator"`
+	Rating    *float64 `json:"rating,omitempty"` // 取值范围由annotation配置决定
+	Tags      []string `json:"tags,omitempty"`   // 如hallucination、wrong_format、refused
+	Note      string   `json:"note,omitempty"`
+	Preferred bool     `json:"preferred,omitempty"`
+}
+
+// RegressionRequest 回归对比请求, 将批量任务与同一数据集上的基线任务逐用例对比
+type RegressionRequest struct {
+	RunID         string                `json:"run_id"`
+	BaselineID    string                `json:"baseline_id"`
+	Model         string                `json:"model,omitempty"`          // 与baseline_model同时指定时只对比这两个模型, 用于模型版本切换
+	BaselineModel string                `json:"baseline_model,omitempty"` // 未指定时对比两次运行中的同名模型
+	Thresholds    *RegressionThresholds `json:"thresholds,omitempty"`     // 覆盖配置文件中的阈值
+}
+
+// RegressionThresholds 回归判定阈值, 为空的字段使用配置文件中的值
+type RegressionThresholds struct {
+	ScoreThreshold     *float64 `json:"score_threshold,omitempty"`      // 归一化分数(0-1)变化超过该值视为改进或退化
+	MaxRegressed       *int     `json:"max_regressed,omitempty"`        // 每个模型允许退化的用例数
+	MaxPassRateDrop    *float64 `json:"max_pass_rate_drop,omitempty"`   // 允许的通过率下降
+	MaxLatencyIncrease *float64 `json:"max_latency_increase,omitempty"` // 允许的平均响应时间增幅比例, 0表示不检查
+	MaxCostIncrease    *float64 `json:"max_cost_increase,omitempty"`    // 允许的总费用增幅比例, 0表示不检查
+}
+
+// SaveDatasetRequest 保存数据集请求
+type SaveDatasetRequest struct {
+	Name        string     `json:"name" binding:"required"`
+	Description string     `json:"description"`
+	Cases       []TestCase `json:"cases"`
+}
+
+// AppendCasesRequest 向数据集追加用例请求
+type AppendCasesRequest struct {
+	Cases []TestCase `json:"cases"`
+}
+
+// TagCasesRequest 批量修改用例标签请求, case_ids为空时作用于全部用例
+type TagCasesRequest struct {
+	CaseIDs []uint64 `json:"case_ids,omitempty"`
+	Add     []string `json:"add,omitempty"`
+	Remove  []string `json:"remove,omitempty"`
+}
+
+// SplitDatasetRequest 划分数据集请求, 按比例随机为用例分配划分
+type SplitDatasetRequest struct {
+	Ratios map[string]float64 `json:"ratios"`         // 划分 -> 比例, 如{"train":0.8,"test":0.2}, 按总和归一化
+	Seed   int64              `json:"seed,omitempty"` // 随机种子, 相同种子及用例得到相同划分
+}
+
+// FreezeDatasetRequest 冻结数据集版本请求
+type FreezeDatasetRequest struct {
+	Note string `json:"note"` // 版本说明
+}
+
 // ModelConfig 模型配置参数
 type ModelConfig struct {
 	Temperature float64 `json:"temperature,omitempty"` // 温度参数
@@ -40,11 +262,45 @@ type ModelConfig struct {
 
 // SaveTemplateRequest 保存模板请求
 type SaveTemplateRequest struct {
-	Name         string `json:"name" binding:"required"`
-	SystemPrompt string `json:"system_prompt"`
-	UserPrompt   string `json:"user_prompt"`
-	AIPrompt     string `json:"ai_prompt"`
-	Description  string `json:"description"`
+	Name         string             `json:"name" binding:"required"`
+	SystemPrompt string             `json:"system_prompt"`
+	UserPrompt   string             `json:"user_prompt"`
+	AIPrompt     string             `json:"ai_prompt"`
+	Description  string             `json:"description"`
+	Variables    []TemplateVariable `json:"variables"`
+	Note         string             `json:"note"` // 本次保存的变更说明
+}
+
+// 模板变量类型
+const (
+	VariableTypeAny     = "any"
+	VariableTypeString  = "string"
+	VariableTypeNumber  = "number"
+	VariableTypeInteger = "integer"
+	VariableTypeBoolean = "boolean"
+	VariableTypeList    = "list"
+	VariableTypeObject  = "object"
+)
+
+// TemplateVariable 模板变量声明
+type TemplateVariable struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"` // 为空时等同于any
+	Required    bool        `json:"required"`
+	Default     interface{} `json:"default,omitempty"`
+	Description string      `json:"description,omitempty"`
+}
+
+// RenderTemplateRequest 渲染模板请求
+type RenderTemplateRequest struct {
+	Version   int                    `json:"version"` // 为空时使用当前版本
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// RollbackTemplateRequest 回滚模板请求, 回滚会以目标版本的内容生成新版本
+type RollbackTemplateRequest struct {
+	Version int    `json:"version" binding:"required"`
+	Note    string `json:"note"`
 }
 
 // SaveModelConfigRequest 保存模型配置请求
@@ -53,6 +309,12 @@ type SaveModelConfigRequest struct {
 	ModelName     string                 `json:"model_name" binding:"required"`
 	ApiKey        string                 `json:"api_key"`
 	BaseURL       string                 `json:"base_url"`
-	DefaultConfig map[string]interface{} `json:"default_config"`
-	Enabled       bool                   `json:"enabled"`
+	DefaultConfig map[string]interface{} `json:"default_config"` // 默认模型参数, 请求中的config优先
+	Enabled       *bool                  `json:"enabled"`        // 为空时创建默认启用, 更新保持不变
+}
+
+// RunRef 引用的一次运行, 格式为<run_type>:<run_id>, 如job:3f2a...、record:12
+type RunRef struct {
+	RunType string `json:"run_type"`
+	RunID   string `json:"run_id"`
 }
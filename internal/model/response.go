@@ -11,7 +11,8 @@ type Response struct {
 
 // TestResult 多模型测试结果
 type TestResult struct {
-	Results   map[string]*ModelResponse `json:"results"`   // 各模型的响应结果
+	RecordID  uint64                    `json:"record_id,omitempty"` // 历史记录ID, 未启用持久化时为空
+	Results   map[string]*ModelResponse `json:"results"`             // 各模型的响应结果
 	StartTime time.Time                 `json:"start_time"`
 	EndTime   time.Time                 `json:"end_time"`
 	Duration  int64                     `json:"duration"` // 总耗时(毫秒)
@@ -19,15 +20,321 @@ type TestResult struct {
 
 // ModelResponse 单个模型的响应结果
 type ModelResponse struct {
+	ModelName        string             `json:"model_name"`
+	Provider         string             `json:"provider"`
+	Content          string             `json:"content"`                     // 模型回复内容
+	Error            string             `json:"error,omitempty"`             // 错误信息
+	Success          bool               `json:"success"`                     // 调用是否成功, 不代表输出质量
+	TokensUsed       int                `json:"tokens_used,omitempty"`       // 使用的token数
+	PromptTokens     int                `json:"prompt_tokens,omitempty"`     // 输入token数
+	CompletionTokens int                `json:"completion_tokens,omitempty"` // 输出token数
+	Cost             *float64           `json:"cost,omitempty"`              // 调用费用, 未配置价格时为空
+	ResponseTime     int64              `json:"response_time"`               // 响应时间(毫秒)
+	StartTime        time.Time          `json:"start_time"`
+	EndTime          time.Time          `json:"end_time"`
+	Assertions       []AssertionResult  `json:"assertions,omitempty"` // 各断言的结果
+	Passed           *bool              `json:"passed,omitempty"`     // 总体结论: 调用成功且全部断言及评审通过, 均未设置时为空
+	Judge            *JudgeResult       `json:"judge,omitempty"`      // 评审结果
+	Metrics          map[string]float64 `json:"metrics,omitempty"`    // 相对期望输出的参考指标, 取值[0, 1]
+}
+
+// JudgeResult 评审模型对单个输出的评价, 费用与被测模型分开统计
+type JudgeResult struct {
+	Rubric     string   `json:"rubric"`
+	Provider   string   `json:"provider"`
+	Model      string   `json:"model"`
+	Score      *float64 `json:"score,omitempty"` // score类型的分数
+	MaxScore   float64  `json:"max_score,omitempty"`
+	Passed     *bool    `json:"passed,omitempty"` // pass_fail类型或设置了pass_score时的结论
+	Reason     string   `json:"reason,omitempty"` // 评审理由
+	Error      string   `json:"error,omitempty"`
+	Cached     bool     `json:"cached"` // 是否命中缓存, 命中时不产生费用
+	TokensUsed int      `json:"tokens_used,omitempty"`
+	Cost       *float64 `json:"cost,omitempty"`
+}
+
+// AssertionResult 单个断言的结果
+type AssertionResult struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"` // 未通过的原因
+}
+
+// BatchResult 批量测试结果矩阵(用例 × 模型)
+type BatchResult struct {
+	Models  []string                 `json:"models"`
+	Cases   []*BatchCaseResult       `json:"cases"`
+	Summary map[string]*ModelSummary `json:"summary,omitempty"` // 按模型名称索引的汇总, 每次保存检查点时更新
+}
+
+// ModelSummary 单个模型在一次批量测试中的汇总
+type ModelSummary struct {
+	Completed       int                `json:"completed"`              // 已完成的用例数
+	Succeeded       int                `json:"succeeded"`              // 调用成功的用例数
+	Evaluated       int                `json:"evaluated"`              // 有总体结论的用例数
+	Passed          int                `json:"passed"`                 // 总体结论为通过的用例数
+	PassRate        *float64           `json:"pass_rate,omitempty"`    // passed / evaluated
+	AvgResponseTime int64              `json:"avg_response_time"`      // 调用成功用例的平均响应时间(毫秒)
+	TokensUsed      int                `json:"tokens_used"`            // 被测模型使用的token数
+	Cost            *float64           `json:"cost,omitempty"`         // 被测模型费用, 未配置价格时为空
+	JudgeCost       *float64           `json:"judge_cost,omitempty"`   // 评审费用
+	JudgeScore      *float64           `json:"judge_score,omitempty"`  // 评审平均分
+	Metrics         map[string]float64 `json:"metrics,omitempty"`      // 各参考指标的平均值
+	MetricCases     map[string]int     `json:"metric_cases,omitempty"` // 各参考指标参与平均的用例数, 包括调用失败(记为0)的用例
+}
+
+// BatchCaseResult 单个用例在各模型上的结果
+type BatchCaseResult struct {
+	Case    TestCase                  `json:"case"`
+	Results map[string]*ModelResponse `json:"results"` // 按模型名称索引, 未完成的模型不存在
+}
+
+// ConversationResult 多轮对话测试结果
+type ConversationResult struct {
+	Models      []string                 `json:"models"`
+	Turns       []string                 `json:"turns"`             // 渲染后的各轮用户消息
+	Transcripts map[string]*Transcript   `json:"transcripts"`       // 按模型名称索引
+	Summary     map[string]*ModelSummary `json:"summary,omitempty"` // 按轮次汇总, 统计口径与批量测试一致
+}
+
+// Transcript 单个模型的多轮对话记录, 某轮调用失败时无法继续对话, 后续轮次不再执行
+type Transcript struct {
 	ModelName    string        `json:"model_name"`
 	Provider     string        `json:"provider"`
-	Content      string        `json:"content"`       // 模型回复内容
-	Error        string        `json:"error,omitempty"` // 错误信息
-	Success      bool          `json:"success"`
-	TokensUsed   int           `json:"tokens_used,omitempty"` // 使用的token数
-	ResponseTime int64         `json:"response_time"` // 响应时间(毫秒)
-	StartTime    time.Time     `json:"start_time"`
-	EndTime      time.Time     `json:"end_time"`
+	Turns        []*TurnResult `json:"turns"`
+	Completed    bool          `json:"completed"`        // 是否执行完全部轮次
+	Passed       *bool         `json:"passed,omitempty"` // 全部轮次均有结论且通过时为true, 均无结论时为空
+	ResponseTime int64         `json:"response_time"`    // 各轮响应时间之和(毫秒)
+	TokensUsed   int           `json:"tokens_used"`
+	Cost         *float64      `json:"cost,omitempty"`
+}
+
+// TurnResult 单轮对话的结果
+type TurnResult struct {
+	Index    int            `json:"index"` // 轮次序号, 从0开始
+	User     string         `json:"user"`  // 渲染后的用户消息
+	Response *ModelResponse `json:"response"`
+}
+
+// SimulationResult 模拟用户评测结果
+type SimulationResult struct {
+	Models      []string                         `json:"models"`
+	Transcripts map[string]*SimulationTranscript `json:"transcripts"` // 按模型名称索引
+}
+
+// 模拟用户对话的结束原因
+const (
+	StopReasonUser           = "user_stopped"    // 模拟用户认为目标已达成或无法达成, 主动结束
+	StopReasonMaxTurns       = "max_turns"       // 达到最大轮数
+	StopReasonModelError     = "model_error"     // 被测模型调用失败
+	StopReasonSimulatorError = "simulator_error" // 模拟用户调用失败
+)
+
+// SimulationTranscript 单个模型与模拟用户的对话记录, 各轮的user为模拟用户发送的消息
+type SimulationTranscript struct {
+	Transcript
+	StopReason      string                `json:"stop_reason,omitempty"`
+	Error           string                `json:"error,omitempty"`  // 模拟用户调用失败的原因
+	SimulatorTokens int                   `json:"simulator_tokens"` // 模拟用户消耗的token, 与被测模型分开统计
+	SimulatorCost   *float64              `json:"simulator_cost,omitempty"`
+	Evaluation      *SimulationEvaluation `json:"evaluation,omitempty"`
+}
+
+// SimulationEvaluation 评审模型对模拟对话的评估
+type SimulationEvaluation struct {
+	Provider    string   `json:"provider"`
+	Model       string   `json:"model"`
+	GoalReached *bool    `json:"goal_reached,omitempty"`
+	TurnsToGoal int      `json:"turns_to_goal,omitempty"` // 达成目标所用的轮数
+	Tone        *float64 `json:"tone,omitempty"`          // 语气评分, 1-10
+	Reason      string   `json:"reason,omitempty"`
+	Error       string   `json:"error,omitempty"`
+	TokensUsed  int      `json:"tokens_used,omitempty"`
+	Cost        *float64 `json:"cost,omitempty"`
+}
+
+// CompareResult 成对对比结果
+type CompareResult struct {
+	Results map[string]*ModelResponse `json:"results"` // 各模型的响应结果, key为provider/model
+	Pairs   []*PairwiseResult         `json:"pairs"`   // 两两对比结果
+}
+
+// PairwiseResult 单对模型的对比结果
+type PairwiseResult struct {
+	ProviderA    string   `json:"provider_a,omitempty"`
+	ModelA       string   `json:"model_a"`
+	ProviderB    string   `json:"provider_b,omitempty"`
+	ModelB       string   `json:"model_b"`
+	Winner       string   `json:"winner,omitempty"`     // a/b/tie, 出错时为空
+	Consistent   *bool    `json:"consistent,omitempty"` // 交换位置后结论是否一致
+	Reason       string   `json:"reason,omitempty"`
+	Error        string   `json:"error,omitempty"`
+	ComparisonID uint64   `json:"comparison_id,omitempty"` // 保存的对比记录ID
+	TokensUsed   int      `json:"tokens_used,omitempty"`   // 评审使用的token数
+	Cost         *float64 `json:"cost,omitempty"`          // 评审费用
+}
+
+// Leaderboard 根据成对对比计算的模型排行榜
+type Leaderboard struct {
+	Method      string             `json:"method"`      // elo/bradley_terry
+	Comparisons int                `json:"comparisons"` // 参与计算的对比数
+	Confidence  float64            `json:"confidence"`  // 置信区间的置信水平
+	Rounds      int                `json:"rounds"`      // 自助法重采样次数
+	Entries     []LeaderboardEntry `json:"entries"`
+}
+
+// LeaderboardEntry 排行榜条目
+type LeaderboardEntry struct {
+	Rank     int     `json:"rank"`
+	Provider string  `json:"provider"`
+	Model    string  `json:"model"`
+	Rating   float64 `json:"rating"`
+	Lower    float64 `json:"lower"` // 置信区间下限
+	Upper    float64 `json:"upper"` // 置信区间上限
+	Games    int     `json:"games"`
+	Wins     int     `json:"wins"`
+	Losses   int     `json:"losses"`
+	Ties     int     `json:"ties"`
+	WinRate  float64 `json:"win_rate"` // 平局计半场
+}
+
+// ArenaSession 盲测会话, 投票前输出仅带有匿名标签
+type ArenaSession struct {
+	ID           string        `json:"id"`
+	Prompt       string        `json:"prompt"`
+	Outputs      []ArenaOutput `json:"outputs"`
+	Winner       string        `json:"winner,omitempty"` // 投票结果, 投票后才有值
+	Reason       string        `json:"reason,omitempty"`
+	ComparisonID uint64        `json:"comparison_id,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+	VotedAt      *time.Time    `json:"voted_at,omitempty"`
+}
+
+// ArenaOutput 盲测中的单个输出, 模型信息在投票后揭晓
+type ArenaOutput struct {
+	Label    string `json:"label"` // Model A/Model B
+	Content  string `json:"content"`
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+}
+
+// AnnotationAgreement 多名标注者之间的一致性, 以被标注的模型响应为单元
+type AnnotationAgreement struct {
+	Annotators int                       `json:"annotators"` // 标注者人数
+	Responses  int                       `json:"responses"`  // 被标注的响应数
+	Rating     AgreementStat             `json:"rating"`     // 评分, 按区间尺度计算
+	Preferred  AgreementStat             `json:"preferred"`  // 是否偏好, 按名义尺度计算
+	Tags       map[string]*AgreementStat `json:"tags"`       // 各标签是否出现, 按名义尺度计算
+}
+
+// AgreementStat 单项标注的一致性统计
+type AgreementStat struct {
+	Alpha            *float64 `json:"alpha,omitempty"`             // Krippendorff's alpha, 取值不足或全部相同时为空
+	PercentAgreement *float64 `json:"percent_agreement,omitempty"` // 标注者两两取值相同的比例
+	Responses        int      `json:"responses"`                   // 至少两名标注者的响应数
+	Pairs            int      `json:"pairs"`                       // 参与比较的标注者对数
+}
+
+// AnnotationExport 运行结果及其人工标注
+type AnnotationExport struct {
+	RunType   string               `json:"run_type"`
+	RunID     string               `json:"run_id"`
+	Responses []*AnnotatedResponse `json:"responses"`
+	Agreement *AnnotationAgreement `json:"agreement"`
+}
+
+// AnnotatedResponse 单个模型响应及其全部标注
+type AnnotatedResponse struct {
+	CaseIndex   int            `json:"case_index"`
+	CaseID      string         `json:"case_id,omitempty"` // 批量任务中的用例标识
+	Response    *ModelResponse `json:"response"`
+	Annotations []*Annotation  `json:"annotations"`
+	MeanRating  *float64       `json:"mean_rating,omitempty"` // 各标注者评分的平均值
+	Preferred   int            `json:"preferred"`             // 标记为偏好的标注者人数
+}
+
+// 回归对比中单个用例的状态
+const (
+	RegressionImproved  = "improved"
+	RegressionRegressed = "regressed"
+	RegressionUnchanged = "unchanged"
+	RegressionMissing   = "missing" // 任一次运行缺少该用例的结果
+)
+
+// 回归对比结论
+const (
+	VerdictPass = "pass"
+	VerdictFail = "fail"
+)
+
+// RegressionReport 回归对比报告
+type RegressionReport struct {
+	RunID                  string               `json:"run_id"`
+	BaselineID             string               `json:"baseline_id"`
+	DatasetID              uint64               `json:"dataset_id,omitempty"`
+	DatasetVersion         int                  `json:"dataset_version,omitempty"` // 两次运行使用的数据集版本, 版本相同时用例一致
+	BaselineDatasetVersion int                  `json:"baseline_dataset_version,omitempty"`
+	Thresholds             RegressionThresholds `json:"thresholds"`        // 实际使用的阈值
+	Verdict                string               `json:"verdict"`           // pass/fail
+	Reasons                []string             `json:"reasons,omitempty"` // 未通过的原因
+	Models                 []*ModelRegression   `json:"models"`
+}
+
+// ModelRegression 单个模型相对基线的回归对比, 汇总值仅统计两次运行都有结果的用例
+type ModelRegression struct {
+	Model                   string            `json:"model"`
+	BaselineModel           string            `json:"baseline_model"`
+	Improved                int               `json:"improved"`
+	Regressed               int               `json:"regressed"`
+	Unchanged               int               `json:"unchanged"`
+	Missing                 int               `json:"missing"`
+	PassRate                *float64          `json:"pass_rate,omitempty"`
+	BaselinePassRate        *float64          `json:"baseline_pass_rate,omitempty"`
+	AvgResponseTime         int64             `json:"avg_response_time"` // 两次均调用成功的用例的平均响应时间(毫秒)
+	BaselineAvgResponseTime int64             `json:"baseline_avg_response_time"`
+	LatencyChange           *float64          `json:"latency_change,omitempty"` // 平均响应时间变化比例
+	Cost                    *float64          `json:"cost,omitempty"`
+	BaselineCost            *float64          `json:"baseline_cost,omitempty"`
+	CostChange              *float64          `json:"cost_change,omitempty"` // 总费用变化比例
+	Cases                   []*CaseRegression `json:"cases"`
+}
+
+// CaseRegression 单个用例相对基线的变化
+type CaseRegression struct {
+	CaseIndex                int                `json:"case_index"` // 本次运行中的序号, 仅存在于基线中的用例为-1
+	CaseID                   string             `json:"case_id,omitempty"`
+	BaselineIndex            int                `json:"baseline_index"`   // 基线中对应用例的序号, 无对应用例时为-1
+	Status                   string             `json:"status"`           // improved/regressed/unchanged/missing
+	Reason                   string             `json:"reason,omitempty"` // 改进或退化的依据
+	Passed                   *bool              `json:"passed,omitempty"`
+	BaselinePassed           *bool              `json:"baseline_passed,omitempty"`
+	Score                    *float64           `json:"score,omitempty"` // 归一化分数, 优先使用评审分数, 否则为参考指标的平均值
+	BaselineScore            *float64           `json:"baseline_score,omitempty"`
+	ScoreDelta               *float64           `json:"score_delta,omitempty"`
+	AssertionsPassed         int                `json:"assertions_passed"`
+	BaselineAssertionsPassed int                `json:"baseline_assertions_passed"`
+	NewlyFailed              []string           `json:"newly_failed,omitempty"` // 基线中通过而本次未通过的断言
+	NewlyPassed              []string           `json:"newly_passed,omitempty"`
+	MetricDeltas             map[string]float64 `json:"metric_deltas,omitempty"`
+	ResponseTimeDelta        int64              `json:"response_time_delta"` // 响应时间变化(毫秒)
+	CostDelta                *float64           `json:"cost_delta,omitempty"`
+}
+
+// Report 一次或多次运行的结果报告
+type Report struct {
+	Title       string       `json:"title"`
+	GeneratedAt time.Time    `json:"generated_at"`
+	Runs        []*RunReport `json:"runs"`
+}
+
+// RunReport 单次运行的结果, 单次测试视为只有一个用例的批量测试
+type RunReport struct {
+	RunType   string    `json:"run_type"` // record/job
+	RunID     string    `json:"run_id"`
+	Status    string    `json:"status,omitempty"` // 任务状态, 未结束的任务为部分结果
+	CreatedAt time.Time `json:"created_at"`
+	BatchResult
 }
 
 // StreamChunk 流式响应数据块
@@ -50,6 +357,29 @@ type ModelInfo struct {
 	Enabled  bool   `json:"enabled"`
 }
 
+// PageResult 分页查询结果
+type PageResult struct {
+	Items    interface{} `json:"items"`
+	Total    int64       `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+}
+
+// TemplateDiff 模板两个版本之间的差异
+type TemplateDiff struct {
+	TemplateID uint64        `json:"template_id"`
+	From       int           `json:"from"`
+	To         int           `json:"to"`
+	Changes    []FieldChange `json:"changes"`
+}
+
+// FieldChange 单个字段的变更
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
 // NewSuccessResponse 创建成功响应
 func NewSuccessResponse(data interface{}) *Response {
 	return &Response{
@@ -65,4 +395,4 @@ func NewErrorResponse(code int, message string) *Response {
 		Code:    code,
 		Message: message,
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,248 @@
+package prompt
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+)
+
+func TestCheckDelimiters(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr string
+	}{
+		{"plain text", "hello world", ""},
+		{"closed expression", "{{ name }}", ""},
+		{"closed statement", "{% if x %}y{% endif %}", ""},
+		{"closed comment", "{# note #}", ""},
+		{"stray closing", "a }} b %}", ""},
+		{"closing inside string", `{{ "}}" ~ name }}`, ""},
+		{"single quoted closing", `{{ '%}' }}`, ""},
+		{"escaped quote", `{{ "a \" }}" }}`, ""},
+		{"quotes in comment are ignored", `{# it's #}`, ""},
+		{"unclosed expression", "{{ name", `unclosed "{{" at line 1`},
+		{"unclosed statement", "{% if x", `unclosed "{%" at line 1`},
+		{"unclosed comment", "{# note", `unclosed "{#" at line 1`},
+		{"closing only inside string", `{{ "}} x`, `unclosed "{{" at line 1`},
+		{"wrong closing", "{{ name %}", `unclosed "{{" at line 1`},
+		{"line number", "{{ x }}\n\n{{ y", `unclosed "{{" at line 3`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDelimiters(tt.text)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkDelimiters: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("checkDelimiters error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVariables(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []string
+		wantErr string
+	}{
+		{"no variables", "hello", []string{}, ""},
+		{"sorted and deduplicated", "{{ b }} {{ a }} {{ b }}", []string{"a", "b"}, ""},
+		{"attribute access", "{{ user.name }}", []string{"user"}, ""},
+		{"filters", "{{ name | upper }} {{ items | join(', ') }}", []string{"items", "name"}, ""},
+		{"function call", "{{ range(n) }}", []string{"n"}, ""},
+		{"keywords and tests", "{% if a is defined and not b %}{{ c }}{% endif %}", []string{"a", "b", "c"}, ""},
+		{"loop scoped names", "{% for item in items %}{{ item.name }} {{ loop.index }}{% endfor %}", []string{"items"}, ""},
+		{"loop with two names", "{% for k, v in pairs %}{{ k }}={{ v }}{% endfor %}", []string{"pairs"}, ""},
+		{"set", "{% set greeting = 'hi' %}{{ greeting }} {{ who }}", []string{"who"}, ""},
+		{"string containing closing", `{{ "}}" ~ name }}`, []string{"name"}, ""},
+		{"raw block", "{% raw %}{{ x }}{% endraw %} {{ y }}", []string{"y"}, ""},
+		{"comment", "{# {{ hidden }} #}{{ x }}", []string{"x"}, ""},
+		{"unclosed expression", "{{ name", nil, "invalid template"},
+		{"unclosed raw", "{% raw %}{{ x }}", nil, "invalid template"},
+		{"include is disabled", `{% include "/etc/passwd" %}`, nil, "disabled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Variables(tt.text)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Variables error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Variables: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Variables = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		vars    map[string]interface{}
+		want    string
+		wantErr string
+	}{
+		{"empty", "", nil, "", ""},
+		{"variable", "Hello {{ name }}", map[string]interface{}{"name": "Ann"}, "Hello Ann", ""},
+		{"filter", "{{ name | upper }}", map[string]interface{}{"name": "ann"}, "ANN", ""},
+		{"loop", "{% for x in items %}{{ x }},{% endfor %}", map[string]interface{}{"items": []interface{}{1, 2}}, "1,2,", ""},
+		{"raw block is literal", "{% raw %}{{ x }}{% endraw %}", nil, "{{ x }}", ""},
+		{"missing variables are listed", "{{ name }} {{ b }}", map[string]interface{}{"x": 1}, "", "missing variables: b, name"},
+		{"unclosed expression", "Hi {{ name", map[string]interface{}{"name": "Ann"}, "", `unclosed "{{"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.text, tt.vars)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Render error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Render = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReferencedVariables(t *testing.T) {
+	got, err := ReferencedVariables("{{ role }}", "", "{{ question }} {{ role }}")
+	if err != nil {
+		t.Fatalf("ReferencedVariables: %v", err)
+	}
+	if want := []string{"question", "role"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ReferencedVariables = %v, want %v", got, want)
+	}
+}
+
+func TestValidateDeclarations(t *testing.T) {
+	tests := []struct {
+		name       string
+		decls      []model.TemplateVariable
+		referenced []string
+		want       *VariableError
+	}{
+		{"no declarations", nil, []string{"a"}, nil},
+		{"consistent", []model.TemplateVariable{{Name: "a", Type: model.VariableTypeString}}, []string{"a"}, nil},
+		{
+			name:       "undeclared and unused",
+			decls:      []model.TemplateVariable{{Name: "b"}, {Name: "a"}},
+			referenced: []string{"c", "a"},
+			want:       &VariableError{Unused: []string{"b"}, Undeclared: []string{"c"}},
+		},
+		{
+			name: "invalid declarations",
+			decls: []model.TemplateVariable{
+				{Name: "a", Type: "date"},
+				{Name: "a"},
+				{Name: ""},
+				{Name: "n", Type: model.VariableTypeInteger, Default: 1.5},
+			},
+			referenced: []string{"a", "n"},
+			want: &VariableError{Invalid: []string{
+				`a: declared more than once`,
+				`a: unknown type "date"`,
+				`n: default expected integer, got float64`,
+				`variable name is required`,
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertVariableError(t, ValidateDeclarations(tt.decls, tt.referenced), tt.want)
+		})
+	}
+}
+
+func TestResolveVariables(t *testing.T) {
+	decls := []model.TemplateVariable{
+		{Name: "name", Type: model.VariableTypeString, Required: true},
+		{Name: "count", Type: model.VariableTypeInteger, Default: float64(3)},
+		{Name: "verbose", Type: model.VariableTypeBoolean},
+	}
+	tests := []struct {
+		name       string
+		decls      []model.TemplateVariable
+		referenced []string
+		values     map[string]interface{}
+		want       map[string]interface{}
+		wantErr    *VariableError
+	}{
+		{
+			name:   "defaults and zero values",
+			decls:  decls,
+			values: map[string]interface{}{"name": "Ann"},
+			want:   map[string]interface{}{"name": "Ann", "count": int64(3), "verbose": false},
+		},
+		{
+			name:   "integer from JSON number",
+			decls:  decls,
+			values: map[string]interface{}{"name": "Ann", "count": float64(5), "verbose": true},
+			want:   map[string]interface{}{"name": "Ann", "count": int64(5), "verbose": true},
+		},
+		{
+			name:    "missing, unused and invalid",
+			decls:   decls,
+			values:  map[string]interface{}{"count": 1.5, "extra": 1, "other": 2},
+			wantErr: &VariableError{Missing: []string{"name"}, Unused: []string{"extra", "other"}, Invalid: []string{"count: expected integer, got float64"}},
+		},
+		{
+			name:       "undeclared templates require every referenced variable",
+			referenced: []string{"a", "b"},
+			values:     map[string]interface{}{"a": 1},
+			wantErr:    &VariableError{Missing: []string{"b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveVariables(tt.decls, tt.referenced, tt.values)
+			assertVariableError(t, err, tt.wantErr)
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ResolveVariables = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVariableErrorMessage(t *testing.T) {
+	err := &VariableError{Missing: []string{"a", "b"}, Invalid: []string{"n: bad"}}
+	if want := "missing variables: a, b; invalid variables: n: bad"; err.Error() != want {
+		t.Fatalf("Error = %q, want %q", err.Error(), want)
+	}
+}
+
+func assertVariableError(t *testing.T, err error, want *VariableError) {
+	t.Helper()
+	if want == nil {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	var verr *VariableError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected VariableError, got %v", err)
+	}
+	if !reflect.DeepEqual(verr, want) {
+		t.Fatalf("VariableError = %+v, want %+v", verr, want)
+	}
+}
@@ -0,0 +1,212 @@
+package prompt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nikolalohinski/gonja"
+	"github.com/nikolalohinski/gonja/config"
+	"github.com/nikolalohinski/gonja/nodes"
+	"github.com/nikolalohinski/gonja/parser"
+	"github.com/nikolalohinski/gonja/tokens"
+)
+
+var (
+	envOnce sync.Once
+	env     *gonja.Environment
+	envErr  error
+)
+
+// getEnv 获取禁用了模板引用语句的Jinja环境, 避免读取服务器上的文件
+func getEnv() (*gonja.Environment, error) {
+	envOnce.Do(func() {
+		env = gonja.NewEnvironment(config.DefaultConfig, gonja.DefaultLoader)
+		for _, stmt := range []string{"include", "extends", "import", "from"} {
+			if !env.Statements.Exists(stmt) {
+				continue
+			}
+			name := stmt
+			err := env.Statements.Replace(name, func(p *parser.Parser, args *parser.Parser) (nodes.Statement, error) {
+				return nil, fmt.Errorf("keyword[%s] has been disabled", name)
+			})
+			if err != nil {
+				envErr = fmt.Errorf("init jinja env fail: %w", err)
+				return
+			}
+		}
+	})
+	return env, envErr
+}
+
+// Render 使用Jinja语法渲染提示词, 模板引用了未提供的变量时返回错误
+func Render(text string, vars map[string]interface{}) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+
+	names, err := Variables(text)
+	if err != nil {
+		return "", err
+	}
+	missing := []string{}
+	for _, name := range names {
+		if _, ok := vars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing variables: %s", strings.Join(missing, ", "))
+	}
+
+	e, err := getEnv()
+	if err != nil {
+		return "", err
+	}
+	tpl, err := e.FromString(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+	out, err := tpl.Execute(vars)
+	if err != nil {
+		return "", fmt.Errorf("render template: %w", err)
+	}
+	return out, nil
+}
+
+// Parse 校验模板语法
+func Parse(text string) error {
+	// gonja在表达式未闭合时会阻塞, 需预先检查定界符
+	if err := checkDelimiters(text); err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	e, err := getEnv()
+	if err != nil {
+		return err
+	}
+	if _, err := e.FromString(text); err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	return nil
+}
+
+// delimiters 起始定界符与对应的结束定界符
+var delimiters = map[string]string{
+	"{{": "}}",
+	"{%": "%}",
+	"{#": "#}",
+}
+
+// checkDelimiters 检查{{ }}、{% %}、{# #}是否闭合, 忽略表达式中字符串内的内容
+func checkDelimiters(text string) error {
+	for i := 0; i < len(text)-1; i++ {
+		end, ok := delimiters[text[i:i+2]]
+		if !ok {
+			continue
+		}
+
+		start := i
+		i += 2
+		var quote byte
+		closed := false
+		for ; i < len(text)-1; i++ {
+			c := text[i]
+			if end != "#}" {
+				if quote != 0 {
+					if c == '\\' {
+						i++
+					} else if c == quote {
+						quote = 0
+					}
+					continue
+				}
+				if c == '"' || c == '\'' {
+					quote = c
+					continue
+				}
+			}
+			if text[i:i+2] == end {
+				closed = true
+				i++
+				break
+			}
+		}
+		if !closed {
+			line := strings.Count(text[:start], "\n") + 1
+			return fmt.Errorf("unclosed %q at line %d", text[start:start+2], line)
+		}
+	}
+	return nil
+}
+
+// keywords Jinja表达式中的关键字及内置名称, 不视为变量
+var keywords = map[string]bool{
+	"and": true, "or": true, "not": true, "in": true, "is": true,
+	"if": true, "elif": true, "else": true, "endif": true,
+	"for": true, "endfor": true, "set": true, "endset": true,
+	"macro": true, "endmacro": true, "call": true, "endcall": true,
+	"filter": true, "endfilter": true, "raw": true, "endraw": true,
+	"with": true, "endwith": true, "autoescape": true, "endautoescape": true,
+	"block": true, "endblock": true, "recursive": true,
+	"true": true, "false": true, "none": true, "True": true, "False": true, "None": true,
+	"loop": true,
+}
+
+// Variables 返回模板中引用的顶层变量名(去重并排序)
+// 属性访问、过滤器、函数调用以及for/set声明的局部变量不计入
+func Variables(text string) ([]string, error) {
+	if err := Parse(text); err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool)
+	locals := make(map[string]bool)
+
+	stream := tokens.Lex(text)
+	inExpr := false
+	var prev *tokens.Token
+	declaring := "" // 正在声明局部变量的语句: for/set
+	for !stream.End() {
+		tok := stream.Next()
+		switch tok.Type {
+		case tokens.VariableBegin, tokens.BlockBegin:
+			inExpr = true
+			prev = nil
+			declaring = ""
+			continue
+		case tokens.VariableEnd, tokens.BlockEnd:
+			inExpr = false
+			continue
+		}
+		if !inExpr {
+			continue
+		}
+
+		if tok.Type == tokens.Name {
+			switch {
+			case prev == nil && (tok.Val == "for" || tok.Val == "set"):
+				declaring = tok.Val
+			case declaring != "":
+				locals[tok.Val] = true
+			case keywords[tok.Val] || locals[tok.Val]:
+			case prev != nil && (prev.Type == tokens.Dot || prev.Type == tokens.Pipe || prev.Val == "is"):
+			case stream.Current() != nil && stream.Current().Type == tokens.Lparen:
+			default:
+				found[tok.Val] = true
+			}
+		}
+		// for x, y in items / set x = value: 声明结束
+		if (declaring == "for" && tok.Type == tokens.In) || (declaring == "set" && tok.Type == tokens.Assign) {
+			declaring = ""
+		}
+		prev = tok
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,254 @@
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+)
+
+// VariableError 变量校验错误, 汇总缺失、多余及类型不符的变量
+type VariableError struct {
+	Missing    []string `json:"missing,omitempty"`    // 必填但未提供
+	Unused     []string `json:"unused,omitempty"`     // 提供了但模板未声明
+	Undeclared []string `json:"undeclared,omitempty"` // 模板引用但未声明
+	Invalid    []string `json:"invalid,omitempty"`    // 类型不符, 格式为"name: 原因"
+}
+
+// Error 实现error接口
+func (e *VariableError) Error() string {
+	parts := []string{}
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing variables: "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Unused) > 0 {
+		parts = append(parts, "unused variables: "+strings.Join(e.Unused, ", "))
+	}
+	if len(e.Undeclared) > 0 {
+		parts = append(parts, "undeclared variables: "+strings.Join(e.Undeclared, ", "))
+	}
+	if len(e.Invalid) > 0 {
+		parts = append(parts, "invalid variables: "+strings.Join(e.Invalid, "; "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// empty 是否没有任何错误
+func (e *VariableError) empty() bool {
+	return len(e.Missing) == 0 && len(e.Unused) == 0 && len(e.Undeclared) == 0 && len(e.Invalid) == 0
+}
+
+// sort 排序各列表, 保证错误信息稳定
+func (e *VariableError) sort() {
+	sort.Strings(e.Missing)
+	sort.Strings(e.Unused)
+	sort.Strings(e.Undeclared)
+	sort.Strings(e.Invalid)
+}
+
+// ReferencedVariables 返回多段模板中引用的变量名(去重并排序)
+func ReferencedVariables(texts ...string) ([]string, error) {
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, text := range texts {
+		if text == "" {
+			continue
+		}
+		vars, err := Variables(text)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range vars {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ValidateDeclarations 校验变量声明与模板引用是否一致
+// 声明为空时不做校验, 引用的变量均视为必填
+func ValidateDeclarations(decls []model.TemplateVariable, referenced []string) error {
+	if len(decls) == 0 {
+		return nil
+	}
+
+	verr := &VariableError{}
+	declared := make(map[string]bool, len(decls))
+	for _, decl := range decls {
+		if decl.Name == "" {
+			verr.Invalid = append(verr.Invalid, "variable name is required")
+			continue
+		}
+		if declared[decl.Name] {
+			verr.Invalid = append(verr.Invalid, decl.Name+": declared more than once")
+			continue
+		}
+		declared[decl.Name] = true
+		if !validType(decl.Type) {
+			verr.Invalid = append(verr.Invalid, fmt.Sprintf("%s: unknown type %q", decl.Name, decl.Type))
+			continue
+		}
+		if decl.Default != nil {
+			if _, err := convertValue(decl.Type, decl.Default); err != nil {
+				verr.Invalid = append(verr.Invalid, fmt.Sprintf("%s: default %v", decl.Name, err))
+			}
+		}
+	}
+
+	refs := make(map[string]bool, len(referenced))
+	for _, name := range referenced {
+		refs[name] = true
+		if !declared[name] {
+			verr.Undeclared = append(verr.Undeclared, name)
+		}
+	}
+	for _, decl := range decls {
+		if decl.Name != "" && !refs[decl.Name] {
+			verr.Unused = append(verr.Unused, decl.Name)
+		}
+	}
+
+	if verr.empty() {
+		return nil
+	}
+	verr.sort()
+	return verr
+}
+
+// ResolveVariables 按声明校验变量取值并补全默认值, 返回用于渲染的变量
+// 声明为空时, 模板引用的变量均视为任意类型的必填变量
+func ResolveVariables(decls []model.TemplateVariable, referenced []string, values map[string]interface{}) (map[string]interface{}, error) {
+	if len(decls) == 0 {
+		decls = make([]model.TemplateVariable, 0, len(referenced))
+		for _, name := range referenced {
+			decls = append(decls, model.TemplateVariable{Name: name, Type: model.VariableTypeAny, Required: true})
+		}
+	}
+
+	verr := &VariableError{}
+	resolved := make(map[string]interface{}, len(decls))
+	declared := make(map[string]bool, len(decls))
+	for _, decl := range decls {
+		declared[decl.Name] = true
+
+		value, ok := values[decl.Name]
+		if !ok || value == nil {
+			switch {
+			case decl.Default != nil:
+				value = decl.Default
+			case decl.Required:
+				verr.Missing = append(verr.Missing, decl.Name)
+				continue
+			default:
+				resolved[decl.Name] = zeroValue(decl.Type)
+				continue
+			}
+		}
+
+		converted, err := convertValue(decl.Type, value)
+		if err != nil {
+			verr.Invalid = append(verr.Invalid, fmt.Sprintf("%s: %v", decl.Name, err))
+			continue
+		}
+		resolved[decl.Name] = converted
+	}
+	for name := range values {
+		if !declared[name] {
+			verr.Unused = append(verr.Unused, name)
+		}
+	}
+
+	if !verr.empty() {
+		verr.sort()
+		return nil, verr
+	}
+	return resolved, nil
+}
+
+// validType 是否为支持的变量类型
+func validType(typ string) bool {
+	switch typ {
+	case "", model.VariableTypeAny, model.VariableTypeString, model.VariableTypeNumber,
+		model.VariableTypeInteger, model.VariableTypeBoolean, model.VariableTypeList, model.VariableTypeObject:
+		return true
+	}
+	return false
+}
+
+// zeroValue 返回可选变量未提供时使用的零值
+func zeroValue(typ string) interface{} {
+	switch typ {
+	case model.VariableTypeNumber:
+		return float64(0)
+	case model.VariableTypeInteger:
+		return int64(0)
+	case model.VariableTypeBoolean:
+		return false
+	case model.VariableTypeList:
+		return []interface{}{}
+	case model.VariableTypeObject:
+		return map[string]interface{}{}
+	default:
+		return ""
+	}
+}
+
+// convertValue 校验取值类型, 数值统一转换为float64或int64
+func convertValue(typ string, value interface{}) (interface{}, error) {
+	switch typ {
+	case "", model.VariableTypeAny:
+		return value, nil
+	case model.VariableTypeString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case model.VariableTypeNumber:
+		if f, ok := toFloat(value); ok {
+			return f, nil
+		}
+	case model.VariableTypeInteger:
+		if f, ok := toFloat(value); ok && f == math.Trunc(f) {
+			return int64(f), nil
+		}
+	case model.VariableTypeBoolean:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case model.VariableTypeList:
+		if l, ok := value.([]interface{}); ok {
+			return l, nil
+		}
+	case model.VariableTypeObject:
+		if m, ok := value.(map[string]interface{}); ok {
+			return m, nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown type %q", typ)
+	}
+	return nil, fmt.Errorf("expected %s, got %T", typ, value)
+}
+
+// toFloat 将JSON解码得到的数值转换为float64
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,96 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino-ext/components/model/openai"
+	"github.com/cloudwego/eino/components/model"
+)
+
+// Params 调用模型时支持的参数, 未设置的参数使用提供者的默认值
+type Params struct {
+	Temperature *float32
+	MaxTokens   *int
+	TopP        *float32
+}
+
+// ParseParams 解析并校验模型参数配置(请求中的config或数据库中的默认参数), 不支持的参数返回错误
+func ParseParams(config map[string]interface{}) (Params, error) {
+	params := Params{}
+	unsupported := []string{}
+	for key, value := range config {
+		if value == nil {
+			continue
+		}
+		n, ok := toFloat(value)
+		switch key {
+		case "temperature":
+			if !ok || n < 0 || n > 2 {
+				return params, errors.New("temperature must be a number between 0 and 2")
+			}
+			v := float32(n)
+			params.Temperature = &v
+		case "top_p":
+			if !ok || n < 0 || n > 1 {
+				return params, errors.New("top_p must be a number between 0 and 1")
+			}
+			v := float32(n)
+			params.TopP = &v
+		case "max_tokens":
+			if !ok || n < 1 || n != math.Trunc(n) || n > math.MaxInt32 {
+				return params, errors.New("max_tokens must be a positive integer")
+			}
+			v := int(n)
+			params.MaxTokens = &v
+		default:
+			unsupported = append(unsupported, key)
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		return params, fmt.Errorf("unsupported model parameters: %s, supported: temperature, max_tokens, top_p", strings.Join(unsupported, ", "))
+	}
+	return params, nil
+}
+
+// toFloat 将JSON或YAML中的数字转换为float64
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// NewChatModel 创建OpenAI兼容接口的聊天模型, 应用请求中的模型参数及提供者配置的超时
+func NewChatModel(ctx context.Context, cfg ProviderConfig, name string, config map[string]interface{}) (model.ToolCallingChatModel, error) {
+	params, err := ParseParams(config)
+	if err != nil {
+		return nil, err
+	}
+	chatConfig := &openai.ChatModelConfig{
+		APIKey:      cfg.ApiKey,
+		BaseURL:     cfg.BaseURL,
+		Model:       name,
+		Temperature: params.Temperature,
+		MaxTokens:   params.MaxTokens,
+		TopP:        params.TopP,
+		Timeout:     time.Duration(cfg.Timeout) * time.Second,
+	}
+	return openai.NewChatModel(ctx, chatConfig)
+}
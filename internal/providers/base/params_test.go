@@ -0,0 +1,101 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestParseParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		want    Params
+		wantErr bool
+	}{
+		{"empty", nil, Params{}, false},
+		{"nil values ignored", map[string]interface{}{"temperature": nil}, Params{}, false},
+		{"all set", map[string]interface{}{"temperature": 0.7, "top_p": 1, "max_tokens": float64(256)}, Params{Temperature: ptr[float32](0.7), TopP: ptr[float32](1), MaxTokens: ptr(256)}, false},
+		{"yaml integers", map[string]interface{}{"temperature": 2, "max_tokens": int64(1)}, Params{Temperature: ptr[float32](2), MaxTokens: ptr(1)}, false},
+		{"temperature too high", map[string]interface{}{"temperature": 2.1}, Params{}, true},
+		{"temperature negative", map[string]interface{}{"temperature": -0.1}, Params{}, true},
+		{"temperature not a number", map[string]interface{}{"temperature": "0.5"}, Params{}, true},
+		{"top_p too high", map[string]interface{}{"top_p": 1.5}, Params{}, true},
+		{"top_p negative", map[string]interface{}{"top_p": -1}, Params{}, true},
+		{"max_tokens zero", map[string]interface{}{"max_tokens": 0}, Params{}, true},
+		{"max_tokens fraction", map[string]interface{}{"max_tokens": 10.5}, Params{}, true},
+		{"max_tokens overflow", map[string]interface{}{"max_tokens": float64(1 << 32)}, Params{}, true},
+		{"unsupported", map[string]interface{}{"temperature": 1, "frequency_penalty": 0.5}, Params{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseParams(tt.config)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseParams: %v", err)
+			}
+			if !equalPtr(got.Temperature, tt.want.Temperature) || !equalPtr(got.TopP, tt.want.TopP) || !equalPtr(got.MaxTokens, tt.want.MaxTokens) {
+				t.Fatalf("got %s, want %s", formatParams(got), formatParams(tt.want))
+			}
+		})
+	}
+}
+
+func TestNewChatModelTimeout(t *testing.T) {
+	// 服务端在测试结束前不返回
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	chatModel, err := NewChatModel(context.Background(), ProviderConfig{ApiKey: "test", BaseURL: server.URL, Timeout: 1}, "m", nil)
+	if err != nil {
+		t.Fatalf("NewChatModel: %v", err)
+	}
+	start := time.Now()
+	if _, err := chatModel.Generate(context.Background(), []*schema.Message{schema.UserMessage("hi")}); err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("call took %s, provider timeout was not applied", elapsed)
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func equalPtr[T comparable](a, b *T) bool {
+	return (a == nil) == (b == nil) && (a == nil || *a == *b)
+}
+
+// formatParams 输出参数值便于定位失败
+func formatParams(p Params) string {
+	format := func(v interface{}) string {
+		switch v := v.(type) {
+		case *float32:
+			if v != nil {
+				return fmt.Sprint(*v)
+			}
+		case *int:
+			if v != nil {
+				return fmt.Sprint(*v)
+			}
+		}
+		return "unset"
+	}
+	return fmt.Sprintf("temperature=%s top_p=%s max_tokens=%s", format(p.Temperature), format(p.TopP), format(p.MaxTokens))
+}
@@ -0,0 +1,19 @@
+package base
+
+import (
+	"github.com/cloudwego/eino/schema"
+	"github.com/multi-agent-testing/backend/internal/model"
+)
+
+// ApplyUsage 将响应中的token用量填入结果, 提供者未返回用量时保持为0
+func ApplyUsage(resp *model.ModelResponse, meta *schema.ResponseMeta) {
+	if meta == nil || meta.Usage == nil {
+		return
+	}
+	resp.PromptTokens = meta.Usage.PromptTokens
+	resp.CompletionTokens = meta.Usage.CompletionTokens
+	resp.TokensUsed = meta.Usage.TotalTokens
+	if resp.TokensUsed == 0 {
+		resp.TokensUsed = resp.PromptTokens + resp.CompletionTokens
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/CoolBanHub/aggo/agent"
-	"github.com/CoolBanHub/aggo/model"
 	"github.com/cloudwego/eino/schema"
 	internalModel "github.com/multi-agent-testing/backend/internal/model"
 	"github.com/multi-agent-testing/backend/internal/providers/base"
@@ -41,11 +40,7 @@ func (p *Provider) Call(ctx context.Context, req *internalModel.CallProvidersReq
 	)
 
 	// 创建聊天模型
-	cm, err := model.NewChatModel(
-		model.WithBaseUrl(p.config.BaseURL),
-		model.WithAPIKey(p.config.ApiKey),
-		model.WithModel(req.Models.Name), // 使用配置中的模型
-	)
+	cm, err := base.NewChatModel(ctx, p.config, req.Models.Name, req.Models.Config)
 	if err != nil {
 		logger.Error("Failed to create chat model",
 			zap.String("provider", p.Name()),
@@ -98,6 +93,10 @@ func (p *Provider) Call(ctx context.Context, req *internalModel.CallProvidersReq
 			messages = append(messages, schema.AssistantMessage(v.Content, []schema.ToolCall{}))
 		}
 	}
+	// 未提供消息列表时使用用户提示词
+	if len(messages) == 0 && req.Prompts.User != "" {
+		messages = append(messages, schema.UserMessage(req.Prompts.User))
+	}
 	// 进行对话
 	response, err := ag.Generate(ctx, messages)
 	if err != nil {
@@ -126,16 +125,17 @@ func (p *Provider) Call(ctx context.Context, req *internalModel.CallProvidersReq
 		zap.Int("content_length", len(response.Content)),
 	)
 
-	return &internalModel.ModelResponse{
+	resp := &internalModel.ModelResponse{
 		ModelName:    req.Models.Name,
 		Provider:     p.Name(),
 		Content:      response.Content,
 		Success:      true,
-		TokensUsed:   response.ResponseMeta.Usage.TotalTokens, // aggo暂时不返回token信息
 		ResponseTime: responseTime,
 		StartTime:    startTime,
 		EndTime:      endTime,
-	}, nil
+	}
+	base.ApplyUsage(resp, response.ResponseMeta)
+	return resp, nil
 }
 
 // Stream 流式调用OpenAI模型
@@ -178,5 +178,8 @@ func (p *Provider) ValidateConfig(config map[string]interface{}) error {
 	if p.config.ApiKey == "" {
 		return errors.New("OpenAI API key is required")
 	}
+	if _, err := base.ParseParams(config); err != nil {
+		return err
+	}
 	return nil
 }
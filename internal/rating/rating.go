@@ -0,0 +1,248 @@
+// Package rating 根据成对对比结果计算模型评分(Elo或Bradley-Terry)及自助法置信区间
+package rating
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// 评分方法
+const (
+	MethodElo          = "elo"
+	MethodBradleyTerry = "bradley_terry"
+)
+
+const (
+	// BaseRating 初始评分, Bradley-Terry评分也换算到同一尺度
+	BaseRating = 1000
+	// EloK Elo每场对比的最大调整幅度
+	EloK = 32
+	// DefaultRounds 默认自助法重采样次数
+	DefaultRounds = 200
+	// MaxRounds 自助法重采样次数上限
+	MaxRounds = 1000
+	// Confidence 置信区间的置信水平
+	Confidence = 0.95
+
+	// bootstrapSeed 固定随机种子, 相同输入得到相同的置信区间
+	bootstrapSeed = 1
+	// btMaxIterations Bradley-Terry迭代上限
+	btMaxIterations = 1000
+	// btTolerance Bradley-Terry收敛阈值
+	btTolerance = 1e-9
+)
+
+// ErrUnknownMethod 不支持的评分方法
+var ErrUnknownMethod = errors.New("unknown rating method")
+
+// Outcome 单次对比结果, Score为A的得分: 1胜, 0负, 0.5平
+type Outcome struct {
+	A     string
+	B     string
+	Score float64
+}
+
+// Rating 单个模型的评分
+type Rating struct {
+	Model  string
+	Rating float64
+	Lower  float64 // 置信区间下限
+	Upper  float64 // 置信区间上限
+	Games  int
+	Wins   int
+	Losses int
+	Ties   int
+}
+
+// Compute 计算全部模型的评分, 按评分从高到低排列
+// rounds为自助法重采样次数, 0使用默认值, 负数不计算置信区间
+func Compute(outcomes []Outcome, method string, rounds int) ([]Rating, error) {
+	var fit func([]Outcome) map[string]float64
+	switch method {
+	case MethodElo:
+		fit = Elo
+	case MethodBradleyTerry, "":
+		fit = BradleyTerry
+	default:
+		return nil, ErrUnknownMethod
+	}
+	if rounds == 0 {
+		rounds = DefaultRounds
+	}
+	if rounds > MaxRounds {
+		rounds = MaxRounds
+	}
+
+	ratings := tally(outcomes)
+	for model, r := range fit(outcomes) {
+		ratings[model].Rating = r
+		ratings[model].Lower, ratings[model].Upper = r, r
+	}
+	if rounds > 0 && len(outcomes) > 0 {
+		for model, interval := range bootstrap(outcomes, fit, rounds) {
+			ratings[model].Lower, ratings[model].Upper = interval[0], interval[1]
+		}
+	}
+
+	result := make([]Rating, 0, len(ratings))
+	for _, r := range ratings {
+		result = append(result, *r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Rating != result[j].Rating {
+			return result[i].Rating > result[j].Rating
+		}
+		return result[i].Model < result[j].Model
+	})
+	return result, nil
+}
+
+// tally 统计各模型的胜负场次
+func tally(outcomes []Outcome) map[string]*Rating {
+	ratings := make(map[string]*Rating)
+	get := func(model string) *Rating {
+		r, ok := ratings[model]
+		if !ok {
+			r = &Rating{Model: model, Rating: BaseRating, Lower: BaseRating, Upper: BaseRating}
+			ratings[model] = r
+		}
+		return r
+	}
+	for _, o := range outcomes {
+		a, b := get(o.A), get(o.B)
+		a.Games++
+		b.Games++
+		switch {
+		case o.Score > 0.5:
+			a.Wins++
+			b.Losses++
+		case o.Score < 0.5:
+			a.Losses++
+			b.Wins++
+		default:
+			a.Ties++
+			b.Ties++
+		}
+	}
+	return ratings
+}
+
+// Elo 按对比顺序依次更新Elo评分
+func Elo(outcomes []Outcome) map[string]float64 {
+	ratings := make(map[string]float64)
+	get := func(model string) float64 {
+		if r, ok := ratings[model]; ok {
+			return r
+		}
+		return BaseRating
+	}
+	for _, o := range outcomes {
+		ra, rb := get(o.A), get(o.B)
+		expected := 1 / (1 + math.Pow(10, (rb-ra)/400))
+		ratings[o.A] = ra + EloK*(o.Score-expected)
+		ratings[o.B] = rb - EloK*(o.Score-expected)
+	}
+	return ratings
+}
+
+// BradleyTerry 使用MM算法求Bradley-Terry模型的极大似然估计, 平局各记半场胜利
+// 每个模型额外与强度固定为1的虚拟对手打平一场, 避免全胜或全负时发散, 同时固定评分尺度
+func BradleyTerry(outcomes []Outcome) map[string]float64 {
+	index := make(map[string]int)
+	models := []string{}
+	for _, o := range outcomes {
+		for _, m := range []string{o.A, o.B} {
+			if _, ok := index[m]; !ok {
+				index[m] = len(models)
+				models = append(models, m)
+			}
+		}
+	}
+	n := len(models)
+	if n == 0 {
+		return map[string]float64{}
+	}
+
+	// 胜场及两两对比场次
+	wins := make([]float64, n)
+	games := make([][]float64, n)
+	for i := range games {
+		games[i] = make([]float64, n)
+	}
+	for _, o := range outcomes {
+		a, b := index[o.A], index[o.B]
+		if a == b {
+			continue
+		}
+		wins[a] += o.Score
+		wins[b] += 1 - o.Score
+		games[a][b]++
+		games[b][a]++
+	}
+
+	const prior = 1.0 // 与虚拟对手的场次
+	strength := make([]float64, n)
+	for i := range strength {
+		strength[i] = 1
+	}
+	next := make([]float64, n)
+	for iter := 0; iter < btMaxIterations; iter++ {
+		delta := 0.0
+		for i := 0; i < n; i++ {
+			denom := prior / (strength[i] + 1)
+			for j := 0; j < n; j++ {
+				if games[i][j] > 0 {
+					denom += games[i][j] / (strength[i] + strength[j])
+				}
+			}
+			next[i] = (wins[i] + prior/2) / denom
+			delta = math.Max(delta, math.Abs(math.Log(next[i]/strength[i])))
+		}
+		strength, next = next, strength
+		if delta < btTolerance {
+			break
+		}
+	}
+
+	ratings := make(map[string]float64, n)
+	for i, m := range models {
+		ratings[m] = BaseRating + 400*math.Log10(strength[i])
+	}
+	return ratings
+}
+
+// bootstrap 有放回重采样对比结果, 返回各模型评分的置信区间
+func bootstrap(outcomes []Outcome, fit func([]Outcome) map[string]float64, rounds int) map[string][2]float64 {
+	rng := rand.New(rand.NewSource(bootstrapSeed))
+	samples := make(map[string][]float64)
+	sample := make([]Outcome, len(outcomes))
+	for round := 0; round < rounds; round++ {
+		for i := range sample {
+			sample[i] = outcomes[rng.Intn(len(outcomes))]
+		}
+		for model, r := range fit(sample) {
+			samples[model] = append(samples[model], r)
+		}
+	}
+
+	alpha := (1 - Confidence) / 2
+	intervals := make(map[string][2]float64, len(samples))
+	for model, values := range samples {
+		sort.Float64s(values)
+		intervals[model] = [2]float64{quantile(values, alpha), quantile(values, 1-alpha)}
+	}
+	return intervals
+}
+
+// quantile 计算已排序数据的分位数, 使用线性插值
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
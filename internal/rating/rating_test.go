@@ -0,0 +1,176 @@
+package rating
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestElo(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcomes []Outcome
+		want     map[string]float64
+	}{
+		{"no games", nil, map[string]float64{}},
+		{"single win", []Outcome{{"a", "b", 1}}, map[string]float64{"a": 1016, "b": 984}},
+		{"single tie", []Outcome{{"a", "b", 0.5}}, map[string]float64{"a": 1000, "b": 1000}},
+		{
+			// 第二场a的期望得分为1/(1+10^(-32/400)), 获胜只再加14.53
+			name:     "repeated win",
+			outcomes: []Outcome{{"a", "b", 1}, {"a", "b", 1}},
+			want:     map[string]float64{"a": 1030.5305, "b": 969.4695},
+		},
+		{"win then loss", []Outcome{{"a", "b", 1}, {"b", "a", 1}}, map[string]float64{"a": 998.5305, "b": 1001.4695}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertRatings(t, Elo(tt.outcomes), tt.want, 1e-4)
+		})
+	}
+}
+
+func TestBradleyTerry(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcomes []Outcome
+		want     map[string]float64
+	}{
+		{"no games", nil, map[string]float64{}},
+		// 与虚拟对手的平局使全胜的模型评分有限
+		{"single win", []Outcome{{"a", "b", 1}}, map[string]float64{"a": 1131.3841, "b": 868.6159}},
+		{"three of four", repeat(Outcome{"a", "b", 1}, 3, Outcome{"b", "a", 1}, 1), map[string]float64{"a": 1082.4193, "b": 917.5807}},
+		{"even record", repeat(Outcome{"a", "b", 1}, 2, Outcome{"b", "a", 1}, 2), map[string]float64{"a": 1000, "b": 1000}},
+		{"ties count as half wins", repeat(Outcome{"a", "b", 0.5}, 3), map[string]float64{"a": 1000, "b": 1000}},
+		{"cycle", []Outcome{{"a", "b", 1}, {"b", "c", 1}, {"c", "a", 1}}, map[string]float64{"a": 1000, "b": 1000, "c": 1000}},
+		{"self play is ignored", []Outcome{{"a", "a", 1}}, map[string]float64{"a": 1000}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertRatings(t, BradleyTerry(tt.outcomes), tt.want, 1e-4)
+		})
+	}
+}
+
+func TestBradleyTerryOrder(t *testing.T) {
+	outcomes := append(repeat(Outcome{"a", "b", 1}, 3, Outcome{"b", "c", 1}, 3), Outcome{"a", "c", 1})
+	r := BradleyTerry(outcomes)
+	if !(r["a"] > r["b"] && r["b"] > r["c"]) {
+		t.Fatalf("expected a > b > c, got %v", r)
+	}
+	// 评分以虚拟对手为基准, 与对比的书写顺序无关
+	swapped := make([]Outcome, len(outcomes))
+	for i, o := range outcomes {
+		swapped[i] = Outcome{A: o.B, B: o.A, Score: 1 - o.Score}
+	}
+	assertRatings(t, BradleyTerry(swapped), r, 1e-6)
+}
+
+func TestCompute(t *testing.T) {
+	outcomes := []Outcome{
+		{"a", "b", 1},
+		{"a", "b", 1},
+		{"b", "a", 1},
+		{"a", "c", 0.5},
+		{"c", "b", 0},
+	}
+
+	for _, method := range []string{MethodBradleyTerry, MethodElo} {
+		t.Run(method, func(t *testing.T) {
+			got, err := Compute(outcomes, method, 100)
+			if err != nil {
+				t.Fatalf("Compute: %v", err)
+			}
+			again, err := Compute(outcomes, method, 100)
+			if err != nil {
+				t.Fatalf("Compute: %v", err)
+			}
+			// 固定随机种子, 置信区间可复现
+			if !reflect.DeepEqual(got, again) {
+				t.Fatalf("Compute is not deterministic:\n%+v\n%+v", got, again)
+			}
+			for i, r := range got {
+				if i > 0 && got[i-1].Rating < r.Rating {
+					t.Fatalf("ratings are not sorted: %+v", got)
+				}
+				if r.Lower > r.Rating+1e-9 || r.Upper < r.Rating-1e-9 {
+					t.Fatalf("%s: rating %.2f outside interval [%.2f, %.2f]", r.Model, r.Rating, r.Lower, r.Upper)
+				}
+			}
+		})
+	}
+
+	got, err := Compute(outcomes, "", -1)
+	if err != nil {
+		t.Fatalf("Compute: %v", err)
+	}
+	records := map[string][4]int{}
+	for _, r := range got {
+		records[r.Model] = [4]int{r.Games, r.Wins, r.Losses, r.Ties}
+		if r.Lower != r.Rating || r.Upper != r.Rating {
+			t.Fatalf("%s: expected no interval with negative rounds, got [%.2f, %.2f]", r.Model, r.Lower, r.Upper)
+		}
+	}
+	want := map[string][4]int{
+		"a": {4, 2, 1, 1},
+		"b": {4, 2, 2, 0},
+		"c": {2, 0, 1, 1},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Fatalf("games/wins/losses/ties = %v, want %v", records, want)
+	}
+
+	if _, err := Compute(outcomes, "glicko", 0); !errors.Is(err, ErrUnknownMethod) {
+		t.Fatalf("expected ErrUnknownMethod, got %v", err)
+	}
+	if got, err := Compute(nil, MethodElo, 0); err != nil || len(got) != 0 {
+		t.Fatalf("expected empty ratings without outcomes, got %v (%v)", got, err)
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	tests := []struct {
+		name   string
+		sorted []float64
+		q      float64
+		want   float64
+	}{
+		{"single value", []float64{5}, 0.025, 5},
+		{"min", []float64{1, 2, 3}, 0, 1},
+		{"max", []float64{1, 2, 3}, 1, 3},
+		{"median", []float64{1, 2, 3}, 0.5, 2},
+		{"interpolated", []float64{0, 10}, 0.25, 2.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quantile(tt.sorted, tt.q); math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("quantile = %g, want %g", got, tt.want)
+			}
+		})
+	}
+}
+
+// repeat 按(对比, 次数)成对展开对比结果
+func repeat(args ...interface{}) []Outcome {
+	outcomes := []Outcome{}
+	for i := 0; i < len(args); i += 2 {
+		for n := 0; n < args[i+1].(int); n++ {
+			outcomes = append(outcomes, args[i].(Outcome))
+		}
+	}
+	return outcomes
+}
+
+func assertRatings(t *testing.T, got, want map[string]float64, tolerance float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("ratings = %v, want %v", got, want)
+	}
+	for model, w := range want {
+		g, ok := got[model]
+		if !ok || math.Abs(g-w) > tolerance {
+			t.Fatalf("%s rating = %.4f, want %.4f (all: %v)", model, g, w, got)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Package report 将测试结果渲染为CSV、JSONL、Markdown及自包含的HTML报告
+package report
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/prompt"
+)
+
+// 报告格式
+const (
+	FormatCSV      = "csv"      // 每个用例 × 模型一行
+	FormatJSONL    = "jsonl"    // 每个用例 × 模型一行原始结果
+	FormatMarkdown = "markdown" // 汇总表及并排输出表
+	FormatHTML     = "html"     // 含并排输出、断言、指标及图表的自包含页面
+)
+
+// Formats 支持的报告格式
+var Formats = []string{FormatCSV, FormatJSONL, FormatMarkdown, FormatHTML}
+
+// 单个模型响应的状态
+const (
+	StatusPass    = "pass"    // 调用成功且断言及评审均通过
+	StatusFail    = "fail"    // 断言或评审未通过
+	StatusError   = "error"   // 模型调用失败
+	StatusDone    = "done"    // 调用成功, 未设置断言及评审
+	StatusMissing = "missing" // 没有结果, 如任务未结束
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// ValidFormat 是否为支持的报告格式
+func ValidFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentType 报告格式对应的HTTP Content-Type
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSONL:
+		return "application/x-ndjson; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	default:
+		return "text/html; charset=utf-8"
+	}
+}
+
+// FileExtension 报告格式对应的文件扩展名
+func FileExtension(format string) string {
+	if format == FormatMarkdown {
+		return "md"
+	}
+	return format
+}
+
+// Write 以指定格式写出报告
+func Write(w io.Writer, r *model.Report, format string) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, r)
+	case FormatJSONL:
+		return WriteJSONL(w, r)
+	case FormatMarkdown:
+		return WriteMarkdown(w, r)
+	case FormatHTML:
+		return WriteHTML(w, r)
+	default:
+		return fmt.Errorf("unsupported report format %q", format)
+	}
+}
+
+// Status 判定单个模型响应的状态, resp为nil时为missing
+func Status(resp *model.ModelResponse) string {
+	switch {
+	case resp == nil:
+		return StatusMissing
+	case !resp.Success:
+		return StatusError
+	case resp.Passed == nil:
+		return StatusDone
+	case *resp.Passed:
+		return StatusPass
+	default:
+		return StatusFail
+	}
+}
+
+// FailureMessage 汇总未通过的断言及评审结论, 调用失败时为错误信息
+func FailureMessage(resp *model.ModelResponse) string {
+	if !resp.Success {
+		return resp.Error
+	}
+	var reasons []string
+	for _, a := range resp.Assertions {
+		if a.Passed {
+			continue
+		}
+		if a.Message != "" {
+			reasons = append(reasons, a.Name+": "+a.Message)
+		} else {
+			reasons = append(reasons, a.Name)
+		}
+	}
+	if j := resp.Judge; j != nil {
+		switch {
+		case j.Error != "":
+			reasons = append(reasons, "judge: "+j.Error)
+		case j.Passed != nil && !*j.Passed:
+			reasons = append(reasons, "judge: "+j.Reason)
+		}
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// renderedPrompt 用例的展示用提示词, 有变量时渲染, 渲染失败时使用原文
+func renderedPrompt(text string, vars map[string]interface{}) string {
+	if len(vars) == 0 {
+		return text
+	}
+	rendered, err := prompt.Render(text, vars)
+	if err != nil {
+		return text
+	}
+	return rendered
+}
+
+// runLabel 运行的展示名称
+func runLabel(run *model.RunReport) string {
+	return run.RunType + " " + run.RunID
+}
+
+// caseLabel 用例的展示名称, 优先使用用例ID
+func caseLabel(index int, tc model.TestCase) string {
+	if tc.ID != "" {
+		return tc.ID
+	}
+	return fmt.Sprintf("#%d", index+1)
+}
@@ -0,0 +1,234 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+// testReport 一个批量任务(2个用例 × 2个模型)及一条单次测试记录
+// 批量任务中a在c1通过、在c2调用失败, b在c1断言未通过、在c2没有结果
+func testReport() *model.Report {
+	created := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	return &model.Report{
+		Title:       "Capitals <v1>",
+		GeneratedAt: created.Add(time.Hour),
+		Runs: []*model.RunReport{
+			{
+				RunType:   model.RunTypeJob,
+				RunID:     "job-1",
+				Status:    model.JobStatusSucceeded,
+				CreatedAt: created,
+				BatchResult: model.BatchResult{
+					Models: []string{"a", "b"},
+					Cases: []*model.BatchCaseResult{
+						{
+							Case: model.TestCase{ID: "c1", Prompt: "Capital of {{ country }}?", Variables: map[string]interface{}{"country": "France"}, Expected: "Paris"},
+							Results: map[string]*model.ModelResponse{
+								"a": {
+									ModelName: "a", Provider: "openai", Content: "Paris", ResponseTime: 120, TokensUsed: 30, Cost: ptr(0.001), Success: true, Passed: ptr(true),
+									Assertions: []model.AssertionResult{{Type: "contains", Name: "contains", Passed: true}},
+									Metrics:    map[string]float64{"rouge_l": 1, "bleu": 0.5},
+								},
+								"b": {
+									ModelName: "b", Provider: "deepseek", Content: "Lyon | <b>", ResponseTime: 80, TokensUsed: 20, Success: true, Passed: ptr(false),
+									Assertions: []model.AssertionResult{{Type: "contains", Name: "contains", Passed: false, Message: "expected Paris"}},
+									Judge:      &model.JudgeResult{Score: ptr(2.0), Passed: ptr(false), Reason: "wrong city"},
+								},
+							},
+						},
+						{
+							Case: model.TestCase{ID: "c2", Prompt: "Capital of Japan?"},
+							Results: map[string]*model.ModelResponse{
+								"a": {ModelName: "a", Provider: "openai", Success: false, Error: "timeout"},
+							},
+						},
+					},
+					Summary: map[string]*model.ModelSummary{
+						"a": {Completed: 2, Succeeded: 1, Evaluated: 1, Passed: 1, PassRate: ptr(1.0), AvgResponseTime: 120, TokensUsed: 30, Cost: ptr(0.001)},
+						"b": {Completed: 1, Succeeded: 1, Evaluated: 1, Passed: 0, PassRate: ptr(0.0), AvgResponseTime: 80, TokensUsed: 20, JudgeScore: ptr(2.0)},
+					},
+				},
+			},
+			{
+				RunType:   model.RunTypeRecord,
+				RunID:     "7",
+				CreatedAt: created,
+				BatchResult: model.BatchResult{
+					Models: []string{"a"},
+					Cases: []*model.BatchCaseResult{{
+						Case:    model.TestCase{System: "Be brief.", Prompt: "Hi"},
+						Results: map[string]*model.ModelResponse{"a": {ModelName: "a", Provider: "openai", Content: "Hello", ResponseTime: 50, TokensUsed: 5, Success: true}},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testReport(), FormatCSV); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid csv: %v", err)
+	}
+	if !reflect.DeepEqual(records[0], csvHeader) {
+		t.Fatalf("header = %v", records[0])
+	}
+	// 每个用例 × 模型一行, 没有结果的模型也输出
+	if len(records) != 6 {
+		t.Fatalf("got %d rows, want header + 5", len(records))
+	}
+
+	want := []map[string]string{
+		{
+			"run_type": "job", "run_id": "job-1", "case_index": "0", "case_id": "c1", "prompt": "Capital of France?", "expected": "Paris",
+			"model_name": "a", "provider": "openai", "status": "pass", "content": "Paris", "response_time": "120", "tokens_used": "30",
+			"cost": "0.001", "judge_score": "", "assertions_passed": "1", "assertions_total": "1", "failures": "", "metrics": "bleu=0.5;rouge_l=1",
+		},
+		{
+			"model_name": "b", "provider": "deepseek", "status": "fail", "content": "Lyon | <b>", "cost": "", "judge_score": "2",
+			"assertions_passed": "0", "assertions_total": "1", "failures": "contains: expected Paris; judge: wrong city", "metrics": "",
+		},
+		{"case_index": "1", "case_id": "c2", "model_name": "a", "status": "error", "error": "timeout", "failures": "timeout"},
+		{"case_index": "1", "model_name": "b", "provider": "", "status": "missing", "content": "", "response_time": "", "metrics": ""},
+		{"run_type": "record", "run_id": "7", "case_index": "0", "case_id": "", "prompt": "Hi", "model_name": "a", "status": "done", "content": "Hello"},
+	}
+	for i, fields := range want {
+		row := records[i+1]
+		if len(row) != len(csvHeader) {
+			t.Fatalf("row %d has %d columns, want %d", i+1, len(row), len(csvHeader))
+		}
+		for col, value := range fields {
+			if got := row[columnIndex(t, col)]; got != value {
+				t.Errorf("row %d %s = %q, want %q", i+1, col, got, value)
+			}
+		}
+	}
+}
+
+// columnIndex CSV列的位置
+func columnIndex(t *testing.T, name string) int {
+	t.Helper()
+	for i, col := range csvHeader {
+		if col == name {
+			return i
+		}
+	}
+	t.Fatalf("unknown column %s", name)
+	return -1
+}
+
+func TestWriteJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testReport(), FormatJSONL); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	var rows []jsonlRow
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var row jsonlRow
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			t.Fatalf("line %d is not valid json: %v", len(rows)+1, err)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("got %d rows, want 5", len(rows))
+	}
+
+	var statuses []string
+	for _, row := range rows {
+		statuses = append(statuses, row.RunType+"/"+row.Model+"/"+row.Status)
+	}
+	if want := []string{"job/a/pass", "job/b/fail", "job/a/error", "job/b/missing", "record/a/done"}; !reflect.DeepEqual(statuses, want) {
+		t.Fatalf("rows = %v, want %v", statuses, want)
+	}
+	// 原始结果不渲染提示词
+	first := rows[0]
+	if first.RunID != "job-1" || first.CaseIndex != 0 || first.Case.Prompt != "Capital of {{ country }}?" || first.Case.Variables["country"] != "France" {
+		t.Fatalf("unexpected first row: %+v", first)
+	}
+	if r := first.Response; r == nil || r.Content != "Paris" || r.Cost == nil || *r.Cost != 0.001 || r.Metrics["bleu"] != 0.5 {
+		t.Fatalf("unexpected first response: %+v", r)
+	}
+	if rows[3].Response != nil || rows[3].CaseIndex != 1 {
+		t.Fatalf("missing result should have no response: %+v", rows[3])
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testReport(), FormatMarkdown); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# Capitals <v1>\n",
+		"Generated at 2026-10-01T09:00:00Z",
+		"## job job-1\n",
+		"Status: succeeded, created at 2026-10-01T08:00:00Z, cases: 2",
+		"| a | 1/1 | 100.0% | 120ms | 30 | 0.0010 | - |\n",
+		"| b | 0/1 | 0.0% | 80ms | 20 | - | 2.00 |\n",
+		"| Case | a | b |\n",
+		"| c1 | PASS Paris | FAIL Lyon \\| &lt;b&gt; |\n",
+		"| c2 | ERROR timeout | MISSING |\n",
+		"- **c1** / b (fail): contains: expected Paris; judge: wrong city\n",
+		"- **c2** / a (error): timeout\n",
+		"## record 7\n",
+		"| #1 | DONE Hello |\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	// 测试记录没有未通过的响应
+	if strings.Count(out, "### Failures") != 1 {
+		t.Errorf("expected a single failures section:\n%s", out)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testReport(), FormatHTML); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<h1>Capitals &lt;v1&gt;</h1>",
+		"<h2>job job-1</h2>",
+		"<h2>record 7</h2>",
+		`<td class="num">1/1</td>`,
+		`<td class="num">100.0%</td>`,
+		`<td class="num">2.00</td>`,
+		"bleu: 0.500 rouge_l: 1.000 ",
+		"<td>c1</td><td>b</td><td><span class=\"badge fail\">FAIL</span></td><td>contains: expected Paris; judge: wrong city</td>",
+		"<pre>Capital of France?</pre>",
+		"<pre>Lyon | &lt;b&gt;</pre>",
+		"<pre>timeout</pre>",
+		`<span class="badge missing">MISSING</span>`,
+		"<pre>Be brief.</pre>",
+		// b的平均响应时间为最大值a的2/3
+		`<div class="bar" style="width: 66.7%">`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<b>") {
+		t.Error("model output is not escaped")
+	}
+}
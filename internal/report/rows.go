@@ -0,0 +1,127 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+)
+
+// csvHeader CSV报告的列, 每个用例 × 模型一行
+var csvHeader = []string{
+	"run_type", "run_id", "case_index", "case_id", "prompt", "expected", "model_name", "provider", "status",
+	"content", "error", "response_time", "tokens_used", "cost", "judge_score", "assertions_passed",
+	"assertions_total", "failures", "metrics",
+}
+
+// WriteCSV 以CSV格式写出报告, 指标以name=value形式用分号分隔
+func WriteCSV(w io.Writer, r *model.Report) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, run := range r.Runs {
+		for i, row := range run.Cases {
+			base := []string{
+				run.RunType, run.RunID, strconv.Itoa(i), row.Case.ID,
+				renderedPrompt(row.Case.Prompt, row.Case.Variables), row.Case.Expected,
+			}
+			for _, name := range run.Models {
+				resp := row.Results[name]
+				record := append(append([]string{}, base...), name)
+				if resp == nil {
+					record = append(record, "", StatusMissing, "", "", "", "", "", "", "", "", "", "")
+				} else {
+					record = append(record, responseFields(resp)...)
+				}
+				if err := writer.Write(record); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// responseFields 单个模型响应在CSV中的列, 从provider列开始
+func responseFields(resp *model.ModelResponse) []string {
+	passed := 0
+	for _, a := range resp.Assertions {
+		if a.Passed {
+			passed++
+		}
+	}
+	judgeScore := ""
+	if resp.Judge != nil && resp.Judge.Score != nil {
+		judgeScore = formatFloat(*resp.Judge.Score)
+	}
+	cost := ""
+	if resp.Cost != nil {
+		cost = formatFloat(*resp.Cost)
+	}
+	failures := ""
+	if status := Status(resp); status == StatusFail || status == StatusError {
+		failures = FailureMessage(resp)
+	}
+
+	names := make([]string, 0, len(resp.Metrics))
+	for name := range resp.Metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	metrics := make([]string, 0, len(names))
+	for _, name := range names {
+		metrics = append(metrics, name+"="+formatFloat(resp.Metrics[name]))
+	}
+
+	return []string{
+		resp.Provider, Status(resp), resp.Content, resp.Error, strconv.FormatInt(resp.ResponseTime, 10),
+		strconv.Itoa(resp.TokensUsed), cost, judgeScore, strconv.Itoa(passed), strconv.Itoa(len(resp.Assertions)),
+		failures, strings.Join(metrics, ";"),
+	}
+}
+
+// jsonlRow JSONL报告的一行
+type jsonlRow struct {
+	RunType   string               `json:"run_type"`
+	RunID     string               `json:"run_id"`
+	CaseIndex int                  `json:"case_index"`
+	Case      model.TestCase       `json:"case"`
+	Model     string               `json:"model"`
+	Status    string               `json:"status"`
+	Response  *model.ModelResponse `json:"response,omitempty"` // 没有结果时为空
+}
+
+// WriteJSONL 以JSON Lines格式写出原始结果, 每个用例 × 模型一行
+func WriteJSONL(w io.Writer, r *model.Report) error {
+	enc := json.NewEncoder(w)
+	for _, run := range r.Runs {
+		for i, row := range run.Cases {
+			for _, name := range run.Models {
+				resp := row.Results[name]
+				if err := enc.Encode(jsonlRow{
+					RunType:   run.RunType,
+					RunID:     run.RunID,
+					CaseIndex: i,
+					Case:      row.Case,
+					Model:     name,
+					Status:    Status(resp),
+					Response:  resp,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// formatFloat 以最短形式输出浮点数
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
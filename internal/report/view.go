@@ -0,0 +1,228 @@
+package report
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+)
+
+// view Markdown及HTML模板使用的报告数据
+type view struct {
+	Title       string
+	GeneratedAt string
+	Runs        []*runView
+}
+
+// runView 单次运行的展示数据
+type runView struct {
+	Label     string
+	Status    string
+	CreatedAt string
+	Models    []string
+	Summaries []*summaryView
+	Latency   []bar // 各模型平均响应时间
+	Cost      []bar // 各模型总费用, 均未配置价格时为空
+	Cases     []*caseView
+	Failures  []failure
+}
+
+// summaryView 单个模型的汇总
+type summaryView struct {
+	Model string
+	*model.ModelSummary
+}
+
+// bar 图表中的一个条目, Percent为相对整份报告最大值的百分比, 使各运行的图表可直接比较
+type bar struct {
+	Label   string
+	Value   string
+	Percent float64
+}
+
+// caseView 单个用例及各模型的并排输出
+type caseView struct {
+	Label    string
+	System   string
+	Prompt   string
+	Expected string
+	Cells    []*cellView
+}
+
+// cellView 单个模型的输出
+type cellView struct {
+	Model    string
+	Status   string
+	Response *model.ModelResponse // 没有结果时为空
+}
+
+// failure 未通过或出错的模型响应
+type failure struct {
+	Case    string
+	Model   string
+	Status  string
+	Message string
+}
+
+// newView 构建模板数据
+func newView(r *model.Report) *view {
+	v := &view{
+		Title:       r.Title,
+		GeneratedAt: r.GeneratedAt.Format(time.RFC3339),
+		Runs:        make([]*runView, 0, len(r.Runs)),
+	}
+
+	var maxLatency, maxCost float64
+	for _, run := range r.Runs {
+		for _, sum := range run.Summary {
+			maxLatency = max(maxLatency, float64(sum.AvgResponseTime))
+			if sum.Cost != nil {
+				maxCost = max(maxCost, *sum.Cost)
+			}
+		}
+	}
+
+	for _, run := range r.Runs {
+		rv := &runView{
+			Label:     runLabel(run),
+			Status:    run.Status,
+			CreatedAt: run.CreatedAt.Format(time.RFC3339),
+			Models:    run.Models,
+		}
+		hasCost := false
+		for _, name := range run.Models {
+			sum := run.Summary[name]
+			if sum == nil {
+				sum = &model.ModelSummary{}
+			}
+			rv.Summaries = append(rv.Summaries, &summaryView{Model: name, ModelSummary: sum})
+			rv.Latency = append(rv.Latency, bar{
+				Label:   name,
+				Value:   formatMillis(sum.AvgResponseTime),
+				Percent: percentOf(float64(sum.AvgResponseTime), maxLatency),
+			})
+			costBar := bar{Label: name, Value: formatCost(sum.Cost)}
+			if sum.Cost != nil {
+				hasCost = true
+				costBar.Percent = percentOf(*sum.Cost, maxCost)
+			}
+			rv.Cost = append(rv.Cost, costBar)
+		}
+		if !hasCost {
+			rv.Cost = nil
+		}
+
+		for i, row := range run.Cases {
+			cv := &caseView{
+				Label:    caseLabel(i, row.Case),
+				System:   renderedPrompt(row.Case.System, row.Case.Variables),
+				Prompt:   renderedPrompt(row.Case.Prompt, row.Case.Variables),
+				Expected: row.Case.Expected,
+			}
+			for _, name := range run.Models {
+				resp := row.Results[name]
+				cell := &cellView{Model: name, Status: Status(resp), Response: resp}
+				cv.Cells = append(cv.Cells, cell)
+				if cell.Status == StatusFail || cell.Status == StatusError {
+					rv.Failures = append(rv.Failures, failure{
+						Case:    cv.Label,
+						Model:   name,
+						Status:  cell.Status,
+						Message: FailureMessage(resp),
+					})
+				}
+			}
+			rv.Cases = append(rv.Cases, cv)
+		}
+		v.Runs = append(v.Runs, rv)
+	}
+	return v
+}
+
+// percentOf 计算占最大值的百分比
+func percentOf(value, maxValue float64) float64 {
+	if maxValue <= 0 {
+		return 0
+	}
+	return value / maxValue * 100
+}
+
+// formatMillis 输出毫秒数
+func formatMillis(ms int64) string {
+	return fmt.Sprintf("%dms", ms)
+}
+
+// formatCost 输出费用, 未知时输出"-"
+func formatCost(cost *float64) string {
+	if cost == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.4f", *cost)
+}
+
+// formatRatio 以百分比输出比例, 为空时输出"-"
+func formatRatio(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", *v*100)
+}
+
+// formatScore 输出分数, 为空时输出"-"
+func formatScore(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", *v)
+}
+
+// truncate 截断过长的文本, 按字符计数
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
+
+// templateFuncs 两种模板共用的函数
+var templateFuncs = map[string]interface{}{
+	"ms":       formatMillis,
+	"cost":     formatCost,
+	"ratio":    formatRatio,
+	"score":    formatScore,
+	"truncate": truncate,
+	"status":   func(s string) string { return strings.ToUpper(s) },
+}
+
+// markdownTemplate Markdown报告模板
+var markdownTemplate = texttemplate.Must(texttemplate.New("report.md.tmpl").
+	Funcs(templateFuncs).
+	Funcs(texttemplate.FuncMap{"md": markdownCell}).
+	ParseFS(templateFS, "templates/report.md.tmpl"))
+
+// htmlTemplate HTML报告模板
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("report.html.tmpl").
+	Funcs(templateFuncs).
+	ParseFS(templateFS, "templates/report.html.tmpl"))
+
+// WriteMarkdown 以Markdown格式写出报告, 包含各运行的汇总表、并排输出表及未通过的原因
+func WriteMarkdown(w io.Writer, r *model.Report) error {
+	return markdownTemplate.Execute(w, newView(r))
+}
+
+// WriteHTML 以自包含的HTML页面写出报告, 样式及图表均内联, 不依赖外部资源
+func WriteHTML(w io.Writer, r *model.Report) error {
+	return htmlTemplate.Execute(w, newView(r))
+}
+
+// markdownCell 转义表格单元格中的竖线及HTML标签, 并将换行替换为<br>
+func markdownCell(s string) string {
+	s = strings.NewReplacer("|", `\|`, "<", "&lt;", ">", "&gt;").Replace(s)
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
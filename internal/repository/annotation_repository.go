@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// AnnotationFilter 人工标注查询条件, 零值字段不参与过滤
+type AnnotationFilter struct {
+	RunType   string
+	RunID     string
+	Provider  string
+	Model     string
+	Annotator string
+	Tag       string
+	Offset    int
+	Limit     int
+}
+
+// AnnotationRepository 人工标注仓储
+type AnnotationRepository struct {
+	db *gorm.DB
+}
+
+// NewAnnotationRepository 创建人工标注仓储
+func NewAnnotationRepository(db *gorm.DB) *AnnotationRepository {
+	return &AnnotationRepository{
+		db: db,
+	}
+}
+
+// Save 保存标注, 同一标注者对同一响应已有标注时覆盖评分、标签、备注及偏好
+// 并发创建同一标注时返回ErrDuplicate
+func (r *AnnotationRepository) Save(ctx context.Context, annotation *model.Annotation) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing model.Annotation
+		err := tx.Where("run_type = ? AND run_id = ? AND case_index = ? AND provider = ? AND model_name = ? AND annotator = ?",
+			annotation.RunType, annotation.RunID, annotation.CaseIndex, annotation.Provider, annotation.ModelName, annotation.Annotator).
+			First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(annotation).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&existing).
+			Select("rating", "tags", "tag_names", "note", "preferred", "updated_at").
+			Updates(annotation).Error
+		if err != nil {
+			return err
+		}
+		return tx.First(annotation, existing.ID).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// Get 获取标注
+func (r *AnnotationRepository) Get(ctx context.Context, id uint64) (*model.Annotation, error) {
+	var annotation model.Annotation
+	if err := r.db.WithContext(ctx).First(&annotation, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &annotation, nil
+}
+
+// List 按条件分页查询标注, 按运行、用例、模型及标注者排序, 同一响应的标注相邻
+func (r *AnnotationRepository) List(ctx context.Context, filter AnnotationFilter) ([]*model.Annotation, int64, error) {
+	query := r.filter(ctx, filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("run_type ASC, run_id ASC, case_index ASC, model_name ASC, provider ASC, annotator ASC").Offset(filter.Offset)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	annotations := []*model.Annotation{}
+	if err := query.Find(&annotations).Error; err != nil {
+		return nil, 0, err
+	}
+	return annotations, total, nil
+}
+
+// Delete 删除标注
+func (r *AnnotationRepository) Delete(ctx context.Context, id uint64) error {
+	result := r.db.WithContext(ctx).Delete(&model.Annotation{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// filter 构造过滤条件
+func (r *AnnotationRepository) filter(ctx context.Context, filter AnnotationFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&model.Annotation{})
+	if filter.RunType != "" {
+		query = query.Where("run_type = ?", filter.RunType)
+	}
+	if filter.RunID != "" {
+		query = query.Where("run_id = ?", filter.RunID)
+	}
+	if filter.Provider != "" {
+		query = query.Where("provider = ?", filter.Provider)
+	}
+	if filter.Model != "" {
+		query = query.Where("model_name = ?", filter.Model)
+	}
+	if filter.Annotator != "" {
+		query = query.Where("annotator = ?", filter.Annotator)
+	}
+	if filter.Tag != "" {
+		query = query.Where("tag_names LIKE ? ESCAPE '!'", "%,"+escapeLike(filter.Tag)+",%")
+	}
+	return query
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// ArenaRepository 盲测对战仓储
+type ArenaRepository struct {
+	db *gorm.DB
+}
+
+// NewArenaRepository 创建盲测对战仓储
+func NewArenaRepository(db *gorm.DB) *ArenaRepository {
+	return &ArenaRepository{
+		db: db,
+	}
+}
+
+// Create 保存对战
+func (r *ArenaRepository) Create(ctx context.Context, battle *model.ArenaBattle) error {
+	return r.db.WithContext(ctx).Create(battle).Error
+}
+
+// Get 获取对战
+func (r *ArenaRepository) Get(ctx context.Context, id string) (*model.ArenaBattle, error) {
+	var battle model.ArenaBattle
+	if err := r.db.WithContext(ctx).First(&battle, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &battle, nil
+}
+
+// Vote 记录投票并保存对应的成对对比, 每场对战只能投票一次, 重复投票返回ErrConflict
+func (r *ArenaRepository) Vote(ctx context.Context, id, winner, reason string, comparison *model.Comparison) (*model.ArenaBattle, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&model.ArenaBattle{}).
+			Where("id = ? AND winner = ?", id, "").
+			Updates(map[string]interface{}{"winner": winner, "reason": reason, "voted_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			var count int64
+			if err := tx.Model(&model.ArenaBattle{}).Where("id = ?", id).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return ErrNotFound
+			}
+			return ErrConflict
+		}
+
+		if err := tx.Create(comparison).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.ArenaBattle{}).Where("id = ?", id).Update("comparison_id", comparison.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, id)
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// ComparisonFilter 成对对比查询条件, 零值字段不参与过滤
+type ComparisonFilter struct {
+	Tag       string
+	DatasetID uint64
+	Source    string
+	Provider  string // 任一方为该提供者, 与Model同时指定时同一方需同时匹配
+	Model     string // 任一方为该模型
+	StartTime *time.Time
+	EndTime   *time.Time
+	Offset    int
+	Limit     int
+}
+
+// ComparisonRepository 成对对比仓储
+type ComparisonRepository struct {
+	db *gorm.DB
+}
+
+// NewComparisonRepository 创建成对对比仓储
+func NewComparisonRepository(db *gorm.DB) *ComparisonRepository {
+	return &ComparisonRepository{
+		db: db,
+	}
+}
+
+// Create 批量保存成对对比
+func (r *ComparisonRepository) Create(ctx context.Context, comparisons []*model.Comparison) error {
+	if len(comparisons) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(comparisons, 100).Error
+}
+
+// Get 获取成对对比
+func (r *ComparisonRepository) Get(ctx context.Context, id uint64) (*model.Comparison, error) {
+	var comparison model.Comparison
+	if err := r.db.WithContext(ctx).First(&comparison, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &comparison, nil
+}
+
+// List 按条件分页查询成对对比, 按时间倒序
+func (r *ComparisonRepository) List(ctx context.Context, filter ComparisonFilter) ([]*model.Comparison, int64, error) {
+	query := r.filter(ctx, filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("id DESC").Offset(filter.Offset)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	comparisons := []*model.Comparison{}
+	if err := query.Find(&comparisons).Error; err != nil {
+		return nil, 0, err
+	}
+	return comparisons, total, nil
+}
+
+// ListOutcomes 按条件查询全部对比的胜负, 按保存顺序排列, 不加载提示词及输出
+func (r *ComparisonRepository) ListOutcomes(ctx context.Context, filter ComparisonFilter) ([]*model.Comparison, error) {
+	comparisons := []*model.Comparison{}
+	err := r.filter(ctx, filter).
+		Select("id", "provider_a", "model_a", "provider_b", "model_b", "winner", "created_at").
+		Order("id ASC").
+		Find(&comparisons).Error
+	return comparisons, err
+}
+
+// Delete 删除成对对比
+func (r *ComparisonRepository) Delete(ctx context.Context, id uint64) error {
+	result := r.db.WithContext(ctx).Delete(&model.Comparison{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// filter 构造过滤条件
+func (r *ComparisonRepository) filter(ctx context.Context, filter ComparisonFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&model.Comparison{})
+	if filter.Tag != "" {
+		query = query.Where("tag_names LIKE ? ESCAPE '!'", "%,"+escapeLike(filter.Tag)+",%")
+	}
+	if filter.DatasetID != 0 {
+		query = query.Where("dataset_id = ?", filter.DatasetID)
+	}
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+	// 同名模型在不同提供者下是不同的参与方
+	switch {
+	case filter.Provider != "" && filter.Model != "":
+		query = query.Where("(provider_a = ? AND model_a = ?) OR (provider_b = ? AND model_b = ?)",
+			filter.Provider, filter.Model, filter.Provider, filter.Model)
+	case filter.Model != "":
+		query = query.Where("model_a = ? OR model_b = ?", filter.Model, filter.Model)
+	case filter.Provider != "":
+		query = query.Where("provider_a = ? OR provider_b = ?", filter.Provider, filter.Provider)
+	}
+	if filter.StartTime != nil {
+		query = query.Where("created_at >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		query = query.Where("created_at < ?", *filter.EndTime)
+	}
+	return query
+}
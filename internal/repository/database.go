@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/repository/migrations"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+var (
+	// ErrNotFound 记录不存在
+	ErrNotFound = errors.New("record not found")
+	// ErrDuplicate 记录违反唯一约束
+	ErrDuplicate = errors.New("record already exists")
+	// ErrConflict 记录状态已改变, 不能再次执行该操作
+	ErrConflict = errors.New("record state conflict")
+)
+
+// NewDB 根据配置创建数据库连接
+func NewDB(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+	return NewDBWithDSN(cfg, "")
+}
+
+// NewDBWithDSN 使用指定的MySQL连接串创建数据库连接, dsn为空时使用配置生成
+func NewDBWithDSN(cfg *config.DatabaseConfig, dsn string) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch cfg.Type {
+	case "mysql", "":
+		if dsn == "" {
+			dsn = cfg.GetDSN()
+		}
+		dialector = mysql.Open(dsn)
+	case "sqlite":
+		if !cfg.IsSQLiteMemory() {
+			if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+				return nil, fmt.Errorf("failed to create database directory: %w", err)
+			}
+		}
+		dialector = sqlite.Open(cfg.GetSQLiteDSN())
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", cfg.Type)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: gormlogger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), gormlogger.Config{
+			SlowThreshold:             200 * time.Millisecond,
+			LogLevel:                  gormlogger.Warn,
+			IgnoreRecordNotFoundError: true,
+		}),
+		TranslateError: true, // 将唯一约束冲突统一转换为gorm.ErrDuplicatedKey
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sql db: %w", err)
+	}
+	if cfg.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.Type == "sqlite" && cfg.IsSQLiteMemory() {
+		// 内存数据库的每个连接相互独立, 只能使用单连接
+		sqlDB.SetMaxOpenConns(1)
+	}
+	sqlDB.SetConnMaxLifetime(time.Hour)
+
+	return db, nil
+}
+
+// NewMigrator 创建与数据库类型匹配的迁移执行器
+func NewMigrator(db *gorm.DB, cfg *config.DatabaseConfig) (*migrations.Migrator, error) {
+	dialect := cfg.Type
+	if dialect == "" {
+		dialect = "mysql"
+	}
+	return migrations.New(db, dialect)
+}
+
+// Close 关闭数据库连接
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// likeEscaper 转义LIKE模式中的通配符, 配合ESCAPE '!'使用
+// MySQL与SQLite对反斜杠字面量的处理不同, 因此使用!作为转义字符
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// escapeLike 转义用户输入, 使其在LIKE模式中按字面匹配
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
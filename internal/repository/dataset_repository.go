@@ -0,0 +1,309 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// DatasetRepository 数据集仓储
+type DatasetRepository struct {
+	db *gorm.DB
+}
+
+// NewDatasetRepository 创建数据集仓储
+func NewDatasetRepository(db *gorm.DB) *DatasetRepository {
+	return &DatasetRepository{
+		db: db,
+	}
+}
+
+// Create 创建数据集及其用例
+func (r *DatasetRepository) Create(ctx context.Context, dataset *model.Dataset, cases []model.TestCase) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		dataset.CaseCount = len(cases)
+		dataset.Modified = true
+		if err := tx.Create(dataset).Error; err != nil {
+			return err
+		}
+		return createCases(tx, dataset.ID, 0, cases)
+	})
+}
+
+// createCases 从position开始写入工作副本的用例
+func createCases(tx *gorm.DB, datasetID uint64, position int, cases []model.TestCase) error {
+	if len(cases) == 0 {
+		return nil
+	}
+	rows := make([]*model.DatasetCase, 0, len(cases))
+	for i, tc := range cases {
+		row := &model.DatasetCase{DatasetID: datasetID, Position: position + i}
+		if err := row.SetTestCase(tc); err != nil {
+			return fmt.Errorf("case %d: %w", i+1, err)
+		}
+		rows = append(rows, row)
+	}
+	return tx.CreateInBatches(rows, 100).Error
+}
+
+// Get 获取数据集
+func (r *DatasetRepository) Get(ctx context.Context, id uint64) (*model.Dataset, error) {
+	var dataset model.Dataset
+	if err := r.db.WithContext(ctx).First(&dataset, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &dataset, nil
+}
+
+// List 分页查询数据集
+func (r *DatasetRepository) List(ctx context.Context, offset, limit int) ([]*model.Dataset, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.Dataset{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	datasets := []*model.Dataset{}
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&datasets).Error; err != nil {
+		return nil, 0, err
+	}
+	return datasets, total, nil
+}
+
+// CaseFilter 用例查询条件, 零值字段不参与过滤
+type CaseFilter struct {
+	Split string
+	Tags  []string // 带有任一标签的用例
+}
+
+// ListCases 按顺序获取数据集指定版本的用例, version为0时获取工作副本
+func (r *DatasetRepository) ListCases(ctx context.Context, datasetID uint64, version int, filter CaseFilter) ([]*model.DatasetCase, error) {
+	query := r.db.WithContext(ctx).Where("dataset_id = ? AND version = ?", datasetID, version)
+	if filter.Split != "" {
+		query = query.Where("split = ?", filter.Split)
+	}
+	cases := []*model.DatasetCase{}
+	if err := query.Order("position ASC").Find(&cases).Error; err != nil {
+		return nil, err
+	}
+	if len(filter.Tags) == 0 {
+		return cases, nil
+	}
+
+	// 标签保存为JSON数组, 各数据库的JSON查询语法不同, 在内存中过滤
+	matched := []*model.DatasetCase{}
+	for _, c := range cases {
+		for _, tag := range filter.Tags {
+			if c.HasTag(tag) {
+				matched = append(matched, c)
+				break
+			}
+		}
+	}
+	return matched, nil
+}
+
+// GetCase 获取工作副本中的用例
+func (r *DatasetRepository) GetCase(ctx context.Context, datasetID, caseID uint64) (*model.DatasetCase, error) {
+	var row model.DatasetCase
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND dataset_id = ? AND version = 0", caseID, datasetID).
+		First(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &row, nil
+}
+
+// AppendCases 向工作副本末尾追加用例
+func (r *DatasetRepository) AppendCases(ctx context.Context, datasetID uint64, cases []model.TestCase) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&model.Dataset{}, datasetID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		var next int
+		err := tx.Model(&model.DatasetCase{}).
+			Where("dataset_id = ? AND version = 0", datasetID).
+			Select("COALESCE(MAX(position) + 1, 0)").
+			Scan(&next).Error
+		if err != nil {
+			return err
+		}
+		if err := createCases(tx, datasetID, next, cases); err != nil {
+			return err
+		}
+		return markModified(tx, datasetID)
+	})
+}
+
+// SaveCases 保存工作副本中已有的用例
+func (r *DatasetRepository) SaveCases(ctx context.Context, datasetID uint64, rows []*model.DatasetCase) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, row := range rows {
+			result := tx.Model(&model.DatasetCase{}).
+				Where("id = ? AND dataset_id = ? AND version = 0", row.ID, datasetID).
+				Updates(map[string]interface{}{
+					"case_key":   row.CaseKey,
+					"system":     row.System,
+					"prompt":     row.Prompt,
+					"variables":  row.Variables,
+					"expected":   row.Expected,
+					"assertions": row.Assertions,
+					"tags":       row.Tags,
+					"split":      row.Split,
+					"updated_at": time.Now(),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrNotFound
+			}
+		}
+		return markModified(tx, datasetID)
+	})
+}
+
+// DeleteCase 删除工作副本中的用例
+func (r *DatasetRepository) DeleteCase(ctx context.Context, datasetID, caseID uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND dataset_id = ? AND version = 0", caseID, datasetID).Delete(&model.DatasetCase{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return markModified(tx, datasetID)
+	})
+}
+
+// markModified 标记工作副本已修改并重新统计用例数
+func markModified(tx *gorm.DB, datasetID uint64) error {
+	var count int64
+	if err := tx.Model(&model.DatasetCase{}).Where("dataset_id = ? AND version = 0", datasetID).Count(&count).Error; err != nil {
+		return err
+	}
+	result := tx.Model(&model.Dataset{ID: datasetID}).Updates(map[string]interface{}{
+		"case_count": count,
+		"modified":   true,
+		"updated_at": time.Now(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Freeze 将工作副本冻结为新版本, 复制当前用例作为不可变快照, 并发冻结冲突时返回ErrDuplicate
+func (r *DatasetRepository) Freeze(ctx context.Context, datasetID uint64, note string) (*model.DatasetVersion, error) {
+	var version *model.DatasetVersion
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Dataset{ID: datasetID}).Updates(map[string]interface{}{
+			"version":    gorm.Expr("version + 1"),
+			"modified":   false,
+			"updated_at": time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		var dataset model.Dataset
+		if err := tx.First(&dataset, datasetID).Error; err != nil {
+			return err
+		}
+
+		rows := []*model.DatasetCase{}
+		if err := tx.Where("dataset_id = ? AND version = 0", datasetID).Order("position ASC").Find(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			row.ID = 0
+			row.Version = dataset.Version
+		}
+		if len(rows) > 0 {
+			if err := tx.CreateInBatches(rows, 100).Error; err != nil {
+				return err
+			}
+		}
+
+		version = &model.DatasetVersion{
+			DatasetID: datasetID,
+			Version:   dataset.Version,
+			Name:      dataset.Name,
+			Note:      note,
+			CaseCount: len(rows),
+		}
+		// (dataset_id, version)唯一索引保证并发冻结时版本号不重复
+		return tx.Create(version).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrDuplicate
+		}
+		return nil, err
+	}
+	return version, nil
+}
+
+// ListVersions 按版本号倒序列出数据集的全部版本
+func (r *DatasetRepository) ListVersions(ctx context.Context, datasetID uint64) ([]*model.DatasetVersion, error) {
+	versions := []*model.DatasetVersion{}
+	err := r.db.WithContext(ctx).
+		Where("dataset_id = ?", datasetID).
+		Order("version DESC").
+		Find(&versions).Error
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetVersion 获取数据集的指定版本
+func (r *DatasetRepository) GetVersion(ctx context.Context, datasetID uint64, version int) (*model.DatasetVersion, error) {
+	var v model.DatasetVersion
+	err := r.db.WithContext(ctx).
+		Where("dataset_id = ? AND version = ?", datasetID, version).
+		First(&v).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Delete 删除数据集及工作副本的用例, 冻结的版本保留
+func (r *DatasetRepository) Delete(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&model.Dataset{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return tx.Where("dataset_id = ? AND version = 0", id).Delete(&model.DatasetCase{}).Error
+	})
+}
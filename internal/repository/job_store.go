@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// JobStore 任务状态存储接口
+type JobStore interface {
+	// Create 创建任务
+	Create(ctx context.Context, job *model.Job) error
+
+	// Update 更新任务
+	Update(ctx context.Context, job *model.Job) error
+
+	// Get 获取任务
+	Get(ctx context.Context, id string) (*model.Job, error)
+
+	// List 按条件分页查询任务
+	List(ctx context.Context, filter JobFilter, offset, limit int) ([]*model.Job, int64, error)
+}
+
+// JobFilter 任务查询条件, 零值字段不参与过滤
+type JobFilter struct {
+	Status string
+	// 按数据集过滤, DatasetVersion仅在DatasetID不为0时生效
+	DatasetID      uint64
+	DatasetVersion int
+}
+
+// match 任务是否满足查询条件
+func (f JobFilter) match(job *model.Job) bool {
+	if f.Status != "" && job.Status != f.Status {
+		return false
+	}
+	if f.DatasetID != 0 {
+		if job.DatasetID != f.DatasetID {
+			return false
+		}
+		if f.DatasetVersion != 0 && job.DatasetVersion != f.DatasetVersion {
+			return false
+		}
+	}
+	return true
+}
+
+// MemoryJobStore 内存任务存储, 进程重启后数据丢失
+type MemoryJobStore struct {
+	mu   sync.RWMutex
+	jobs map[string]*model.Job
+}
+
+// NewMemoryJobStore 创建内存任务存储
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{
+		jobs: make(map[string]*model.Job),
+	}
+}
+
+// Create 创建任务
+func (s *MemoryJobStore) Create(ctx context.Context, job *model.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *job
+	s.jobs[job.ID] = &copied
+	return nil
+}
+
+// Update 更新任务
+func (s *MemoryJobStore) Update(ctx context.Context, job *model.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[job.ID]; !ok {
+		return ErrNotFound
+	}
+	copied := *job
+	s.jobs[job.ID] = &copied
+	return nil
+}
+
+// Get 获取任务
+func (s *MemoryJobStore) Get(ctx context.Context, id string) (*model.Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *job
+	return &copied, nil
+}
+
+// List 按条件分页查询任务
+func (s *MemoryJobStore) List(ctx context.Context, filter JobFilter, offset, limit int) ([]*model.Job, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jobs := make([]*model.Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		if !filter.match(job) {
+			continue
+		}
+		copied := *job
+		jobs = append(jobs, &copied)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+
+	total := int64(len(jobs))
+	if offset >= len(jobs) {
+		return []*model.Job{}, total, nil
+	}
+	jobs = jobs[offset:]
+	if limit > 0 && limit < len(jobs) {
+		jobs = jobs[:limit]
+	}
+	return jobs, total, nil
+}
+
+// GormJobStore 基于数据库的任务存储
+type GormJobStore struct {
+	db *gorm.DB
+}
+
+// NewGormJobStore 创建数据库任务存储
+func NewGormJobStore(db *gorm.DB) *GormJobStore {
+	return &GormJobStore{
+		db: db,
+	}
+}
+
+// Create 创建任务
+func (s *GormJobStore) Create(ctx context.Context, job *model.Job) error {
+	return s.db.WithContext(ctx).Create(job).Error
+}
+
+// Update 更新任务, 任务不存在时返回ErrNotFound而不是插入新记录
+func (s *GormJobStore) Update(ctx context.Context, job *model.Job) error {
+	db := s.db.WithContext(ctx)
+	result := db.Model(job).Select("*").Omit("id", "created_at").Updates(job)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+	// MySQL只统计实际变化的行, 内容未变时需再确认任务是否存在
+	var count int64
+	if err := db.Model(&model.Job{}).Where("id = ?", job.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Get 获取任务
+func (s *GormJobStore) Get(ctx context.Context, id string) (*model.Job, error) {
+	var job model.Job
+	if err := s.db.WithContext(ctx).First(&job, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// List 按条件分页查询任务
+func (s *GormJobStore) List(ctx context.Context, filter JobFilter, offset, limit int) ([]*model.Job, int64, error) {
+	query := s.db.WithContext(ctx).Model(&model.Job{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.DatasetID != 0 {
+		query = query.Where("dataset_id = ?", filter.DatasetID)
+		if filter.DatasetVersion != 0 {
+			query = query.Where("dataset_version = ?", filter.DatasetVersion)
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("created_at DESC").Offset(offset)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	jobs := []*model.Job{}
+	if err := query.Find(&jobs).Error; err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
@@ -0,0 +1,304 @@
+// Package migrations 内嵌的版本化数据库迁移及执行器
+//
+// 迁移文件按数据库类型存放在mysql/与sqlite/目录下, 命名为<版本号>_<名称>.up.sql
+// 与<版本号>_<名称>.down.sql, 已执行的版本记录在schema_migrations表中.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed mysql/*.sql sqlite/*.sql
+var files embed.FS
+
+// TableName 迁移记录表名
+const TableName = "schema_migrations"
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration 单个迁移
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status 迁移执行状态
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// appliedMigration 迁移记录表行
+type appliedMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName 指定表名
+func (appliedMigration) TableName() string {
+	return TableName
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db         *gorm.DB
+	dialect    string
+	migrations []Migration
+}
+
+// New 创建迁移执行器, dialect为mysql或sqlite
+func New(db *gorm.DB, dialect string) (*Migrator, error) {
+	migrations, err := Load(dialect)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:         db,
+		dialect:    dialect,
+		migrations: migrations,
+	}, nil
+}
+
+// Load 加载指定数据库类型的全部迁移, 按版本号升序排列
+func Load(dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported migration dialect: %s", dialect)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		matches := fileNamePattern.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+		version, _ := strconv.ParseInt(matches[1], 10, 64)
+		content, err := fs.ReadFile(files, path.Join(dialect, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = m
+		} else if m.Name != matches[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names: %s, %s", version, m.Name, matches[2])
+		}
+		if matches[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// ensureTable 创建迁移记录表
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	// SQLite驱动仅将声明为DATETIME的列解析为时间
+	timeType := "DATETIME(3)"
+	if m.dialect == "sqlite" {
+		timeType = "DATETIME"
+	}
+	return m.db.WithContext(ctx).Exec(`CREATE TABLE IF NOT EXISTS ` + TableName + ` (
+    version    BIGINT       NOT NULL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    applied_at ` + timeType + ` NOT NULL
+)`).Error
+}
+
+// applied 查询已执行的迁移, 只读; 迁移记录表不存在时视为尚未执行任何迁移
+func (m *Migrator) applied(ctx context.Context) (map[int64]appliedMigration, error) {
+	db := m.db.WithContext(ctx)
+	if !db.Migrator().HasTable(TableName) {
+		return map[int64]appliedMigration{}, nil
+	}
+
+	rows := []appliedMigration{}
+	if err := db.Order("version ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]appliedMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// Status 查询全部迁移的执行状态
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending 返回待执行的迁移, steps<=0时返回全部
+func (m *Migrator) Pending(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []Migration{}
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		pending = append(pending, migration)
+		if steps > 0 && len(pending) == steps {
+			break
+		}
+	}
+	return pending, nil
+}
+
+// Applied 返回可回滚的迁移(按版本号降序), steps<=0时返回全部
+func (m *Migrator) Applied(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rollback := []Migration{}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		rollback = append(rollback, migration)
+		if steps > 0 && len(rollback) == steps {
+			break
+		}
+	}
+	return rollback, nil
+}
+
+// Up 执行待执行的迁移, steps<=0时执行全部, 返回已执行的迁移
+// 每个迁移在独立事务中执行; MySQL的DDL会隐式提交, 失败时需根据错误信息人工修复
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create %s table: %w", TableName, err)
+	}
+	pending, err := m.Pending(ctx, steps)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for _, migration := range pending {
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, migration.Up); err != nil {
+				return err
+			}
+			return tx.Create(&appliedMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d_%s up failed: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down 回滚最近执行的迁移, steps<=0时回滚1个, 返回已回滚的迁移
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+	rollback, err := m.Applied(ctx, steps)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for _, migration := range rollback {
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, migration.Down); err != nil {
+				return err
+			}
+			return tx.Delete(&appliedMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d_%s down failed: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// execScript 逐条执行SQL脚本
+func execScript(tx *gorm.DB, script string) error {
+	for _, stmt := range SplitStatements(script) {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("%w\n%s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// SplitStatements 按行尾分号拆分SQL脚本, 忽略--注释行
+func SplitStatements(script string) []string {
+	statements := []string{}
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
@@ -0,0 +1,90 @@
+CREATE TABLE test_records (
+    id            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    prompts       JSON            NOT NULL,
+    models        JSON            NOT NULL,
+    results       JSON            NOT NULL,
+    model_names   VARCHAR(1000)   NOT NULL DEFAULT '',
+    providers     VARCHAR(500)    NOT NULL DEFAULT '',
+    model_count   BIGINT          NOT NULL DEFAULT 0,
+    success_count BIGINT          NOT NULL DEFAULT 0,
+    success       TINYINT(1)      NOT NULL DEFAULT 0,
+    duration      BIGINT          NOT NULL DEFAULT 0,
+    created_at    DATETIME(3)     NULL,
+    updated_at    DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    KEY idx_test_records_success (success),
+    KEY idx_test_records_created_at (created_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE prompt_templates (
+    id            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    name          VARCHAR(255)    NOT NULL,
+    system_prompt TEXT            NULL,
+    user_prompt   TEXT            NULL,
+    ai_prompt     TEXT            NULL,
+    description   VARCHAR(500)    NULL,
+    created_at    DATETIME(3)     NULL,
+    updated_at    DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    KEY idx_prompt_templates_name (name),
+    KEY idx_prompt_templates_created_at (created_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE model_configs (
+    id             BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    provider       VARCHAR(50)     NOT NULL,
+    model_name     VARCHAR(100)    NOT NULL,
+    api_key        VARCHAR(500)    NULL,
+    base_url       VARCHAR(500)    NULL,
+    default_config JSON            NULL,
+    enabled        TINYINT(1)      NOT NULL DEFAULT 1,
+    created_at     DATETIME(3)     NULL,
+    updated_at     DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uk_model_configs_provider_model (provider, model_name),
+    KEY idx_model_configs_enabled (enabled)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE jobs (
+    id          VARCHAR(64)  NOT NULL,
+    type        VARCHAR(32)  NOT NULL,
+    status      VARCHAR(32)  NOT NULL,
+    total       BIGINT       NOT NULL DEFAULT 0,
+    completed   BIGINT       NOT NULL DEFAULT 0,
+    request     JSON         NULL,
+    result      JSON         NULL,
+    error       TEXT         NULL,
+    created_at  DATETIME(3)  NULL,
+    updated_at  DATETIME(3)  NULL,
+    finished_at DATETIME(3)  NULL,
+    PRIMARY KEY (id),
+    KEY idx_jobs_type (type),
+    KEY idx_jobs_status (status),
+    KEY idx_jobs_created_at (created_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE datasets (
+    id          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    name        VARCHAR(255)    NOT NULL,
+    description VARCHAR(500)    NULL,
+    case_count  BIGINT          NOT NULL DEFAULT 0,
+    created_at  DATETIME(3)     NULL,
+    updated_at  DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    KEY idx_datasets_name (name)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE dataset_cases (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    dataset_id BIGINT UNSIGNED NOT NULL,
+    position   BIGINT          NOT NULL DEFAULT 0,
+    case_key   VARCHAR(100)    NULL,
+    `system`   TEXT            NULL,
+    prompt     TEXT            NOT NULL,
+    variables  JSON            NULL,
+    expected   TEXT            NULL,
+    created_at DATETIME(3)     NULL,
+    updated_at DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    KEY idx_dataset_cases_dataset_id (dataset_id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,25 @@
+ALTER TABLE prompt_templates ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+CREATE TABLE prompt_template_versions (
+    id            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    template_id   BIGINT UNSIGNED NOT NULL,
+    version       INT             NOT NULL,
+    name          VARCHAR(255)    NOT NULL,
+    system_prompt TEXT            NULL,
+    user_prompt   TEXT            NULL,
+    ai_prompt     TEXT            NULL,
+    description   VARCHAR(500)    NULL,
+    variables     JSON            NULL,
+    note          VARCHAR(255)    NULL,
+    created_at    DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uk_prompt_template_versions_version (template_id, version)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 为已有模板生成初始版本
+INSERT INTO prompt_template_versions (template_id, version, name, system_prompt, user_prompt, ai_prompt, description, variables, note, created_at)
+SELECT id, 1, name, system_prompt, user_prompt, ai_prompt, description, variables, 'initial version', updated_at FROM prompt_templates;
+
+ALTER TABLE test_records ADD COLUMN template_id BIGINT UNSIGNED NOT NULL DEFAULT 0;
+ALTER TABLE test_records ADD COLUMN template_version INT NOT NULL DEFAULT 0;
+CREATE INDEX idx_test_records_template ON test_records (template_id, template_version);
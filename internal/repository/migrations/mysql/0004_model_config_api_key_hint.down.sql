@@ -0,0 +1,2 @@
+-- api_key保持VARCHAR(1024), 加密后的值超过500字符, 缩短会截断或失败
+ALTER TABLE model_configs DROP COLUMN api_key_hint;
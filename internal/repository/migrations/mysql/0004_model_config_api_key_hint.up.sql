@@ -0,0 +1,2 @@
+ALTER TABLE model_configs MODIFY COLUMN api_key VARCHAR(1024) NULL;
+ALTER TABLE model_configs ADD COLUMN api_key_hint VARCHAR(32) NULL;
@@ -0,0 +1,28 @@
+CREATE TABLE comparisons (
+    id             BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `system`       TEXT            NULL,
+    prompt         TEXT            NOT NULL,
+    provider_a     VARCHAR(50)     NOT NULL,
+    model_a        VARCHAR(100)    NOT NULL,
+    output_a       TEXT            NULL,
+    provider_b     VARCHAR(50)     NOT NULL,
+    model_b        VARCHAR(100)    NOT NULL,
+    output_b       TEXT            NULL,
+    winner         VARCHAR(16)     NOT NULL,
+    source         VARCHAR(16)     NOT NULL,
+    judge_provider VARCHAR(50)     NULL,
+    judge_model    VARCHAR(100)    NULL,
+    consistent     TINYINT(1)      NULL,
+    reason         TEXT            NULL,
+    tags           JSON            NULL,
+    tag_names      VARCHAR(500)    NOT NULL DEFAULT '',
+    dataset_id     BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    case_key       VARCHAR(100)    NULL,
+    created_at     DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    KEY idx_comparisons_model_a (model_a),
+    KEY idx_comparisons_model_b (model_b),
+    KEY idx_comparisons_source (source),
+    KEY idx_comparisons_dataset_id (dataset_id),
+    KEY idx_comparisons_created_at (created_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
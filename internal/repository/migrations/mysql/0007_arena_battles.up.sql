@@ -0,0 +1,18 @@
+CREATE TABLE arena_battles (
+    id            VARCHAR(64)     NOT NULL,
+    `system`      TEXT            NULL,
+    prompt        TEXT            NOT NULL,
+    provider_a    VARCHAR(50)     NOT NULL,
+    model_a       VARCHAR(100)    NOT NULL,
+    output_a      TEXT            NULL,
+    provider_b    VARCHAR(50)     NOT NULL,
+    model_b       VARCHAR(100)    NOT NULL,
+    output_b      TEXT            NULL,
+    winner        VARCHAR(16)     NOT NULL DEFAULT '',
+    reason        TEXT            NULL,
+    comparison_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    created_at    DATETIME(3)     NULL,
+    voted_at      DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    KEY idx_arena_battles_created_at (created_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
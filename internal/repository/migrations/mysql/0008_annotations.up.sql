@@ -0,0 +1,18 @@
+CREATE TABLE annotations (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    run_type   VARCHAR(16)     NOT NULL,
+    run_id     VARCHAR(64)     NOT NULL,
+    case_index BIGINT          NOT NULL DEFAULT 0,
+    model_name VARCHAR(100)    NOT NULL,
+    annotator  VARCHAR(100)    NOT NULL,
+    rating     DOUBLE          NULL,
+    tags       JSON            NULL,
+    tag_names  VARCHAR(500)    NOT NULL DEFAULT '',
+    note       TEXT            NULL,
+    preferred  TINYINT(1)      NOT NULL DEFAULT 0,
+    created_at DATETIME(3)     NULL,
+    updated_at DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uk_annotations_target (run_type, run_id, case_index, model_name, annotator),
+    KEY idx_annotations_annotator (annotator)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
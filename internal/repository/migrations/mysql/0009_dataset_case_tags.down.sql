@@ -0,0 +1 @@
+ALTER TABLE dataset_cases DROP COLUMN tags;
@@ -0,0 +1 @@
+ALTER TABLE dataset_cases ADD COLUMN tags JSON NULL;
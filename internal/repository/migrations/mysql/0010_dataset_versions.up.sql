@@ -0,0 +1,25 @@
+ALTER TABLE datasets ADD COLUMN version INT NOT NULL DEFAULT 0;
+ALTER TABLE datasets ADD COLUMN modified TINYINT(1) NOT NULL DEFAULT 1;
+
+-- version为0的用例为可编辑的工作副本, 其余为冻结版本的快照
+ALTER TABLE dataset_cases ADD COLUMN version INT NOT NULL DEFAULT 0;
+ALTER TABLE dataset_cases ADD COLUMN split VARCHAR(16) NULL;
+CREATE INDEX idx_dataset_cases_version ON dataset_cases (dataset_id, version);
+
+CREATE TABLE dataset_versions (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    dataset_id BIGINT UNSIGNED NOT NULL,
+    version    INT             NOT NULL,
+    name       VARCHAR(255)    NOT NULL,
+    note       VARCHAR(255)    NULL,
+    case_count INT             NOT NULL DEFAULT 0,
+    created_at DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uk_dataset_versions_version (dataset_id, version)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE jobs ADD COLUMN dataset_id BIGINT UNSIGNED NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN dataset_version INT NOT NULL DEFAULT 0;
+CREATE INDEX idx_jobs_dataset ON jobs (dataset_id, dataset_version);
+-- 已有批量任务只记录数据集, 不记录版本
+UPDATE jobs SET dataset_id = COALESCE(JSON_EXTRACT(request, '$.dataset_id'), 0) WHERE type = 'batch';
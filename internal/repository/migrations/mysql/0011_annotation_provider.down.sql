@@ -0,0 +1,8 @@
+-- 同一标注者对不同提供者下同名模型的标注只保留最早的一条
+DELETE a FROM annotations a
+    JOIN annotations b ON a.run_type = b.run_type AND a.run_id = b.run_id AND a.case_index = b.case_index
+        AND a.model_name = b.model_name AND a.annotator = b.annotator AND a.id > b.id;
+ALTER TABLE annotations
+    DROP INDEX uk_annotations_target,
+    ADD UNIQUE KEY uk_annotations_target (run_type, run_id, case_index, model_name, annotator);
+ALTER TABLE annotations DROP COLUMN provider;
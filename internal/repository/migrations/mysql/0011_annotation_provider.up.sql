@@ -0,0 +1,5 @@
+-- 不同提供者下的同名模型分别标注, 已有标注的提供者为空
+ALTER TABLE annotations ADD COLUMN provider VARCHAR(50) NOT NULL DEFAULT '' AFTER case_index;
+ALTER TABLE annotations
+    DROP INDEX uk_annotations_target,
+    ADD UNIQUE KEY uk_annotations_target (run_type, run_id, case_index, provider, model_name, annotator);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS dataset_cases;
+DROP TABLE IF EXISTS datasets;
+DROP TABLE IF EXISTS jobs;
+DROP TABLE IF EXISTS model_configs;
+DROP TABLE IF EXISTS prompt_templates;
+DROP TABLE IF EXISTS test_records;
@@ -0,0 +1,84 @@
+CREATE TABLE test_records (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    prompts       JSON          NOT NULL,
+    models        JSON          NOT NULL,
+    results       JSON          NOT NULL,
+    model_names   VARCHAR(1000) NOT NULL DEFAULT '',
+    providers     VARCHAR(500)  NOT NULL DEFAULT '',
+    model_count   INTEGER       NOT NULL DEFAULT 0,
+    success_count INTEGER       NOT NULL DEFAULT 0,
+    success       BOOLEAN       NOT NULL DEFAULT 0,
+    duration      INTEGER       NOT NULL DEFAULT 0,
+    created_at    DATETIME      NULL,
+    updated_at    DATETIME      NULL
+);
+CREATE INDEX idx_test_records_success ON test_records (success);
+CREATE INDEX idx_test_records_created_at ON test_records (created_at);
+
+CREATE TABLE prompt_templates (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    name          VARCHAR(255) NOT NULL,
+    system_prompt TEXT         NULL,
+    user_prompt   TEXT         NULL,
+    ai_prompt     TEXT         NULL,
+    description   VARCHAR(500) NULL,
+    created_at    DATETIME     NULL,
+    updated_at    DATETIME     NULL
+);
+CREATE INDEX idx_prompt_templates_name ON prompt_templates (name);
+CREATE INDEX idx_prompt_templates_created_at ON prompt_templates (created_at);
+
+CREATE TABLE model_configs (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    provider       VARCHAR(50)  NOT NULL,
+    model_name     VARCHAR(100) NOT NULL,
+    api_key        VARCHAR(500) NULL,
+    base_url       VARCHAR(500) NULL,
+    default_config JSON         NULL,
+    enabled        BOOLEAN      NOT NULL DEFAULT 1,
+    created_at     DATETIME     NULL,
+    updated_at     DATETIME     NULL
+);
+CREATE UNIQUE INDEX uk_model_configs_provider_model ON model_configs (provider, model_name);
+CREATE INDEX idx_model_configs_enabled ON model_configs (enabled);
+
+CREATE TABLE jobs (
+    id          VARCHAR(64) NOT NULL PRIMARY KEY,
+    type        VARCHAR(32) NOT NULL,
+    status      VARCHAR(32) NOT NULL,
+    total       INTEGER     NOT NULL DEFAULT 0,
+    completed   INTEGER     NOT NULL DEFAULT 0,
+    request     JSON        NULL,
+    result      JSON        NULL,
+    error       TEXT        NULL,
+    created_at  DATETIME    NULL,
+    updated_at  DATETIME    NULL,
+    finished_at DATETIME    NULL
+);
+CREATE INDEX idx_jobs_type ON jobs (type);
+CREATE INDEX idx_jobs_status ON jobs (status);
+CREATE INDEX idx_jobs_created_at ON jobs (created_at);
+
+CREATE TABLE datasets (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    name        VARCHAR(255) NOT NULL,
+    description VARCHAR(500) NULL,
+    case_count  INTEGER      NOT NULL DEFAULT 0,
+    created_at  DATETIME     NULL,
+    updated_at  DATETIME     NULL
+);
+CREATE INDEX idx_datasets_name ON datasets (name);
+
+CREATE TABLE dataset_cases (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    dataset_id INTEGER      NOT NULL,
+    position   INTEGER      NOT NULL DEFAULT 0,
+    case_key   VARCHAR(100) NULL,
+    system     TEXT         NULL,
+    prompt     TEXT         NOT NULL,
+    variables  JSON         NULL,
+    expected   TEXT         NULL,
+    created_at DATETIME     NULL,
+    updated_at DATETIME     NULL
+);
+CREATE INDEX idx_dataset_cases_dataset_id ON dataset_cases (dataset_id);
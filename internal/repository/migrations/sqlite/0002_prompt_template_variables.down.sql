@@ -0,0 +1 @@
+ALTER TABLE prompt_templates DROP COLUMN variables;
@@ -0,0 +1 @@
+ALTER TABLE prompt_templates ADD COLUMN variables JSON NULL;
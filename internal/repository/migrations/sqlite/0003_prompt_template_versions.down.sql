@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_test_records_template;
+ALTER TABLE test_records DROP COLUMN template_version;
+ALTER TABLE test_records DROP COLUMN template_id;
+DROP TABLE IF EXISTS prompt_template_versions;
+ALTER TABLE prompt_templates DROP COLUMN version;
@@ -0,0 +1,24 @@
+ALTER TABLE prompt_templates ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE prompt_template_versions (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    template_id   INTEGER      NOT NULL,
+    version       INTEGER      NOT NULL,
+    name          VARCHAR(255) NOT NULL,
+    system_prompt TEXT         NULL,
+    user_prompt   TEXT         NULL,
+    ai_prompt     TEXT         NULL,
+    description   VARCHAR(500) NULL,
+    variables     JSON         NULL,
+    note          VARCHAR(255) NULL,
+    created_at    DATETIME     NULL
+);
+CREATE UNIQUE INDEX uk_prompt_template_versions_version ON prompt_template_versions (template_id, version);
+
+-- 为已有模板生成初始版本
+INSERT INTO prompt_template_versions (template_id, version, name, system_prompt, user_prompt, ai_prompt, description, variables, note, created_at)
+SELECT id, 1, name, system_prompt, user_prompt, ai_prompt, description, variables, 'initial version', updated_at FROM prompt_templates;
+
+ALTER TABLE test_records ADD COLUMN template_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE test_records ADD COLUMN template_version INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX idx_test_records_template ON test_records (template_id, template_version);
@@ -0,0 +1 @@
+ALTER TABLE model_configs DROP COLUMN api_key_hint;
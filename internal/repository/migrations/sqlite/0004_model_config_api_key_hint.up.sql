@@ -0,0 +1 @@
+ALTER TABLE model_configs ADD COLUMN api_key_hint VARCHAR(32) NULL;
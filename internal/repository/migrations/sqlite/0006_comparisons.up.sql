@@ -0,0 +1,27 @@
+CREATE TABLE comparisons (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    system         TEXT         NULL,
+    prompt         TEXT         NOT NULL,
+    provider_a     VARCHAR(50)  NOT NULL,
+    model_a        VARCHAR(100) NOT NULL,
+    output_a       TEXT         NULL,
+    provider_b     VARCHAR(50)  NOT NULL,
+    model_b        VARCHAR(100) NOT NULL,
+    output_b       TEXT         NULL,
+    winner         VARCHAR(16)  NOT NULL,
+    source         VARCHAR(16)  NOT NULL,
+    judge_provider VARCHAR(50)  NULL,
+    judge_model    VARCHAR(100) NULL,
+    consistent     BOOLEAN      NULL,
+    reason         TEXT         NULL,
+    tags           JSON         NULL,
+    tag_names      VARCHAR(500) NOT NULL DEFAULT '',
+    dataset_id     INTEGER      NOT NULL DEFAULT 0,
+    case_key       VARCHAR(100) NULL,
+    created_at     DATETIME     NULL
+);
+CREATE INDEX idx_comparisons_model_a ON comparisons (model_a);
+CREATE INDEX idx_comparisons_model_b ON comparisons (model_b);
+CREATE INDEX idx_comparisons_source ON comparisons (source);
+CREATE INDEX idx_comparisons_dataset_id ON comparisons (dataset_id);
+CREATE INDEX idx_comparisons_created_at ON comparisons (created_at);
@@ -0,0 +1,17 @@
+CREATE TABLE arena_battles (
+    id            VARCHAR(64)  NOT NULL PRIMARY KEY,
+    system        TEXT         NULL,
+    prompt        TEXT         NOT NULL,
+    provider_a    VARCHAR(50)  NOT NULL,
+    model_a       VARCHAR(100) NOT NULL,
+    output_a      TEXT         NULL,
+    provider_b    VARCHAR(50)  NOT NULL,
+    model_b       VARCHAR(100) NOT NULL,
+    output_b      TEXT         NULL,
+    winner        VARCHAR(16)  NOT NULL DEFAULT '',
+    reason        TEXT         NULL,
+    comparison_id INTEGER      NOT NULL DEFAULT 0,
+    created_at    DATETIME     NULL,
+    voted_at      DATETIME     NULL
+);
+CREATE INDEX idx_arena_battles_created_at ON arena_battles (created_at);
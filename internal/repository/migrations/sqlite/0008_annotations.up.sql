@@ -0,0 +1,17 @@
+CREATE TABLE annotations (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    run_type   VARCHAR(16)  NOT NULL,
+    run_id     VARCHAR(64)  NOT NULL,
+    case_index INTEGER      NOT NULL DEFAULT 0,
+    model_name VARCHAR(100) NOT NULL,
+    annotator  VARCHAR(100) NOT NULL,
+    rating     REAL         NULL,
+    tags       JSON         NULL,
+    tag_names  VARCHAR(500) NOT NULL DEFAULT '',
+    note       TEXT         NULL,
+    preferred  BOOLEAN      NOT NULL DEFAULT 0,
+    created_at DATETIME     NULL,
+    updated_at DATETIME     NULL
+);
+CREATE UNIQUE INDEX uk_annotations_target ON annotations (run_type, run_id, case_index, model_name, annotator);
+CREATE INDEX idx_annotations_annotator ON annotations (annotator);
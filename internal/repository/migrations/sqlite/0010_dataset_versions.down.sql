@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_jobs_dataset;
+ALTER TABLE jobs DROP COLUMN dataset_version;
+ALTER TABLE jobs DROP COLUMN dataset_id;
+DROP TABLE IF EXISTS dataset_versions;
+DELETE FROM dataset_cases WHERE version <> 0;
+DROP INDEX IF EXISTS idx_dataset_cases_version;
+ALTER TABLE dataset_cases DROP COLUMN split;
+ALTER TABLE dataset_cases DROP COLUMN version;
+ALTER TABLE datasets DROP COLUMN modified;
+ALTER TABLE datasets DROP COLUMN version;
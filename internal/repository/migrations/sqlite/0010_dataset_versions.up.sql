@@ -0,0 +1,24 @@
+ALTER TABLE datasets ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE datasets ADD COLUMN modified BOOLEAN NOT NULL DEFAULT 1;
+
+-- version为0的用例为可编辑的工作副本, 其余为冻结版本的快照
+ALTER TABLE dataset_cases ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE dataset_cases ADD COLUMN split VARCHAR(16) NULL;
+CREATE INDEX idx_dataset_cases_version ON dataset_cases (dataset_id, version);
+
+CREATE TABLE dataset_versions (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    dataset_id INTEGER      NOT NULL,
+    version    INTEGER      NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    note       VARCHAR(255) NULL,
+    case_count INTEGER      NOT NULL DEFAULT 0,
+    created_at DATETIME     NULL
+);
+CREATE UNIQUE INDEX uk_dataset_versions_version ON dataset_versions (dataset_id, version);
+
+ALTER TABLE jobs ADD COLUMN dataset_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN dataset_version INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX idx_jobs_dataset ON jobs (dataset_id, dataset_version);
+-- 已有批量任务只记录数据集, 不记录版本
+UPDATE jobs SET dataset_id = COALESCE(json_extract(request, '$.dataset_id'), 0) WHERE type = 'batch';
@@ -0,0 +1,9 @@
+-- 同一标注者对不同提供者下同名模型的标注只保留最早的一条
+DELETE FROM annotations WHERE EXISTS (
+    SELECT 1 FROM annotations b
+    WHERE b.run_type = annotations.run_type AND b.run_id = annotations.run_id AND b.case_index = annotations.case_index
+        AND b.model_name = annotations.model_name AND b.annotator = annotations.annotator AND b.id < annotations.id
+);
+DROP INDEX IF EXISTS uk_annotations_target;
+CREATE UNIQUE INDEX uk_annotations_target ON annotations (run_type, run_id, case_index, model_name, annotator);
+ALTER TABLE annotations DROP COLUMN provider;
@@ -0,0 +1,4 @@
+-- 不同提供者下的同名模型分别标注, 已有标注的提供者为空
+ALTER TABLE annotations ADD COLUMN provider VARCHAR(50) NOT NULL DEFAULT '';
+DROP INDEX IF EXISTS uk_annotations_target;
+CREATE UNIQUE INDEX uk_annotations_target ON annotations (run_type, run_id, case_index, provider, model_name, annotator);
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// ModelConfigRepository 模型配置仓储
+type ModelConfigRepository struct {
+	db *gorm.DB
+}
+
+// NewModelConfigRepository 创建模型配置仓储
+func NewModelConfigRepository(db *gorm.DB) *ModelConfigRepository {
+	return &ModelConfigRepository{
+		db: db,
+	}
+}
+
+// Create 创建模型配置, 同一提供者下模型名称重复时返回ErrDuplicate
+func (r *ModelConfigRepository) Create(ctx context.Context, cfg *model.ModelConfigEntity) error {
+	if err := r.db.WithContext(ctx).Create(cfg).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrDuplicate
+		}
+		return err
+	}
+	return nil
+}
+
+// Update 更新模型配置的全部可编辑字段
+func (r *ModelConfigRepository) Update(ctx context.Context, cfg *model.ModelConfigEntity) error {
+	result := r.db.WithContext(ctx).Model(&model.ModelConfigEntity{ID: cfg.ID}).Updates(map[string]interface{}{
+		"provider":       cfg.Provider,
+		"model_name":     cfg.ModelName,
+		"api_key":        cfg.ApiKey,
+		"api_key_hint":   cfg.ApiKeyHint,
+		"base_url":       cfg.BaseURL,
+		"default_config": cfg.DefaultConfig,
+		"enabled":        cfg.Enabled,
+		"updated_at":     time.Now(),
+	})
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return ErrDuplicate
+		}
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// UpdateAPIKey 更新加密保存的API Key, 用于主密钥轮换
+func (r *ModelConfigRepository) UpdateAPIKey(ctx context.Context, id uint64, apiKey, hint string) error {
+	result := r.db.WithContext(ctx).Model(&model.ModelConfigEntity{ID: id}).Updates(map[string]interface{}{
+		"api_key":      apiKey,
+		"api_key_hint": hint,
+		"updated_at":   time.Now(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetEnabled 启用或禁用模型配置
+func (r *ModelConfigRepository) SetEnabled(ctx context.Context, id uint64, enabled bool) error {
+	result := r.db.WithContext(ctx).Model(&model.ModelConfigEntity{ID: id}).Updates(map[string]interface{}{
+		"enabled":    enabled,
+		"updated_at": time.Now(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Get 获取模型配置
+func (r *ModelConfigRepository) Get(ctx context.Context, id uint64) (*model.ModelConfigEntity, error) {
+	var cfg model.ModelConfigEntity
+	if err := r.db.WithContext(ctx).First(&cfg, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// List 列出模型配置, provider不为空时按提供者过滤
+func (r *ModelConfigRepository) List(ctx context.Context, provider string) ([]*model.ModelConfigEntity, error) {
+	query := r.db.WithContext(ctx).Model(&model.ModelConfigEntity{})
+	if provider != "" {
+		query = query.Where("provider = ?", provider)
+	}
+
+	configs := []*model.ModelConfigEntity{}
+	if err := query.Order("provider ASC, model_name ASC").Find(&configs).Error; err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+// Delete 删除模型配置
+func (r *ModelConfigRepository) Delete(ctx context.Context, id uint64) error {
+	result := r.db.WithContext(ctx).Delete(&model.ModelConfigEntity{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
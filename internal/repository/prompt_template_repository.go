@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// PromptTemplateRepository 提示词模板仓储
+type PromptTemplateRepository struct {
+	db *gorm.DB
+}
+
+// NewPromptTemplateRepository 创建提示词模板仓储
+func NewPromptTemplateRepository(db *gorm.DB) *PromptTemplateRepository {
+	return &PromptTemplateRepository{
+		db: db,
+	}
+}
+
+// Create 创建模板并生成第一个版本
+func (r *PromptTemplateRepository) Create(ctx context.Context, tpl *model.PromptTemplate, note string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tpl.Version = 1
+		if err := tx.Create(tpl).Error; err != nil {
+			return err
+		}
+		return tx.Create(tpl.NewVersion(note)).Error
+	})
+}
+
+// Update 更新模板并生成新版本, tpl会被替换为更新后的内容
+func (r *PromptTemplateRepository) Update(ctx context.Context, tpl *model.PromptTemplate, note string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.PromptTemplate{ID: tpl.ID}).Updates(map[string]interface{}{
+			"name":          tpl.Name,
+			"system_prompt": tpl.SystemPrompt,
+			"user_prompt":   tpl.UserPrompt,
+			"ai_prompt":     tpl.AIPrompt,
+			"description":   tpl.Description,
+			"variables":     tpl.Variables,
+			"version":       gorm.Expr("version + 1"),
+			"updated_at":    time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		if err := tx.First(tpl, tpl.ID).Error; err != nil {
+			return err
+		}
+		// (template_id, version)唯一索引保证并发保存时版本号不重复
+		return tx.Create(tpl.NewVersion(note)).Error
+	})
+}
+
+// Get 获取模板
+func (r *PromptTemplateRepository) Get(ctx context.Context, id uint64) (*model.PromptTemplate, error) {
+	var tpl model.PromptTemplate
+	if err := r.db.WithContext(ctx).First(&tpl, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &tpl, nil
+}
+
+// List 分页查询模板, name不为空时按名称模糊匹配
+func (r *PromptTemplateRepository) List(ctx context.Context, name string, offset, limit int) ([]*model.PromptTemplate, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.PromptTemplate{})
+	if name != "" {
+		query = query.Where("name LIKE ? ESCAPE '!'", "%"+escapeLike(name)+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("id DESC").Offset(offset)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	templates := []*model.PromptTemplate{}
+	if err := query.Find(&templates).Error; err != nil {
+		return nil, 0, err
+	}
+	return templates, total, nil
+}
+
+// ListVersions 按版本号倒序列出模板的全部版本
+func (r *PromptTemplateRepository) ListVersions(ctx context.Context, templateID uint64) ([]*model.PromptTemplateVersion, error) {
+	versions := []*model.PromptTemplateVersion{}
+	err := r.db.WithContext(ctx).
+		Where("template_id = ?", templateID).
+		Order("version DESC").
+		Find(&versions).Error
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetVersion 获取模板的指定版本
+func (r *PromptTemplateRepository) GetVersion(ctx context.Context, templateID uint64, version int) (*model.PromptTemplateVersion, error) {
+	var v model.PromptTemplateVersion
+	err := r.db.WithContext(ctx).
+		Where("template_id = ? AND version = ?", templateID, version).
+		First(&v).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Delete 删除模板, 版本记录保留
+func (r *PromptTemplateRepository) Delete(ctx context.Context, id uint64) error {
+	result := r.db.WithContext(ctx).Delete(&model.PromptTemplate{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+)
+
+// testAnnotationRepository 人工标注仓储一致性测试
+func testAnnotationRepository(t *testing.T, repo *repository.AnnotationRepository) {
+	four, two := 4.0, 2.0
+	annotations := []*model.Annotation{
+		newAnnotation("1", 0, "gpt-4.1", "alice", &four, ",refused,"),
+		newAnnotation("1", 0, "gpt-4.1", "bob", &two, ""),
+		newAnnotation("1", 0, "glm-4", "alice", nil, ",hallucination,refused,"),
+	}
+	annotations[0].Tags = model.JSONArray{"refused"}
+	annotations[0].Note = "declined to answer"
+	for _, a := range annotations {
+		mustNoError(t, repo.Save(ctx(), a), "save annotation")
+	}
+	job := newAnnotation("job-1", 2, "gpt-4.1", "alice", nil, "")
+	job.RunType = model.RunTypeJob
+	job.Preferred = true
+	mustNoError(t, repo.Save(ctx(), job), "save job annotation")
+
+	got, err := repo.Get(ctx(), annotations[0].ID)
+	mustNoError(t, err, "get annotation")
+	if got.Rating == nil || *got.Rating != 4 || len(got.Tags) != 1 || got.Note != "declined to answer" {
+		t.Fatalf("unexpected annotation: %+v", got)
+	}
+
+	// 同一标注者再次保存时覆盖原标注, 保留ID
+	update := newAnnotation("1", 0, "gpt-4.1", "alice", nil, "")
+	update.Preferred = true
+	mustNoError(t, repo.Save(ctx(), update), "overwrite annotation")
+	if update.ID != annotations[0].ID {
+		t.Fatalf("expected overwrite to keep id %d, got %d", annotations[0].ID, update.ID)
+	}
+	got, err = repo.Get(ctx(), annotations[0].ID)
+	mustNoError(t, err, "get overwritten annotation")
+	if got.Rating != nil || len(got.Tags) != 0 || got.Note != "" || !got.Preferred || got.TagNames != "" {
+		t.Fatalf("expected annotation to be overwritten, got %+v", got)
+	}
+
+	cases := []struct {
+		name   string
+		filter repository.AnnotationFilter
+		want   []uint64
+	}{
+		{"all", repository.AnnotationFilter{}, []uint64{job.ID, annotations[2].ID, annotations[0].ID, annotations[1].ID}},
+		{"run", repository.AnnotationFilter{RunType: model.RunTypeRecord, RunID: "1"}, []uint64{annotations[2].ID, annotations[0].ID, annotations[1].ID}},
+		{"model", repository.AnnotationFilter{Model: "gpt-4.1"}, []uint64{job.ID, annotations[0].ID, annotations[1].ID}},
+		{"annotator", repository.AnnotationFilter{Annotator: "bob"}, []uint64{annotations[1].ID}},
+		{"tag", repository.AnnotationFilter{Tag: "refused"}, []uint64{annotations[2].ID}},
+		{"tag wildcard is literal", repository.AnnotationFilter{Tag: "%"}, []uint64{}},
+		{"page", repository.AnnotationFilter{Offset: 1, Limit: 2}, []uint64{annotations[2].ID, annotations[0].ID}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			list, total, err := repo.List(ctx(), tc.filter)
+			mustNoError(t, err, "list annotations")
+			if tc.filter.Limit == 0 && total != int64(len(tc.want)) {
+				t.Fatalf("expected total %d, got %d", len(tc.want), total)
+			}
+			if len(list) != len(tc.want) {
+				t.Fatalf("expected %d annotations, got %d", len(tc.want), len(list))
+			}
+			for i, a := range list {
+				if a.ID != tc.want[i] {
+					t.Fatalf("annotation %d: expected id %d, got %d", i, tc.want[i], a.ID)
+				}
+			}
+		})
+	}
+
+	// 不同提供者下的同名模型分别标注, 不覆盖彼此
+	other := newAnnotation("1", 0, "gpt-4.1", "alice", &two, "")
+	other.Provider = "azure"
+	mustNoError(t, repo.Save(ctx(), other), "save annotation for another provider")
+	if other.ID == annotations[0].ID {
+		t.Fatalf("annotation for another provider overwrote annotation %d", annotations[0].ID)
+	}
+	list, _, err := repo.List(ctx(), repository.AnnotationFilter{RunType: model.RunTypeRecord, RunID: "1", Model: "gpt-4.1", Annotator: "alice"})
+	mustNoError(t, err, "list annotations")
+	if len(list) != 2 || list[0].Provider != "azure" || list[1].Provider != "openai" || !list[1].Preferred {
+		t.Fatalf("expected one annotation per provider, got %+v", list)
+	}
+	list, _, err = repo.List(ctx(), repository.AnnotationFilter{Provider: "azure"})
+	mustNoError(t, err, "list annotations by provider")
+	if len(list) != 1 || list[0].ID != other.ID {
+		t.Fatalf("expected only the azure annotation, got %+v", list)
+	}
+
+	mustNoError(t, repo.Delete(ctx(), job.ID), "delete annotation")
+	if _, err := repo.Get(ctx(), job.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := repo.Delete(ctx(), job.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound when deleting twice, got %v", err)
+	}
+}
+
+// newAnnotation 构造测试历史记录上的标注
+func newAnnotation(runID string, caseIndex int, modelName, annotator string, rating *float64, tagNames string) *model.Annotation {
+	return &model.Annotation{
+		RunType:   model.RunTypeRecord,
+		RunID:     runID,
+		CaseIndex: caseIndex,
+		Provider:  "openai",
+		ModelName: modelName,
+		Annotator: annotator,
+		Rating:    rating,
+		TagNames:  tagNames,
+	}
+}
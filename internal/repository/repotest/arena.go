@@ -0,0 +1,60 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+)
+
+// testArenaRepository 盲测对战仓储一致性测试
+func testArenaRepository(t *testing.T, repo *repository.ArenaRepository) {
+	battle := &model.ArenaBattle{
+		ID:        "battle-1",
+		Prompt:    "hello",
+		ProviderA: "openai",
+		ModelA:    "gpt-4.1",
+		OutputA:   "hi",
+		ProviderB: "deepseek",
+		ModelB:    "deepseek-chat",
+		OutputB:   "hello",
+	}
+	mustNoError(t, repo.Create(ctx(), battle), "create battle")
+
+	got, err := repo.Get(ctx(), battle.ID)
+	mustNoError(t, err, "get battle")
+	if got.Voted() || got.VotedAt != nil || got.OutputB != "hello" {
+		t.Fatalf("unexpected battle before vote: %+v", got)
+	}
+
+	comparison := &model.Comparison{
+		Prompt:    battle.Prompt,
+		ProviderA: battle.ProviderA,
+		ModelA:    battle.ModelA,
+		ProviderB: battle.ProviderB,
+		ModelB:    battle.ModelB,
+		Winner:    model.WinnerBothBad,
+		Source:    model.ComparisonSourceArena,
+	}
+	voted, err := repo.Vote(ctx(), battle.ID, model.WinnerBothBad, "neither answered", comparison)
+	mustNoError(t, err, "vote")
+	if !voted.Voted() || voted.Winner != model.WinnerBothBad || voted.VotedAt == nil || voted.ComparisonID == 0 || voted.ComparisonID != comparison.ID {
+		t.Fatalf("unexpected battle after vote: %+v", voted)
+	}
+
+	again := *comparison
+	again.ID = 0
+	if _, err := repo.Vote(ctx(), battle.ID, model.WinnerA, "", &again); !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("expected ErrConflict when voting twice, got %v", err)
+	}
+	if again.ID != 0 {
+		t.Fatalf("comparison should not be saved for a rejected vote")
+	}
+	if _, err := repo.Vote(ctx(), "missing", model.WinnerA, "", &again); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for missing battle, got %v", err)
+	}
+	if _, err := repo.Get(ctx(), "missing"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
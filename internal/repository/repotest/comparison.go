@@ -0,0 +1,109 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+)
+
+// testComparisonRepository 成对对比仓储一致性测试
+func testComparisonRepository(t *testing.T, repo *repository.ComparisonRepository) {
+	now := time.Now().Truncate(time.Second)
+	consistent := true
+	comparisons := []*model.Comparison{
+		newComparison("gpt-4.1", "deepseek-chat", model.WinnerA, model.ComparisonSourceJudge, ",math,", 1, now.Add(-48*time.Hour)),
+		newComparison("deepseek-chat", "glm-4", model.WinnerTie, model.ComparisonSourceHuman, ",math,chat,", 0, now.Add(-time.Hour)),
+		newComparison("gpt-4.1", "glm-4", model.WinnerB, model.ComparisonSourceJudge, "", 1, now),
+	}
+	// 同名模型在另一个提供者下
+	comparisons[1].ProviderB = "q"
+	comparisons[0].Consistent = &consistent
+	comparisons[0].Tags = model.JSONArray{"math"}
+	mustNoError(t, repo.Create(ctx(), comparisons), "create comparisons")
+
+	got, err := repo.Get(ctx(), comparisons[0].ID)
+	mustNoError(t, err, "get comparison")
+	if got.Winner != model.WinnerA || got.Consistent == nil || !*got.Consistent || len(got.Tags) != 1 || got.OutputA != "output" {
+		t.Fatalf("unexpected comparison: %+v", got)
+	}
+	if got, err := repo.Get(ctx(), comparisons[1].ID); err != nil || got.Consistent != nil {
+		t.Fatalf("expected nil consistent for human vote, got %+v (%v)", got, err)
+	}
+
+	since := now.Add(-2 * time.Hour)
+	cases := []struct {
+		name   string
+		filter repository.ComparisonFilter
+		want   []uint64
+	}{
+		{"all", repository.ComparisonFilter{}, []uint64{comparisons[2].ID, comparisons[1].ID, comparisons[0].ID}},
+		{"tag", repository.ComparisonFilter{Tag: "math"}, []uint64{comparisons[1].ID, comparisons[0].ID}},
+		{"tag prefix does not match", repository.ComparisonFilter{Tag: "mat"}, []uint64{}},
+		{"tag wildcards are literal", repository.ComparisonFilter{Tag: "ma_h"}, []uint64{}},
+		{"dataset", repository.ComparisonFilter{DatasetID: 1}, []uint64{comparisons[2].ID, comparisons[0].ID}},
+		{"source", repository.ComparisonFilter{Source: model.ComparisonSourceHuman}, []uint64{comparisons[1].ID}},
+		{"model", repository.ComparisonFilter{Model: "glm-4"}, []uint64{comparisons[2].ID, comparisons[1].ID}},
+		{"provider", repository.ComparisonFilter{Provider: "q"}, []uint64{comparisons[1].ID}},
+		{"provider and model", repository.ComparisonFilter{Provider: "p", Model: "glm-4"}, []uint64{comparisons[2].ID}},
+		{"provider and model on the other side", repository.ComparisonFilter{Provider: "q", Model: "glm-4"}, []uint64{comparisons[1].ID}},
+		{"provider and model on different sides", repository.ComparisonFilter{Provider: "q", Model: "deepseek-chat"}, []uint64{}},
+		{"provider and model combined with source", repository.ComparisonFilter{Provider: "p", Model: "glm-4", Source: model.ComparisonSourceHuman}, []uint64{}},
+		{"time range", repository.ComparisonFilter{StartTime: &since}, []uint64{comparisons[2].ID, comparisons[1].ID}},
+		{"page", repository.ComparisonFilter{Offset: 1, Limit: 1}, []uint64{comparisons[1].ID}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			list, total, err := repo.List(ctx(), tc.filter)
+			mustNoError(t, err, "list comparisons")
+			if tc.filter.Limit == 0 && total != int64(len(tc.want)) {
+				t.Fatalf("expected total %d, got %d", len(tc.want), total)
+			}
+			if len(list) != len(tc.want) {
+				t.Fatalf("expected %d comparisons, got %d", len(tc.want), len(list))
+			}
+			for i, c := range list {
+				if c.ID != tc.want[i] {
+					t.Fatalf("comparison %d: expected id %d, got %d", i, tc.want[i], c.ID)
+				}
+			}
+		})
+	}
+
+	outcomes, err := repo.ListOutcomes(ctx(), repository.ComparisonFilter{Source: model.ComparisonSourceJudge})
+	mustNoError(t, err, "list outcomes")
+	if len(outcomes) != 2 || outcomes[0].ID != comparisons[0].ID || outcomes[1].Winner != model.WinnerB {
+		t.Fatalf("unexpected outcomes: %+v", outcomes)
+	}
+	if outcomes[0].Prompt != "" || outcomes[0].ModelA != "gpt-4.1" || outcomes[0].ProviderB != "p" {
+		t.Fatalf("outcomes should only load players and winner: %+v", outcomes[0])
+	}
+
+	mustNoError(t, repo.Delete(ctx(), comparisons[0].ID), "delete comparison")
+	if _, err := repo.Get(ctx(), comparisons[0].ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := repo.Delete(ctx(), comparisons[0].ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound when deleting twice, got %v", err)
+	}
+}
+
+// newComparison 构造成对对比
+func newComparison(modelA, modelB, winner, source, tagNames string, datasetID uint64, createdAt time.Time) *model.Comparison {
+	return &model.Comparison{
+		Prompt:    "hello",
+		ProviderA: "p",
+		ModelA:    modelA,
+		OutputA:   "output",
+		ProviderB: "p",
+		ModelB:    modelB,
+		OutputB:   "output",
+		Winner:    winner,
+		Source:    source,
+		TagNames:  tagNames,
+		DatasetID: datasetID,
+		CreatedAt: createdAt,
+	}
+}
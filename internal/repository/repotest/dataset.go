@@ -0,0 +1,170 @@
+package repotest
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+)
+
+// testDatasetRepository 数据集仓储一致性测试
+func testDatasetRepository(t *testing.T, repo *repository.DatasetRepository) {
+	ds := &model.Dataset{Name: "qa", Description: "question answering"}
+	cases := []model.TestCase{
+		{ID: "c1", Prompt: "What is {{x}}?", Variables: map[string]interface{}{"x": "Go"}, Expected: "A language"},
+		{ID: "c2", System: "Be brief", Prompt: "Say hi", Expected: "hi", Assertions: []model.Assertion{{Type: model.AssertContains, Value: "hi"}}, Tags: []string{"greeting", "easy"}, Split: model.SplitTest},
+	}
+	mustNoError(t, repo.Create(ctx(), ds, cases), "create dataset")
+	if ds.ID == 0 || ds.CaseCount != 2 || !ds.Modified || ds.Version != 0 {
+		t.Fatalf("unexpected dataset: %+v", ds)
+	}
+
+	got, err := repo.Get(ctx(), ds.ID)
+	mustNoError(t, err, "get dataset")
+	if got.Name != "qa" || got.CaseCount != 2 {
+		t.Fatalf("unexpected dataset: %+v", got)
+	}
+
+	rows, err := repo.ListCases(ctx(), ds.ID, 0, repository.CaseFilter{})
+	mustNoError(t, err, "list cases")
+	if len(rows) != 2 || rows[0].CaseKey != "c1" || rows[1].System != "Be brief" {
+		t.Fatalf("unexpected cases: %+v", rows)
+	}
+	if tc := rows[0].ToTestCase(); tc.Variables["x"] != "Go" || tc.Expected != "A language" {
+		t.Fatalf("variables not persisted: %+v", tc)
+	}
+	if tc := rows[1].ToTestCase(); len(tc.Assertions) != 1 || tc.Assertions[0].Value != "hi" {
+		t.Fatalf("assertions not persisted: %+v", tc)
+	}
+	if tc := rows[1].ToTestCase(); len(tc.Tags) != 2 || tc.Tags[0] != "greeting" || tc.Tags[1] != "easy" {
+		t.Fatalf("tags not persisted: %+v", tc)
+	}
+	if tc := rows[0].ToTestCase(); tc.Tags != nil {
+		t.Fatalf("expected no tags: %+v", tc)
+	}
+	if rows[1].Split != model.SplitTest {
+		t.Fatalf("split not persisted: %+v", rows[1])
+	}
+
+	for _, tc := range []struct {
+		name   string
+		filter repository.CaseFilter
+		want   []string
+	}{
+		{"split", repository.CaseFilter{Split: model.SplitTest}, []string{"c2"}},
+		{"tag", repository.CaseFilter{Tags: []string{"missing", "easy"}}, []string{"c2"}},
+		{"no match", repository.CaseFilter{Split: model.SplitTrain}, []string{}},
+	} {
+		got, err := repo.ListCases(ctx(), ds.ID, 0, tc.filter)
+		mustNoError(t, err, "list cases by "+tc.name)
+		if keys := caseKeys(got); !slices.Equal(keys, tc.want) {
+			t.Fatalf("filter %s: expected %v, got %v", tc.name, tc.want, keys)
+		}
+	}
+
+	// 冻结版本1后修改工作副本, 版本快照不受影响
+	v1, err := repo.Freeze(ctx(), ds.ID, "first")
+	mustNoError(t, err, "freeze dataset")
+	if v1.Version != 1 || v1.CaseCount != 2 || v1.Name != "qa" {
+		t.Fatalf("unexpected version: %+v", v1)
+	}
+	got, err = repo.Get(ctx(), ds.ID)
+	mustNoError(t, err, "get frozen dataset")
+	if got.Version != 1 || got.Modified {
+		t.Fatalf("dataset not marked frozen: %+v", got)
+	}
+
+	mustNoError(t, repo.AppendCases(ctx(), ds.ID, []model.TestCase{{ID: "c3", Prompt: "Count to {{n}}", Tags: []string{"math"}}}), "append cases")
+	edited := rows[0]
+	edited.Prompt = "What is {{x}} used for?"
+	edited.Split = model.SplitTrain
+	mustNoError(t, repo.SaveCases(ctx(), ds.ID, []*model.DatasetCase{edited}), "save cases")
+	mustNoError(t, repo.DeleteCase(ctx(), ds.ID, rows[1].ID), "delete case")
+	if err := repo.DeleteCase(ctx(), ds.ID, rows[1].ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound on second case delete, got %v", err)
+	}
+	if err := repo.AppendCases(ctx(), ds.ID+1000, []model.TestCase{{Prompt: "x"}}); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound appending to missing dataset, got %v", err)
+	}
+
+	got, err = repo.Get(ctx(), ds.ID)
+	mustNoError(t, err, "get edited dataset")
+	if got.CaseCount != 2 || !got.Modified || got.Version != 1 {
+		t.Fatalf("unexpected edited dataset: %+v", got)
+	}
+	working, err := repo.ListCases(ctx(), ds.ID, 0, repository.CaseFilter{})
+	mustNoError(t, err, "list working cases")
+	if keys := caseKeys(working); !slices.Equal(keys, []string{"c1", "c3"}) || working[0].Prompt != "What is {{x}} used for?" || working[1].Position != 2 {
+		t.Fatalf("unexpected working cases: %+v", working)
+	}
+	one, err := repo.GetCase(ctx(), ds.ID, working[1].ID)
+	mustNoError(t, err, "get case")
+	if one.CaseKey != "c3" {
+		t.Fatalf("unexpected case: %+v", one)
+	}
+
+	snapshot, err := repo.ListCases(ctx(), ds.ID, 1, repository.CaseFilter{})
+	mustNoError(t, err, "list version cases")
+	if keys := caseKeys(snapshot); !slices.Equal(keys, []string{"c1", "c2"}) || snapshot[0].Prompt != "What is {{x}}?" || snapshot[0].Split != "" {
+		t.Fatalf("version snapshot changed: %+v", snapshot)
+	}
+	if _, err := repo.GetCase(ctx(), ds.ID, snapshot[0].ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected snapshot cases to be read-only, got %v", err)
+	}
+
+	v2, err := repo.Freeze(ctx(), ds.ID, "")
+	mustNoError(t, err, "freeze second version")
+	if v2.Version != 2 || v2.CaseCount != 2 {
+		t.Fatalf("unexpected second version: %+v", v2)
+	}
+	versions, err := repo.ListVersions(ctx(), ds.ID)
+	mustNoError(t, err, "list versions")
+	if len(versions) != 2 || versions[0].Version != 2 || versions[1].Note != "first" {
+		t.Fatalf("unexpected versions: %+v", versions)
+	}
+	if _, err := repo.GetVersion(ctx(), ds.ID, 3); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for missing version, got %v", err)
+	}
+	if _, err := repo.Freeze(ctx(), ds.ID+1000, ""); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound freezing missing dataset, got %v", err)
+	}
+
+	list, total, err := repo.List(ctx(), 0, 10)
+	mustNoError(t, err, "list datasets")
+	if total != 1 || len(list) != 1 {
+		t.Fatalf("unexpected datasets: total=%d", total)
+	}
+
+	mustNoError(t, repo.Delete(ctx(), ds.ID), "delete dataset")
+	if _, err := repo.Get(ctx(), ds.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := repo.Delete(ctx(), ds.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound on second delete, got %v", err)
+	}
+	rows, err = repo.ListCases(ctx(), ds.ID, 0, repository.CaseFilter{})
+	mustNoError(t, err, "list cases after delete")
+	if len(rows) != 0 {
+		t.Fatalf("cases not deleted: %d", len(rows))
+	}
+	// 冻结的版本在数据集删除后保留
+	if _, err := repo.GetVersion(ctx(), ds.ID, 1); err != nil {
+		t.Fatalf("version deleted with dataset: %v", err)
+	}
+	snapshot, err = repo.ListCases(ctx(), ds.ID, 1, repository.CaseFilter{})
+	mustNoError(t, err, "list version cases after delete")
+	if len(snapshot) != 2 {
+		t.Fatalf("version cases deleted with dataset: %d", len(snapshot))
+	}
+}
+
+// caseKeys 提取用例标识, 便于输出
+func caseKeys(rows []*model.DatasetCase) []string {
+	keys := make([]string, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, row.CaseKey)
+	}
+	return keys
+}
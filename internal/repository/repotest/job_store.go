@@ -0,0 +1,105 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+)
+
+// RunJobStore 对任意JobStore实现运行一致性测试, store需为空
+func RunJobStore(t *testing.T, store repository.JobStore) {
+	now := time.Now().Truncate(time.Second)
+	jobs := []*model.Job{
+		{ID: "job-1", Type: model.JobTypeTest, Status: model.JobStatusRunning, Total: 2, CreatedAt: now.Add(-2 * time.Minute), UpdatedAt: now},
+		{ID: "job-2", Type: model.JobTypeBatch, Status: model.JobStatusPending, Total: 4, DatasetID: 5, DatasetVersion: 2, CreatedAt: now.Add(-time.Minute), UpdatedAt: now},
+		{ID: "job-3", Type: model.JobTypeTest, Status: model.JobStatusRunning, Total: 1, CreatedAt: now, UpdatedAt: now},
+	}
+	for _, job := range jobs {
+		mustNoError(t, store.Create(ctx(), job), "create job")
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		got, err := store.Get(ctx(), "job-2")
+		mustNoError(t, err, "get job")
+		if got.Type != model.JobTypeBatch || got.Total != 4 {
+			t.Fatalf("unexpected job: %+v", got)
+		}
+		if _, err := store.Get(ctx(), "missing"); !errors.Is(err, repository.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		got, err := store.Get(ctx(), "job-1")
+		mustNoError(t, err, "get job")
+
+		finished := now.Add(time.Minute)
+		got.Status = model.JobStatusSucceeded
+		got.Completed = 2
+		got.Result = model.JSONField{"ok": true}
+		got.FinishedAt = &finished
+		mustNoError(t, store.Update(ctx(), got), "update job")
+
+		got, err = store.Get(ctx(), "job-1")
+		mustNoError(t, err, "get job")
+		if got.Status != model.JobStatusSucceeded || got.Completed != 2 || got.Result["ok"] != true || got.FinishedAt == nil {
+			t.Fatalf("update not persisted: %+v", got)
+		}
+
+		missing := &model.Job{ID: "missing", Type: model.JobTypeTest, Status: model.JobStatusPending}
+		if err := store.Update(ctx(), missing); !errors.Is(err, repository.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound updating a missing job, got %v", err)
+		}
+		if _, err := store.Get(ctx(), "missing"); !errors.Is(err, repository.ErrNotFound) {
+			t.Fatalf("update must not insert a missing job, got %v", err)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		all, total, err := store.List(ctx(), repository.JobFilter{}, 0, 0)
+		mustNoError(t, err, "list jobs")
+		if total != 3 || len(all) != 3 || all[0].ID != "job-3" {
+			t.Fatalf("expected 3 jobs newest first, got total=%d jobs=%v", total, jobIDs(all))
+		}
+
+		running, total, err := store.List(ctx(), repository.JobFilter{Status: model.JobStatusRunning}, 0, 10)
+		mustNoError(t, err, "list running jobs")
+		if total != 1 || len(running) != 1 || running[0].ID != "job-3" {
+			t.Fatalf("unexpected running jobs: total=%d jobs=%v", total, jobIDs(running))
+		}
+
+		page, total, err := store.List(ctx(), repository.JobFilter{}, 1, 1)
+		mustNoError(t, err, "list page")
+		if total != 3 || len(page) != 1 || page[0].ID != "job-2" {
+			t.Fatalf("unexpected page: total=%d jobs=%v", total, jobIDs(page))
+		}
+
+		for _, tc := range []struct {
+			filter repository.JobFilter
+			want   int64
+		}{
+			{repository.JobFilter{DatasetID: 5}, 1},
+			{repository.JobFilter{DatasetID: 5, DatasetVersion: 2}, 1},
+			{repository.JobFilter{DatasetID: 5, DatasetVersion: 1}, 0},
+			{repository.JobFilter{DatasetID: 6}, 0},
+		} {
+			byDataset, total, err := store.List(ctx(), tc.filter, 0, 0)
+			mustNoError(t, err, "list jobs by dataset")
+			if total != tc.want || int64(len(byDataset)) != tc.want {
+				t.Fatalf("filter %+v: expected %d jobs, got total=%d jobs=%v", tc.filter, tc.want, total, jobIDs(byDataset))
+			}
+		}
+	})
+}
+
+// jobIDs 提取任务ID, 便于输出
+func jobIDs(jobs []*model.Job) []string {
+	ids := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		ids = append(ids, job.ID)
+	}
+	return ids
+}
@@ -0,0 +1,12 @@
+package repotest_test
+
+import (
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/repository/repotest"
+)
+
+func TestMemoryJobStore(t *testing.T) {
+	repotest.RunJobStore(t, repository.NewMemoryJobStore())
+}
@@ -0,0 +1,65 @@
+package repotest
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/repository/migrations"
+	"gorm.io/gorm"
+)
+
+// RunMigrations 在已迁移的数据库上回滚全部迁移再重新执行, 保证每个down脚本可执行且能清除对应的up
+func RunMigrations(t *testing.T, db *gorm.DB, dialect string) {
+	migrator, err := migrations.New(db, dialect)
+	if err != nil {
+		t.Fatalf("create migrator: %v", err)
+	}
+	all, err := migrations.Load(dialect)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	tables, err := db.Migrator().GetTables()
+	mustNoError(t, err, "list tables")
+
+	done, err := migrator.Down(ctx(), len(all))
+	mustNoError(t, err, "roll back all migrations")
+	if len(done) != len(all) {
+		t.Fatalf("rolled back %d of %d migrations", len(done), len(all))
+	}
+	assertApplied(t, migrator, 0)
+	remaining, err := db.Migrator().GetTables()
+	mustNoError(t, err, "list tables")
+	for _, table := range remaining {
+		// sqlite_sequence等为SQLite内部表, 不由迁移管理
+		if table == migrations.TableName || strings.HasPrefix(table, "sqlite_") {
+			continue
+		}
+		if slices.Contains(tables, table) {
+			t.Fatalf("table %s still exists after rolling back all migrations", table)
+		}
+	}
+
+	done, err = migrator.Up(ctx(), 0)
+	mustNoError(t, err, "re-apply all migrations")
+	if len(done) != len(all) {
+		t.Fatalf("re-applied %d of %d migrations", len(done), len(all))
+	}
+	assertApplied(t, migrator, len(all))
+}
+
+// assertApplied 校验已执行的迁移数
+func assertApplied(t *testing.T, migrator *migrations.Migrator, want int) {
+	t.Helper()
+	statuses, err := migrator.Status(ctx())
+	mustNoError(t, err, "migration status")
+	applied := 0
+	for _, s := range statuses {
+		if s.Applied {
+			applied++
+		}
+	}
+	if applied != want {
+		t.Fatalf("expected %d applied migrations, got %d", want, applied)
+	}
+}
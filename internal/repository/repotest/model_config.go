@@ -0,0 +1,71 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+)
+
+// testModelConfigRepository 模型配置仓储一致性测试
+func testModelConfigRepository(t *testing.T, repo *repository.ModelConfigRepository) {
+	cfg := &model.ModelConfigEntity{
+		Provider:      "openai",
+		ModelName:     "gpt-4.1",
+		ApiKey:        "enc:v1:test",
+		ApiKeyHint:    "sk-****test",
+		DefaultConfig: model.JSONField{"temperature": 0.2},
+		Enabled:       true,
+	}
+	mustNoError(t, repo.Create(ctx(), cfg), "create model config")
+	mustNoError(t, repo.Create(ctx(), &model.ModelConfigEntity{Provider: "deepseek", ModelName: "deepseek-chat", Enabled: true}), "create model config")
+
+	dup := &model.ModelConfigEntity{Provider: "openai", ModelName: "gpt-4.1"}
+	if err := repo.Create(ctx(), dup); !errors.Is(err, repository.ErrDuplicate) {
+		t.Fatalf("expected ErrDuplicate, got %v", err)
+	}
+
+	got, err := repo.Get(ctx(), cfg.ID)
+	mustNoError(t, err, "get model config")
+	if got.ApiKey != "enc:v1:test" || got.ApiKeyHint != "sk-****test" || got.DefaultConfig["temperature"] != 0.2 || !got.Enabled {
+		t.Fatalf("unexpected model config: %+v", got)
+	}
+
+	got.BaseURL = "https://example.com/v1"
+	got.DefaultConfig = model.JSONField{"temperature": 0.7}
+	mustNoError(t, repo.Update(ctx(), got), "update model config")
+	mustNoError(t, repo.SetEnabled(ctx(), cfg.ID, false), "disable model config")
+	mustNoError(t, repo.UpdateAPIKey(ctx(), cfg.ID, "enc:v1:rotated", "sk-****abcd"), "update api key")
+	got, err = repo.Get(ctx(), cfg.ID)
+	mustNoError(t, err, "get updated model config")
+	if got.BaseURL != "https://example.com/v1" || got.DefaultConfig["temperature"] != 0.7 || got.Enabled ||
+		got.ApiKey != "enc:v1:rotated" || got.ApiKeyHint != "sk-****abcd" {
+		t.Fatalf("update not persisted: %+v", got)
+	}
+
+	got.ModelName = "deepseek-chat"
+	got.Provider = "deepseek"
+	if err := repo.Update(ctx(), got); !errors.Is(err, repository.ErrDuplicate) {
+		t.Fatalf("expected ErrDuplicate on conflicting update, got %v", err)
+	}
+	if err := repo.SetEnabled(ctx(), cfg.ID+100, true); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	list, err := repo.List(ctx(), "")
+	mustNoError(t, err, "list model configs")
+	if len(list) != 2 || list[0].Provider != "deepseek" {
+		t.Fatalf("unexpected model configs: %+v", list)
+	}
+	list, err = repo.List(ctx(), "openai")
+	mustNoError(t, err, "list model configs by provider")
+	if len(list) != 1 || list[0].ID != cfg.ID {
+		t.Fatalf("unexpected model configs: %+v", list)
+	}
+
+	mustNoError(t, repo.Delete(ctx(), cfg.ID), "delete model config")
+	if _, err := repo.Get(ctx(), cfg.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
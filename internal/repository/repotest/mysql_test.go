@@ -0,0 +1,19 @@
+package repotest_test
+
+import (
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/repository/repotest"
+)
+
+// TestMySQL 需设置MAT_TEST_MYSQL_DSN指向可清空的测试库, 未设置时跳过
+func TestMySQL(t *testing.T) {
+	repotest.Run(t, repotest.OpenMySQL(t))
+}
+
+// TestMySQLMigrations 回滚并重新执行全部迁移后, 仓储行为仍然一致; 未设置MAT_TEST_MYSQL_DSN时跳过
+func TestMySQLMigrations(t *testing.T) {
+	db := repotest.OpenMySQL(t)
+	repotest.RunMigrations(t, db, "mysql")
+	repotest.Run(t, db)
+}
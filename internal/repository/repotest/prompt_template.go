@@ -0,0 +1,93 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+)
+
+// testPromptTemplateRepository 提示词模板仓储一致性测试
+func testPromptTemplateRepository(t *testing.T, repo *repository.PromptTemplateRepository) {
+	vars, err := model.NewJSONArray([]model.TemplateVariable{
+		{Name: "topic", Type: model.VariableTypeString, Required: true},
+	})
+	mustNoError(t, err, "encode variables")
+	tpl := &model.PromptTemplate{
+		Name:       "explain",
+		UserPrompt: "Explain {{ topic }}",
+		Variables:  vars,
+	}
+	mustNoError(t, repo.Create(ctx(), tpl, "first"), "create template")
+	if tpl.ID == 0 || tpl.Version != 1 {
+		t.Fatalf("unexpected template: %+v", tpl)
+	}
+	mustNoError(t, repo.Create(ctx(), &model.PromptTemplate{Name: "greeting", UserPrompt: "Hi"}, ""), "create template")
+
+	got, err := repo.Get(ctx(), tpl.ID)
+	mustNoError(t, err, "get template")
+	decls, err := got.VariableDecls()
+	mustNoError(t, err, "decode variables")
+	if got.UserPrompt != "Explain {{ topic }}" || len(decls) != 1 || decls[0].Name != "topic" || !decls[0].Required {
+		t.Fatalf("unexpected template: %+v %+v", got, decls)
+	}
+
+	got.Description = "updated"
+	got.Variables = nil
+	mustNoError(t, repo.Update(ctx(), got, "second"), "update template")
+	if got.Version != 2 {
+		t.Fatalf("expected version 2 after update, got %d", got.Version)
+	}
+	got, err = repo.Get(ctx(), tpl.ID)
+	mustNoError(t, err, "get updated template")
+	if got.Description != "updated" || len(got.Variables) != 0 || got.Version != 2 {
+		t.Fatalf("update not persisted: %+v", got)
+	}
+	if err := repo.Update(ctx(), &model.PromptTemplate{ID: tpl.ID + 100, Name: "none"}, ""); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound on missing update, got %v", err)
+	}
+
+	versions, err := repo.ListVersions(ctx(), tpl.ID)
+	mustNoError(t, err, "list versions")
+	if len(versions) != 2 || versions[0].Version != 2 || versions[0].Note != "second" || versions[1].Description != "" {
+		t.Fatalf("unexpected versions: %+v", versions)
+	}
+	first, err := repo.GetVersion(ctx(), tpl.ID, 1)
+	mustNoError(t, err, "get version")
+	if first.UserPrompt != "Explain {{ topic }}" || len(first.Variables) != 1 || first.Note != "first" {
+		t.Fatalf("version snapshot changed: %+v", first)
+	}
+	if _, err := repo.GetVersion(ctx(), tpl.ID, 3); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for missing version, got %v", err)
+	}
+
+	list, total, err := repo.List(ctx(), "expl", 0, 10)
+	mustNoError(t, err, "list templates")
+	if total != 1 || len(list) != 1 || list[0].ID != tpl.ID {
+		t.Fatalf("unexpected templates: total=%d", total)
+	}
+	_, total, err = repo.List(ctx(), "e_pl", 0, 10)
+	mustNoError(t, err, "list templates by wildcard")
+	if total != 0 {
+		t.Fatalf("expected wildcard in name to match literally, got %d templates", total)
+	}
+	_, total, err = repo.List(ctx(), "", 0, 1)
+	mustNoError(t, err, "list all templates")
+	if total != 2 {
+		t.Fatalf("expected 2 templates, got %d", total)
+	}
+
+	mustNoError(t, repo.Delete(ctx(), tpl.ID), "delete template")
+	if _, err := repo.Get(ctx(), tpl.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := repo.Delete(ctx(), tpl.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound on second delete, got %v", err)
+	}
+	versions, err = repo.ListVersions(ctx(), tpl.ID)
+	mustNoError(t, err, "list versions after delete")
+	if len(versions) != 2 {
+		t.Fatalf("versions should be kept after delete, got %d", len(versions))
+	}
+}
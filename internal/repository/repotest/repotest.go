@@ -0,0 +1,123 @@
+// Package repotest 提供仓储层的一致性测试套件, 保证各数据库后端行为一致
+//
+// 在测试中使用:
+//
+//	func TestSQLite(t *testing.T) {
+//		repotest.Run(t, repotest.OpenSQLite(t))
+//	}
+//
+//	func TestMySQL(t *testing.T) {
+//		repotest.Run(t, repotest.OpenMySQL(t)) // 未设置MAT_TEST_MYSQL_DSN时跳过
+//	}
+package repotest
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// MySQLDSNEnv MySQL测试库连接串环境变量
+const MySQLDSNEnv = "MAT_TEST_MYSQL_DSN"
+
+// OpenSQLite 打开已迁移的SQLite内存数据库
+func OpenSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	return open(t, &config.DatabaseConfig{Type: "sqlite", Path: ":memory:"}, "")
+}
+
+// OpenMySQL 打开已迁移的MySQL测试库, 未配置连接串时跳过测试
+func OpenMySQL(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv(MySQLDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", MySQLDSNEnv)
+	}
+	return open(t, &config.DatabaseConfig{Type: "mysql"}, dsn)
+}
+
+// open 打开数据库并迁移表结构, 测试结束时自动关闭
+func open(t *testing.T, cfg *config.DatabaseConfig, dsn string) *gorm.DB {
+	t.Helper()
+
+	db, err := repository.NewDBWithDSN(cfg, dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = repository.Close(db)
+	})
+
+	migrator, err := repository.NewMigrator(db, cfg)
+	if err != nil {
+		t.Fatalf("create migrator: %v", err)
+	}
+	if _, err := migrator.Up(context.Background(), 0); err != nil {
+		t.Fatalf("migrate database: %v", err)
+	}
+	return db
+}
+
+// Run 对给定数据库运行全部仓储一致性测试, 会清空相关表
+func Run(t *testing.T, db *gorm.DB) {
+	t.Run("JobStore", func(t *testing.T) {
+		reset(t, db, &model.Job{})
+		RunJobStore(t, repository.NewGormJobStore(db))
+	})
+	t.Run("DatasetRepository", func(t *testing.T) {
+		reset(t, db, &model.Dataset{}, &model.DatasetCase{}, &model.DatasetVersion{})
+		testDatasetRepository(t, repository.NewDatasetRepository(db))
+	})
+	t.Run("TestRecordRepository", func(t *testing.T) {
+		reset(t, db, &model.TestRecord{})
+		testTestRecordRepository(t, repository.NewTestRecordRepository(db))
+	})
+	t.Run("PromptTemplateRepository", func(t *testing.T) {
+		reset(t, db, &model.PromptTemplate{}, &model.PromptTemplateVersion{})
+		testPromptTemplateRepository(t, repository.NewPromptTemplateRepository(db))
+	})
+	t.Run("ModelConfigRepository", func(t *testing.T) {
+		reset(t, db, &model.ModelConfigEntity{})
+		testModelConfigRepository(t, repository.NewModelConfigRepository(db))
+	})
+	t.Run("ComparisonRepository", func(t *testing.T) {
+		reset(t, db, &model.Comparison{})
+		testComparisonRepository(t, repository.NewComparisonRepository(db))
+	})
+	t.Run("ArenaRepository", func(t *testing.T) {
+		reset(t, db, &model.ArenaBattle{}, &model.Comparison{})
+		testArenaRepository(t, repository.NewArenaRepository(db))
+	})
+	t.Run("AnnotationRepository", func(t *testing.T) {
+		reset(t, db, &model.Annotation{})
+		testAnnotationRepository(t, repository.NewAnnotationRepository(db))
+	})
+}
+
+// reset 清空指定表
+func reset(t *testing.T, db *gorm.DB, models ...interface{}) {
+	t.Helper()
+	for _, m := range models {
+		if err := db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(m).Error; err != nil {
+			t.Fatalf("reset table: %v", err)
+		}
+	}
+}
+
+// mustNoError 出错时终止测试
+func mustNoError(t *testing.T, err error, action string) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: %v", action, err)
+	}
+}
+
+// ctx 测试使用的上下文
+func ctx() context.Context {
+	return context.Background()
+}
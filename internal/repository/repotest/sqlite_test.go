@@ -0,0 +1,18 @@
+package repotest_test
+
+import (
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/repository/repotest"
+)
+
+func TestSQLite(t *testing.T) {
+	repotest.Run(t, repotest.OpenSQLite(t))
+}
+
+// TestSQLiteMigrations 回滚并重新执行全部迁移后, 仓储行为仍然一致
+func TestSQLiteMigrations(t *testing.T) {
+	db := repotest.OpenSQLite(t)
+	repotest.RunMigrations(t, db, "sqlite")
+	repotest.Run(t, db)
+}
@@ -0,0 +1,83 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+)
+
+// testTestRecordRepository 测试记录仓储一致性测试
+func testTestRecordRepository(t *testing.T, repo *repository.TestRecordRepository) {
+	now := time.Now().Truncate(time.Second)
+	records := []*model.TestRecord{
+		newRecord(",gpt-4.1,deepseek-chat,", ",openai,deepseek,", true, now.Add(-48*time.Hour)),
+		newRecord(",deepseek-chat,", ",deepseek,", false, now.Add(-time.Hour)),
+		newRecord(",gpt-4.1,", ",openai,", true, now),
+	}
+	records[1].TemplateID, records[1].TemplateVersion = 7, 1
+	records[2].TemplateID, records[2].TemplateVersion = 7, 2
+	for _, record := range records {
+		mustNoError(t, repo.Create(ctx(), record), "create record")
+	}
+
+	got, err := repo.Get(ctx(), records[0].ID)
+	mustNoError(t, err, "get record")
+	if got.Prompts["user"] != "hello" || len(got.Models) != 1 || !got.Success {
+		t.Fatalf("unexpected record: %+v", got)
+	}
+
+	success := true
+	since := now.Add(-2 * time.Hour)
+	cases := []struct {
+		name   string
+		filter repository.HistoryFilter
+		want   []uint64
+	}{
+		{"all", repository.HistoryFilter{}, []uint64{records[2].ID, records[1].ID, records[0].ID}},
+		{"model", repository.HistoryFilter{Model: "deepseek-chat"}, []uint64{records[1].ID, records[0].ID}},
+		{"model prefix does not match", repository.HistoryFilter{Model: "gpt-4"}, []uint64{}},
+		{"model wildcards are literal", repository.HistoryFilter{Model: "gpt-4_1"}, []uint64{}},
+		{"provider wildcard is literal", repository.HistoryFilter{Provider: "%"}, []uint64{}},
+		{"provider", repository.HistoryFilter{Provider: "openai"}, []uint64{records[2].ID, records[0].ID}},
+		{"success", repository.HistoryFilter{Success: &success}, []uint64{records[2].ID, records[0].ID}},
+		{"time range", repository.HistoryFilter{StartTime: &since}, []uint64{records[2].ID, records[1].ID}},
+		{"page", repository.HistoryFilter{Offset: 1, Limit: 1}, []uint64{records[1].ID}},
+		{"template", repository.HistoryFilter{TemplateID: 7}, []uint64{records[2].ID, records[1].ID}},
+		{"template version", repository.HistoryFilter{TemplateID: 7, TemplateVersion: 1}, []uint64{records[1].ID}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			list, _, err := repo.List(ctx(), tc.filter)
+			mustNoError(t, err, "list records")
+			if len(list) != len(tc.want) {
+				t.Fatalf("expected %d records, got %d", len(tc.want), len(list))
+			}
+			for i, record := range list {
+				if record.ID != tc.want[i] {
+					t.Fatalf("record %d: expected id %d, got %d", i, tc.want[i], record.ID)
+				}
+			}
+		})
+	}
+
+	mustNoError(t, repo.Delete(ctx(), records[0].ID), "delete record")
+	if _, err := repo.Get(ctx(), records[0].ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+// newRecord 构造测试记录
+func newRecord(modelNames, providers string, success bool, createdAt time.Time) *model.TestRecord {
+	return &model.TestRecord{
+		Prompts:    model.JSONField{"user": "hello"},
+		Models:     model.JSONArray{map[string]interface{}{"name": "m", "provider": "p"}},
+		Results:    model.JSONField{},
+		ModelNames: modelNames,
+		Providers:  providers,
+		Success:    success,
+		CreatedAt:  createdAt,
+	}
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"gorm.io/gorm"
+)
+
+// HistoryFilter 历史记录查询条件, 零值字段不参与过滤
+type HistoryFilter struct {
+	Model     string
+	Provider  string
+	StartTime *time.Time
+	EndTime   *time.Time
+	Success   *bool
+	// 按模板过滤, TemplateVersion仅在TemplateID不为0时生效
+	TemplateID      uint64
+	TemplateVersion int
+	Offset          int
+	Limit           int
+}
+
+// TestRecordRepository 测试记录仓储
+type TestRecordRepository struct {
+	db *gorm.DB
+}
+
+// NewTestRecordRepository 创建测试记录仓储
+func NewTestRecordRepository(db *gorm.DB) *TestRecordRepository {
+	return &TestRecordRepository{
+		db: db,
+	}
+}
+
+// Create 保存测试记录
+func (r *TestRecordRepository) Create(ctx context.Context, record *model.TestRecord) error {
+	return r.db.WithContext(ctx).Create(record).Error
+}
+
+// Get 获取测试记录
+func (r *TestRecordRepository) Get(ctx context.Context, id uint64) (*model.TestRecord, error) {
+	var record model.TestRecord
+	if err := r.db.WithContext(ctx).First(&record, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+// List 按条件分页查询测试记录
+func (r *TestRecordRepository) List(ctx context.Context, filter HistoryFilter) ([]*model.TestRecord, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.TestRecord{})
+	if filter.Model != "" {
+		query = query.Where("model_names LIKE ? ESCAPE '!'", "%,"+escapeLike(filter.Model)+",%")
+	}
+	if filter.Provider != "" {
+		query = query.Where("providers LIKE ? ESCAPE '!'", "%,"+escapeLike(filter.Provider)+",%")
+	}
+	if filter.StartTime != nil {
+		query = query.Where("created_at >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		query = query.Where("created_at < ?", *filter.EndTime)
+	}
+	if filter.Success != nil {
+		query = query.Where("success = ?", *filter.Success)
+	}
+	if filter.TemplateID != 0 {
+		query = query.Where("template_id = ?", filter.TemplateID)
+		if filter.TemplateVersion != 0 {
+			query = query.Where("template_version = ?", filter.TemplateVersion)
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("id DESC").Offset(filter.Offset)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	records := []*model.TestRecord{}
+	if err := query.Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+	return records, total, nil
+}
+
+// Delete 删除测试记录
+func (r *TestRecordRepository) Delete(ctx context.Context, id uint64) error {
+	result := r.db.WithContext(ctx).Delete(&model.TestRecord{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+// Package secret 提供敏感配置(如提供者API Key)的信封加密与脱敏
+//
+// 每个值使用随机生成的数据密钥(DEK)以AES-256-GCM加密, DEK再由主密钥(KEK)加密后与密文一起保存:
+//
+//	enc:v1:<主密钥指纹>:<base64(加密后的DEK)>:<base64(密文)>
+//
+// 轮换主密钥时只需用新主密钥重新加密DEK, 无需解密数据本身.
+package secret
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	prefix  = "enc:v1:"
+	keySize = 32
+)
+
+var (
+	// ErrNoKeyring 未配置主密钥
+	ErrNoKeyring = errors.New("master key is not configured")
+	// ErrUnknownKey 加密值使用的主密钥不在密钥环中
+	ErrUnknownKey = errors.New("value was encrypted with an unknown master key")
+)
+
+// Keyring 主密钥环, 第一个密钥用于加密, 其余仅用于解密轮换前的数据
+type Keyring struct {
+	primary string
+	keys    map[string][]byte
+}
+
+// NewKeyring 使用一个或多个32字节主密钥创建密钥环
+func NewKeyring(keys ...[]byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeyring
+	}
+	k := &Keyring{keys: make(map[string][]byte, len(keys))}
+	for i, key := range keys {
+		if len(key) != keySize {
+			return nil, fmt.Errorf("master key must be %d bytes, got %d", keySize, len(key))
+		}
+		id := Fingerprint(key)
+		if i == 0 {
+			k.primary = id
+		}
+		k.keys[id] = key
+	}
+	return k, nil
+}
+
+// Load 从环境变量与密钥文件加载主密钥, 均未配置时返回nil
+// 环境变量中的密钥优先作为当前密钥; 密钥文件每行一个base64密钥, #开头为注释
+func Load(envName, file string) (*Keyring, error) {
+	keys := [][]byte{}
+	if envName != "" {
+		if value := strings.TrimSpace(os.Getenv(envName)); value != "" {
+			key, err := decodeKey(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid master key in %s: %w", envName, err)
+			}
+			keys = append(keys, key)
+		}
+	}
+	if file != "" {
+		fileKeys, err := readKeyFile(file)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fileKeys...)
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	return NewKeyring(keys...)
+}
+
+// readKeyFile 读取密钥文件
+func readKeyFile(file string) ([][]byte, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open master key file: %w", err)
+	}
+	defer f.Close()
+
+	keys := [][]byte{}
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		value := strings.TrimSpace(scanner.Text())
+		if value == "" || strings.HasPrefix(value, "#") {
+			continue
+		}
+		key, err := decodeKey(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid master key at %s:%d: %w", file, line, err)
+		}
+		keys = append(keys, key)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read master key file: %w", err)
+	}
+	return keys, nil
+}
+
+// decodeKey 解码base64格式的主密钥
+func decodeKey(value string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("key must be base64 encoded")
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", keySize, len(key))
+	}
+	return key, nil
+}
+
+// Fingerprint 返回主密钥指纹, 用于标识加密值使用的主密钥
+func Fingerprint(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:4])
+}
+
+// PrimaryID 返回当前主密钥的指纹
+func (k *Keyring) PrimaryID() string {
+	return k.primary
+}
+
+// IsEncrypted 判断值是否为加密格式
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Encrypt 使用新的数据密钥加密明文, 并用当前主密钥加密数据密钥
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	dek := make([]byte, keySize)
+	if _, err := rand.Read(dek); err != nil {
+		return "", err
+	}
+	data, err := seal(dek, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := seal(k.keys[k.primary], dek)
+	if err != nil {
+		return "", err
+	}
+	return format(k.primary, wrapped, data), nil
+}
+
+// Decrypt 解密加密值, 未加密的值原样返回
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	id, wrapped, data, err := parse(value)
+	if err != nil {
+		return "", err
+	}
+	dek, err := k.unwrap(id, wrapped)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dek, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// Rewrap 使用当前主密钥重新加密数据密钥, 未加密的值会被加密
+// 返回的changed为false表示已使用当前主密钥, 无需更新
+func (k *Keyring) Rewrap(value string) (string, bool, error) {
+	if !IsEncrypted(value) {
+		encrypted, err := k.Encrypt(value)
+		return encrypted, err == nil, err
+	}
+	id, wrapped, data, err := parse(value)
+	if err != nil {
+		return "", false, err
+	}
+	if id == k.primary {
+		return value, false, nil
+	}
+	dek, err := k.unwrap(id, wrapped)
+	if err != nil {
+		return "", false, err
+	}
+	rewrapped, err := seal(k.keys[k.primary], dek)
+	if err != nil {
+		return "", false, err
+	}
+	return format(k.primary, rewrapped, data), true, nil
+}
+
+// unwrap 使用指定主密钥解密数据密钥
+func (k *Keyring) unwrap(id string, wrapped []byte) ([]byte, error) {
+	kek, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+	dek, err := open(kek, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return dek, nil
+}
+
+// format 拼接加密值
+func format(id string, wrapped, data []byte) string {
+	return prefix + id + ":" + base64.StdEncoding.EncodeToString(wrapped) + ":" + base64.StdEncoding.EncodeToString(data)
+}
+
+// parse 解析加密值
+func parse(value string) (id string, wrapped, data []byte, err error) {
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, errors.New("malformed encrypted value")
+	}
+	if wrapped, err = base64.StdEncoding.DecodeString(parts[1]); err != nil {
+		return "", nil, nil, errors.New("malformed encrypted value")
+	}
+	if data, err = base64.StdEncoding.DecodeString(parts[2]); err != nil {
+		return "", nil, nil, errors.New("malformed encrypted value")
+	}
+	return parts[0], wrapped, data, nil
+}
+
+// seal 使用AES-GCM加密, 随机nonce置于密文前
+func seal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// open 解密seal生成的密文
+func open(key, ciphertext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, data := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, data, nil)
+}
+
+// newGCM 创建AES-GCM
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,214 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testKey 生成由同一字节填充的测试主密钥
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, keySize)
+}
+
+// mustKeyring 创建密钥环, 出错时终止测试
+func mustKeyring(t *testing.T, keys ...[]byte) *Keyring {
+	t.Helper()
+	k, err := NewKeyring(keys...)
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	return k
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	k := mustKeyring(t, testKey(1))
+	tests := []struct {
+		name      string
+		plaintext string
+	}{
+		{"empty", ""},
+		{"api key", "sk-1234567890abcdef"},
+		{"unicode", "密钥-🔑"},
+		{"long", strings.Repeat("x", 4096)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encrypted, err := k.Encrypt(tt.plaintext)
+			if err != nil {
+				t.Fatalf("Encrypt: %v", err)
+			}
+			if !IsEncrypted(encrypted) || !strings.HasPrefix(encrypted, prefix+k.PrimaryID()+":") {
+				t.Fatalf("unexpected format: %q", encrypted)
+			}
+			if tt.plaintext != "" && strings.Contains(encrypted, tt.plaintext) {
+				t.Fatalf("ciphertext contains plaintext")
+			}
+			got, err := k.Decrypt(encrypted)
+			if err != nil {
+				t.Fatalf("Decrypt: %v", err)
+			}
+			if got != tt.plaintext {
+				t.Fatalf("Decrypt = %q, want %q", got, tt.plaintext)
+			}
+		})
+	}
+
+	t.Run("plaintext passthrough", func(t *testing.T) {
+		got, err := k.Decrypt("sk-plain")
+		if err != nil || got != "sk-plain" {
+			t.Fatalf("Decrypt = %q, %v", got, err)
+		}
+	})
+	t.Run("random data key", func(t *testing.T) {
+		a, _ := k.Encrypt("same")
+		b, _ := k.Encrypt("same")
+		if a == b {
+			t.Fatalf("encrypting the same value twice produced identical output")
+		}
+	})
+}
+
+func TestRewrap(t *testing.T) {
+	oldKey, newKey := testKey(1), testKey(2)
+	before := mustKeyring(t, oldKey)
+	after := mustKeyring(t, newKey, oldKey)
+	encrypted, err := before.Encrypt("sk-rotate")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		value       string
+		wantChanged bool
+	}{
+		{"old key", encrypted, true},
+		{"plaintext", "sk-rotate", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rewrapped, changed, err := after.Rewrap(tt.value)
+			if err != nil {
+				t.Fatalf("Rewrap: %v", err)
+			}
+			if changed != tt.wantChanged {
+				t.Fatalf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if !strings.HasPrefix(rewrapped, prefix+after.PrimaryID()+":") {
+				t.Fatalf("value not wrapped with the new primary key: %q", rewrapped)
+			}
+			// 轮换后只保留新主密钥也能解密
+			got, err := mustKeyring(t, newKey).Decrypt(rewrapped)
+			if err != nil || got != "sk-rotate" {
+				t.Fatalf("Decrypt = %q, %v", got, err)
+			}
+			again, changed, err := after.Rewrap(rewrapped)
+			if err != nil || changed || again != rewrapped {
+				t.Fatalf("second Rewrap = %q, %v, %v, want unchanged", again, changed, err)
+			}
+		})
+	}
+
+	t.Run("data unchanged", func(t *testing.T) {
+		rewrapped, _, err := after.Rewrap(encrypted)
+		if err != nil {
+			t.Fatalf("Rewrap: %v", err)
+		}
+		// 仅重新加密数据密钥, 数据密文保持不变
+		_, _, data, _ := parse(encrypted)
+		_, _, rewrappedData, _ := parse(rewrapped)
+		if !bytes.Equal(data, rewrappedData) {
+			t.Fatalf("rewrap re-encrypted the data")
+		}
+	})
+}
+
+func TestDecryptUnknownKey(t *testing.T) {
+	encrypted, err := mustKeyring(t, testKey(1)).Encrypt("sk-unknown")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	other := mustKeyring(t, testKey(2))
+	if _, err := other.Decrypt(encrypted); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Decrypt error = %v, want ErrUnknownKey", err)
+	}
+	if _, _, err := other.Rewrap(encrypted); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Rewrap error = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	k := mustKeyring(t, testKey(1))
+	encrypted, err := k.Encrypt("sk-tamper")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	id, wrapped, data, err := parse(encrypted)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	flip := func(b []byte, i int) []byte {
+		out := append([]byte{}, b...)
+		out[i] ^= 0x01
+		return out
+	}
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"data ciphertext", format(id, wrapped, flip(data, len(data)-1))},
+		{"data nonce", format(id, wrapped, flip(data, 0))},
+		{"wrapped data key", format(id, flip(wrapped, len(wrapped)-1), data)},
+		{"swapped data", format(id, wrapped, mustEncryptData(t, k))},
+		{"truncated data", format(id, wrapped, data[:4])},
+		{"missing part", prefix + id + ":" + base64.StdEncoding.EncodeToString(wrapped)},
+		{"invalid base64", prefix + id + ":!!!:" + base64.StdEncoding.EncodeToString(data)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := k.Decrypt(tt.value); err == nil {
+				t.Fatalf("Decrypt succeeded with %q", got)
+			}
+		})
+	}
+}
+
+// mustEncryptData 返回另一个值的数据密文, 其数据密钥与被篡改的值不同
+func mustEncryptData(t *testing.T, k *Keyring) []byte {
+	t.Helper()
+	encrypted, err := k.Encrypt("sk-other")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	_, _, data, err := parse(encrypted)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	return data
+}
+
+func TestNewKeyringInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		keys [][]byte
+		want error
+	}{
+		{"no keys", nil, ErrNoKeyring},
+		{"short key", [][]byte{make([]byte, 16)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyring(tt.keys...)
+			if err == nil {
+				t.Fatalf("NewKeyring succeeded")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
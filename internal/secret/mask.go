@@ -0,0 +1,19 @@
+package secret
+
+import "strings"
+
+// Mask 脱敏密钥, 保留前缀与末4位, 如sk-abcdef123456显示为sk-****3456
+func Mask(value string) string {
+	if value == "" {
+		return ""
+	}
+	if len(value) < 12 {
+		return "****"
+	}
+
+	head := ""
+	if i := strings.IndexByte(value, '-'); i > 0 && i <= 6 {
+		head = value[:i+1]
+	}
+	return head + "****" + value[len(value)-4:]
+}
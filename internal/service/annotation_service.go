@@ -0,0 +1,389 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/multi-agent-testing/backend/internal/agreement"
+	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidAnnotation 标注请求不合法
+var ErrInvalidAnnotation = errors.New("invalid annotation")
+
+const maxAnnotatorLength = 100
+
+// AnnotationService 人工标注服务, 标注对象为测试历史或异步任务中的单个模型响应
+type AnnotationService struct {
+	repo    *repository.AnnotationRepository
+	records *repository.TestRecordRepository
+	jobs    repository.JobStore
+
+	mu     sync.RWMutex
+	config config.AnnotationConfig
+}
+
+// NewAnnotationService 创建人工标注服务
+func NewAnnotationService(repo *repository.AnnotationRepository, records *repository.TestRecordRepository, jobs repository.JobStore, cfg config.AnnotationConfig) *AnnotationService {
+	return &AnnotationService{
+		repo:    repo,
+		records: records,
+		jobs:    jobs,
+		config:  cfg,
+	}
+}
+
+// ReloadConfig 应用热加载后的标注配置
+func (s *AnnotationService) ReloadConfig(cfg config.AnnotationConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = cfg
+}
+
+// currentConfig 获取当前标注配置
+func (s *AnnotationService) currentConfig() config.AnnotationConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
+
+// Save 保存标注, 被标注的响应需存在; 同一标注者对同一响应重复提交时覆盖原标注
+func (s *AnnotationService) Save(ctx context.Context, req *model.AnnotationRequest) (*model.Annotation, error) {
+	annotation, err := s.validate(req)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := s.loadRun(ctx, req.RunType, req.RunID)
+	if err != nil {
+		return nil, err
+	}
+	matched := []*model.AnnotatedResponse{}
+	for _, r := range responses {
+		if r.CaseIndex == req.CaseIndex && r.Response.ModelName == req.ModelName && (req.Provider == "" || r.Response.Provider == req.Provider) {
+			matched = append(matched, r)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return nil, fmt.Errorf("%w: run has no response from model %s in case %d", ErrInvalidAnnotation, req.ModelName, req.CaseIndex)
+	case 1:
+		annotation.Provider = matched[0].Response.Provider
+	default:
+		return nil, fmt.Errorf("%w: model %s in case %d is served by several providers, provider is required", ErrInvalidAnnotation, req.ModelName, req.CaseIndex)
+	}
+
+	if err := s.repo.Save(ctx, annotation); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Annotation saved",
+		zap.Uint64("annotation_id", annotation.ID),
+		zap.String("run_type", annotation.RunType),
+		zap.String("run_id", annotation.RunID),
+		zap.String("provider", annotation.Provider),
+		zap.String("model", annotation.ModelName),
+		zap.String("annotator", annotation.Annotator),
+	)
+	return annotation, nil
+}
+
+// validate 校验标注请求并转换为实体
+func (s *AnnotationService) validate(req *model.AnnotationRequest) (*model.Annotation, error) {
+	cfg := s.currentConfig()
+	annotator := strings.TrimSpace(req.Annotator)
+	switch {
+	case req.RunType != model.RunTypeRecord && req.RunType != model.RunTypeJob:
+		return nil, fmt.Errorf("%w: run_type must be %s or %s", ErrInvalidAnnotation, model.RunTypeRecord, model.RunTypeJob)
+	case req.RunID == "":
+		return nil, fmt.Errorf("%w: run_id is required", ErrInvalidAnnotation)
+	case req.CaseIndex < 0:
+		return nil, fmt.Errorf("%w: case_index must not be negative", ErrInvalidAnnotation)
+	case req.ModelName == "":
+		return nil, fmt.Errorf("%w: model_name is required", ErrInvalidAnnotation)
+	case annotator == "" || len(annotator) > maxAnnotatorLength:
+		return nil, fmt.Errorf("%w: annotator is required and must be at most %d characters", ErrInvalidAnnotation, maxAnnotatorLength)
+	}
+	if req.Rating != nil {
+		minRating, maxRating := cfg.RatingRange()
+		if *req.Rating < minRating || *req.Rating > maxRating {
+			return nil, fmt.Errorf("%w: rating must be between %g and %g", ErrInvalidAnnotation, minRating, maxRating)
+		}
+	}
+
+	tags, err := normalizeTags(req.Tags, ErrInvalidAnnotation)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Tags) > 0 {
+		for _, tag := range tags.values {
+			if !slices.Contains(cfg.Tags, tag.(string)) {
+				return nil, fmt.Errorf("%w: unknown tag %q, allowed tags: %s", ErrInvalidAnnotation, tag, strings.Join(cfg.Tags, ", "))
+			}
+		}
+	}
+	if req.Rating == nil && len(tags.values) == 0 && strings.TrimSpace(req.Note) == "" && !req.Preferred {
+		return nil, fmt.Errorf("%w: rating, tags, note or preferred is required", ErrInvalidAnnotation)
+	}
+
+	return &model.Annotation{
+		RunType:   req.RunType,
+		RunID:     req.RunID,
+		CaseIndex: req.CaseIndex,
+		ModelName: req.ModelName,
+		Annotator: annotator,
+		Rating:    req.Rating,
+		Tags:      tags.values,
+		TagNames:  tags.filter,
+		Note:      req.Note,
+		Preferred: req.Preferred,
+	}, nil
+}
+
+// loadRun 加载运行中的全部模型响应, 按用例序号、模型名称及提供者排序, 运行不存在时返回ErrNotFound
+func (s *AnnotationService) loadRun(ctx context.Context, runType, runID string) ([]*model.AnnotatedResponse, error) {
+	run, err := loadRunReport(ctx, s.records, s.jobs, model.RunRef{RunType: runType, RunID: runID}, ErrInvalidAnnotation)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []*model.AnnotatedResponse{}
+	for i, row := range run.Cases {
+		for _, resp := range row.Results {
+			responses = append(responses, &model.AnnotatedResponse{CaseIndex: i, CaseID: row.Case.ID, Response: resp})
+		}
+	}
+	sort.Slice(responses, func(i, j int) bool {
+		if responses[i].CaseIndex != responses[j].CaseIndex {
+			return responses[i].CaseIndex < responses[j].CaseIndex
+		}
+		if responses[i].Response.ModelName != responses[j].Response.ModelName {
+			return responses[i].Response.ModelName < responses[j].Response.ModelName
+		}
+		return responses[i].Response.Provider < responses[j].Response.Provider
+	})
+	return responses, nil
+}
+
+// List 按条件分页查询标注
+func (s *AnnotationService) List(ctx context.Context, filter repository.AnnotationFilter) ([]*model.Annotation, int64, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Get 获取标注
+func (s *AnnotationService) Get(ctx context.Context, id uint64) (*model.Annotation, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// Delete 删除标注
+func (s *AnnotationService) Delete(ctx context.Context, id uint64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// Agreement 计算符合条件的标注在标注者之间的一致性
+func (s *AnnotationService) Agreement(ctx context.Context, filter repository.AnnotationFilter) (*model.AnnotationAgreement, error) {
+	filter.Offset, filter.Limit = 0, 0
+	annotations, _, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return computeAgreement(annotations), nil
+}
+
+// Export 导出运行中的全部模型响应及其标注
+func (s *AnnotationService) Export(ctx context.Context, runType, runID string) (*model.AnnotationExport, error) {
+	responses, err := s.loadRun(ctx, runType, runID)
+	if err != nil {
+		return nil, err
+	}
+	annotations, _, err := s.repo.List(ctx, repository.AnnotationFilter{RunType: runType, RunID: runID})
+	if err != nil {
+		return nil, err
+	}
+
+	// 记录提供者之前保存的标注提供者为空, 当时运行中的模型名称不重复, 按名称归入对应响应
+	providers := make(map[string]string, len(responses))
+	for _, r := range responses {
+		providers[responseKey(r.CaseIndex, "", r.Response.ModelName)] = r.Response.Provider
+	}
+	byResponse := make(map[string][]*model.Annotation, len(responses))
+	for _, a := range annotations {
+		provider := a.Provider
+		if provider == "" {
+			provider = providers[responseKey(a.CaseIndex, "", a.ModelName)]
+		}
+		key := responseKey(a.CaseIndex, provider, a.ModelName)
+		byResponse[key] = append(byResponse[key], a)
+	}
+	for _, r := range responses {
+		r.Annotations = byResponse[responseKey(r.CaseIndex, r.Response.Provider, r.Response.ModelName)]
+		if r.Annotations == nil {
+			r.Annotations = []*model.Annotation{}
+		}
+		var sum float64
+		rated := 0
+		for _, a := range r.Annotations {
+			if a.Rating != nil {
+				sum += *a.Rating
+				rated++
+			}
+			if a.Preferred {
+				r.Preferred++
+			}
+		}
+		if rated > 0 {
+			mean := sum / float64(rated)
+			r.MeanRating = &mean
+		}
+	}
+
+	return &model.AnnotationExport{
+		RunType:   runType,
+		RunID:     runID,
+		Responses: responses,
+		Agreement: computeAgreement(annotations),
+	}, nil
+}
+
+// responseKey 运行内模型响应的标识, 同名模型在不同提供者下是不同的响应
+func responseKey(caseIndex int, provider, modelName string) string {
+	return strconv.Itoa(caseIndex) + "/" + provider + "/" + modelName
+}
+
+// computeAgreement 以模型响应为单元计算评分、偏好及各标签的一致性
+func computeAgreement(annotations []*model.Annotation) *model.AnnotationAgreement {
+	type unit struct {
+		ratings   []float64
+		preferred []float64
+		tags      []map[string]bool // 各标注者使用的标签
+	}
+	units := map[string]*unit{}
+	keys := []string{}
+	annotators := map[string]bool{}
+	tagNames := map[string]bool{}
+	for _, a := range annotations {
+		key := a.RunType + "/" + a.RunID + "/" + responseKey(a.CaseIndex, a.Provider, a.ModelName)
+		u, ok := units[key]
+		if !ok {
+			u = &unit{}
+			units[key] = u
+			keys = append(keys, key)
+		}
+		annotators[a.Annotator] = true
+
+		if a.Rating != nil {
+			u.ratings = append(u.ratings, *a.Rating)
+		}
+		preferred := 0.0
+		if a.Preferred {
+			preferred = 1
+		}
+		u.preferred = append(u.preferred, preferred)
+		tags := make(map[string]bool, len(a.Tags))
+		for _, tag := range a.Tags {
+			if name, ok := tag.(string); ok {
+				tags[name] = true
+				tagNames[name] = true
+			}
+		}
+		u.tags = append(u.tags, tags)
+	}
+
+	ratings := make([][]float64, 0, len(keys))
+	preferred := make([][]float64, 0, len(keys))
+	for _, key := range keys {
+		ratings = append(ratings, units[key].ratings)
+		preferred = append(preferred, units[key].preferred)
+	}
+	result := &model.AnnotationAgreement{
+		Annotators: len(annotators),
+		Responses:  len(keys),
+		Rating:     agreementStat(agreement.Compute(ratings, agreement.Interval)),
+		Preferred:  agreementStat(agreement.Compute(preferred, agreement.Nominal)),
+		Tags:       make(map[string]*model.AgreementStat, len(tagNames)),
+	}
+	for name := range tagNames {
+		presence := make([][]float64, 0, len(keys))
+		for _, key := range keys {
+			values := make([]float64, len(units[key].tags))
+			for i, tags := range units[key].tags {
+				if tags[name] {
+					values[i] = 1
+				}
+			}
+			presence = append(presence, values)
+		}
+		stat := agreementStat(agreement.Compute(presence, agreement.Nominal))
+		result.Tags[name] = &stat
+	}
+	return result
+}
+
+// agreementStat 转换为对外的一致性统计
+func agreementStat(r agreement.Result) model.AgreementStat {
+	return model.AgreementStat{
+		Alpha:            r.Alpha,
+		PercentAgreement: r.PercentAgreement,
+		Responses:        r.Units,
+		Pairs:            r.Pairs,
+	}
+}
+
+// annotationCSVHeader 导出CSV的列, 每条标注一行, 未标注的响应单独一行
+var annotationCSVHeader = []string{
+	"run_type", "run_id", "case_index", "case_id", "model_name", "provider", "success", "passed", "content",
+	"annotator", "rating", "tags", "preferred", "note",
+}
+
+// WriteAnnotationCSV 以CSV格式写出运行结果及标注, 多个标签以分号分隔
+func WriteAnnotationCSV(w io.Writer, export *model.AnnotationExport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(annotationCSVHeader); err != nil {
+		return err
+	}
+	for _, r := range export.Responses {
+		resp := r.Response
+		passed := ""
+		if resp.Passed != nil {
+			passed = strconv.FormatBool(*resp.Passed)
+		}
+		base := []string{
+			export.RunType, export.RunID, strconv.Itoa(r.CaseIndex), r.CaseID, resp.ModelName, resp.Provider,
+			strconv.FormatBool(resp.Success), passed, resp.Content,
+		}
+		if len(r.Annotations) == 0 {
+			if err := writer.Write(append(base, "", "", "", "", "")); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, a := range r.Annotations {
+			rating := ""
+			if a.Rating != nil {
+				rating = strconv.FormatFloat(*a.Rating, 'f', -1, 64)
+			}
+			tags := make([]string, 0, len(a.Tags))
+			for _, tag := range a.Tags {
+				tags = append(tags, fmt.Sprint(tag))
+			}
+			row := append(slices.Clone(base), a.Annotator, rating, strings.Join(tags, ";"), strconv.FormatBool(a.Preferred), a.Note)
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/repository/repotest"
+)
+
+func TestComputeAgreementGroupsByProvider(t *testing.T) {
+	annotation := func(provider, annotator string, rating float64) *model.Annotation {
+		return &model.Annotation{RunType: model.RunTypeJob, RunID: "job-1", Provider: provider, ModelName: "x", Annotator: annotator, Rating: ptr(rating)}
+	}
+	// 同名模型在两个提供者下各有两位标注者的评分, 应视为两个响应
+	got := computeAgreement([]*model.Annotation{
+		annotation("fake", "alice", 5), annotation("fake", "bob", 5),
+		annotation("other", "alice", 1), annotation("other", "bob", 1),
+	})
+	if got.Responses != 2 || got.Annotators != 2 || got.Rating.Pairs != 2 {
+		t.Fatalf("agreement = %+v, want 2 responses with 2 pairs", got)
+	}
+	if got.Rating.Alpha == nil || *got.Rating.Alpha != 1 {
+		t.Fatalf("alpha = %v, want perfect agreement", got.Rating.Alpha)
+	}
+}
+
+func TestAnnotationProvider(t *testing.T) {
+	ctx := context.Background()
+	jobs := repository.NewMemoryJobStore()
+	result, err := model.NewJSONField(&model.BatchResult{
+		Models: []string{"x"},
+		Cases: []*model.BatchCaseResult{{
+			Case:    model.TestCase{ID: "1", Prompt: "hi"},
+			Results: map[string]*model.ModelResponse{"x": {ModelName: "x", Provider: "fake", Success: true}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := jobs.Create(ctx, &model.Job{ID: "job-1", Type: model.JobTypeBatch, Status: model.JobStatusSucceeded, Result: result}); err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.NewAnnotationRepository(repotest.OpenSQLite(t))
+	s := NewAnnotationService(repo, nil, jobs, config.AnnotationConfig{})
+
+	// 未指定提供者时按模型名称匹配, 保存响应的提供者
+	saved, err := s.Save(ctx, &model.AnnotationRequest{RunType: model.RunTypeJob, RunID: "job-1", ModelName: "x", Annotator: "alice", Rating: ptr(4.0)})
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if saved.Provider != "fake" {
+		t.Fatalf("provider = %q, want fake", saved.Provider)
+	}
+	_, err = s.Save(ctx, &model.AnnotationRequest{RunType: model.RunTypeJob, RunID: "job-1", Provider: "other", ModelName: "x", Annotator: "alice", Rating: ptr(4.0)})
+	if !errors.Is(err, ErrInvalidAnnotation) {
+		t.Fatalf("expected ErrInvalidAnnotation for a provider without a response, got %v", err)
+	}
+
+	// 记录提供者之前保存的标注仍归入对应响应
+	legacy := &model.Annotation{RunType: model.RunTypeJob, RunID: "job-1", ModelName: "x", Annotator: "bob", Rating: ptr(2.0)}
+	if err := repo.Save(ctx, legacy); err != nil {
+		t.Fatalf("save legacy annotation: %v", err)
+	}
+	export, err := s.Export(ctx, model.RunTypeJob, "job-1")
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if len(export.Responses) != 1 || len(export.Responses[0].Annotations) != 2 {
+		t.Fatalf("export = %+v, want both annotations on the single response", export.Responses)
+	}
+	if mean := export.Responses[0].MeanRating; mean == nil || *mean != 3 {
+		t.Fatalf("mean rating = %v, want 3", mean)
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidArena 盲测请求不合法
+var ErrInvalidArena = errors.New("invalid arena request")
+
+// ErrArenaModelFailed 盲测中有模型调用失败, 不创建对战
+var ErrArenaModelFailed = errors.New("model call failed, please try again")
+
+// 盲测中展示的匿名标签
+const (
+	arenaLabelA = "Model A"
+	arenaLabelB = "Model B"
+)
+
+// ArenaService 盲测服务, 投票前隐藏模型身份, 投票计入成对对比以更新排行榜
+type ArenaService struct {
+	repo   *repository.ArenaRepository
+	models *MultiModelService
+}
+
+// NewArenaService 创建盲测服务
+func NewArenaService(repo *repository.ArenaRepository, models *MultiModelService) *ArenaService {
+	return &ArenaService{
+		repo:   repo,
+		models: models,
+	}
+}
+
+// Start 调用两个模型并以随机顺序创建匿名对战, req需已经过PrepareRequest
+// 盲测不记录测试历史, 避免在投票前通过历史记录得知模型身份
+func (s *ArenaService) Start(ctx context.Context, req *model.TestRequest) (*model.ArenaSession, error) {
+	if len(req.Models) != 2 {
+		return nil, fmt.Errorf("%w: blind mode requires exactly two models", ErrInvalidArena)
+	}
+	if req.Models[0].Name == req.Models[1].Name && req.Models[0].Provider == req.Models[1].Provider {
+		return nil, fmt.Errorf("%w: blind mode requires two different models", ErrInvalidArena)
+	}
+	// 盲测只返回匿名输出, 不执行评估, 避免静默忽略评估选项
+	if len(req.Assertions) > 0 || req.Judge != nil || req.Expected != "" || req.Metrics != nil {
+		return nil, fmt.Errorf("%w: assertions, judge, expected and metrics are not supported in blind mode", ErrInvalidArena)
+	}
+
+	models := []model.ModelReq{req.Models[0], req.Models[1]}
+	rand.Shuffle(len(models), func(i, j int) {
+		models[i], models[j] = models[j], models[i]
+	})
+
+	responses := make([]*model.ModelResponse, len(models))
+	var wg sync.WaitGroup
+	for i, modelReq := range models {
+		wg.Add(1)
+		go func(i int, modelReq model.ModelReq) {
+			defer wg.Done()
+			responses[i] = s.models.CallModel(ctx, req.Prompts, modelReq)
+		}(i, modelReq)
+	}
+	wg.Wait()
+
+	for _, resp := range responses {
+		if !resp.Success {
+			// 错误信息中可能包含提供者名称, 仅记录在日志中
+			logger.Error("Arena model call failed",
+				zap.String("provider", resp.Provider),
+				zap.String("model", resp.ModelName),
+				zap.String("error", resp.Error),
+			)
+			return nil, ErrArenaModelFailed
+		}
+	}
+
+	battle := &model.ArenaBattle{
+		ID:        uuid.NewString(),
+		System:    req.Prompts.System,
+		Prompt:    req.Prompts.User,
+		ProviderA: models[0].Provider,
+		ModelA:    models[0].Name,
+		OutputA:   responses[0].Content,
+		ProviderB: models[1].Provider,
+		ModelB:    models[1].Name,
+		OutputB:   responses[1].Content,
+	}
+	if err := s.repo.Create(ctx, battle); err != nil {
+		return nil, fmt.Errorf("failed to save arena battle: %w", err)
+	}
+
+	logger.Info("Arena battle created", zap.String("battle_id", battle.ID))
+	return arenaSession(battle), nil
+}
+
+// Get 获取对战, 未投票时不包含模型身份
+func (s *ArenaService) Get(ctx context.Context, id string) (*model.ArenaSession, error) {
+	battle, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return arenaSession(battle), nil
+}
+
+// Vote 记录投票并揭晓模型身份, 投票同时保存为来源为arena的成对对比
+func (s *ArenaService) Vote(ctx context.Context, id string, req *model.ArenaVoteRequest) (*model.ArenaSession, error) {
+	switch req.Winner {
+	case model.WinnerA, model.WinnerB, model.WinnerTie, model.WinnerBothBad:
+	default:
+		return nil, fmt.Errorf("%w: winner must be a, b, tie or both_bad", ErrInvalidArena)
+	}
+
+	battle, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if battle.Voted() {
+		return nil, repository.ErrConflict
+	}
+
+	comparison := &model.Comparison{
+		System:    battle.System,
+		Prompt:    battle.Prompt,
+		ProviderA: battle.ProviderA,
+		ModelA:    battle.ModelA,
+		OutputA:   battle.OutputA,
+		ProviderB: battle.ProviderB,
+		ModelB:    battle.ModelB,
+		OutputB:   battle.OutputB,
+		Winner:    req.Winner,
+		Source:    model.ComparisonSourceArena,
+		Reason:    req.Reason,
+		CaseKey:   battle.ID,
+	}
+	battle, err = s.repo.Vote(ctx, id, req.Winner, req.Reason, comparison)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Arena vote recorded",
+		zap.String("battle_id", battle.ID),
+		zap.String("winner", battle.Winner),
+	)
+	return arenaSession(battle), nil
+}
+
+// arenaSession 转换为对外的会话, 仅在投票后填充模型身份
+func arenaSession(battle *model.ArenaBattle) *model.ArenaSession {
+	session := &model.ArenaSession{
+		ID:     battle.ID,
+		Prompt: battle.Prompt,
+		Outputs: []model.ArenaOutput{
+			{Label: arenaLabelA, Content: battle.OutputA},
+			{Label: arenaLabelB, Content: battle.OutputB},
+		},
+		CreatedAt: battle.CreatedAt,
+	}
+	if battle.Voted() {
+		session.Outputs[0].Provider, session.Outputs[0].Model = battle.ProviderA, battle.ModelA
+		session.Outputs[1].Provider, session.Outputs[1].Model = battle.ProviderB, battle.ModelB
+		session.Winner = battle.Winner
+		session.Reason = battle.Reason
+		session.ComparisonID = battle.ComparisonID
+		session.VotedAt = battle.VotedAt
+	}
+	return session
+}
@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/multi-agent-testing/backend/internal/assertion"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/prompt"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultBatchConcurrency = 4
+	maxBatchConcurrency     = 32
+	checkpointInterval      = 2 * time.Second // 检查点最小保存间隔
+)
+
+// batchCell 批量任务中的单个用例 × 模型调用
+type batchCell struct {
+	caseIndex int
+	modelReq  model.ModelReq
+}
+
+// SubmitBatch 提交批量测试任务
+func (s *JobService) SubmitBatch(ctx context.Context, req *model.BatchRequest) (*model.Job, error) {
+	if len(req.Models) == 0 {
+		return nil, fmt.Errorf("%w: at least one model is required", ErrInvalidJob)
+	}
+	if err := validateModelNames(req.Models); err != nil {
+		return nil, invalidJob(err)
+	}
+	if err := s.testService.ValidateModels(req.Models); err != nil {
+		return nil, invalidJob(err)
+	}
+	if err := assertion.Validate(req.Assertions); err != nil {
+		return nil, invalidJob(err)
+	}
+	if err := validateMetricOptions(req.Metrics); err != nil {
+		return nil, invalidJob(err)
+	}
+	if req.Judge != nil {
+		if err := s.testService.judge.Validate(req.Judge); err != nil {
+			return nil, invalidJob(err)
+		}
+	}
+
+	cases, err := s.resolveCases(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for i, tc := range cases {
+		if _, err := renderCase(tc); err != nil {
+			return nil, fmt.Errorf("%w: case %d: %w", ErrInvalidJob, i+1, err)
+		}
+		if err := assertion.Validate(tc.Assertions); err != nil {
+			return nil, fmt.Errorf("%w: case %d: %w", ErrInvalidJob, i+1, err)
+		}
+	}
+	// 保存解析后的用例, 恢复执行时不依赖数据集的后续修改
+	req.Cases = cases
+
+	request, err := model.NewJSONField(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	result := newBatchResult(cases, req.Models)
+	resultField, err := model.NewJSONField(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+
+	now := time.Now()
+	job := &model.Job{
+		ID:             uuid.NewString(),
+		Type:           model.JobTypeBatch,
+		Status:         model.JobStatusPending,
+		Total:          len(cases) * len(req.Models),
+		Request:        request,
+		DatasetID:      req.DatasetID,
+		DatasetVersion: req.DatasetVersion,
+		Result:         resultField,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.store.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	if err := s.launch(job, func(ctx context.Context, job *model.Job) {
+		s.runBatch(ctx, job, req, result)
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Batch job submitted",
+		zap.String("job_id", job.ID),
+		zap.Int("case_count", len(cases)),
+		zap.Int("model_count", len(req.Models)),
+	)
+
+	return job, nil
+}
+
+// Resume 从检查点恢复批量任务, 仅重新执行未完成的用例
+func (s *JobService) Resume(ctx context.Context, id string) (*model.Job, error) {
+	job, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Type != model.JobTypeBatch || job.Status == model.JobStatusSucceeded {
+		return nil, ErrJobNotResumable
+	}
+
+	var req model.BatchRequest
+	if err := job.Request.Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode job request: %w", err)
+	}
+	var result model.BatchResult
+	if err := job.Result.Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode job checkpoint: %w", err)
+	}
+	if len(result.Cases) != len(req.Cases) {
+		return nil, fmt.Errorf("job checkpoint does not match request: %w", ErrJobNotResumable)
+	}
+	for _, row := range result.Cases {
+		if row.Results == nil {
+			row.Results = make(map[string]*model.ModelResponse)
+		}
+	}
+
+	if err := s.launch(job, func(ctx context.Context, job *model.Job) {
+		s.runBatch(ctx, job, &req, &result)
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Batch job resumed", zap.String("job_id", job.ID))
+	return job, nil
+}
+
+// autoFreezeNote 批量任务自动冻结数据集时的版本说明
+const autoFreezeNote = "auto-frozen for batch run"
+
+// resolveCases 获取批量任务的用例, 使用数据集时将req.DatasetVersion设置为实际使用的版本
+// 请求不合法时返回包装了ErrInvalidJob的错误, 读取或冻结数据集失败时原样返回
+func (s *JobService) resolveCases(ctx context.Context, req *model.BatchRequest) ([]model.TestCase, error) {
+	if req.DatasetID == 0 {
+		if len(req.Cases) == 0 {
+			return nil, fmt.Errorf("%w: dataset_id or cases is required", ErrInvalidJob)
+		}
+		if req.DatasetVersion != 0 || req.Split != "" || len(req.Tags) > 0 {
+			return nil, fmt.Errorf("%w: dataset_version, split and tags require dataset_id", ErrInvalidJob)
+		}
+		return req.Cases, nil
+	}
+	if len(req.Cases) > 0 {
+		return nil, fmt.Errorf("%w: dataset_id and cases cannot be combined", ErrInvalidJob)
+	}
+	if req.Split != "" && !slices.Contains(model.Splits, req.Split) {
+		return nil, fmt.Errorf("%w: unsupported split: %s", ErrInvalidJob, req.Split)
+	}
+
+	if s.datasets == nil {
+		return nil, fmt.Errorf("%w: dataset storage is not enabled", ErrInvalidJob)
+	}
+	version, err := s.resolveDatasetVersion(ctx, req.DatasetID, req.DatasetVersion)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.datasets.ListCases(ctx, req.DatasetID, version, repository.CaseFilter{Split: req.Split, Tags: req.Tags})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dataset %d: %w", req.DatasetID, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: dataset %d version %d has no matching cases", ErrInvalidJob, req.DatasetID, version)
+	}
+	req.DatasetVersion = version
+
+	cases := make([]model.TestCase, 0, len(rows))
+	for _, row := range rows {
+		cases = append(cases, row.ToTestCase())
+	}
+	return cases, nil
+}
+
+// resolveDatasetVersion 确定批量任务使用的数据集版本
+// 未指定版本时使用最新版本, 数据集从未冻结或工作副本有未冻结的修改时先冻结新版本, 保证任务总是引用不可变的快照
+func (s *JobService) resolveDatasetVersion(ctx context.Context, datasetID uint64, version int) (int, error) {
+	if version != 0 {
+		if _, err := s.datasets.GetVersion(ctx, datasetID, version); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return 0, fmt.Errorf("%w: dataset %d version %d not found", ErrInvalidJob, datasetID, version)
+			}
+			return 0, fmt.Errorf("failed to load dataset %d: %w", datasetID, err)
+		}
+		return version, nil
+	}
+
+	ds, err := s.datasets.Get(ctx, datasetID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return 0, fmt.Errorf("%w: dataset %d not found", ErrInvalidJob, datasetID)
+		}
+		return 0, fmt.Errorf("failed to load dataset %d: %w", datasetID, err)
+	}
+	if ds.Version > 0 && !ds.Modified {
+		return ds.Version, nil
+	}
+	if ds.CaseCount == 0 {
+		return 0, fmt.Errorf("%w: dataset %d has no cases", ErrInvalidJob, datasetID)
+	}
+	frozen, err := s.datasets.Freeze(ctx, datasetID, autoFreezeNote)
+	if err != nil {
+		return 0, fmt.Errorf("failed to freeze dataset %d: %w", datasetID, err)
+	}
+	logger.Info("Dataset version frozen for batch run",
+		zap.Uint64("dataset_id", datasetID),
+		zap.Int("version", frozen.Version),
+	)
+	return frozen.Version, nil
+}
+
+// runBatch 并发执行批量任务, 定期保存检查点
+func (s *JobService) runBatch(ctx context.Context, job *model.Job, req *model.BatchRequest, result *model.BatchResult) {
+	// 仅用于持久化, 不受任务取消影响
+	storeCtx := context.Background()
+
+	// 收集未完成的调用
+	cells := []batchCell{}
+	for i, row := range result.Cases {
+		for _, modelReq := range req.Models {
+			if _, done := row.Results[modelReq.Name]; !done {
+				cells = append(cells, batchCell{caseIndex: i, modelReq: modelReq})
+			}
+		}
+	}
+
+	job.Status = model.JobStatusRunning
+	job.Error = ""
+	job.FinishedAt = nil
+	job.Completed = job.Total - len(cells)
+	job.UpdatedAt = time.Now()
+	if err := s.store.Update(storeCtx, job); err != nil {
+		logger.Error("Failed to update job", zap.String("job_id", job.ID), zap.Error(err))
+	}
+
+	concurrency := req.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	if concurrency > maxBatchConcurrency {
+		concurrency = maxBatchConcurrency
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		lastSave = time.Now()
+		cellCh   = make(chan batchCell)
+	)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for cell := range cellCh {
+				row := result.Cases[cell.caseIndex]
+				prompts, err := renderCase(row.Case)
+				var resp *model.ModelResponse
+				if err != nil {
+					resp = &model.ModelResponse{
+						ModelName: cell.modelReq.Name,
+						Provider:  cell.modelReq.Provider,
+						Error:     err.Error(),
+						StartTime: time.Now(),
+						EndTime:   time.Now(),
+					}
+				} else {
+					resp = s.testService.CallModel(ctx, prompts, cell.modelReq)
+				}
+				// 取消后失败的调用通常由取消导致, 不记录, 恢复后重新执行
+				if ctx.Err() != nil && !resp.Success {
+					continue
+				}
+				// 已成功的调用在任务取消后仍完成评估并记录, 恢复时不会重复调用及计费
+				s.testService.evaluate(context.WithoutCancel(ctx), caseEvaluation(req, row.Case), prompts, resp)
+
+				mu.Lock()
+				row.Results[cell.modelReq.Name] = resp
+				job.Completed++
+				if time.Since(lastSave) >= checkpointInterval {
+					result.Summary = summarizeBatch(result)
+					s.saveResult(storeCtx, job, result)
+					lastSave = time.Now()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, cell := range cells {
+		select {
+		case cellCh <- cell:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(cellCh)
+	wg.Wait()
+
+	result.Summary = summarizeBatch(result)
+	s.saveResult(storeCtx, job, result)
+	if errors.Is(context.Cause(ctx), errShutdown) {
+		// 保持运行中状态, 重启后由Recover从检查点恢复
+		logger.Info("Batch job checkpointed on shutdown", zap.String("job_id", job.ID), zap.Int("completed", job.Completed))
+		return
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		s.finish(storeCtx, job, model.JobStatusCanceled, "")
+		return
+	}
+	s.finish(storeCtx, job, model.JobStatusSucceeded, "")
+}
+
+// newBatchResult 创建空的结果矩阵
+func newBatchResult(cases []model.TestCase, models []model.ModelReq) *model.BatchResult {
+	result := &model.BatchResult{
+		Models: make([]string, 0, len(models)),
+		Cases:  make([]*model.BatchCaseResult, 0, len(cases)),
+	}
+	for _, modelReq := range models {
+		result.Models = append(result.Models, modelReq.Name)
+	}
+	for _, tc := range cases {
+		result.Cases = append(result.Cases, &model.BatchCaseResult{
+			Case:    tc,
+			Results: make(map[string]*model.ModelResponse),
+		})
+	}
+	return result
+}
+
+// caseEvaluation 单个用例的评估配置, 合并批量请求与用例中的断言
+func caseEvaluation(req *model.BatchRequest, tc model.TestCase) evaluation {
+	assertions := make([]model.Assertion, 0, len(req.Assertions)+len(tc.Assertions))
+	assertions = append(assertions, req.Assertions...)
+	return evaluation{
+		assertions: append(assertions, tc.Assertions...),
+		judge:      req.Judge,
+		metrics:    req.Metrics,
+		expected:   tc.Expected,
+	}
+}
+
+// renderCase 渲染用例中的变量, 生成提示词
+// 没有变量的用例按原文发送, 提示词中的{{、{%等代码或模板片段不会被当作模板解析
+func renderCase(tc model.TestCase) (model.PromptSet, error) {
+	if len(tc.Variables) == 0 {
+		return model.PromptSet{System: tc.System, User: tc.Prompt}, nil
+	}
+	system, err := prompt.Render(tc.System, tc.Variables)
+	if err != nil {
+		return model.PromptSet{}, fmt.Errorf("system prompt: %w", err)
+	}
+	user, err := prompt.Render(tc.Prompt, tc.Variables)
+	if err != nil {
+		return model.PromptSet{}, fmt.Errorf("user prompt: %w", err)
+	}
+	return model.PromptSet{
+		System: system,
+		User:   user,
+	}, nil
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+)
+
+// fakeProvider 回显用户提示词的提供者, 可在指定提示词上阻塞直到调用被取消
+type fakeProvider struct {
+	mu      sync.Mutex
+	calls   []string // 按调用顺序记录的"模型:提示词"
+	block   string   // 阻塞的用户提示词, 为空时不阻塞
+	blocked chan struct{}
+}
+
+func newFakeProvider(block string) *fakeProvider {
+	return &fakeProvider{block: block, blocked: make(chan struct{}, 1)}
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Call(ctx context.Context, req *model.CallProvidersRequest) (*model.ModelResponse, error) {
+	p.mu.Lock()
+	p.calls = append(p.calls, req.Models.Name+":"+req.Prompts.User)
+	block := p.block != "" && req.Prompts.User == p.block
+	p.mu.Unlock()
+
+	if block {
+		p.blocked <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &model.ModelResponse{Provider: p.Name(), Content: "echo " + req.Prompts.User, Success: true}, nil
+}
+
+func (p *fakeProvider) Stream(context.Context, *model.CallProvidersRequest) (<-chan *model.StreamChunk, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p *fakeProvider) ValidateConfig(map[string]interface{}) error { return nil }
+
+// takeCalls 返回并清空调用记录, 并取消阻塞
+func (p *fakeProvider) takeCalls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	calls := p.calls
+	p.calls, p.block = nil, ""
+	return calls
+}
+
+// newBatchService 创建使用内存任务存储及fake提供者的任务服务
+func newBatchService(provider *fakeProvider, store repository.JobStore) *JobService {
+	testService := NewMultiModelService(&config.Config{})
+	testService.providers[provider.Name()] = provider
+	return NewJobService(store, testService, nil)
+}
+
+// batchRequest 四个用例 × 两个模型, 逐个执行以固定调用顺序
+func batchRequest() *model.BatchRequest {
+	return &model.BatchRequest{
+		Models: []model.ModelReq{{Name: "a", Provider: "fake"}, {Name: "b", Provider: "fake"}},
+		Cases: []model.TestCase{
+			{ID: "1", Prompt: "c1"}, {ID: "2", Prompt: "c2"}, {ID: "3", Prompt: "c3"}, {ID: "4", Prompt: "c4"},
+		},
+		Concurrency: 1,
+	}
+}
+
+// checkpoint 读取任务及其保存的结果
+func checkpoint(t *testing.T, store repository.JobStore, id string) (*model.Job, *model.BatchResult) {
+	t.Helper()
+	job, err := store.Get(context.Background(), id)
+	if err != nil {
+		t.Fatalf("get job: %v", err)
+	}
+	var result model.BatchResult
+	if err := job.Result.Decode(&result); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	return job, &result
+}
+
+// completedCases 各用例已记录结果的模型数
+func completedCases(result *model.BatchResult) []int {
+	counts := make([]int, len(result.Cases))
+	for i, row := range result.Cases {
+		counts[i] = len(row.Results)
+	}
+	return counts
+}
+
+func TestBatchCancelAndResume(t *testing.T) {
+	ctx := context.Background()
+	provider := newFakeProvider("c3")
+	store := repository.NewMemoryJobStore()
+	s := newBatchService(provider, store)
+
+	job, err := s.SubmitBatch(ctx, batchRequest())
+	if err != nil {
+		t.Fatalf("SubmitBatch: %v", err)
+	}
+	<-provider.blocked
+	if _, err := s.Cancel(ctx, job.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	s.wg.Wait()
+
+	// 取消前完成的调用保存在检查点中, 被取消的调用不记录
+	saved, result := checkpoint(t, store, job.ID)
+	if saved.Status != model.JobStatusCanceled || saved.Completed != 4 || saved.Total != 8 {
+		t.Fatalf("after cancel: status=%s completed=%d/%d", saved.Status, saved.Completed, saved.Total)
+	}
+	if got := completedCases(result); !slices.Equal(got, []int{2, 2, 0, 0}) {
+		t.Fatalf("checkpoint results per case = %v", got)
+	}
+	if got := provider.takeCalls(); len(got) != 5 {
+		t.Fatalf("calls before cancel = %v", got)
+	}
+
+	if _, err := s.Resume(ctx, job.ID); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	s.wg.Wait()
+
+	// 恢复后只执行未完成的调用
+	if got, want := provider.takeCalls(), []string{"a:c3", "b:c3", "a:c4", "b:c4"}; !slices.Equal(got, want) {
+		t.Fatalf("calls after resume = %v, want %v", got, want)
+	}
+	saved, result = checkpoint(t, store, job.ID)
+	if saved.Status != model.JobStatusSucceeded || saved.Completed != 8 || saved.FinishedAt == nil {
+		t.Fatalf("after resume: status=%s completed=%d", saved.Status, saved.Completed)
+	}
+	if got := completedCases(result); !slices.Equal(got, []int{2, 2, 2, 2}) {
+		t.Fatalf("results per case = %v", got)
+	}
+	if r := result.Cases[0].Results["a"]; r == nil || r.Content != "echo c1" {
+		t.Fatalf("result from before the cancel was lost: %+v", r)
+	}
+	if summary := result.Summary["b"]; summary == nil || summary.Completed != 4 || summary.Succeeded != 4 {
+		t.Fatalf("summary = %+v", summary)
+	}
+
+	if _, err := s.Resume(ctx, job.ID); !errors.Is(err, ErrJobNotResumable) {
+		t.Fatalf("expected ErrJobNotResumable for a finished job, got %v", err)
+	}
+}
+
+func TestBatchShutdownRecover(t *testing.T) {
+	ctx := context.Background()
+	provider := newFakeProvider("c2")
+	store := repository.NewMemoryJobStore()
+	s := newBatchService(provider, store)
+
+	job, err := s.SubmitBatch(ctx, batchRequest())
+	if err != nil {
+		t.Fatalf("SubmitBatch: %v", err)
+	}
+	<-provider.blocked
+	s.Shutdown(ctx)
+
+	// 关闭时保持运行中状态并保存检查点
+	saved, result := checkpoint(t, store, job.ID)
+	if saved.Status != model.JobStatusRunning || saved.Completed != 2 {
+		t.Fatalf("after shutdown: status=%s completed=%d", saved.Status, saved.Completed)
+	}
+	if got := completedCases(result); !slices.Equal(got, []int{2, 0, 0, 0}) {
+		t.Fatalf("checkpoint results per case = %v", got)
+	}
+	provider.takeCalls()
+
+	// 重启后从检查点恢复
+	restarted := newBatchService(provider, store)
+	if err := restarted.Recover(ctx); err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	restarted.wg.Wait()
+	if got := provider.takeCalls(); len(got) != 6 {
+		t.Fatalf("calls after recover = %v, want the 6 unfinished cells", got)
+	}
+	if saved, _ := checkpoint(t, store, job.ID); saved.Status != model.JobStatusSucceeded || saved.Completed != 8 {
+		t.Fatalf("after recover: status=%s completed=%d", saved.Status, saved.Completed)
+	}
+}
+
+func TestSubmitBatchRejectsDuplicateModelNames(t *testing.T) {
+	s := newBatchService(newFakeProvider(""), repository.NewMemoryJobStore())
+	req := batchRequest()
+	req.Models = []model.ModelReq{{Name: "x", Provider: "fake"}, {Name: "x", Provider: "openai"}}
+	if _, err := s.SubmitBatch(context.Background(), req); !errors.Is(err, ErrInvalidJob) {
+		t.Fatalf("expected ErrInvalidJob, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"github.com/multi-agent-testing/backend/internal/model"
+)
+
+// summarizeBatch 按模型汇总批量测试结果, 指标取参与计算用例的平均值, 调用失败的用例指标为0
+func summarizeBatch(result *model.BatchResult) map[string]*model.ModelSummary {
+	summary := make(map[string]*model.ModelSummary, len(result.Models))
+	for _, name := range result.Models {
+		var (
+			sum          = &model.ModelSummary{}
+			responseTime int64
+			judgeScore   float64
+			judged       int
+			metricSums   = make(map[string]float64)
+		)
+		for _, row := range result.Cases {
+			resp, ok := row.Results[name]
+			if !ok {
+				continue
+			}
+			sum.Completed++
+			if resp.Success {
+				sum.Succeeded++
+				responseTime += resp.ResponseTime
+			}
+			if resp.Passed != nil {
+				sum.Evaluated++
+				if *resp.Passed {
+					sum.Passed++
+				}
+			}
+			sum.TokensUsed += resp.TokensUsed
+			sum.Cost = addCost(sum.Cost, resp.Cost)
+			if resp.Judge != nil {
+				sum.JudgeCost = addCost(sum.JudgeCost, resp.Judge.Cost)
+				if resp.Judge.Score != nil {
+					judgeScore += *resp.Judge.Score
+					judged++
+				}
+			}
+			for metric, value := range resp.Metrics {
+				if sum.MetricCases == nil {
+					sum.MetricCases = make(map[string]int)
+				}
+				metricSums[metric] += value
+				sum.MetricCases[metric]++
+			}
+		}
+
+		if sum.Succeeded > 0 {
+			sum.AvgResponseTime = responseTime / int64(sum.Succeeded)
+		}
+		if sum.Evaluated > 0 {
+			rate := float64(sum.Passed) / float64(sum.Evaluated)
+			sum.PassRate = &rate
+		}
+		if judged > 0 {
+			avg := judgeScore / float64(judged)
+			sum.JudgeScore = &avg
+		}
+		if len(metricSums) > 0 {
+			sum.Metrics = make(map[string]float64, len(metricSums))
+			for metric, total := range metricSums {
+				sum.Metrics[metric] = total / float64(sum.MetricCases[metric])
+			}
+		}
+		summary[name] = sum
+	}
+	return summary
+}
+
+// addCost 累加费用, 均未知时保持为空
+func addCost(total, cost *float64) *float64 {
+	if cost == nil {
+		return total
+	}
+	v := *cost
+	if total != nil {
+		v += *total
+	}
+	return &v
+}
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/rating"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidComparison 对比请求不合法
+var ErrInvalidComparison = errors.New("invalid comparison")
+
+const (
+	maxTags      = 10 // 单次对比或标注的标签数上限
+	maxTagLength = 50
+)
+
+// ComparisonService 成对对比服务, 保存评审模型或人工的胜负判断并计算排行榜
+type ComparisonService struct {
+	repo   *repository.ComparisonRepository
+	models *MultiModelService
+}
+
+// NewComparisonService 创建成对对比服务
+func NewComparisonService(repo *repository.ComparisonRepository, models *MultiModelService) *ComparisonService {
+	return &ComparisonService{
+		repo:   repo,
+		models: models,
+	}
+}
+
+// Compare 调用各模型后由评审模型两两比较输出, 保存得出胜负的对比
+func (s *ComparisonService) Compare(ctx context.Context, req *model.CompareRequest) (*model.CompareResult, error) {
+	judgeReq := req.Judge
+	if judgeReq == nil {
+		judgeReq = &model.JudgeRequest{}
+	}
+	if err := s.validateCompare(req, judgeReq); err != nil {
+		return nil, err
+	}
+	tags, err := normalizeTags(req.Tags, ErrInvalidComparison)
+	if err != nil {
+		return nil, err
+	}
+
+	// 调用失败的模型不参与对比
+	responses := make([]*model.ModelResponse, len(req.Models))
+	var wg sync.WaitGroup
+	for i, modelReq := range req.Models {
+		wg.Add(1)
+		go func(i int, modelReq model.ModelReq) {
+			defer wg.Done()
+			responses[i] = s.models.CallModel(ctx, req.Prompts, modelReq)
+		}(i, modelReq)
+	}
+	wg.Wait()
+
+	result := &model.CompareResult{
+		Results: make(map[string]*model.ModelResponse, len(responses)),
+	}
+	for i, resp := range responses {
+		result.Results[ratingKey(req.Models[i].Provider, req.Models[i].Name)] = resp
+	}
+
+	type pair struct{ a, b int }
+	pairs := []pair{}
+	for i := range responses {
+		for j := i + 1; j < len(responses); j++ {
+			pairs = append(pairs, pair{i, j})
+		}
+	}
+	result.Pairs = make([]*model.PairwiseResult, len(pairs))
+	for k, p := range pairs {
+		a, b := responses[p.a], responses[p.b]
+		if !a.Success || !b.Success {
+			result.Pairs[k] = &model.PairwiseResult{ModelA: a.ModelName, ModelB: b.ModelName, Error: "model call failed"}
+			continue
+		}
+		wg.Add(1)
+		go func(k int, a, b *model.ModelResponse) {
+			defer wg.Done()
+			result.Pairs[k] = s.models.judge.Compare(ctx, judgeReq, req.Prompts, a, b, !req.NoSwap)
+		}(k, a, b)
+	}
+	wg.Wait()
+	for k, p := range pairs {
+		// 区分不同提供者下的同名模型
+		result.Pairs[k].ProviderA, result.Pairs[k].ProviderB = req.Models[p.a].Provider, req.Models[p.b].Provider
+	}
+
+	judgeModel, _ := s.models.judge.resolveModel(s.models.currentConfig().Judge, judgeReq)
+	comparisons := []*model.Comparison{}
+	saved := []*model.PairwiseResult{}
+	for k, p := range pairs {
+		pr := result.Pairs[k]
+		if pr.Winner == "" {
+			continue
+		}
+		a, b := req.Models[p.a], req.Models[p.b]
+		comparisons = append(comparisons, &model.Comparison{
+			System:        req.Prompts.System,
+			Prompt:        req.Prompts.User,
+			ProviderA:     a.Provider,
+			ModelA:        a.Name,
+			OutputA:       responses[p.a].Content,
+			ProviderB:     b.Provider,
+			ModelB:        b.Name,
+			OutputB:       responses[p.b].Content,
+			Winner:        pr.Winner,
+			Source:        model.ComparisonSourceJudge,
+			JudgeProvider: judgeModel.Provider,
+			JudgeModel:    judgeModel.Name,
+			Consistent:    pr.Consistent,
+			Reason:        pr.Reason,
+			Tags:          tags.values,
+			TagNames:      tags.filter,
+			DatasetID:     req.DatasetID,
+			CaseKey:       req.CaseKey,
+		})
+		saved = append(saved, pr)
+	}
+	if err := s.repo.Create(ctx, comparisons); err != nil {
+		return nil, fmt.Errorf("failed to save comparisons: %w", err)
+	}
+	for i, c := range comparisons {
+		saved[i].ComparisonID = c.ID
+	}
+
+	logger.Info("Pairwise comparison completed",
+		zap.Int("model_count", len(req.Models)),
+		zap.Int("pair_count", len(pairs)),
+		zap.Int("saved_count", len(comparisons)),
+	)
+	return result, nil
+}
+
+// validateCompare 校验成对对比请求
+func (s *ComparisonService) validateCompare(req *model.CompareRequest, judgeReq *model.JudgeRequest) error {
+	if req.Prompts.User == "" {
+		return fmt.Errorf("%w: user prompt is required", ErrInvalidComparison)
+	}
+	if len(req.Models) < 2 {
+		return fmt.Errorf("%w: at least two models are required", ErrInvalidComparison)
+	}
+	// 与排行榜一致, 不同提供者下的同名模型视为不同模型
+	seen := make(map[string]bool, len(req.Models))
+	for _, m := range req.Models {
+		key := ratingKey(m.Provider, m.Name)
+		if seen[key] {
+			return fmt.Errorf("%w: duplicate model %s", ErrInvalidComparison, key)
+		}
+		seen[key] = true
+	}
+	if err := s.models.ValidateModels(req.Models); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidComparison, err)
+	}
+	if err := s.models.judge.ValidatePairwise(judgeReq); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidComparison, err)
+	}
+	return nil
+}
+
+// Vote 保存人工投票
+func (s *ComparisonService) Vote(ctx context.Context, req *model.VoteRequest) (*model.Comparison, error) {
+	switch {
+	case req.Prompts.User == "":
+		return nil, fmt.Errorf("%w: user prompt is required", ErrInvalidComparison)
+	case req.ModelA.Name == "" || req.ModelA.Provider == "" || req.ModelB.Name == "" || req.ModelB.Provider == "":
+		return nil, fmt.Errorf("%w: model_a and model_b require name and provider", ErrInvalidComparison)
+	case req.ModelA.Name == req.ModelB.Name && req.ModelA.Provider == req.ModelB.Provider:
+		return nil, fmt.Errorf("%w: model_a and model_b must be different", ErrInvalidComparison)
+	case req.Winner != model.WinnerA && req.Winner != model.WinnerB && req.Winner != model.WinnerTie && req.Winner != model.WinnerBothBad:
+		return nil, fmt.Errorf("%w: winner must be a, b, tie or both_bad", ErrInvalidComparison)
+	}
+	tags, err := normalizeTags(req.Tags, ErrInvalidComparison)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &model.Comparison{
+		System:    req.Prompts.System,
+		Prompt:    req.Prompts.User,
+		ProviderA: req.ModelA.Provider,
+		ModelA:    req.ModelA.Name,
+		OutputA:   req.OutputA,
+		ProviderB: req.ModelB.Provider,
+		ModelB:    req.ModelB.Name,
+		OutputB:   req.OutputB,
+		Winner:    req.Winner,
+		Source:    model.ComparisonSourceHuman,
+		Reason:    req.Reason,
+		Tags:      tags.values,
+		TagNames:  tags.filter,
+		DatasetID: req.DatasetID,
+		CaseKey:   req.CaseKey,
+	}
+	if err := s.repo.Create(ctx, []*model.Comparison{comparison}); err != nil {
+		return nil, err
+	}
+	return comparison, nil
+}
+
+// List 按条件分页查询成对对比
+func (s *ComparisonService) List(ctx context.Context, filter repository.ComparisonFilter) ([]*model.Comparison, int64, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Get 获取成对对比
+func (s *ComparisonService) Get(ctx context.Context, id uint64) (*model.Comparison, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// Delete 删除成对对比
+func (s *ComparisonService) Delete(ctx context.Context, id uint64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// Leaderboard 根据符合条件的对比计算排行榜, rounds为自助法重采样次数
+func (s *ComparisonService) Leaderboard(ctx context.Context, filter repository.ComparisonFilter, method string, rounds int) (*model.Leaderboard, error) {
+	switch method {
+	case "":
+		method = rating.MethodBradleyTerry
+	case rating.MethodBradleyTerry, rating.MethodElo:
+	default:
+		return nil, fmt.Errorf("%w: method must be %s or %s", ErrInvalidComparison, rating.MethodBradleyTerry, rating.MethodElo)
+	}
+	switch {
+	case rounds == 0:
+		rounds = rating.DefaultRounds
+	case rounds > rating.MaxRounds:
+		rounds = rating.MaxRounds
+	case rounds < 0:
+		rounds = -1
+	}
+	rows, err := s.repo.ListOutcomes(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := make([]rating.Outcome, 0, len(rows))
+	for _, row := range rows {
+		score := 0.5 // 平局及都不可接受
+		switch row.Winner {
+		case model.WinnerA:
+			score = 1
+		case model.WinnerB:
+			score = 0
+		}
+		outcomes = append(outcomes, rating.Outcome{
+			A:     ratingKey(row.ProviderA, row.ModelA),
+			B:     ratingKey(row.ProviderB, row.ModelB),
+			Score: score,
+		})
+	}
+	ratings, err := rating.Compute(outcomes, method, rounds)
+	if err != nil {
+		return nil, err
+	}
+
+	board := &model.Leaderboard{
+		Method:      method,
+		Comparisons: len(outcomes),
+		Confidence:  rating.Confidence,
+		Rounds:      max(rounds, 0),
+		Entries:     make([]model.LeaderboardEntry, 0, len(ratings)),
+	}
+	for i, r := range ratings {
+		provider, name, _ := strings.Cut(r.Model, "/")
+		board.Entries = append(board.Entries, model.LeaderboardEntry{
+			Rank:     i + 1,
+			Provider: provider,
+			Model:    name,
+			Rating:   r.Rating,
+			Lower:    r.Lower,
+			Upper:    r.Upper,
+			Games:    r.Games,
+			Wins:     r.Wins,
+			Losses:   r.Losses,
+			Ties:     r.Ties,
+			WinRate:  (float64(r.Wins) + float64(r.Ties)/2) / float64(r.Games),
+		})
+	}
+	return board, nil
+}
+
+// ratingKey 评分使用的模型标识, 同名模型在不同提供者下分开计分
+func ratingKey(provider, name string) string {
+	return provider + "/" + name
+}
+
+// tagSet 规范化后的标签
+type tagSet struct {
+	values model.JSONArray
+	filter string
+}
+
+// normalizeTags 去除空白及重复标签, 标签不能包含逗号, 校验失败时返回包装invalid的错误
+func normalizeTags(tags []string, invalid error) (tagSet, error) {
+	if len(tags) > maxTags {
+		return tagSet{}, fmt.Errorf("%w: at most %d tags are allowed", invalid, maxTags)
+	}
+	seen := make(map[string]bool, len(tags))
+	names := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if strings.Contains(tag, ",") || len(tag) > maxTagLength {
+			return tagSet{}, fmt.Errorf("%w: invalid tag %q", invalid, tag)
+		}
+		seen[tag] = true
+		names = append(names, tag)
+	}
+	if len(names) == 0 {
+		return tagSet{}, nil
+	}
+	values := make(model.JSONArray, 0, len(names))
+	for _, name := range names {
+		values = append(values, name)
+	}
+	return tagSet{values: values, filter: joinForFilter(names)}, nil
+}
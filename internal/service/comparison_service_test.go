@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/model"
+)
+
+// newComparisonService 创建不保存对比记录的对比服务, fake与other两个提供者均回显提示词
+func newComparisonService() *ComparisonService {
+	models := NewMultiModelService(&config.Config{})
+	provider := newFakeProvider("")
+	models.providers["fake"] = provider
+	models.providers["other"] = provider
+	return NewComparisonService(nil, models)
+}
+
+func TestValidateCompare(t *testing.T) {
+	s := newComparisonService()
+	judge := &model.JudgeRequest{Provider: "fake", Model: "judge"}
+	tests := []struct {
+		name   string
+		models []model.ModelReq
+		ok     bool
+	}{
+		{"different models", []model.ModelReq{{Name: "x", Provider: "fake"}, {Name: "y", Provider: "fake"}}, true},
+		{"same name across providers", []model.ModelReq{{Name: "x", Provider: "fake"}, {Name: "x", Provider: "other"}}, true},
+		{"duplicate model", []model.ModelReq{{Name: "x", Provider: "fake"}, {Name: "y", Provider: "fake"}, {Name: "x", Provider: "fake"}}, false},
+		{"single model", []model.ModelReq{{Name: "x", Provider: "fake"}}, false},
+		{"unknown provider", []model.ModelReq{{Name: "x", Provider: "fake"}, {Name: "x", Provider: "missing"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &model.CompareRequest{Prompts: model.PromptSet{User: "hi"}, Models: tt.models}
+			err := s.validateCompare(req, judge)
+			if tt.ok && err != nil {
+				t.Fatalf("validateCompare: %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidComparison) {
+				t.Fatalf("expected ErrInvalidComparison, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCompareKeysResultsByProvider(t *testing.T) {
+	s := newComparisonService()
+	req := &model.CompareRequest{
+		Prompts: model.PromptSet{User: "hi"},
+		Models:  []model.ModelReq{{Name: "x", Provider: "fake"}, {Name: "x", Provider: "other"}},
+		Judge:   &model.JudgeRequest{Provider: "fake", Model: "judge"},
+	}
+	result, err := s.Compare(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if len(result.Results) != 2 || result.Results["fake/x"] == nil || result.Results["other/x"] == nil {
+		t.Fatalf("results = %v, want fake/x and other/x", result.Results)
+	}
+	if len(result.Pairs) != 1 {
+		t.Fatalf("pairs = %d, want 1", len(result.Pairs))
+	}
+	if pr := result.Pairs[0]; pr.ProviderA != "fake" || pr.ProviderB != "other" || pr.ModelA != "x" || pr.ModelB != "x" {
+		t.Fatalf("pair = %+v", pr)
+	}
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/multi-agent-testing/backend/internal/assertion"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/prompt"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// maxConversationTurns 单个对话脚本的最大轮数
+const maxConversationTurns = 50
+
+// SubmitConversation 提交多轮对话测试任务, 各模型并发执行, 同一模型的轮次依次执行
+func (s *JobService) SubmitConversation(ctx context.Context, req *model.ConversationRequest) (*model.Job, error) {
+	system, turns, err := s.validateConversation(req)
+	if err != nil {
+		return nil, invalidJob(err)
+	}
+
+	request, err := model.NewJSONField(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	result := newConversationResult(turns, req.Models)
+
+	now := time.Now()
+	job := &model.Job{
+		ID:        uuid.NewString(),
+		Type:      model.JobTypeConversation,
+		Status:    model.JobStatusPending,
+		Total:     len(turns) * len(req.Models),
+		Request:   request,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.store.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	if err := s.launch(job, func(ctx context.Context, job *model.Job) {
+		s.runConversation(ctx, job, req, system, result)
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Conversation job submitted",
+		zap.String("job_id", job.ID),
+		zap.Int("turn_count", len(turns)),
+		zap.Int("model_count", len(req.Models)),
+	)
+
+	return job, nil
+}
+
+// validateConversation 校验多轮对话请求, 返回渲染后的系统提示词及各轮用户消息
+func (s *JobService) validateConversation(req *model.ConversationRequest) (string, []string, error) {
+	if len(req.Models) == 0 {
+		return "", nil, errors.New("at least one model is required")
+	}
+	if err := validateModelNames(req.Models); err != nil {
+		return "", nil, err
+	}
+	if err := s.testService.ValidateModels(req.Models); err != nil {
+		return "", nil, err
+	}
+	if len(req.Turns) == 0 {
+		return "", nil, errors.New("at least one turn is required")
+	}
+	if len(req.Turns) > maxConversationTurns {
+		return "", nil, fmt.Errorf("conversation exceeds %d turns", maxConversationTurns)
+	}
+	if err := assertion.Validate(req.Assertions); err != nil {
+		return "", nil, err
+	}
+	if err := validateMetricOptions(req.Metrics); err != nil {
+		return "", nil, err
+	}
+	for i, msg := range req.History {
+		if msg.Role != "user" && msg.Role != "assistant" {
+			return "", nil, fmt.Errorf("history message %d: role must be user or assistant", i+1)
+		}
+	}
+
+	system, turns, err := renderConversation(req)
+	if err != nil {
+		return "", nil, err
+	}
+	for i, turn := range req.Turns {
+		if err := assertion.Validate(turn.Assertions); err != nil {
+			return "", nil, fmt.Errorf("turn %d: %w", i+1, err)
+		}
+	}
+	return system, turns, nil
+}
+
+// renderConversation 渲染系统提示词及各轮用户消息
+// 没有变量时按原文发送, 消息中的{{、{%等代码或模板片段不会被当作模板解析
+func renderConversation(req *model.ConversationRequest) (string, []string, error) {
+	verbatim := len(req.Variables) == 0
+	system := req.System
+	if !verbatim {
+		var err error
+		if system, err = prompt.Render(req.System, req.Variables); err != nil {
+			return "", nil, fmt.Errorf("system prompt: %w", err)
+		}
+	}
+	turns := make([]string, 0, len(req.Turns))
+	for i, turn := range req.Turns {
+		if turn.User == "" {
+			return "", nil, fmt.Errorf("turn %d: user message is empty", i+1)
+		}
+		if verbatim {
+			turns = append(turns, turn.User)
+			continue
+		}
+		user, err := prompt.Render(turn.User, req.Variables)
+		if err != nil {
+			return "", nil, fmt.Errorf("turn %d: %w", i+1, err)
+		}
+		turns = append(turns, user)
+	}
+	return system, turns, nil
+}
+
+// runConversation 执行多轮对话任务, 每个模型的回复加入其自身的历史, 调用失败时该模型的对话终止
+func (s *JobService) runConversation(ctx context.Context, job *model.Job, req *model.ConversationRequest, system string, result *model.ConversationResult) {
+	// 仅用于持久化, 不受任务取消影响
+	storeCtx := context.Background()
+
+	job.Status = model.JobStatusRunning
+	job.UpdatedAt = time.Now()
+	if err := s.store.Update(storeCtx, job); err != nil {
+		logger.Error("Failed to update job", zap.String("job_id", job.ID), zap.Error(err))
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, modelReq := range req.Models {
+		transcript := result.Transcripts[modelReq.Name]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			history := append([]model.Message{}, req.History...)
+			for i, user := range result.Turns {
+				history = append(history, model.Message{Role: "user", Content: user})
+				prompts := model.PromptSet{
+					System:  system,
+					User:    user,
+					Message: append([]model.Message{}, history...),
+				}
+				resp := s.testService.CallModel(ctx, prompts, modelReq)
+				s.testService.evaluate(ctx, turnEvaluation(req, req.Turns[i]), prompts, resp)
+				// 取消导致的失败不记录
+				if ctx.Err() != nil {
+					return
+				}
+
+				mu.Lock()
+				transcript.Turns = append(transcript.Turns, &model.TurnResult{Index: i, User: user, Response: resp})
+				job.Completed++
+				s.saveResult(storeCtx, job, result)
+				mu.Unlock()
+
+				if !resp.Success {
+					return
+				}
+				history = append(history, model.Message{Role: "assistant", Content: resp.Content})
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, transcript := range result.Transcripts {
+		finalizeTranscript(transcript, len(result.Turns))
+	}
+	result.Summary = summarizeBatch(conversationBatch(result))
+	s.saveResult(storeCtx, job, result)
+	if errors.Is(ctx.Err(), context.Canceled) {
+		s.finish(storeCtx, job, model.JobStatusCanceled, "")
+		return
+	}
+	s.finish(storeCtx, job, model.JobStatusSucceeded, "")
+}
+
+// newConversationResult 创建空的对话记录
+func newConversationResult(turns []string, models []model.ModelReq) *model.ConversationResult {
+	result := &model.ConversationResult{
+		Models:      make([]string, 0, len(models)),
+		Turns:       turns,
+		Transcripts: make(map[string]*model.Transcript, len(models)),
+	}
+	for _, modelReq := range models {
+		result.Models = append(result.Models, modelReq.Name)
+		result.Transcripts[modelReq.Name] = &model.Transcript{
+			ModelName: modelReq.Name,
+			Provider:  modelReq.Provider,
+			Turns:     []*model.TurnResult{},
+		}
+	}
+	return result
+}
+
+// turnEvaluation 单轮对话的评估配置, 合并请求与本轮的断言
+func turnEvaluation(req *model.ConversationRequest, turn model.ConversationTurn) evaluation {
+	assertions := make([]model.Assertion, 0, len(req.Assertions)+len(turn.Assertions))
+	assertions = append(assertions, req.Assertions...)
+	return evaluation{
+		assertions: append(assertions, turn.Assertions...),
+		metrics:    req.Metrics,
+		expected:   turn.Expected,
+	}
+}
+
+// finalizeTranscript 汇总单个模型各轮的耗时、token、费用及断言结论
+func finalizeTranscript(transcript *model.Transcript, turnCount int) {
+	transcript.Completed = len(transcript.Turns) == turnCount
+	transcript.ResponseTime = 0
+	transcript.TokensUsed = 0
+	transcript.Cost = nil
+	transcript.Passed = nil
+
+	evaluated, passed := 0, true
+	for _, turn := range transcript.Turns {
+		resp := turn.Response
+		transcript.ResponseTime += resp.ResponseTime
+		transcript.TokensUsed += resp.TokensUsed
+		transcript.Cost = addCost(transcript.Cost, resp.Cost)
+		if resp.Passed != nil {
+			evaluated++
+			passed = passed && *resp.Passed
+		}
+	}
+	// 未执行完的对话存在未评估的轮次, 不能判定为通过
+	if evaluated > 0 {
+		passed = passed && transcript.Completed
+		transcript.Passed = &passed
+	}
+}
+
+// conversationBatch 将对话记录转换为批量测试结果, 每一轮作为一个用例, 用于汇总及报告
+func conversationBatch(result *model.ConversationResult) *model.BatchResult {
+	batch := &model.BatchResult{
+		Models: result.Models,
+		Cases:  make([]*model.BatchCaseResult, 0, len(result.Turns)),
+	}
+	for i, user := range result.Turns {
+		batch.Cases = append(batch.Cases, &model.BatchCaseResult{
+			Case:    model.TestCase{ID: "turn-" + strconv.Itoa(i+1), Prompt: user},
+			Results: make(map[string]*model.ModelResponse),
+		})
+	}
+	for name, transcript := range result.Transcripts {
+		for _, turn := range transcript.Turns {
+			if turn.Index < len(batch.Cases) {
+				batch.Cases[turn.Index].Results[name] = turn.Response
+			}
+		}
+	}
+	return batch
+}
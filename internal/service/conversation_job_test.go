@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+)
+
+func TestRenderConversation(t *testing.T) {
+	turns := func(users ...string) []model.ConversationTurn {
+		out := make([]model.ConversationTurn, len(users))
+		for i, u := range users {
+			out[i] = model.ConversationTurn{User: u}
+		}
+		return out
+	}
+	tests := []struct {
+		name       string
+		req        model.ConversationRequest
+		wantSystem string
+		wantTurns  []string
+		wantErr    bool
+	}{
+		{
+			name:       "rendered",
+			req:        model.ConversationRequest{System: "You help {{ name }}.", Turns: turns("Hi, I am {{ name }}", "Bye"), Variables: map[string]interface{}{"name": "Ann"}},
+			wantSystem: "You help Ann.",
+			wantTurns:  []string{"Hi, I am Ann", "Bye"},
+		},
+		{
+			// 没有变量时按原文发送, 代码片段不会被解析
+			name:       "verbatim without variables",
+			req:        model.ConversationRequest{System: "Use {{ }} in Jinja", Turns: turns("What does {% if x %} do?")},
+			wantSystem: "Use {{ }} in Jinja",
+			wantTurns:  []string{"What does {% if x %} do?"},
+		},
+		{"missing variable", model.ConversationRequest{Turns: turns("Hi {{ name }}"), Variables: map[string]interface{}{"other": 1}}, "", nil, true},
+		{"invalid system", model.ConversationRequest{System: "{{ name", Turns: turns("Hi"), Variables: map[string]interface{}{"name": "Ann"}}, "", nil, true},
+		{"empty turn", model.ConversationRequest{Turns: turns("Hi", "")}, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			system, got, err := renderConversation(&tt.req)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q %v", system, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("renderConversation: %v", err)
+			}
+			if system != tt.wantSystem || !slices.Equal(got, tt.wantTurns) {
+				t.Fatalf("got %q %q, want %q %q", system, got, tt.wantSystem, tt.wantTurns)
+			}
+		})
+	}
+}
+
+// turnResults 按结论构造各轮结果, 每轮耗时100ms、10个token、费用0.5
+func turnResults(passed ...*bool) []*model.TurnResult {
+	out := make([]*model.TurnResult, len(passed))
+	for i, p := range passed {
+		out[i] = &model.TurnResult{Index: i, User: "u", Response: &model.ModelResponse{ResponseTime: 100, TokensUsed: 10, Cost: ptr(0.5), Success: true, Passed: p}}
+	}
+	return out
+}
+
+func TestFinalizeTranscript(t *testing.T) {
+	tests := []struct {
+		name          string
+		turns         []*model.TurnResult
+		turnCount     int
+		wantCompleted bool
+		wantPassed    *bool
+	}{
+		{"all passed", turnResults(ptr(true), ptr(true)), 2, true, ptr(true)},
+		{"one failed", turnResults(ptr(true), ptr(false)), 2, true, ptr(false)},
+		{"no verdicts", turnResults(nil, nil), 2, true, nil},
+		{"partial verdicts", turnResults(nil, ptr(true)), 2, true, ptr(true)},
+		// 未执行完的对话即使已执行的轮次都通过也不能判定为通过
+		{"incomplete", turnResults(ptr(true)), 3, false, ptr(false)},
+		{"incomplete without verdicts", turnResults(nil), 3, false, nil},
+		{"no turns", nil, 2, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 重复汇总时不应累加
+			transcript := &model.Transcript{Turns: tt.turns, ResponseTime: 999, TokensUsed: 999, Cost: ptr(9.0)}
+			finalizeTranscript(transcript, tt.turnCount)
+			if transcript.Completed != tt.wantCompleted {
+				t.Fatalf("completed = %v, want %v", transcript.Completed, tt.wantCompleted)
+			}
+			if (transcript.Passed == nil) != (tt.wantPassed == nil) || (transcript.Passed != nil && *transcript.Passed != *tt.wantPassed) {
+				t.Fatalf("passed = %v, want %v", transcript.Passed, tt.wantPassed)
+			}
+			n := len(tt.turns)
+			if transcript.ResponseTime != int64(100*n) || transcript.TokensUsed != 10*n {
+				t.Fatalf("totals = %dms %d tokens for %d turns", transcript.ResponseTime, transcript.TokensUsed, n)
+			}
+			if (n == 0) != (transcript.Cost == nil) || (n > 0 && *transcript.Cost != 0.5*float64(n)) {
+				t.Fatalf("cost = %v for %d turns", transcript.Cost, n)
+			}
+		})
+	}
+}
+
+func TestConversationBatch(t *testing.T) {
+	result := newConversationResult([]string{"first", "second", "third"}, []model.ModelReq{
+		{Name: "a", Provider: "fake"},
+		{Name: "b", Provider: "fake"},
+	})
+	result.Transcripts["a"].Turns = turnResults(ptr(true), ptr(false), ptr(true))
+	// b在第二轮失败后终止
+	result.Transcripts["b"].Turns = turnResults(ptr(true), nil)
+
+	batch := conversationBatch(result)
+	if !slices.Equal(batch.Models, []string{"a", "b"}) || len(batch.Cases) != 3 {
+		t.Fatalf("unexpected batch: models=%v cases=%d", batch.Models, len(batch.Cases))
+	}
+	for i, want := range []struct {
+		id, prompt string
+		models     int
+	}{{"turn-1", "first", 2}, {"turn-2", "second", 2}, {"turn-3", "third", 1}} {
+		c := batch.Cases[i]
+		if c.Case.ID != want.id || c.Case.Prompt != want.prompt || len(c.Results) != want.models {
+			t.Fatalf("case %d = %+v with %d results, want %s %q with %d", i, c.Case, len(c.Results), want.id, want.prompt, want.models)
+		}
+	}
+	if batch.Cases[1].Results["a"] != result.Transcripts["a"].Turns[1].Response {
+		t.Fatal("turn results are not mapped to their turn")
+	}
+
+	summary := summarizeBatch(batch)
+	if summary["a"].Completed != 3 || summary["b"].Completed != 2 || summary["a"].Passed != 2 {
+		t.Fatalf("summary a=%+v b=%+v", summary["a"], summary["b"])
+	}
+}
+
+func TestSubmitConversationRejectsDuplicateModelNames(t *testing.T) {
+	s := newBatchService(newFakeProvider(""), repository.NewMemoryJobStore())
+	req := &model.ConversationRequest{
+		Turns:  []model.ConversationTurn{{User: "hi"}},
+		Models: []model.ModelReq{{Name: "x", Provider: "fake"}, {Name: "x", Provider: "openai"}},
+	}
+	if _, err := s.SubmitConversation(context.Background(), req); !errors.Is(err, ErrInvalidJob) {
+		t.Fatalf("expected ErrInvalidJob, got %v", err)
+	}
+}
@@ -0,0 +1,447 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/multi-agent-testing/backend/internal/assertion"
+	"github.com/multi-agent-testing/backend/internal/dataset"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrInvalidDatasetChange 数据集或用例修改请求不合法
+	ErrInvalidDatasetChange = errors.New("invalid dataset change")
+	// ErrDatasetUnchanged 工作副本在最新版本之后没有修改, 无需冻结
+	ErrDatasetUnchanged = errors.New("dataset has no changes since the latest version")
+)
+
+// DatasetService 数据集服务
+type DatasetService struct {
+	repo *repository.DatasetRepository
+	jobs repository.JobStore
+}
+
+// NewDatasetService 创建数据集服务
+func NewDatasetService(repo *repository.DatasetRepository, jobs repository.JobStore) *DatasetService {
+	return &DatasetService{
+		repo: repo,
+		jobs: jobs,
+	}
+}
+
+// DatasetDetail 数据集详情
+type DatasetDetail struct {
+	*model.Dataset
+	Cases []*model.DatasetCase `json:"cases"`
+}
+
+// DatasetVersionDetail 数据集版本详情
+type DatasetVersionDetail struct {
+	*model.DatasetVersion
+	Cases []*model.DatasetCase `json:"cases"`
+}
+
+// Create 创建数据集
+func (s *DatasetService) Create(ctx context.Context, req *model.SaveDatasetRequest) (*model.Dataset, error) {
+	if req.Name == "" {
+		return nil, errors.New("dataset name is required")
+	}
+	if err := validateCases(nil, req.Cases); err != nil {
+		return nil, err
+	}
+
+	ds := &model.Dataset{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := s.repo.Create(ctx, ds, req.Cases); err != nil {
+		return nil, fmt.Errorf("failed to create dataset: %w", err)
+	}
+
+	logger.Info("Dataset created",
+		zap.Uint64("dataset_id", ds.ID),
+		zap.Int("case_count", ds.CaseCount),
+	)
+	return ds, nil
+}
+
+// validateCases 校验新增或修改的用例, existing为工作副本中不受本次修改影响的用例, 用于检查用例标识重复
+func validateCases(existing []*model.DatasetCase, cases []model.TestCase) error {
+	keys := make(map[string]bool, len(existing)+len(cases))
+	for _, row := range existing {
+		if row.CaseKey != "" {
+			keys[row.CaseKey] = true
+		}
+	}
+	for i, tc := range cases {
+		if tc.Prompt == "" {
+			return fmt.Errorf("%w: case %d: prompt is required", ErrInvalidDatasetChange, i+1)
+		}
+		if err := assertion.Validate(tc.Assertions); err != nil {
+			return fmt.Errorf("%w: case %d: %v", ErrInvalidDatasetChange, i+1, err)
+		}
+		if tc.Split != "" && !slices.Contains(model.Splits, tc.Split) {
+			return fmt.Errorf("%w: case %d: unsupported split %s", ErrInvalidDatasetChange, i+1, tc.Split)
+		}
+		if tc.ID != "" {
+			if keys[tc.ID] {
+				return fmt.Errorf("%w: case %d: duplicate id %s", ErrInvalidDatasetChange, i+1, tc.ID)
+			}
+			keys[tc.ID] = true
+		}
+	}
+	return nil
+}
+
+// ErrInvalidDataset 导入的数据集文件存在不合法的行
+var ErrInvalidDataset = errors.New("invalid dataset file")
+
+// ImportDatasetRequest 从文件导入数据集的参数
+type ImportDatasetRequest struct {
+	Name        string
+	Description string
+	Filename    string
+	Options     dataset.Options // Format为空时根据文件名推断
+	DryRun      bool            // 只校验不保存
+	SkipInvalid bool            // 跳过不合法的行, 只导入合法的用例
+}
+
+// DatasetImport 数据集导入结果
+type DatasetImport struct {
+	Dataset *model.Dataset  `json:"dataset,omitempty"` // 试导入或导入失败时为空
+	Import  *dataset.Result `json:"import"`
+}
+
+// Import 从JSONL/CSV/OpenAI Evals/promptfoo文件导入数据集
+// 存在不合法的行且未设置SkipInvalid时不保存, 返回包装了ErrInvalidDataset的错误及逐行问题
+func (s *DatasetService) Import(ctx context.Context, req *ImportDatasetRequest, r io.Reader) (*DatasetImport, error) {
+	opts := req.Options
+	if opts.Format == "" {
+		format, err := dataset.DetectFormat(req.Filename)
+		if err != nil {
+			return nil, err
+		}
+		opts.Format = format
+	}
+	result, err := dataset.Import(r, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	imported := &DatasetImport{Import: result}
+	if err := result.Err(); err != nil && !req.SkipInvalid {
+		return imported, fmt.Errorf("%w: %v", ErrInvalidDataset, err)
+	}
+	if result.Rows > 0 && result.Imported == 0 {
+		return imported, fmt.Errorf("%w: no valid cases", ErrInvalidDataset)
+	}
+	if req.DryRun {
+		return imported, nil
+	}
+
+	name := req.Name
+	if name == "" {
+		name = req.Filename
+	}
+	ds, err := s.Create(ctx, &model.SaveDatasetRequest{
+		Name:        name,
+		Description: req.Description,
+		Cases:       result.Cases,
+	})
+	if err != nil {
+		return nil, err
+	}
+	imported.Dataset = ds
+	if len(result.Errors) > 0 {
+		logger.Warn("Dataset imported with invalid rows skipped",
+			zap.Uint64("dataset_id", ds.ID),
+			zap.Int("skipped", len(result.Errors)),
+		)
+	}
+	return imported, nil
+}
+
+// Get 获取数据集详情, 用例为工作副本中满足过滤条件的用例
+func (s *DatasetService) Get(ctx context.Context, id uint64, filter repository.CaseFilter) (*DatasetDetail, error) {
+	ds, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cases, err := s.repo.ListCases(ctx, id, 0, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &DatasetDetail{Dataset: ds, Cases: cases}, nil
+}
+
+// List 分页查询数据集
+func (s *DatasetService) List(ctx context.Context, offset, limit int) ([]*model.Dataset, int64, error) {
+	return s.repo.List(ctx, offset, limit)
+}
+
+// Delete 删除数据集, 冻结的版本保留
+func (s *DatasetService) Delete(ctx context.Context, id uint64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// AppendCases 向数据集工作副本追加用例
+func (s *DatasetService) AppendCases(ctx context.Context, id uint64, req *model.AppendCasesRequest) (*model.Dataset, error) {
+	if len(req.Cases) == 0 {
+		return nil, fmt.Errorf("%w: cases are required", ErrInvalidDatasetChange)
+	}
+	existing, err := s.workingCases(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCases(existing, req.Cases); err != nil {
+		return nil, err
+	}
+	if err := s.repo.AppendCases(ctx, id, req.Cases); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Dataset cases appended",
+		zap.Uint64("dataset_id", id),
+		zap.Int("case_count", len(req.Cases)),
+	)
+	return s.repo.Get(ctx, id)
+}
+
+// UpdateCase 修改工作副本中的用例
+func (s *DatasetService) UpdateCase(ctx context.Context, id, caseID uint64, tc model.TestCase) (*model.DatasetCase, error) {
+	existing, err := s.workingCases(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	idx := slices.IndexFunc(existing, func(row *model.DatasetCase) bool { return row.ID == caseID })
+	if idx < 0 {
+		return nil, repository.ErrNotFound
+	}
+	row := existing[idx]
+	if err := validateCases(slices.Delete(slices.Clone(existing), idx, idx+1), []model.TestCase{tc}); err != nil {
+		return nil, err
+	}
+	if err := row.SetTestCase(tc); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveCases(ctx, id, []*model.DatasetCase{row}); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCase(ctx, id, caseID)
+}
+
+// DeleteCase 删除工作副本中的用例
+func (s *DatasetService) DeleteCase(ctx context.Context, id, caseID uint64) error {
+	return s.repo.DeleteCase(ctx, id, caseID)
+}
+
+// TagCases 批量添加或移除用例标签, 返回标签发生变化的用例
+func (s *DatasetService) TagCases(ctx context.Context, id uint64, req *model.TagCasesRequest) ([]*model.DatasetCase, error) {
+	add, remove := normalizeTagList(req.Add), normalizeTagList(req.Remove)
+	if len(add) == 0 && len(remove) == 0 {
+		return nil, fmt.Errorf("%w: add or remove is required", ErrInvalidDatasetChange)
+	}
+	rows, err := s.workingCases(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.CaseIDs) > 0 {
+		selected := make([]*model.DatasetCase, 0, len(req.CaseIDs))
+		for _, caseID := range req.CaseIDs {
+			idx := slices.IndexFunc(rows, func(row *model.DatasetCase) bool { return row.ID == caseID })
+			if idx < 0 {
+				return nil, fmt.Errorf("%w: case %d not found in dataset", ErrInvalidDatasetChange, caseID)
+			}
+			selected = append(selected, rows[idx])
+		}
+		rows = selected
+	}
+
+	changed := []*model.DatasetCase{}
+	for _, row := range rows {
+		tc := row.ToTestCase()
+		tags := slices.DeleteFunc(slices.Clone(tc.Tags), func(tag string) bool { return slices.Contains(remove, tag) })
+		for _, tag := range add {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		if slices.Equal(tags, tc.Tags) {
+			continue
+		}
+		tc.Tags = tags
+		if err := row.SetTestCase(tc); err != nil {
+			return nil, err
+		}
+		changed = append(changed, row)
+	}
+	if len(changed) > 0 {
+		if err := s.repo.SaveCases(ctx, id, changed); err != nil {
+			return nil, err
+		}
+	}
+	return changed, nil
+}
+
+// normalizeTagList 去除标签首尾空白、空标签及重复标签
+func normalizeTagList(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// Split 按比例随机划分工作副本中的全部用例, 返回各划分的用例数
+// 相同的种子、比例及用例得到相同的划分, 比例按总和归一化, 各划分的用例数按最大余数法取整
+func (s *DatasetService) Split(ctx context.Context, id uint64, req *model.SplitDatasetRequest) (map[string]int, error) {
+	var total float64
+	for name, ratio := range req.Ratios {
+		if !slices.Contains(model.Splits, name) {
+			return nil, fmt.Errorf("%w: unsupported split %s", ErrInvalidDatasetChange, name)
+		}
+		if ratio < 0 || math.IsNaN(ratio) || math.IsInf(ratio, 0) {
+			return nil, fmt.Errorf("%w: ratio of %s must be a non-negative number", ErrInvalidDatasetChange, name)
+		}
+		total += ratio
+	}
+	if total <= 0 {
+		return nil, fmt.Errorf("%w: ratios are required", ErrInvalidDatasetChange)
+	}
+	rows, err := s.workingCases(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: dataset has no cases", ErrInvalidDatasetChange)
+	}
+
+	counts := splitCounts(req.Ratios, total, len(rows))
+	shuffled := slices.Clone(rows)
+	rand.New(rand.NewSource(req.Seed)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	next := 0
+	for _, name := range model.Splits {
+		for range counts[name] {
+			shuffled[next].Split = name
+			next++
+		}
+	}
+	if err := s.repo.SaveCases(ctx, id, rows); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Dataset split",
+		zap.Uint64("dataset_id", id),
+		zap.Any("counts", counts),
+	)
+	return counts, nil
+}
+
+// splitCounts 按比例计算各划分的用例数, 余下的用例依次分给小数部分最大的划分
+func splitCounts(ratios map[string]float64, total float64, n int) map[string]int {
+	counts := make(map[string]int, len(ratios))
+	remainders := make(map[string]float64, len(ratios))
+	assigned := 0
+	for _, name := range model.Splits {
+		ratio, ok := ratios[name]
+		if !ok {
+			continue
+		}
+		exact := ratio / total * float64(n)
+		counts[name] = int(exact)
+		remainders[name] = exact - float64(counts[name])
+		assigned += counts[name]
+	}
+	for ; assigned < n; assigned++ {
+		best := ""
+		for _, name := range model.Splits {
+			if _, ok := counts[name]; ok && (best == "" || remainders[name] > remainders[best]) {
+				best = name
+			}
+		}
+		counts[best]++
+		remainders[best] = -1
+	}
+	return counts
+}
+
+// workingCases 获取工作副本的全部用例, 数据集不存在时返回ErrNotFound
+func (s *DatasetService) workingCases(ctx context.Context, id uint64) ([]*model.DatasetCase, error) {
+	if _, err := s.repo.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListCases(ctx, id, 0, repository.CaseFilter{})
+}
+
+// Freeze 将工作副本冻结为新版本, 批量任务可通过dataset_version引用该版本
+func (s *DatasetService) Freeze(ctx context.Context, id uint64, note string) (*model.DatasetVersion, error) {
+	ds, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ds.Version > 0 && !ds.Modified {
+		return nil, fmt.Errorf("%w (version %d)", ErrDatasetUnchanged, ds.Version)
+	}
+	if ds.CaseCount == 0 {
+		return nil, fmt.Errorf("%w: dataset has no cases", ErrInvalidDatasetChange)
+	}
+	version, err := s.repo.Freeze(ctx, id, note)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Dataset version frozen",
+		zap.Uint64("dataset_id", id),
+		zap.Int("version", version.Version),
+		zap.Int("case_count", version.CaseCount),
+	)
+	return version, nil
+}
+
+// ListVersions 列出数据集的全部版本
+func (s *DatasetService) ListVersions(ctx context.Context, id uint64) ([]*model.DatasetVersion, error) {
+	versions, err := s.repo.ListVersions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		// 区分数据集不存在与尚未冻结
+		if _, err := s.repo.Get(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	return versions, nil
+}
+
+// GetVersion 获取数据集版本及其用例快照
+func (s *DatasetService) GetVersion(ctx context.Context, id uint64, version int, filter repository.CaseFilter) (*DatasetVersionDetail, error) {
+	v, err := s.repo.GetVersion(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	cases, err := s.repo.ListCases(ctx, id, version, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &DatasetVersionDetail{DatasetVersion: v, Cases: cases}, nil
+}
+
+// ListRuns 分页查询使用数据集的批量任务, version不为0时只查询使用该版本的任务
+// 使用同一版本的任务用例相同, 结果可直接比较
+func (s *DatasetService) ListRuns(ctx context.Context, id uint64, version int, offset, limit int) ([]*model.Job, int64, error) {
+	return s.jobs.List(ctx, repository.JobFilter{DatasetID: id, DatasetVersion: version}, offset, limit)
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+)
+
+// HistoryService 测试历史记录服务
+type HistoryService struct {
+	repo *repository.TestRecordRepository
+}
+
+// NewHistoryService 创建测试历史记录服务
+func NewHistoryService(repo *repository.TestRecordRepository) *HistoryService {
+	return &HistoryService{
+		repo: repo,
+	}
+}
+
+// Record 保存一次测试的请求与结果
+func (s *HistoryService) Record(ctx context.Context, req *model.TestRequest, result *model.TestResult) (*model.TestRecord, error) {
+	prompts, err := model.NewJSONField(req.Prompts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode prompts: %w", err)
+	}
+	models, err := model.NewJSONArray(req.Models)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode models: %w", err)
+	}
+	results, err := model.NewJSONField(result.Results)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode results: %w", err)
+	}
+
+	names := make([]string, 0, len(req.Models))
+	providers := make([]string, 0, len(req.Models))
+	seen := make(map[string]bool)
+	for _, m := range req.Models {
+		names = append(names, m.Name)
+		if !seen[m.Provider] {
+			seen[m.Provider] = true
+			providers = append(providers, m.Provider)
+		}
+	}
+	successCount := countSuccessful(result.Results)
+
+	record := &model.TestRecord{
+		Prompts:      prompts,
+		Models:       models,
+		Results:      results,
+		ModelNames:   joinForFilter(names),
+		Providers:    joinForFilter(providers),
+		ModelCount:   len(req.Models),
+		SuccessCount: successCount,
+		Success:      successCount == len(req.Models),
+		Duration:     result.Duration,
+
+		TemplateID:      req.TemplateID,
+		TemplateVersion: req.TemplateVersion,
+	}
+	if err := s.repo.Create(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to save test record: %w", err)
+	}
+	return record, nil
+}
+
+// Get 获取测试记录
+func (s *HistoryService) Get(ctx context.Context, id uint64) (*model.TestRecord, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// List 按条件分页查询测试记录
+func (s *HistoryService) List(ctx context.Context, filter repository.HistoryFilter) ([]*model.TestRecord, int64, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Delete 删除测试记录
+func (s *HistoryService) Delete(ctx context.Context, id uint64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// joinForFilter 以逗号包裹拼接, 便于使用LIKE '%,name,%'精确匹配
+func joinForFilter(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return "," + strings.Join(values, ",") + ","
+}
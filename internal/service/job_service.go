@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrJobFinished 任务已结束, 无法取消
+	ErrJobFinished = errors.New("job already finished")
+	// ErrJobNotResumable 任务无法恢复执行
+	ErrJobNotResumable = errors.New("job cannot be resumed")
+	// ErrJobRunning 任务已在本进程中运行
+	ErrJobRunning = errors.New("job is already running")
+	// ErrInvalidJob 任务请求不合法, 区别于保存任务等服务端错误
+	ErrInvalidJob = errors.New("invalid job request")
+	// errShutdown 服务关闭导致的取消, 批量任务保持运行中状态, 重启后从检查点恢复
+	errShutdown = errors.New("server shutting down")
+)
+
+// JobService 异步任务服务
+type JobService struct {
+	store       repository.JobStore
+	testService *MultiModelService
+	datasets    *repository.DatasetRepository // 未启用数据库时为nil
+
+	mu      sync.Mutex
+	cancels map[string]context.CancelCauseFunc // 本进程中运行的任务
+	wg      sync.WaitGroup
+}
+
+// NewJobService 创建异步任务服务
+func NewJobService(store repository.JobStore, testService *MultiModelService, datasets *repository.DatasetRepository) *JobService {
+	return &JobService{
+		store:       store,
+		testService: testService,
+		datasets:    datasets,
+		cancels:     make(map[string]context.CancelCauseFunc),
+	}
+}
+
+// Recover 处理上次进程退出时未结束的任务, 批量任务从检查点恢复, 其余任务标记为失败
+func (s *JobService) Recover(ctx context.Context) error {
+	unfinished := []*model.Job{}
+	for _, status := range []string{model.JobStatusPending, model.JobStatusRunning} {
+		jobs, _, err := s.store.List(ctx, repository.JobFilter{Status: status}, 0, 0)
+		if err != nil {
+			return fmt.Errorf("failed to list unfinished jobs: %w", err)
+		}
+		unfinished = append(unfinished, jobs...)
+	}
+
+	for _, job := range unfinished {
+		if job.Type == model.JobTypeBatch {
+			_, err := s.Resume(ctx, job.ID)
+			if err == nil {
+				logger.Info("Batch job resumed after restart", zap.String("job_id", job.ID))
+				continue
+			}
+			logger.Error("Failed to resume batch job", zap.String("job_id", job.ID), zap.Error(err))
+		}
+		s.finish(ctx, job, model.JobStatusFailed, "interrupted by server restart")
+		logger.Warn("Job interrupted by restart", zap.String("job_id", job.ID))
+	}
+	return nil
+}
+
+// SubmitTest 提交异步多模型测试任务, 立即返回任务信息
+func (s *JobService) SubmitTest(ctx context.Context, req *model.TestRequest) (*model.Job, error) {
+	if req.Blind {
+		return nil, fmt.Errorf("%w: blind mode is not supported for async jobs", ErrInvalidJob)
+	}
+	if err := s.testService.PrepareRequest(ctx, req); err != nil {
+		if errors.Is(err, ErrInvalidRequest) {
+			return nil, invalidJob(err)
+		}
+		return nil, err
+	}
+
+	request, err := model.NewJSONField(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	now := time.Now()
+	job := &model.Job{
+		ID:        uuid.NewString(),
+		Type:      model.JobTypeTest,
+		Status:    model.JobStatusPending,
+		Total:     len(req.Models),
+		Request:   request,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.store.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	if err := s.launch(job, func(ctx context.Context, job *model.Job) {
+		s.runTest(ctx, job, req)
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Test job submitted",
+		zap.String("job_id", job.ID),
+		zap.Int("model_count", len(req.Models)),
+	)
+
+	return job, nil
+}
+
+// invalidJob 将请求校验错误包装为ErrInvalidJob
+func invalidJob(err error) error {
+	return fmt.Errorf("%w: %w", ErrInvalidJob, err)
+}
+
+// validateModelNames 校验模型名称不重复, 任务结果按模型名称索引, 不同提供者的同名模型会相互覆盖
+func validateModelNames(models []model.ModelReq) error {
+	providers := make(map[string]string, len(models))
+	for _, m := range models {
+		if provider, ok := providers[m.Name]; ok {
+			return fmt.Errorf("duplicate model name %s (%s and %s)", m.Name, provider, m.Provider)
+		}
+		providers[m.Name] = m.Provider
+	}
+	return nil
+}
+
+// launch 在后台运行任务, 任务可通过Cancel取消
+// 检查与登记在同一把锁内完成, 同一任务已在运行时返回ErrJobRunning, 避免并发恢复重复执行
+func (s *JobService) launch(job *model.Job, run func(ctx context.Context, job *model.Job)) error {
+	s.mu.Lock()
+	if _, running := s.cancels[job.ID]; running {
+		s.mu.Unlock()
+		return ErrJobRunning
+	}
+	ctx, cancel := context.WithCancelCause(context.Background())
+	s.cancels[job.ID] = cancel
+	s.mu.Unlock()
+
+	copied := *job
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.release(copied.ID)
+		run(ctx, &copied)
+	}()
+	return nil
+}
+
+// runTest 执行测试任务并持续记录进度
+func (s *JobService) runTest(ctx context.Context, job *model.Job, req *model.TestRequest) {
+	// 仅用于持久化, 不受任务取消影响
+	storeCtx := context.Background()
+
+	var mu sync.Mutex
+	partial := &model.TestResult{
+		Results:   make(map[string]*model.ModelResponse),
+		StartTime: time.Now(),
+	}
+	// 取消后失败的模型调用通常由取消导致, 此时结果不完整
+	interrupted := false
+
+	job.Status = model.JobStatusRunning
+	job.UpdatedAt = time.Now()
+	if err := s.store.Update(storeCtx, job); err != nil {
+		logger.Error("Failed to update job", zap.String("job_id", job.ID), zap.Error(err))
+	}
+
+	result, err := s.testService.ExecuteTestWithProgress(ctx, req, func(resp *model.ModelResponse) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		partial.Results[resp.ModelName] = resp
+		if ctx.Err() != nil {
+			interrupted = interrupted || !resp.Success
+			return
+		}
+		job.Completed = len(partial.Results)
+		s.saveResult(storeCtx, job, partial)
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// 先检查是否已得到完整结果, 测试完成后才到达的取消不应丢弃结果
+	if err == nil && !interrupted {
+		job.Completed = len(result.Results)
+		s.saveResult(storeCtx, job, result)
+		s.finish(storeCtx, job, model.JobStatusSucceeded, "")
+		return
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		s.saveResult(storeCtx, job, partial)
+		s.finish(storeCtx, job, model.JobStatusCanceled, "")
+		return
+	}
+	s.finish(storeCtx, job, model.JobStatusFailed, err.Error())
+}
+
+// saveResult 保存任务(部分)结果
+func (s *JobService) saveResult(ctx context.Context, job *model.Job, result interface{}) {
+	field, err := model.NewJSONField(result)
+	if err != nil {
+		logger.Error("Failed to encode job result", zap.String("job_id", job.ID), zap.Error(err))
+		return
+	}
+	job.Result = field
+	job.UpdatedAt = time.Now()
+	if err := s.store.Update(ctx, job); err != nil {
+		logger.Error("Failed to update job", zap.String("job_id", job.ID), zap.Error(err))
+	}
+}
+
+// finish 将任务置为结束状态
+func (s *JobService) finish(ctx context.Context, job *model.Job, status, errMsg string) {
+	now := time.Now()
+	job.Status = status
+	job.Error = errMsg
+	job.UpdatedAt = now
+	job.FinishedAt = &now
+	if err := s.store.Update(ctx, job); err != nil {
+		logger.Error("Failed to update job", zap.String("job_id", job.ID), zap.Error(err))
+		return
+	}
+
+	logger.Info("Job finished",
+		zap.String("job_id", job.ID),
+		zap.String("status", status),
+		zap.Int("completed", job.Completed),
+		zap.Int("total", job.Total),
+	)
+}
+
+// release 释放任务取消函数
+func (s *JobService) release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cancel, ok := s.cancels[id]; ok {
+		cancel(nil)
+		delete(s.cancels, id)
+	}
+}
+
+// Get 获取任务状态
+func (s *JobService) Get(ctx context.Context, id string) (*model.Job, error) {
+	return s.store.Get(ctx, id)
+}
+
+// List 按条件分页查询任务
+func (s *JobService) List(ctx context.Context, filter repository.JobFilter, offset, limit int) ([]*model.Job, int64, error) {
+	return s.store.List(ctx, filter, offset, limit)
+}
+
+// Cancel 取消任务, 通过context取消正在进行的模型调用
+// 运行中的任务返回当前保存的状态, 最终状态由运行的goroutine写入, 取消前已完成的任务仍记为成功
+func (s *JobService) Cancel(ctx context.Context, id string) (*model.Job, error) {
+	job, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.IsFinished() {
+		return job, ErrJobFinished
+	}
+
+	s.mu.Lock()
+	cancel, running := s.cancels[id]
+	s.mu.Unlock()
+
+	if running {
+		// 由运行中的goroutine负责写入最终状态
+		cancel(nil)
+		logger.Info("Job cancel requested", zap.String("job_id", id))
+		return job, nil
+	}
+
+	// 任务不在本进程中运行, 直接标记为已取消
+	s.finish(ctx, job, model.JobStatusCanceled, "")
+	return job, nil
+}
+
+// Shutdown 取消所有运行中的任务, 并等待任务保存进度直到ctx结束
+// 批量任务保持运行中状态, 下次启动时由Recover从检查点恢复
+func (s *JobService) Shutdown(ctx context.Context) {
+	s.mu.Lock()
+	for id, cancel := range s.cancels {
+		cancel(errShutdown)
+		logger.Info("Job canceled on shutdown", zap.String("job_id", id))
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logger.Warn("Timed out waiting for jobs to stop")
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+)
+
+func TestLaunchRejectsRunningJob(t *testing.T) {
+	s := NewJobService(repository.NewMemoryJobStore(), nil, nil)
+	job := &model.Job{ID: "job-1"}
+	release := make(chan struct{})
+	var runs sync.WaitGroup
+	run := func(ctx context.Context, job *model.Job) {
+		runs.Done()
+		<-release
+	}
+
+	const attempts = 8
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		started int
+	)
+	runs.Add(1)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.launch(job, run)
+			switch {
+			case err == nil:
+				mu.Lock()
+				started++
+				mu.Unlock()
+			case !errors.Is(err, ErrJobRunning):
+				t.Errorf("launch: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	runs.Wait()
+	if started != 1 {
+		t.Fatalf("job started %d times, want 1", started)
+	}
+
+	// 结束后可再次运行
+	close(release)
+	s.wg.Wait()
+	runs.Add(1)
+	if err := s.launch(job, func(ctx context.Context, job *model.Job) { runs.Done() }); err != nil {
+		t.Fatalf("launch after the job finished: %v", err)
+	}
+	runs.Wait()
+	s.wg.Wait()
+}
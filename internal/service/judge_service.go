@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/judge"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrInvalidJudge 评审配置不合法
+var ErrInvalidJudge = errors.New("invalid judge")
+
+// JudgeService 评审服务, 通过现有的提供者调用评审模型为输出打分
+// 评审结果按评审模型及提示词缓存, 费用记录在评审结果中, 与被测模型分开统计
+type JudgeService struct {
+	models   *MultiModelService
+	cache    *judge.Cache // 关闭缓存时为nil
+	inflight singleflight.Group
+}
+
+// NewJudgeService 创建评审服务, cacheSize为0时使用默认容量, 负数关闭缓存
+func NewJudgeService(models *MultiModelService, cacheSize int) *JudgeService {
+	if cacheSize == 0 {
+		cacheSize = judge.DefaultCacheSize
+	}
+	return &JudgeService{
+		models: models,
+		cache:  judge.NewCache(cacheSize),
+	}
+}
+
+// Validate 校验评审配置, 评分标准及评审模型均需可用
+func (s *JudgeService) Validate(req *model.JudgeRequest) error {
+	_, _, err := s.resolve(req)
+	return err
+}
+
+// resolve 解析评审请求使用的评分标准及评审模型
+func (s *JudgeService) resolve(req *model.JudgeRequest) (model.Rubric, model.ModelReq, error) {
+	cfg := s.models.currentConfig().Judge
+	rubric, err := resolveRubric(cfg, req)
+	if err != nil {
+		return rubric, model.ModelReq{}, err
+	}
+	rubric, err = judge.Normalize(rubric)
+	if err != nil {
+		return rubric, model.ModelReq{}, fmt.Errorf("%w: %v", ErrInvalidJudge, err)
+	}
+	judgeModel, err := s.resolveModel(cfg, req)
+	return rubric, judgeModel, err
+}
+
+// resolveRubric 解析评审请求中的评分标准, 未指定时返回错误
+func resolveRubric(cfg config.JudgeConfig, req *model.JudgeRequest) (model.Rubric, error) {
+	switch {
+	case req.InlineRubric != nil && req.Rubric != "":
+		return model.Rubric{}, fmt.Errorf("%w: rubric and inline_rubric are mutually exclusive", ErrInvalidJudge)
+	case req.InlineRubric != nil:
+		return *req.InlineRubric, nil
+	case req.Rubric != "":
+		found, ok := findRubric(cfg.Rubrics, req.Rubric)
+		if !ok {
+			return model.Rubric{}, fmt.Errorf("%w: rubric %s not found", ErrInvalidJudge, req.Rubric)
+		}
+		return found, nil
+	default:
+		return model.Rubric{}, fmt.Errorf("%w: rubric or inline_rubric is required", ErrInvalidJudge)
+	}
+}
+
+// resolveModel 解析评审模型, 请求中未指定时使用配置文件中的默认评审模型
+func (s *JudgeService) resolveModel(cfg config.JudgeConfig, req *model.JudgeRequest) (model.ModelReq, error) {
+	judgeModel := model.ModelReq{Provider: req.Provider, Name: req.Model}
+	if judgeModel.Provider == "" && judgeModel.Name == "" {
+		judgeModel = model.ModelReq{Provider: cfg.Provider, Name: cfg.Model}
+	}
+	if judgeModel.Provider == "" || judgeModel.Name == "" {
+		return judgeModel, fmt.Errorf("%w: judge model is not configured, set judge.provider and judge.model", ErrInvalidJudge)
+	}
+	if err := s.models.ValidateModels([]model.ModelReq{judgeModel}); err != nil {
+		return judgeModel, fmt.Errorf("%w: %v", ErrInvalidJudge, err)
+	}
+	return judgeModel, nil
+}
+
+// findRubric 按名称查找配置文件中的评分标准
+func findRubric(rubrics []config.RubricConfig, name string) (model.Rubric, bool) {
+	for _, r := range rubrics {
+		if r.Name == name {
+			return model.Rubric{
+				Name:      r.Name,
+				Type:      r.Type,
+				Criteria:  r.Criteria,
+				MinScore:  r.MinScore,
+				MaxScore:  r.MaxScore,
+				PassScore: r.PassScore,
+				Template:  r.Template,
+			}, true
+		}
+	}
+	return model.Rubric{}, false
+}
+
+// Evaluate 评审单个模型输出, 结果写入resp.Judge, 评分标准可判定通过时合并到总体结论
+// reference为默认参考答案, 请求中设置的reference优先
+func (s *JudgeService) Evaluate(ctx context.Context, req *model.JudgeRequest, prompts model.PromptSet, reference string, resp *model.ModelResponse) {
+	if req == nil {
+		return
+	}
+	if req.Reference != "" {
+		reference = req.Reference
+	}
+
+	rubric, judgeModel, err := s.resolve(req)
+	result := &model.JudgeResult{
+		Rubric:   rubric.Name,
+		Provider: judgeModel.Provider,
+		Model:    judgeModel.Name,
+	}
+	resp.Judge = result
+	decisive := rubric.Type == model.RubricTypePassFail || rubric.PassScore != 0
+	if err != nil {
+		result.Error = err.Error()
+		setVerdict(resp, false)
+		return
+	}
+	if !resp.Success {
+		result.Error = "model call failed"
+		if decisive {
+			setVerdict(resp, false)
+		}
+		return
+	}
+
+	judgePrompts, err := judge.BuildPrompt(rubric, judge.Input{
+		Prompt:    prompts.User,
+		Output:    resp.Content,
+		Reference: reference,
+	})
+	if err != nil {
+		result.Error = err.Error()
+		setVerdict(resp, false)
+		return
+	}
+
+	// 命中缓存或复用并发中相同评审的结果时不产生费用
+	key := judge.Key(judgeModel.Provider, judgeModel.Name, rubric, judgePrompts)
+	cached, ok := s.cache.Get(key)
+	if !ok {
+		executed := false
+		v, _, _ := s.inflight.Do(key, func() (interface{}, error) {
+			executed = true
+			out := *result
+			s.call(ctx, rubric, judgeModel, judgePrompts, &out)
+			if out.Error == "" {
+				s.cache.Put(key, out)
+			}
+			return out, nil
+		})
+		cached, ok = v.(model.JudgeResult), !executed
+	}
+	*result = cached
+	if ok {
+		result.Cached = true
+		result.TokensUsed = 0
+		result.Cost = nil
+	}
+
+	if result.Passed != nil {
+		setVerdict(resp, *result.Passed)
+	} else if result.Error != "" && decisive {
+		setVerdict(resp, false)
+	}
+}
+
+// call 调用评审模型并解析结果
+func (s *JudgeService) call(ctx context.Context, rubric model.Rubric, judgeModel model.ModelReq, prompts model.PromptSet, result *model.JudgeResult) {
+	out := s.models.CallModel(ctx, prompts, judgeModel)
+	result.TokensUsed = out.TokensUsed
+	result.Cost = out.Cost
+	if !out.Success {
+		result.Error = "judge call failed: " + out.Error
+		return
+	}
+	if err := judge.Parse(rubric, out.Content, result); err != nil {
+		result.Error = err.Error()
+		logger.Warn("Failed to parse judge output",
+			zap.String("provider", judgeModel.Provider),
+			zap.String("model", judgeModel.Name),
+			zap.Error(err),
+		)
+	}
+}
+
+// setVerdict 将结论合并到响应的总体结论
+func setVerdict(resp *model.ModelResponse, passed bool) {
+	if resp.Passed != nil {
+		passed = passed && *resp.Passed
+	} else {
+		passed = passed && resp.Success
+	}
+	resp.Passed = &passed
+}
+
+// ValidatePairwise 校验成对对比的评审配置, 评分标准可选
+func (s *JudgeService) ValidatePairwise(req *model.JudgeRequest) error {
+	_, _, err := s.resolveCriteria(req)
+	return err
+}
+
+// resolveCriteria 解析成对对比或模拟对话评估的评分要求及评审模型, 未指定评分标准时评分要求为空
+func (s *JudgeService) resolveCriteria(req *model.JudgeRequest) (string, model.ModelReq, error) {
+	cfg := s.models.currentConfig().Judge
+	criteria := ""
+	if req.Rubric != "" || req.InlineRubric != nil {
+		rubric, err := resolveRubric(cfg, req)
+		if err != nil {
+			return "", model.ModelReq{}, err
+		}
+		if strings.TrimSpace(rubric.Criteria) == "" {
+			return "", model.ModelReq{}, fmt.Errorf("%w: rubric %s has no criteria", ErrInvalidJudge, rubric.Name)
+		}
+		criteria = rubric.Criteria
+	}
+	judgeModel, err := s.resolveModel(cfg, req)
+	return criteria, judgeModel, err
+}
+
+// Compare 评审a、b两个输出的优劣, swap为true时交换位置再评审一次, 两次结论不一致记为平局
+func (s *JudgeService) Compare(ctx context.Context, req *model.JudgeRequest, prompts model.PromptSet, a, b *model.ModelResponse, swap bool) *model.PairwiseResult {
+	result := &model.PairwiseResult{ModelA: a.ModelName, ModelB: b.ModelName}
+	criteria, judgeModel, err := s.resolveCriteria(req)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	orders := [][2]*model.ModelResponse{{a, b}}
+	if swap {
+		orders = append(orders, [2]*model.ModelResponse{b, a})
+	}
+	rounds := make([]pairwiseRound, len(orders))
+	var wg sync.WaitGroup
+	for i, order := range orders {
+		wg.Add(1)
+		go func(i int, first, second *model.ModelResponse) {
+			defer wg.Done()
+			rounds[i] = s.comparePair(ctx, criteria, judgeModel, prompts, req.Reference, first, second)
+		}(i, order[0], order[1])
+	}
+	wg.Wait()
+
+	for _, round := range rounds {
+		result.TokensUsed += round.tokens
+		result.Cost = addCost(result.Cost, round.cost)
+		if round.err != nil && result.Error == "" {
+			result.Error = round.err.Error()
+		}
+	}
+	if result.Error != "" {
+		return result
+	}
+
+	result.Winner, result.Reason = rounds[0].winner, rounds[0].reason
+	if swap {
+		// 第二轮中A为b, 换算回原始位置
+		second := rounds[1].winner
+		switch second {
+		case model.WinnerA:
+			second = model.WinnerB
+		case model.WinnerB:
+			second = model.WinnerA
+		}
+		consistent := second == result.Winner
+		result.Consistent = &consistent
+		if !consistent {
+			result.Winner = model.WinnerTie
+			result.Reason = "inconsistent verdicts after swapping positions: " + rounds[0].reason + " / " + rounds[1].reason
+		}
+	}
+	return result
+}
+
+// pairwiseRound 单轮成对评审的结果, winner为呈现顺序中的胜者
+type pairwiseRound struct {
+	winner string
+	reason string
+	tokens int
+	cost   *float64
+	err    error
+}
+
+// comparePair 按first为A、second为B的顺序调用评审模型
+func (s *JudgeService) comparePair(ctx context.Context, criteria string, judgeModel model.ModelReq, prompts model.PromptSet, reference string, first, second *model.ModelResponse) pairwiseRound {
+	judgePrompts, err := judge.BuildPairwisePrompt(criteria, judge.PairwiseInput{
+		Prompt:    prompts.User,
+		OutputA:   first.Content,
+		OutputB:   second.Content,
+		Reference: reference,
+	})
+	if err != nil {
+		return pairwiseRound{err: err}
+	}
+
+	out := s.models.CallModel(ctx, judgePrompts, judgeModel)
+	round := pairwiseRound{tokens: out.TokensUsed, cost: out.Cost}
+	if !out.Success {
+		round.err = errors.New("judge call failed: " + out.Error)
+		return round
+	}
+	round.winner, round.reason, round.err = judge.ParsePairwise(out.Content)
+	if round.err != nil {
+		logger.Warn("Failed to parse pairwise judge output",
+			zap.String("provider", judgeModel.Provider),
+			zap.String("model", judgeModel.Name),
+			zap.Error(round.err),
+		)
+	}
+	return round
+}
+
+// ValidateSimulation 校验模拟对话评估的评审配置, 评分标准可选
+func (s *JudgeService) ValidateSimulation(req *model.JudgeRequest) error {
+	_, _, err := s.resolveCriteria(req)
+	return err
+}
+
+// EvaluateSimulation 评估模拟用户对话, 判断目标是否达成、所用轮数及被测模型的语气
+// 评分标准可选, 其评分要求作为语气的评分要求
+func (s *JudgeService) EvaluateSimulation(ctx context.Context, req *model.JudgeRequest, in judge.SimulationInput) *model.SimulationEvaluation {
+	criteria, judgeModel, err := s.resolveCriteria(req)
+	result := &model.SimulationEvaluation{Provider: judgeModel.Provider, Model: judgeModel.Name}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	judgePrompts, err := judge.BuildSimulationPrompt(criteria, in)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	out := s.models.CallModel(ctx, judgePrompts, judgeModel)
+	result.TokensUsed = out.TokensUsed
+	result.Cost = out.Cost
+	if !out.Success {
+		result.Error = "judge call failed: " + out.Error
+		return result
+	}
+	if err := judge.ParseSimulation(out.Content, len(in.Turns), result); err != nil {
+		result.Error = err.Error()
+		logger.Warn("Failed to parse simulation judge output",
+			zap.String("provider", judgeModel.Provider),
+			zap.String("model", judgeModel.Name),
+			zap.Error(err),
+		)
+	}
+	return result
+}
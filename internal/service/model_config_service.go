@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/secret"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidModelConfig 模型配置不合法
+var ErrInvalidModelConfig = errors.New("invalid model config")
+
+// ModelConfigService 模型配置管理服务, 保存后立即重建对应的提供者
+// API Key使用主密钥加密保存, 接口仅返回脱敏后的值
+type ModelConfigService struct {
+	repo        *repository.ModelConfigRepository
+	testService *MultiModelService
+	keyring     *secret.Keyring // 未配置主密钥时为nil, 此时不允许保存API Key
+}
+
+// NewModelConfigService 创建模型配置管理服务
+func NewModelConfigService(repo *repository.ModelConfigRepository, testService *MultiModelService, keyring *secret.Keyring) *ModelConfigService {
+	return &ModelConfigService{
+		repo:        repo,
+		testService: testService,
+		keyring:     keyring,
+	}
+}
+
+// Load 启动时加载数据库中的全部模型配置, 配置了主密钥时顺带加密遗留的明文API Key
+func (s *ModelConfigService) Load(ctx context.Context) error {
+	configs, err := s.repo.List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to load model configs: %w", err)
+	}
+	for _, cfg := range configs {
+		if cfg.ApiKey != "" && !secret.IsEncrypted(cfg.ApiKey) {
+			if err := s.encryptLegacy(ctx, cfg); err != nil {
+				logger.Warn("Model config API key is stored in plaintext",
+					zap.String("provider", cfg.Provider),
+					zap.String("model", cfg.ModelName),
+					zap.Error(err),
+				)
+			}
+		}
+
+		plain, err := s.decrypt(cfg)
+		if err == nil {
+			err = s.testService.ApplyModelConfig(plain)
+		}
+		if err != nil {
+			logger.Error("Failed to apply model config",
+				zap.String("provider", cfg.Provider),
+				zap.String("model", cfg.ModelName),
+				zap.Error(err),
+			)
+		}
+	}
+	return nil
+}
+
+// encryptLegacy 加密明文保存的API Key
+func (s *ModelConfigService) encryptLegacy(ctx context.Context, cfg *model.ModelConfigEntity) error {
+	if s.keyring == nil {
+		return secret.ErrNoKeyring
+	}
+	plain := cfg.ApiKey
+	if err := s.seal(cfg, plain); err != nil {
+		return err
+	}
+	if err := s.repo.UpdateAPIKey(ctx, cfg.ID, cfg.ApiKey, cfg.ApiKeyHint); err != nil {
+		return err
+	}
+	logger.Info("Encrypted plaintext API key",
+		zap.String("provider", cfg.Provider),
+		zap.String("model", cfg.ModelName),
+		zap.String("api_key", cfg.ApiKeyHint),
+	)
+	return nil
+}
+
+// Create 创建模型配置, enabled未指定时默认启用
+func (s *ModelConfigService) Create(ctx context.Context, req *model.SaveModelConfigRequest) (*model.ModelConfigEntity, error) {
+	plain := &model.ModelConfigEntity{
+		Provider:      req.Provider,
+		ModelName:     req.ModelName,
+		ApiKey:        req.ApiKey,
+		BaseURL:       req.BaseURL,
+		DefaultConfig: req.DefaultConfig,
+		Enabled:       req.Enabled == nil || *req.Enabled,
+	}
+	if err := s.testService.CheckModelConfig(plain); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidModelConfig, err)
+	}
+
+	entity := *plain
+	if err := s.seal(&entity, req.ApiKey); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, &entity); err != nil {
+		return nil, err
+	}
+
+	plain.ID = entity.ID
+	if err := s.testService.ApplyModelConfig(plain); err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// Update 更新模型配置
+// api_key为空时保留原值, 但修改提供者或base_url时必须重新填写, 避免已保存的密钥被发往其他地址
+func (s *ModelConfigService) Update(ctx context.Context, id uint64, req *model.SaveModelConfigRequest) (*model.ModelConfigEntity, error) {
+	old, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	entity := &model.ModelConfigEntity{
+		ID:            id,
+		Provider:      req.Provider,
+		ModelName:     req.ModelName,
+		ApiKey:        old.ApiKey,
+		ApiKeyHint:    old.ApiKeyHint,
+		BaseURL:       req.BaseURL,
+		DefaultConfig: req.DefaultConfig,
+		Enabled:       old.Enabled,
+		CreatedAt:     old.CreatedAt,
+	}
+	if req.Enabled != nil {
+		entity.Enabled = *req.Enabled
+	}
+	if req.ApiKey != "" {
+		if err := s.seal(entity, req.ApiKey); err != nil {
+			return nil, err
+		}
+	} else if old.ApiKey != "" && (old.Provider != req.Provider || old.BaseURL != req.BaseURL) {
+		return nil, fmt.Errorf("%w: api_key must be re-entered when provider or base_url changes", ErrInvalidModelConfig)
+	}
+
+	plain, err := s.decrypt(entity)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.testService.CheckModelConfig(plain); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidModelConfig, err)
+	}
+	if err := s.repo.Update(ctx, entity); err != nil {
+		return nil, err
+	}
+
+	if old.Provider != entity.Provider || old.ModelName != entity.ModelName {
+		s.testService.RemoveModelConfig(old.Provider, old.ModelName)
+	}
+	if err := s.testService.ApplyModelConfig(plain); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, id)
+}
+
+// SetEnabled 启用或禁用模型配置
+func (s *ModelConfigService) SetEnabled(ctx context.Context, id uint64, enabled bool) (*model.ModelConfigEntity, error) {
+	entity, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := s.decrypt(entity)
+	if err != nil {
+		return nil, err
+	}
+	plain.Enabled = enabled
+	if err := s.testService.CheckModelConfig(plain); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidModelConfig, err)
+	}
+	if err := s.repo.SetEnabled(ctx, id, enabled); err != nil {
+		return nil, err
+	}
+	if err := s.testService.ApplyModelConfig(plain); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, id)
+}
+
+// seal 加密API Key并生成脱敏值
+func (s *ModelConfigService) seal(entity *model.ModelConfigEntity, apiKey string) error {
+	if apiKey == "" {
+		entity.ApiKey, entity.ApiKeyHint = "", ""
+		return nil
+	}
+	if s.keyring == nil {
+		return fmt.Errorf("%w: cannot store api_key, %v", ErrInvalidModelConfig, secret.ErrNoKeyring)
+	}
+	encrypted, err := s.keyring.Encrypt(apiKey)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt api key: %w", err)
+	}
+	entity.ApiKey, entity.ApiKeyHint = encrypted, secret.Mask(apiKey)
+	return nil
+}
+
+// decrypt 返回API Key解密后的配置副本, 用于创建提供者
+func (s *ModelConfigService) decrypt(entity *model.ModelConfigEntity) (*model.ModelConfigEntity, error) {
+	plain := *entity
+	if !secret.IsEncrypted(plain.ApiKey) {
+		return &plain, nil
+	}
+	if s.keyring == nil {
+		return nil, fmt.Errorf("cannot decrypt api key: %w", secret.ErrNoKeyring)
+	}
+	apiKey, err := s.keyring.Decrypt(plain.ApiKey)
+	if err != nil {
+		return nil, err
+	}
+	plain.ApiKey = apiKey
+	return &plain, nil
+}
+
+// Get 获取模型配置
+func (s *ModelConfigService) Get(ctx context.Context, id uint64) (*model.ModelConfigEntity, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// List 列出模型配置
+func (s *ModelConfigService) List(ctx context.Context, provider string) ([]*model.ModelConfigEntity, error) {
+	return s.repo.List(ctx, provider)
+}
+
+// Delete 删除模型配置, 该模型回退到配置文件中的提供者
+func (s *ModelConfigService) Delete(ctx context.Context, id uint64) error {
+	entity, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.testService.RemoveModelConfig(entity.Provider, entity.ModelName)
+	return nil
+}
+
+// RotateAPIKeys 使用当前主密钥重新加密全部API Key, 明文保存的API Key一并加密, 返回更新的数量
+func RotateAPIKeys(ctx context.Context, repo *repository.ModelConfigRepository, keyring *secret.Keyring) (int, error) {
+	configs, err := repo.List(ctx, "")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list model configs: %w", err)
+	}
+
+	rotated := 0
+	for _, cfg := range configs {
+		if cfg.ApiKey == "" {
+			continue
+		}
+		plain, err := keyring.Decrypt(cfg.ApiKey)
+		if err != nil {
+			return rotated, fmt.Errorf("model config %d (%s/%s): %w", cfg.ID, cfg.Provider, cfg.ModelName, err)
+		}
+		value, changed, err := keyring.Rewrap(cfg.ApiKey)
+		if err != nil {
+			return rotated, fmt.Errorf("model config %d (%s/%s): %w", cfg.ID, cfg.Provider, cfg.ModelName, err)
+		}
+		if !changed {
+			continue
+		}
+		if err := repo.UpdateAPIKey(ctx, cfg.ID, value, secret.Mask(plain)); err != nil {
+			return rotated, err
+		}
+		rotated++
+	}
+	return rotated, nil
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+	"github.com/multi-agent-testing/backend/internal/repository/repotest"
+)
+
+func TestModelConfigCreateValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     model.SaveModelConfigRequest
+		wantErr bool
+	}{
+		{"valid", model.SaveModelConfigRequest{Provider: "openai", ModelName: "gpt-4.1"}, false},
+		{"empty model name", model.SaveModelConfigRequest{Provider: "openai"}, true},
+		{"blank model name", model.SaveModelConfigRequest{Provider: "openai", ModelName: "  "}, true},
+		{"unknown provider", model.SaveModelConfigRequest{Provider: "nope", ModelName: "x"}, true},
+		{"invalid default config", model.SaveModelConfigRequest{Provider: "openai", ModelName: "gpt-4.1", DefaultConfig: map[string]interface{}{"temperature": 3}}, true},
+	}
+	// API Key沿用配置文件
+	cfg := &config.Config{Models: map[string]config.ModelConfig{"openai": {ApiKey: "sk-test"}}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewModelConfigRepository(repotest.OpenSQLite(t))
+			s := NewModelConfigService(repo, NewMultiModelService(cfg), nil)
+			_, err := s.Create(context.Background(), &tt.req)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidModelConfig) {
+				t.Fatalf("expected ErrInvalidModelConfig, got %v", err)
+			}
+			// 校验失败时不保存
+			if configs, err := repo.List(context.Background(), ""); err != nil || len(configs) != 0 {
+				t.Fatalf("saved %d configs (%v)", len(configs), err)
+			}
+		})
+	}
+}
@@ -2,91 +2,319 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/multi-agent-testing/backend/internal/assertion"
 	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/metrics"
 	"github.com/multi-agent-testing/backend/internal/model"
 	"github.com/multi-agent-testing/backend/internal/providers/base"
 	"github.com/multi-agent-testing/backend/internal/providers/deepseek"
 	"github.com/multi-agent-testing/backend/internal/providers/minimax"
 	"github.com/multi-agent-testing/backend/internal/providers/openai"
 	"github.com/multi-agent-testing/backend/internal/providers/zhipu"
+	"github.com/multi-agent-testing/backend/internal/secret"
 	"github.com/multi-agent-testing/backend/pkg/logger"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 )
 
+// ErrInvalidRequest 测试请求不合法, 区别于读取模板等服务端错误
+var ErrInvalidRequest = errors.New("invalid test request")
+
 // MultiModelService 多模型测试服务
 type MultiModelService struct {
-	providers map[string]base.ModelProvider
+	mu        sync.RWMutex
+	providers map[string]base.ModelProvider // 配置文件中启用的提供者
+	models    map[string]*modelOverride     // 数据库中的模型配置, key为provider/model
+	limiters  map[string]chan struct{}      // 各提供者的并发限制
 	config    *config.Config
+	history   *HistoryService  // 未启用持久化时为nil
+	templates *TemplateService // 未启用持久化时为nil
+	judge     *JudgeService
+}
+
+// modelOverride 数据库中的单个模型配置, 优先于配置文件
+type modelOverride struct {
+	entity   *model.ModelConfigEntity
+	provider base.ModelProvider
 }
 
 // NewMultiModelService 创建多模型服务
 func NewMultiModelService(cfg *config.Config) *MultiModelService {
 	service := &MultiModelService{
 		providers: make(map[string]base.ModelProvider),
+		models:    make(map[string]*modelOverride),
+		limiters:  make(map[string]chan struct{}),
 		config:    cfg,
 	}
 
 	// 初始化各个模型提供者
 	service.initProviders()
+	service.judge = NewJudgeService(service, cfg.Judge.CacheSize)
 
 	return service
 }
 
-// initProviders 初始化模型提供者
+// EnableHistory 启用测试结果持久化
+func (s *MultiModelService) EnableHistory(history *HistoryService) {
+	s.history = history
+}
+
+// EnableTemplates 启用提示词模板
+func (s *MultiModelService) EnableTemplates(templates *TemplateService) {
+	s.templates = templates
+}
+
+// currentConfig 返回当前生效的配置
+func (s *MultiModelService) currentConfig() *config.Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
+
+// initProviders 初始化配置文件中启用的模型提供者
 func (s *MultiModelService) initProviders() {
-	// 初始化OpenAI
-	if modelCfg, ok := s.config.Models["openai"]; ok && modelCfg.Enabled {
-		provider := openai.NewProvider(base.ProviderConfig{
-			ApiKey:  modelCfg.ApiKey,
-			BaseURL: modelCfg.BaseURL,
-			Timeout: int(modelCfg.Timeout.Seconds()),
-		})
-		s.providers["openai"] = provider
-		logger.Info("OpenAI provider initialized", zap.String("base_url", modelCfg.BaseURL))
+	s.syncProviders(nil)
+}
+
+// ReloadConfig 应用热加载后的配置, 仅重建发生变化的提供者
+func (s *MultiModelService) ReloadConfig(cfg *config.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.config
+	s.config = cfg
+	s.syncProviders(old.Models)
+
+	// 数据库模型配置未设置的连接参数沿用配置文件, 需随之重建
+	for key, override := range s.models {
+		rebuilt, err := s.buildOverride(override.entity)
+		if err != nil {
+			logger.Error("Failed to rebuild model config after reload", zap.String("model", key), zap.Error(err))
+			continue
+		}
+		s.models[key] = rebuilt
 	}
+}
 
-	if modelCfg, ok := s.config.Models["deepseek"]; ok && modelCfg.Enabled {
-		provider := deepseek.NewProvider(base.ProviderConfig{
-			ApiKey:  modelCfg.ApiKey,
-			BaseURL: modelCfg.BaseURL,
-			Timeout: int(modelCfg.Timeout.Seconds()),
-		})
-		s.providers["deepseek"] = provider
-		logger.Info("deepseek provider initialized", zap.String("base_url", modelCfg.BaseURL))
+// syncProviders 按当前配置同步提供者与并发限制, old为变更前的配置, 调用方需持有写锁
+// 进行中的调用继续使用原提供者及并发限制, 新请求使用新配置
+func (s *MultiModelService) syncProviders(old map[string]config.ModelConfig) {
+	names := make([]string, 0, len(s.config.Models))
+	for name := range s.config.Models {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		modelCfg := s.config.Models[name]
+		oldCfg, existed := old[name]
+		if existed && oldCfg == modelCfg {
+			continue
+		}
+
+		// 并发限制, 数据库中的模型配置共用所属提供者的限制
+		if !existed || oldCfg.MaxConcurrency != modelCfg.MaxConcurrency {
+			if modelCfg.MaxConcurrency > 0 {
+				s.limiters[name] = make(chan struct{}, modelCfg.MaxConcurrency)
+			} else {
+				delete(s.limiters, name)
+			}
+		}
+
+		if !modelCfg.Enabled {
+			if _, ok := s.providers[name]; ok {
+				delete(s.providers, name)
+				logger.Info("Provider disabled", zap.String("provider", name))
+			}
+			continue
+		}
 
-	if modelCfg, ok := s.config.Models["minimax"]; ok && modelCfg.Enabled {
-		provider := minimax.NewProvider(base.ProviderConfig{
+		provider, err := newProvider(name, base.ProviderConfig{
 			ApiKey:  modelCfg.ApiKey,
 			BaseURL: modelCfg.BaseURL,
 			Timeout: int(modelCfg.Timeout.Seconds()),
 		})
-		s.providers["minimax"] = provider
-		logger.Info("minimax provider initialized", zap.String("base_url", modelCfg.BaseURL))
+		if err != nil {
+			logger.Warn("Skip unsupported provider", zap.String("provider", name), zap.Error(err))
+			continue
+		}
+		s.providers[name] = provider
+		logger.Info("Provider initialized", zap.String("provider", name), zap.String("base_url", modelCfg.BaseURL))
 	}
-	if modelCfg, ok := s.config.Models["zhipu"]; ok && modelCfg.Enabled {
-		provider := zhipu.NewProvider(base.ProviderConfig{
-			ApiKey:  modelCfg.ApiKey,
-			BaseURL: modelCfg.BaseURL,
-			Timeout: int(modelCfg.Timeout.Seconds()),
-		})
-		s.providers["zhipu"] = provider
-		logger.Info("zhipu provider initialized", zap.String("base_url", modelCfg.BaseURL))
+
+	for name := range old {
+		if _, ok := s.config.Models[name]; !ok {
+			delete(s.limiters, name)
+			if _, ok := s.providers[name]; ok {
+				delete(s.providers, name)
+				logger.Info("Provider removed", zap.String("provider", name))
+			}
+		}
 	}
+}
+
+// newProvider 根据提供者名称创建提供者实例
+func newProvider(name string, cfg base.ProviderConfig) (base.ModelProvider, error) {
+	switch name {
+	case "openai":
+		return openai.NewProvider(cfg), nil
+	case "deepseek":
+		return deepseek.NewProvider(cfg), nil
+	case "minimax":
+		return minimax.NewProvider(cfg), nil
+	case "zhipu":
+		return zhipu.NewProvider(cfg), nil
 	// TODO: 初始化其他提供者(Claude, GLM, Qwen等)
-	// if modelCfg, ok := s.config.Models["anthropic"]; ok && modelCfg.Enabled {
-	// 	provider := anthropic.NewProvider(...)
-	// 	s.providers["anthropic"] = provider
-	// }
+	// case "anthropic":
+	// 	return anthropic.NewProvider(cfg), nil
+	default:
+		return nil, fmt.Errorf("unsupported provider: %s", name)
+	}
+}
+
+// modelKey 数据库模型配置的索引键
+func modelKey(provider, name string) string {
+	return provider + "/" + name
+}
+
+// buildOverride 根据数据库配置创建提供者, 未设置的连接参数沿用配置文件, 调用方需持有锁
+func (s *MultiModelService) buildOverride(entity *model.ModelConfigEntity) (*modelOverride, error) {
+	if strings.TrimSpace(entity.ModelName) == "" {
+		return nil, errors.New("model_name is required")
+	}
+	fileCfg := s.config.Models[entity.Provider]
+	cfg := base.ProviderConfig{
+		ApiKey:  entity.ApiKey,
+		BaseURL: entity.BaseURL,
+		Timeout: int(fileCfg.Timeout.Seconds()),
+	}
+	if cfg.ApiKey == "" {
+		cfg.ApiKey = fileCfg.ApiKey
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = fileCfg.BaseURL
+	}
+
+	provider, err := newProvider(entity.Provider, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if entity.Enabled {
+		if err := provider.ValidateConfig(entity.DefaultConfig); err != nil {
+			return nil, err
+		}
+	}
+	return &modelOverride{entity: entity, provider: provider}, nil
+}
+
+// CheckModelConfig 校验数据库模型配置能否创建提供者
+func (s *MultiModelService) CheckModelConfig(entity *model.ModelConfigEntity) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, err := s.buildOverride(entity)
+	return err
+}
+
+// ApplyModelConfig 重建数据库模型配置对应的提供者, 立即对新请求生效
+func (s *MultiModelService) ApplyModelConfig(entity *model.ModelConfigEntity) error {
+	s.mu.Lock()
+	override, err := s.buildOverride(entity)
+	if err == nil {
+		s.models[modelKey(entity.Provider, entity.ModelName)] = override
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Model config applied",
+		zap.String("provider", entity.Provider),
+		zap.String("model", entity.ModelName),
+		zap.String("api_key", secret.Mask(entity.ApiKey)),
+		zap.Bool("enabled", entity.Enabled),
+	)
+	return nil
+}
+
+// RemoveModelConfig 移除数据库模型配置, 之后该模型回退到配置文件中的提供者
+func (s *MultiModelService) RemoveModelConfig(provider, name string) {
+	s.mu.Lock()
+	delete(s.models, modelKey(provider, name))
+	s.mu.Unlock()
+
+	logger.Info("Model config removed", zap.String("provider", provider), zap.String("model", name))
+}
+
+// resolve 查找模型对应的提供者, 合并数据库配置中的默认参数并校验
+func (s *MultiModelService) resolve(modelReq model.ModelReq) (base.ModelProvider, model.ModelReq, error) {
+	provider, modelReq, err := s.lookup(modelReq)
+	if err != nil {
+		return nil, modelReq, err
+	}
+	if _, err := base.ParseParams(modelReq.Config); err != nil {
+		return nil, modelReq, fmt.Errorf("model %s/%s: %w", modelReq.Provider, modelReq.Name, err)
+	}
+	return provider, modelReq, nil
+}
+
+// lookup 查找模型对应的提供者, 并合并数据库配置中的默认参数
+func (s *MultiModelService) lookup(modelReq model.ModelReq) (base.ModelProvider, model.ModelReq, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if override, ok := s.models[modelKey(modelReq.Provider, modelReq.Name)]; ok {
+		if !override.entity.Enabled {
+			return nil, modelReq, fmt.Errorf("model %s/%s is disabled", modelReq.Provider, modelReq.Name)
+		}
+		modelReq.Config = mergeConfig(override.entity.DefaultConfig, modelReq.Config)
+		return override.provider, modelReq, nil
+	}
+
+	provider, exists := s.providers[modelReq.Provider]
+	if !exists {
+		return nil, modelReq, fmt.Errorf("provider %s not found or not enabled", modelReq.Provider)
+	}
+	return provider, modelReq, nil
 }
 
-// ExecuteTest 执行多模型测试
+// mergeConfig 合并默认参数与请求参数, 请求参数优先
+func mergeConfig(defaults, values map[string]interface{}) map[string]interface{} {
+	if len(defaults) == 0 {
+		return values
+	}
+	merged := make(map[string]interface{}, len(defaults)+len(values))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range values {
+		merged[k] = v
+	}
+	return merged
+}
+
+// ProgressFunc 单个模型调用完成时的回调
+type ProgressFunc func(resp *model.ModelResponse)
+
+// syncTestTimeout 同步测试接口的总超时, 异步任务不受此限制
+const syncTestTimeout = 60 * time.Second
+
+// ExecuteTest 执行多模型测试, 同步返回结果, 总耗时不超过syncTestTimeout
 func (s *MultiModelService) ExecuteTest(ctx context.Context, req *model.TestRequest) (*model.TestResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, syncTestTimeout)
+	defer cancel()
+	return s.ExecuteTestWithProgress(ctx, req, nil)
+}
+
+// ExecuteTestWithProgress 执行多模型测试, 每个模型调用完成后触发onResult回调
+// 不设置总超时, 由ctx控制取消, 单个模型的调用受提供者配置的超时限制
+func (s *MultiModelService) ExecuteTestWithProgress(ctx context.Context, req *model.TestRequest, onResult ProgressFunc) (*model.TestResult, error) {
 	startTime := time.Now()
 
 	logger.Info("Starting multi-model test",
@@ -94,9 +322,8 @@ func (s *MultiModelService) ExecuteTest(ctx context.Context, req *model.TestRequ
 		zap.String("user_prompt", req.Prompts.User),
 	)
 
-	// 创建超时上下文
-	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
-	defer cancel()
+	// 保存结果时不受调用取消的影响
+	recordCtx := context.WithoutCancel(ctx)
 
 	// 使用errgroup并发调用多个模型
 	g, ctx := errgroup.WithContext(ctx)
@@ -104,19 +331,19 @@ func (s *MultiModelService) ExecuteTest(ctx context.Context, req *model.TestRequ
 	var mu sync.Mutex
 	for _, _modelReq := range req.Models {
 		modelReq := _modelReq // 避免闭包陷阱
-		callProvidersRequest := &model.CallProvidersRequest{
-			Prompts: req.Prompts,
-			Models:  modelReq,
-		}
 		g.Go(func() error {
 			// 获取对应的提供者
-			provider, exists := s.providers[modelReq.Provider]
-			if !exists {
-				return fmt.Errorf("provider %s not found or not enabled", modelReq.Provider)
+			provider, resolved, err := s.resolve(modelReq)
+			if err != nil {
+				return err
+			}
+			callProvidersRequest := &model.CallProvidersRequest{
+				Prompts: req.Prompts,
+				Models:  resolved,
 			}
 
 			// 调用模型
-			resp, err := provider.Call(ctx, callProvidersRequest)
+			resp, err := s.callProvider(ctx, provider, callProvidersRequest)
 			if err != nil {
 				logger.Error("Model call failed",
 					zap.String("provider", modelReq.Provider),
@@ -125,8 +352,7 @@ func (s *MultiModelService) ExecuteTest(ctx context.Context, req *model.TestRequ
 				)
 
 				// 记录错误但继续执行其他模型
-				mu.Lock()
-				results[modelReq.Name] = &model.ModelResponse{
+				failed := &model.ModelResponse{
 					ModelName:    modelReq.Name,
 					Provider:     modelReq.Provider,
 					Content:      "",
@@ -136,15 +362,25 @@ func (s *MultiModelService) ExecuteTest(ctx context.Context, req *model.TestRequ
 					StartTime:    time.Now(),
 					EndTime:      time.Now(),
 				}
+				s.evaluate(ctx, testEvaluation(req), req.Prompts, failed)
+				mu.Lock()
+				results[modelReq.Name] = failed
 				mu.Unlock()
+				if onResult != nil {
+					onResult(failed)
+				}
 				return nil // 返回nil以允许其他模型继续执行
 			}
 
 			// 保存成功的响应
-			mu.Lock()
 			resp.ModelName = modelReq.Name
+			s.evaluate(ctx, testEvaluation(req), req.Prompts, resp)
+			mu.Lock()
 			results[modelReq.Name] = resp
 			mu.Unlock()
+			if onResult != nil {
+				onResult(resp)
+			}
 
 			logger.Info("Model response received",
 				zap.String("provider", modelReq.Provider),
@@ -172,6 +408,15 @@ func (s *MultiModelService) ExecuteTest(ctx context.Context, req *model.TestRequ
 		Duration:  duration,
 	}
 
+	if s.history != nil {
+		record, err := s.history.Record(recordCtx, req, result)
+		if err != nil {
+			logger.Error("Failed to save test record", zap.Error(err))
+		} else {
+			result.RecordID = record.ID
+		}
+	}
+
 	logger.Info("Multi-model test completed",
 		zap.Int("total_models", len(req.Models)),
 		zap.Int("success_count", countSuccessful(results)),
@@ -181,8 +426,181 @@ func (s *MultiModelService) ExecuteTest(ctx context.Context, req *model.TestRequ
 	return result, nil
 }
 
-// GetAvailableModels 获取可用的模型列表
+// CallModel 调用单个模型, 调用失败时返回带错误信息的响应
+func (s *MultiModelService) CallModel(ctx context.Context, prompts model.PromptSet, modelReq model.ModelReq) *model.ModelResponse {
+	startTime := time.Now()
+
+	resp, err := func() (*model.ModelResponse, error) {
+		provider, resolved, err := s.resolve(modelReq)
+		if err != nil {
+			return nil, err
+		}
+		return s.callProvider(ctx, provider, &model.CallProvidersRequest{
+			Prompts: prompts,
+			Models:  resolved,
+		})
+	}()
+	if err != nil {
+		return &model.ModelResponse{
+			ModelName:    modelReq.Name,
+			Provider:     modelReq.Provider,
+			Error:        err.Error(),
+			Success:      false,
+			ResponseTime: time.Since(startTime).Milliseconds(),
+			StartTime:    startTime,
+			EndTime:      time.Now(),
+		}
+	}
+
+	resp.ModelName = modelReq.Name
+	return resp
+}
+
+// evaluation 对模型输出的评估配置
+type evaluation struct {
+	assertions []model.Assertion
+	judge      *model.JudgeRequest
+	metrics    *model.MetricOptions
+	expected   string // 期望输出, 为空时不计算参考指标
+}
+
+// testEvaluation 多模型测试请求的评估配置
+func testEvaluation(req *model.TestRequest) evaluation {
+	return evaluation{
+		assertions: req.Assertions,
+		judge:      req.Judge,
+		metrics:    req.Metrics,
+		expected:   req.Expected,
+	}
+}
+
+// evaluate 对模型响应计算参考指标, 执行断言及评审, 更新总体结论
+// 调用失败时各参考指标记为0, 使平均值覆盖全部用例而不只是调用成功的用例
+func (s *MultiModelService) evaluate(ctx context.Context, ev evaluation, prompts model.PromptSet, resp *model.ModelResponse) {
+	if ev.expected != "" && (ev.metrics == nil || !ev.metrics.Disabled) {
+		opts := metrics.Options{}
+		if ev.metrics != nil {
+			opts.AbsTolerance = ev.metrics.Tolerance
+			opts.RelTolerance = ev.metrics.RelTolerance
+		}
+		resp.Metrics = metrics.Compute(resp.Content, ev.expected, opts)
+		if !resp.Success {
+			for metric := range resp.Metrics {
+				resp.Metrics[metric] = 0
+			}
+		}
+	}
+	assertion.Evaluate(ev.assertions, resp)
+	s.judge.Evaluate(ctx, ev.judge, prompts, ev.expected, resp)
+}
+
+// validateMetricOptions 校验参考指标选项
+func validateMetricOptions(opts *model.MetricOptions) error {
+	if opts != nil && (opts.Tolerance < 0 || opts.RelTolerance < 0) {
+		return errors.New("metric tolerance must not be negative")
+	}
+	return nil
+}
+
+// callProvider 在提供者并发限制内调用模型
+func (s *MultiModelService) callProvider(ctx context.Context, provider base.ModelProvider, req *model.CallProvidersRequest) (*model.ModelResponse, error) {
+	s.mu.RLock()
+	limiter, ok := s.limiters[provider.Name()]
+	s.mu.RUnlock()
+	if ok {
+		select {
+		case limiter <- struct{}{}:
+			defer func() { <-limiter }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	resp, err := provider.Call(ctx, req)
+	if err == nil && resp != nil {
+		s.applyCost(resp, req.Models.Provider, req.Models.Name)
+	}
+	return resp, err
+}
+
+// applyCost 按配置的价格计算调用费用, 未配置价格或提供者未返回token用量时不计算
+func (s *MultiModelService) applyCost(resp *model.ModelResponse, provider, name string) {
+	if resp.PromptTokens == 0 && resp.CompletionTokens == 0 {
+		return
+	}
+	s.mu.RLock()
+	price, ok := s.config.Price(provider, name)
+	s.mu.RUnlock()
+	if !ok {
+		return
+	}
+	cost := (float64(resp.PromptTokens)*price.Input + float64(resp.CompletionTokens)*price.Output) / 1e6
+	resp.Cost = &cost
+}
+
+// PrepareRequest 在调用模型前渲染请求引用的模板并校验请求
+// 请求不合法时返回ErrInvalidRequest, 模板不存在时返回repository.ErrNotFound, 其余为读取模板等服务端错误
+func (s *MultiModelService) PrepareRequest(ctx context.Context, req *model.TestRequest) error {
+	if req.TemplateID != 0 || req.TemplateVersion != 0 || len(req.Variables) > 0 {
+		if s.templates == nil {
+			return invalidRequest(fmt.Errorf("%w: templates require database to be enabled", ErrRenderTemplate))
+		}
+		if err := s.templates.ResolveTestRequest(ctx, req); err != nil {
+			if errors.Is(err, ErrRenderTemplate) {
+				return invalidRequest(err)
+			}
+			return err
+		}
+	}
+	if err := s.validatePrepared(req); err != nil {
+		return invalidRequest(err)
+	}
+	return nil
+}
+
+// validatePrepared 校验渲染模板后的请求
+func (s *MultiModelService) validatePrepared(req *model.TestRequest) error {
+	if req.Prompts.User == "" {
+		return errors.New("user prompt is empty")
+	}
+	if err := assertion.Validate(req.Assertions); err != nil {
+		return err
+	}
+	if err := validateMetricOptions(req.Metrics); err != nil {
+		return err
+	}
+	if req.Judge != nil {
+		if err := s.judge.Validate(req.Judge); err != nil {
+			return err
+		}
+	}
+	return s.ValidateRequest(req)
+}
+
+// invalidRequest 将请求校验错误包装为ErrInvalidRequest
+func invalidRequest(err error) error {
+	return fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+}
+
+// ValidateRequest 校验测试请求中的提供者是否可用
+func (s *MultiModelService) ValidateRequest(req *model.TestRequest) error {
+	return s.ValidateModels(req.Models)
+}
+
+// ValidateModels 校验模型列表中的提供者是否可用
+func (s *MultiModelService) ValidateModels(models []model.ModelReq) error {
+	for _, modelReq := range models {
+		if _, _, err := s.resolve(modelReq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAvailableModels 获取可用的模型列表, 数据库中的模型配置覆盖内置列表
 func (s *MultiModelService) GetAvailableModels() []model.ModelInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	models := []model.ModelInfo{}
 
 	// OpenAI模型
@@ -217,6 +635,27 @@ func (s *MultiModelService) GetAvailableModels() []model.ModelInfo {
 
 	// TODO: 添加其他提供者的模型
 
+	// 数据库中的模型配置
+	keys := make([]string, 0, len(s.models))
+	for key := range s.models {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		entity := s.models[key].entity
+		replaced := false
+		for i := range models {
+			if models[i].Provider == entity.Provider && models[i].Name == entity.ModelName {
+				models[i].Enabled = entity.Enabled
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			models = append(models, model.ModelInfo{Name: entity.ModelName, Provider: entity.Provider, Enabled: entity.Enabled})
+		}
+	}
+
 	return models
 }
 
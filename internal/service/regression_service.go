@@ -0,0 +1,490 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+)
+
+// ErrInvalidRegression 回归对比请求不合法
+var ErrInvalidRegression = errors.New("invalid regression request")
+
+// passRateTolerance 比较通过率时忽略浮点误差
+const passRateTolerance = 1e-9
+
+// RegressionService 回归对比服务, 逐用例对比批量任务与基线任务的结果
+type RegressionService struct {
+	jobs repository.JobStore
+
+	mu     sync.RWMutex
+	config config.RegressionConfig
+}
+
+// NewRegressionService 创建回归对比服务
+func NewRegressionService(jobs repository.JobStore, cfg config.RegressionConfig) *RegressionService {
+	return &RegressionService{
+		jobs:   jobs,
+		config: cfg,
+	}
+}
+
+// ReloadConfig 应用热加载后的回归阈值
+func (s *RegressionService) ReloadConfig(cfg config.RegressionConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = cfg
+}
+
+// currentConfig 获取当前回归阈值
+func (s *RegressionService) currentConfig() config.RegressionConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
+
+// regressionRun 参与对比的一次批量运行
+type regressionRun struct {
+	request *model.BatchRequest
+	result  *model.BatchResult
+}
+
+// Compare 对比批量任务与基线任务, 两者需已成功结束且使用同一数据集的同一版本
+func (s *RegressionService) Compare(ctx context.Context, req *model.RegressionRequest) (*model.RegressionReport, error) {
+	if req.RunID == "" || req.BaselineID == "" {
+		return nil, fmt.Errorf("%w: run_id and baseline_id are required", ErrInvalidRegression)
+	}
+	if (req.Model == "") != (req.BaselineModel == "") {
+		return nil, fmt.Errorf("%w: model and baseline_model must be set together", ErrInvalidRegression)
+	}
+	thresholds, err := s.resolveThresholds(req.Thresholds)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.loadRun(ctx, req.RunID)
+	if err != nil {
+		return nil, err
+	}
+	baseline, err := s.loadRun(ctx, req.BaselineID)
+	if err != nil {
+		return nil, err
+	}
+	currentDataset, baselineDataset := current.request.DatasetID, baseline.request.DatasetID
+	if currentDataset != 0 && baselineDataset != 0 && currentDataset != baselineDataset {
+		return nil, fmt.Errorf("%w: runs use different datasets (%d and %d)", ErrInvalidRegression, currentDataset, baselineDataset)
+	}
+	currentVersion, baselineVersion := current.request.DatasetVersion, baseline.request.DatasetVersion
+	if currentVersion != 0 && baselineVersion != 0 && currentVersion != baselineVersion {
+		return nil, fmt.Errorf("%w: runs use different dataset versions (%d and %d)", ErrInvalidRegression, currentVersion, baselineVersion)
+	}
+
+	pairs, err := regressionPairs(req, current.result.Models, baseline.result.Models)
+	if err != nil {
+		return nil, err
+	}
+	matches := matchCases(current.result.Cases, baseline.result.Cases)
+
+	report := &model.RegressionReport{
+		RunID:                  req.RunID,
+		BaselineID:             req.BaselineID,
+		DatasetID:              max(currentDataset, baselineDataset),
+		DatasetVersion:         current.request.DatasetVersion,
+		BaselineDatasetVersion: baseline.request.DatasetVersion,
+		Thresholds:             thresholds,
+		Verdict:                model.VerdictPass,
+		Models:                 make([]*model.ModelRegression, 0, len(pairs)),
+	}
+	for _, pair := range pairs {
+		mr := compareModel(pair[0], pair[1], current.result, baseline.result, matches, *thresholds.ScoreThreshold)
+		report.Models = append(report.Models, mr)
+		report.Reasons = append(report.Reasons, regressionFailures(mr, thresholds)...)
+	}
+	if len(report.Reasons) > 0 {
+		report.Verdict = model.VerdictFail
+	}
+	return report, nil
+}
+
+// resolveThresholds 合并请求与配置文件中的阈值
+func (s *RegressionService) resolveThresholds(override *model.RegressionThresholds) (model.RegressionThresholds, error) {
+	cfg := s.currentConfig()
+	if cfg.ScoreThreshold == 0 {
+		cfg.ScoreThreshold = config.DefaultScoreThreshold
+	}
+	t := model.RegressionThresholds{
+		ScoreThreshold:     &cfg.ScoreThreshold,
+		MaxRegressed:       &cfg.MaxRegressed,
+		MaxPassRateDrop:    &cfg.MaxPassRateDrop,
+		MaxLatencyIncrease: &cfg.MaxLatencyIncrease,
+		MaxCostIncrease:    &cfg.MaxCostIncrease,
+	}
+	if override != nil {
+		if override.ScoreThreshold != nil {
+			t.ScoreThreshold = override.ScoreThreshold
+		}
+		if override.MaxRegressed != nil {
+			t.MaxRegressed = override.MaxRegressed
+		}
+		if override.MaxPassRateDrop != nil {
+			t.MaxPassRateDrop = override.MaxPassRateDrop
+		}
+		if override.MaxLatencyIncrease != nil {
+			t.MaxLatencyIncrease = override.MaxLatencyIncrease
+		}
+		if override.MaxCostIncrease != nil {
+			t.MaxCostIncrease = override.MaxCostIncrease
+		}
+	}
+
+	switch {
+	case *t.ScoreThreshold < 0 || *t.ScoreThreshold > 1:
+		return t, fmt.Errorf("%w: score_threshold must be between 0 and 1", ErrInvalidRegression)
+	case *t.MaxRegressed < 0:
+		return t, fmt.Errorf("%w: max_regressed must not be negative", ErrInvalidRegression)
+	case *t.MaxPassRateDrop < 0 || *t.MaxPassRateDrop > 1:
+		return t, fmt.Errorf("%w: max_pass_rate_drop must be between 0 and 1", ErrInvalidRegression)
+	case *t.MaxLatencyIncrease < 0 || *t.MaxCostIncrease < 0:
+		return t, fmt.Errorf("%w: max_latency_increase and max_cost_increase must not be negative", ErrInvalidRegression)
+	}
+	return t, nil
+}
+
+// loadRun 加载成功结束的批量任务, 失败或取消的任务结果不完整, 不能参与对比
+func (s *RegressionService) loadRun(ctx context.Context, id string) (*regressionRun, error) {
+	job, err := s.jobs.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Type != model.JobTypeBatch {
+		return nil, fmt.Errorf("%w: job %s is not a batch job", ErrInvalidRegression, id)
+	}
+	if job.Status != model.JobStatusSucceeded {
+		return nil, fmt.Errorf("%w: job %s has not succeeded (status %s)", ErrInvalidRegression, id, job.Status)
+	}
+
+	run := &regressionRun{request: &model.BatchRequest{}, result: &model.BatchResult{}}
+	if err := job.Request.Decode(run.request); err != nil {
+		return nil, fmt.Errorf("failed to decode job request: %w", err)
+	}
+	if err := job.Result.Decode(run.result); err != nil {
+		return nil, fmt.Errorf("failed to decode job result: %w", err)
+	}
+	return run, nil
+}
+
+// regressionPairs 确定需要对比的模型对(本次, 基线)
+// 未指定模型时对比同名模型, 两次运行各只有一个模型且名称不同时直接对比这两个模型
+func regressionPairs(req *model.RegressionRequest, current, baseline []string) ([][2]string, error) {
+	if req.Model != "" {
+		switch {
+		case !slices.Contains(current, req.Model):
+			return nil, fmt.Errorf("%w: run has no model %s", ErrInvalidRegression, req.Model)
+		case !slices.Contains(baseline, req.BaselineModel):
+			return nil, fmt.Errorf("%w: baseline has no model %s", ErrInvalidRegression, req.BaselineModel)
+		}
+		return [][2]string{{req.Model, req.BaselineModel}}, nil
+	}
+
+	pairs := [][2]string{}
+	for _, name := range current {
+		if slices.Contains(baseline, name) {
+			pairs = append(pairs, [2]string{name, name})
+		}
+	}
+	if len(pairs) == 0 && len(current) == 1 && len(baseline) == 1 {
+		pairs = append(pairs, [2]string{current[0], baseline[0]})
+	}
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("%w: runs have no models in common, set model and baseline_model", ErrInvalidRegression)
+	}
+	return pairs, nil
+}
+
+// matchCases 为本次运行的每个用例找到基线中对应用例的序号, 没有时为-1
+// 两次运行的用例标识均完整且不重复时按标识匹配, 否则按序号匹配
+func matchCases(current, baseline []*model.BatchCaseResult) []int {
+	_, okCurrent := caseIDs(current)
+	baselineIDs, okBaseline := caseIDs(baseline)
+
+	matches := make([]int, len(current))
+	for i := range current {
+		matches[i] = -1
+		if okCurrent && okBaseline {
+			if j, ok := baselineIDs[current[i].Case.ID]; ok {
+				matches[i] = j
+			}
+			continue
+		}
+		if i < len(baseline) {
+			matches[i] = i
+		}
+	}
+	return matches
+}
+
+// caseIDs 用例标识到序号的映射, 存在空标识或重复标识时返回false
+func caseIDs(cases []*model.BatchCaseResult) (map[string]int, bool) {
+	ids := make(map[string]int, len(cases))
+	for i, row := range cases {
+		id := row.Case.ID
+		if id == "" {
+			return nil, false
+		}
+		if _, dup := ids[id]; dup {
+			return nil, false
+		}
+		ids[id] = i
+	}
+	return ids, true
+}
+
+// compareModel 逐用例对比一对模型并汇总, 未被匹配的基线用例计为缺失
+func compareModel(name, baselineName string, current, baseline *model.BatchResult, matches []int, scoreThreshold float64) *model.ModelRegression {
+	mr := &model.ModelRegression{
+		Model:         name,
+		BaselineModel: baselineName,
+		Cases:         make([]*model.CaseRegression, 0, len(current.Cases)),
+	}
+
+	var (
+		evaluated, passed                 int
+		baselineEvaluated, baselinePassed int
+		timed                             int
+		responseTime, baselineTime        int64
+	)
+	for i, row := range current.Cases {
+		var cur, base *model.ModelResponse
+		cur = row.Results[name]
+		if matches[i] >= 0 {
+			base = baseline.Cases[matches[i]].Results[baselineName]
+		}
+
+		cr := compareCase(cur, base, scoreThreshold)
+		cr.CaseIndex, cr.CaseID, cr.BaselineIndex = i, row.Case.ID, matches[i]
+		mr.Cases = append(mr.Cases, cr)
+
+		switch cr.Status {
+		case model.RegressionImproved:
+			mr.Improved++
+		case model.RegressionRegressed:
+			mr.Regressed++
+		case model.RegressionUnchanged:
+			mr.Unchanged++
+		default:
+			mr.Missing++
+			continue
+		}
+
+		if cur.Passed != nil {
+			evaluated++
+			if *cur.Passed {
+				passed++
+			}
+		}
+		if base.Passed != nil {
+			baselineEvaluated++
+			if *base.Passed {
+				baselinePassed++
+			}
+		}
+		if cur.Success && base.Success {
+			timed++
+			responseTime += cur.ResponseTime
+			baselineTime += base.ResponseTime
+		}
+		mr.Cost = addCost(mr.Cost, cur.Cost)
+		mr.BaselineCost = addCost(mr.BaselineCost, base.Cost)
+	}
+
+	// 本次运行中没有对应用例的基线用例, 删减用例不能使运行通过门禁
+	matched := make(map[int]bool, len(matches))
+	for _, j := range matches {
+		matched[j] = true
+	}
+	for j, row := range baseline.Cases {
+		if matched[j] {
+			continue
+		}
+		mr.Cases = append(mr.Cases, &model.CaseRegression{
+			CaseIndex:     -1,
+			CaseID:        row.Case.ID,
+			BaselineIndex: j,
+			Status:        model.RegressionMissing,
+			Reason:        "case is not in the run",
+		})
+		mr.Missing++
+	}
+
+	if evaluated > 0 {
+		rate := float64(passed) / float64(evaluated)
+		mr.PassRate = &rate
+	}
+	if baselineEvaluated > 0 {
+		rate := float64(baselinePassed) / float64(baselineEvaluated)
+		mr.BaselinePassRate = &rate
+	}
+	if timed > 0 {
+		mr.AvgResponseTime = responseTime / int64(timed)
+		mr.BaselineAvgResponseTime = baselineTime / int64(timed)
+		if baselineTime > 0 {
+			change := float64(responseTime-baselineTime) / float64(baselineTime)
+			mr.LatencyChange = &change
+		}
+	}
+	if mr.Cost != nil && mr.BaselineCost != nil && *mr.BaselineCost > 0 {
+		change := (*mr.Cost - *mr.BaselineCost) / *mr.BaselineCost
+		mr.CostChange = &change
+	}
+	return mr
+}
+
+// compareCase 对比同一用例的本次与基线结果
+// 依次根据调用是否成功、总体结论、断言变化及分数变化判定状态, 先满足的条件决定结果
+func compareCase(cur, base *model.ModelResponse, scoreThreshold float64) *model.CaseRegression {
+	cr := &model.CaseRegression{Status: model.RegressionMissing}
+	if cur == nil || base == nil {
+		return cr
+	}
+
+	cr.Passed, cr.BaselinePassed = cur.Passed, base.Passed
+	cr.Score, cr.BaselineScore = responseScores(cur, base)
+	if cr.Score != nil && cr.BaselineScore != nil {
+		delta := *cr.Score - *cr.BaselineScore
+		cr.ScoreDelta = &delta
+	}
+
+	baselineAssertions := make(map[string]bool, len(base.Assertions))
+	for i, a := range base.Assertions {
+		baselineAssertions[assertionLabel(a, i)] = a.Passed
+		if a.Passed {
+			cr.BaselineAssertionsPassed++
+		}
+	}
+	for i, a := range cur.Assertions {
+		if a.Passed {
+			cr.AssertionsPassed++
+		}
+		before, ok := baselineAssertions[assertionLabel(a, i)]
+		switch {
+		case !ok:
+		case before && !a.Passed:
+			cr.NewlyFailed = append(cr.NewlyFailed, assertionLabel(a, i))
+		case !before && a.Passed:
+			cr.NewlyPassed = append(cr.NewlyPassed, assertionLabel(a, i))
+		}
+	}
+
+	for metric, value := range cur.Metrics {
+		if before, ok := base.Metrics[metric]; ok {
+			if cr.MetricDeltas == nil {
+				cr.MetricDeltas = make(map[string]float64)
+			}
+			cr.MetricDeltas[metric] = value - before
+		}
+	}
+	if cur.Success && base.Success {
+		cr.ResponseTimeDelta = cur.ResponseTime - base.ResponseTime
+	}
+	if cur.Cost != nil && base.Cost != nil {
+		delta := *cur.Cost - *base.Cost
+		cr.CostDelta = &delta
+	}
+
+	switch {
+	case base.Success && !cur.Success:
+		cr.Status, cr.Reason = model.RegressionRegressed, "model call failed"
+	case !base.Success && cur.Success:
+		cr.Status, cr.Reason = model.RegressionImproved, "model call succeeded"
+	case cur.Passed != nil && base.Passed != nil && *cur.Passed != *base.Passed:
+		if *cur.Passed {
+			cr.Status, cr.Reason = model.RegressionImproved, "failed -> passed"
+		} else {
+			cr.Status, cr.Reason = model.RegressionRegressed, "passed -> failed"
+		}
+	case len(cr.NewlyFailed) > 0 && len(cr.NewlyPassed) == 0:
+		cr.Status, cr.Reason = model.RegressionRegressed, fmt.Sprintf("%d assertions newly failed", len(cr.NewlyFailed))
+	case len(cr.NewlyPassed) > 0 && len(cr.NewlyFailed) == 0:
+		cr.Status, cr.Reason = model.RegressionImproved, fmt.Sprintf("%d assertions newly passed", len(cr.NewlyPassed))
+	case cr.ScoreDelta != nil && *cr.ScoreDelta < -scoreThreshold:
+		cr.Status, cr.Reason = model.RegressionRegressed, fmt.Sprintf("score dropped by %.3f", -*cr.ScoreDelta)
+	case cr.ScoreDelta != nil && *cr.ScoreDelta > scoreThreshold:
+		cr.Status, cr.Reason = model.RegressionImproved, fmt.Sprintf("score rose by %.3f", *cr.ScoreDelta)
+	default:
+		cr.Status = model.RegressionUnchanged
+	}
+	return cr
+}
+
+// responseScores 两次结果的归一化分数, 均有评审分数时使用评审分数除以满分, 否则均有参考指标时使用共同指标的平均值
+func responseScores(cur, base *model.ModelResponse) (*float64, *float64) {
+	if c, b := judgeScore(cur), judgeScore(base); c != nil && b != nil {
+		return c, b
+	}
+
+	metrics := []string{}
+	for metric := range cur.Metrics {
+		if _, ok := base.Metrics[metric]; ok {
+			metrics = append(metrics, metric)
+		}
+	}
+	if len(metrics) == 0 {
+		return nil, nil
+	}
+	sort.Strings(metrics)
+	var c, b float64
+	for _, metric := range metrics {
+		c += cur.Metrics[metric]
+		b += base.Metrics[metric]
+	}
+	c /= float64(len(metrics))
+	b /= float64(len(metrics))
+	return &c, &b
+}
+
+// judgeScore 评审分数除以满分, 没有分数时为空
+func judgeScore(resp *model.ModelResponse) *float64 {
+	if resp.Judge == nil || resp.Judge.Score == nil || resp.Judge.MaxScore <= 0 {
+		return nil
+	}
+	score := *resp.Judge.Score / resp.Judge.MaxScore
+	return &score
+}
+
+// assertionLabel 断言的展示名称, 未命名时使用类型及序号
+func assertionLabel(a model.AssertionResult, index int) string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return fmt.Sprintf("%s#%d", a.Type, index+1)
+}
+
+// regressionFailures 根据阈值判定模型是否未通过回归检查, 返回全部原因
+func regressionFailures(mr *model.ModelRegression, t model.RegressionThresholds) []string {
+	reasons := []string{}
+	// 缺少结果的用例无法判断是否退化
+	if mr.Missing > 0 {
+		reasons = append(reasons, fmt.Sprintf("%s: %d cases have no result in the run or the baseline", mr.Model, mr.Missing))
+	}
+	if mr.Regressed > *t.MaxRegressed {
+		reasons = append(reasons, fmt.Sprintf("%s: %d regressed cases exceed max_regressed %d", mr.Model, mr.Regressed, *t.MaxRegressed))
+	}
+	if mr.PassRate != nil && mr.BaselinePassRate != nil {
+		if drop := *mr.BaselinePassRate - *mr.PassRate; drop > *t.MaxPassRateDrop+passRateTolerance {
+			reasons = append(reasons, fmt.Sprintf("%s: pass rate dropped by %.1f%% (max %.1f%%)", mr.Model, drop*100, *t.MaxPassRateDrop*100))
+		}
+	}
+	if *t.MaxLatencyIncrease > 0 && mr.LatencyChange != nil && *mr.LatencyChange > *t.MaxLatencyIncrease {
+		reasons = append(reasons, fmt.Sprintf("%s: average latency increased by %.1f%% (max %.1f%%)", mr.Model, *mr.LatencyChange*100, *t.MaxLatencyIncrease*100))
+	}
+	if *t.MaxCostIncrease > 0 && mr.CostChange != nil && *mr.CostChange > *t.MaxCostIncrease {
+		reasons = append(reasons, fmt.Sprintf("%s: cost increased by %.1f%% (max %.1f%%)", mr.Model, *mr.CostChange*100, *t.MaxCostIncrease*100))
+	}
+	return reasons
+}
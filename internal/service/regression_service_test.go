@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/config"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+)
+
+func TestMatchCases(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  []string
+		baseline []string
+		want     []int
+	}{
+		{"by id", []string{"a", "b", "c"}, []string{"c", "a", "b"}, []int{1, 2, 0}},
+		{"id not in baseline", []string{"a", "x"}, []string{"a", "b"}, []int{0, -1}},
+		{"missing id falls back to index", []string{"a", ""}, []string{"b", "a"}, []int{0, 1}},
+		{"duplicate id falls back to index", []string{"a", "b"}, []string{"b", "b"}, []int{0, 1}},
+		{"extra cases by index", []string{"", "", ""}, []string{"", ""}, []int{0, 1, -1}},
+		{"fewer cases by index", []string{""}, []string{"", ""}, []int{0}},
+		{"empty run", nil, []string{"a"}, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchCases(caseRows(tt.current), caseRows(tt.baseline)); !slices.Equal(got, tt.want) {
+				t.Fatalf("matchCases = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareCase(t *testing.T) {
+	tests := []struct {
+		name       string
+		cur, base  *model.ModelResponse
+		threshold  float64
+		wantStatus string
+		wantReason string
+	}{
+		{name: "no current result", cur: nil, base: response(true, nil), wantStatus: model.RegressionMissing},
+		{name: "no baseline result", cur: response(true, nil), base: nil, wantStatus: model.RegressionMissing},
+		{name: "call failed", cur: failedResponse(), base: response(true, nil), wantStatus: model.RegressionRegressed, wantReason: "model call failed"},
+		{name: "call recovered", cur: response(true, nil), base: failedResponse(), wantStatus: model.RegressionImproved, wantReason: "model call succeeded"},
+		{name: "passed to failed", cur: response(true, ptr(false)), base: response(true, ptr(true)), wantStatus: model.RegressionRegressed, wantReason: "passed -> failed"},
+		{name: "failed to passed", cur: response(true, ptr(true)), base: response(true, ptr(false)), wantStatus: model.RegressionImproved, wantReason: "failed -> passed"},
+		{
+			name:       "assertion newly failed",
+			cur:        withAssertions(response(true, nil), false, true),
+			base:       withAssertions(response(true, nil), true, true),
+			wantStatus: model.RegressionRegressed,
+			wantReason: "1 assertions newly failed",
+		},
+		{
+			name:       "assertions swapped",
+			cur:        withAssertions(response(true, nil), false, true),
+			base:       withAssertions(response(true, nil), true, false),
+			wantStatus: model.RegressionUnchanged,
+		},
+		{name: "score dropped", cur: judged(6), base: judged(8), threshold: 0.05, wantStatus: model.RegressionRegressed, wantReason: "score dropped by 0.200"},
+		{name: "score rose", cur: judged(8), base: judged(6), threshold: 0.05, wantStatus: model.RegressionImproved, wantReason: "score rose by 0.200"},
+		{name: "score drop within threshold", cur: judged(6), base: judged(8), threshold: 0.25, wantStatus: model.RegressionUnchanged},
+		{name: "metrics used without judge", cur: withMetrics(0.5), base: withMetrics(0.9), threshold: 0.05, wantStatus: model.RegressionRegressed},
+		{name: "unchanged", cur: response(true, ptr(true)), base: response(true, ptr(true)), wantStatus: model.RegressionUnchanged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := compareCase(tt.cur, tt.base, tt.threshold)
+			if cr.Status != tt.wantStatus {
+				t.Fatalf("Status = %s (%s), want %s", cr.Status, cr.Reason, tt.wantStatus)
+			}
+			if tt.wantReason != "" && cr.Reason != tt.wantReason {
+				t.Fatalf("Reason = %q, want %q", cr.Reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestRegressionCompare(t *testing.T) {
+	pass, fail := ptr(true), ptr(false)
+	baseline := []*model.BatchCaseResult{
+		caseResult("a", response(true, pass)),
+		caseResult("b", response(true, pass)),
+		caseResult("c", response(true, fail)),
+	}
+
+	tests := []struct {
+		name        string
+		current     []*model.BatchCaseResult
+		status      string
+		version     int
+		req         model.RegressionRequest
+		wantErr     error
+		wantVerdict string
+		wantModel   model.ModelRegression
+		wantReason  string
+	}{
+		{
+			name:        "unchanged run passes",
+			current:     baseline,
+			wantVerdict: model.VerdictPass,
+			wantModel:   model.ModelRegression{Unchanged: 3},
+		},
+		{
+			name:        "reordered cases match by id",
+			current:     []*model.BatchCaseResult{baseline[2], baseline[0], baseline[1]},
+			wantVerdict: model.VerdictPass,
+			wantModel:   model.ModelRegression{Unchanged: 3},
+		},
+		{
+			name: "regressed case fails",
+			current: []*model.BatchCaseResult{
+				caseResult("a", response(true, fail)),
+				baseline[1],
+				baseline[2],
+			},
+			wantVerdict: model.VerdictFail,
+			wantModel:   model.ModelRegression{Regressed: 1, Unchanged: 2},
+			wantReason:  "1 regressed cases exceed max_regressed 0",
+		},
+		{
+			name: "max_regressed override allows the regression",
+			current: []*model.BatchCaseResult{
+				caseResult("a", response(true, fail)),
+				baseline[1],
+				caseResult("c", response(true, pass)),
+			},
+			req:         model.RegressionRequest{Thresholds: &model.RegressionThresholds{MaxRegressed: ptr(1)}},
+			wantVerdict: model.VerdictPass,
+			wantModel:   model.ModelRegression{Improved: 1, Regressed: 1, Unchanged: 1},
+		},
+		{
+			name:        "baseline case missing from the run",
+			current:     baseline[:2],
+			wantVerdict: model.VerdictFail,
+			wantModel:   model.ModelRegression{Unchanged: 2, Missing: 1},
+			wantReason:  "1 cases have no result in the run or the baseline",
+		},
+		{
+			name:        "extra case in the run",
+			current:     append(slices.Clone(baseline), caseResult("d", response(true, pass))),
+			wantVerdict: model.VerdictFail,
+			wantModel:   model.ModelRegression{Unchanged: 3, Missing: 1},
+			wantReason:  "1 cases have no result in the run or the baseline",
+		},
+		{
+			name:    "mismatched dataset versions",
+			current: baseline,
+			version: 2,
+			wantErr: ErrInvalidRegression,
+		},
+		{
+			name:    "run has not succeeded",
+			current: baseline,
+			status:  model.JobStatusCanceled,
+			wantErr: ErrInvalidRegression,
+		},
+		{
+			name:    "invalid threshold override",
+			current: baseline,
+			req:     model.RegressionRequest{Thresholds: &model.RegressionThresholds{ScoreThreshold: ptr(1.5)}},
+			wantErr: ErrInvalidRegression,
+		},
+		{
+			name:    "unknown model",
+			current: baseline,
+			req:     model.RegressionRequest{Model: "other", BaselineModel: "gpt-4.1"},
+			wantErr: ErrInvalidRegression,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := repository.NewMemoryJobStore()
+			createRun(t, store, "baseline", model.JobStatusSucceeded, 1, baseline)
+			status := tt.status
+			if status == "" {
+				status = model.JobStatusSucceeded
+			}
+			version := tt.version
+			if version == 0 {
+				version = 1
+			}
+			createRun(t, store, "run", status, version, tt.current)
+
+			req := tt.req
+			req.RunID, req.BaselineID = "run", "baseline"
+			report, err := NewRegressionService(store, config.RegressionConfig{}).Compare(ctx, &req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Compare error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Compare: %v", err)
+			}
+			if report.Verdict != tt.wantVerdict {
+				t.Fatalf("Verdict = %s (%v), want %s", report.Verdict, report.Reasons, tt.wantVerdict)
+			}
+			if len(report.Models) != 1 {
+				t.Fatalf("got %d models, want 1", len(report.Models))
+			}
+			mr := report.Models[0]
+			got := [4]int{mr.Improved, mr.Regressed, mr.Unchanged, mr.Missing}
+			want := [4]int{tt.wantModel.Improved, tt.wantModel.Regressed, tt.wantModel.Unchanged, tt.wantModel.Missing}
+			if got != want {
+				t.Fatalf("improved/regressed/unchanged/missing = %v, want %v", got, want)
+			}
+			if tt.wantReason != "" && !slices.ContainsFunc(report.Reasons, func(r string) bool { return strings.Contains(r, tt.wantReason) }) {
+				t.Fatalf("Reasons = %v, want %q", report.Reasons, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestRegressionFailures(t *testing.T) {
+	zero, none := 0, 0.0
+	thresholds := model.RegressionThresholds{
+		MaxRegressed:       &zero,
+		MaxPassRateDrop:    ptr(0.1),
+		MaxLatencyIncrease: ptr(0.2),
+		MaxCostIncrease:    &none,
+	}
+	tests := []struct {
+		name string
+		mr   model.ModelRegression
+		want int
+	}{
+		{"clean", model.ModelRegression{Unchanged: 3}, 0},
+		{"pass rate drop within limit", model.ModelRegression{PassRate: ptr(0.9), BaselinePassRate: ptr(1.0)}, 0},
+		{"pass rate drop over limit", model.ModelRegression{PassRate: ptr(0.5), BaselinePassRate: ptr(1.0)}, 1},
+		{"latency over limit", model.ModelRegression{LatencyChange: ptr(0.5)}, 1},
+		{"cost check disabled", model.ModelRegression{CostChange: ptr(10.0)}, 0},
+		{"missing and regressed", model.ModelRegression{Missing: 1, Regressed: 1}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := regressionFailures(&tt.mr, thresholds); len(got) != tt.want {
+				t.Fatalf("regressionFailures = %v, want %d reasons", got, tt.want)
+			}
+		})
+	}
+}
+
+// createRun 保存一次成功结束的批量运行, 只有一个模型gpt-4.1
+func createRun(t *testing.T, store repository.JobStore, id, status string, version int, cases []*model.BatchCaseResult) {
+	t.Helper()
+	request, err := model.NewJSONField(&model.BatchRequest{DatasetID: 1, DatasetVersion: version})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := model.NewJSONField(&model.BatchResult{Models: []string{"gpt-4.1"}, Cases: cases})
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := &model.Job{ID: id, Type: model.JobTypeBatch, Status: status, Request: request, Result: result}
+	if err := store.Create(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func caseRows(ids []string) []*model.BatchCaseResult {
+	rows := make([]*model.BatchCaseResult, len(ids))
+	for i, id := range ids {
+		rows[i] = &model.BatchCaseResult{Case: model.TestCase{ID: id}}
+	}
+	return rows
+}
+
+func caseResult(id string, resp *model.ModelResponse) *model.BatchCaseResult {
+	return &model.BatchCaseResult{
+		Case:    model.TestCase{ID: id},
+		Results: map[string]*model.ModelResponse{"gpt-4.1": resp},
+	}
+}
+
+func response(success bool, passed *bool) *model.ModelResponse {
+	return &model.ModelResponse{Success: success, Passed: passed, ResponseTime: 100}
+}
+
+func failedResponse() *model.ModelResponse {
+	return &model.ModelResponse{Success: false, Error: "timeout"}
+}
+
+func withAssertions(resp *model.ModelResponse, results ...bool) *model.ModelResponse {
+	for _, passed := range results {
+		resp.Assertions = append(resp.Assertions, model.AssertionResult{Type: "contains", Passed: passed})
+	}
+	return resp
+}
+
+func judged(score float64) *model.ModelResponse {
+	resp := response(true, nil)
+	resp.Judge = &model.JudgeResult{Score: &score, MaxScore: 10}
+	return resp
+}
+
+func withMetrics(value float64) *model.ModelResponse {
+	resp := response(true, nil)
+	resp.Metrics = map[string]float64{"rouge_l": value}
+	return resp
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
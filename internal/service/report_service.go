@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+)
+
+// ErrInvalidReport 报告请求不合法
+var ErrInvalidReport = errors.New("invalid report request")
+
+// MaxReportRuns 单个报告最多包含的运行数
+const MaxReportRuns = 20
+
+// ReportService 结果报告服务, 汇总测试记录及任务的结果供导出
+type ReportService struct {
+	records *repository.TestRecordRepository // 未启用数据库时为nil
+	jobs    repository.JobStore
+}
+
+// NewReportService 创建结果报告服务, records为nil时只支持任务
+func NewReportService(records *repository.TestRecordRepository, jobs repository.JobStore) *ReportService {
+	return &ReportService{
+		records: records,
+		jobs:    jobs,
+	}
+}
+
+// ParseRunRefs 解析<run_type>:<run_id>格式的运行引用, 忽略重复项
+func ParseRunRefs(values []string) ([]model.RunRef, error) {
+	refs := make([]model.RunRef, 0, len(values))
+	seen := make(map[model.RunRef]bool, len(values))
+	for _, value := range values {
+		runType, runID, ok := strings.Cut(strings.TrimSpace(value), ":")
+		if !ok || runID == "" {
+			return nil, fmt.Errorf("%w: run %q must be <run_type>:<run_id>", ErrInvalidReport, value)
+		}
+		ref := model.RunRef{RunType: runType, RunID: runID}
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// Build 按顺序加载各运行的结果生成报告, 任一运行不存在时返回ErrNotFound
+func (s *ReportService) Build(ctx context.Context, refs []model.RunRef, title string) (*model.Report, error) {
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("%w: at least one run is required", ErrInvalidReport)
+	}
+	if len(refs) > MaxReportRuns {
+		return nil, fmt.Errorf("%w: at most %d runs are allowed", ErrInvalidReport, MaxReportRuns)
+	}
+
+	report := &model.Report{
+		Title:       title,
+		GeneratedAt: time.Now(),
+		Runs:        make([]*model.RunReport, 0, len(refs)),
+	}
+	for _, ref := range refs {
+		run, err := loadRunReport(ctx, s.records, s.jobs, ref, ErrInvalidReport)
+		if err != nil {
+			return nil, err
+		}
+		report.Runs = append(report.Runs, run)
+	}
+	if report.Title == "" {
+		report.Title = defaultReportTitle(refs)
+	}
+	return report, nil
+}
+
+// defaultReportTitle 未指定标题时以运行引用作为标题
+func defaultReportTitle(refs []model.RunRef) string {
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		names = append(names, ref.RunType+" "+ref.RunID)
+	}
+	return "Test report: " + strings.Join(names, ", ")
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+)
+
+// loadRunReport 读取测试记录或任务的结果, 统一转换为批量测试结果, 单次测试为只有一个用例的批量测试
+// records为nil时不支持测试记录, run_type不合法时返回包装了invalid的错误
+func loadRunReport(ctx context.Context, records *repository.TestRecordRepository, jobs repository.JobStore, ref model.RunRef, invalid error) (*model.RunReport, error) {
+	run := &model.RunReport{RunType: ref.RunType, RunID: ref.RunID}
+
+	switch ref.RunType {
+	case model.RunTypeRecord:
+		if records == nil {
+			return nil, fmt.Errorf("%w: test records require database to be enabled", invalid)
+		}
+		id, err := strconv.ParseUint(ref.RunID, 10, 64)
+		if err != nil {
+			return nil, repository.ErrNotFound
+		}
+		record, err := records.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		var prompts model.PromptSet
+		if err := record.Prompts.Decode(&prompts); err != nil {
+			return nil, fmt.Errorf("failed to decode record prompts: %w", err)
+		}
+		var models []model.ModelReq
+		if err := record.Models.Decode(&models); err != nil {
+			return nil, fmt.Errorf("failed to decode record models: %w", err)
+		}
+		results := map[string]*model.ModelResponse{}
+		if err := record.Results.Decode(&results); err != nil {
+			return nil, fmt.Errorf("failed to decode record results: %w", err)
+		}
+		run.CreatedAt = record.CreatedAt
+		run.Models = modelNames(models)
+		run.Cases = []*model.BatchCaseResult{{
+			Case:    model.TestCase{System: prompts.System, Prompt: prompts.User},
+			Results: results,
+		}}
+	case model.RunTypeJob:
+		job, err := jobs.Get(ctx, ref.RunID)
+		if err != nil {
+			return nil, err
+		}
+		run.Status = job.Status
+		run.CreatedAt = job.CreatedAt
+		if job.Type == model.JobTypeBatch {
+			if err := job.Result.Decode(&run.BatchResult); err != nil {
+				return nil, fmt.Errorf("failed to decode job result: %w", err)
+			}
+			break
+		}
+		// 模拟用户对话中各模型收到的消息不同, 无法按用例对齐
+		if job.Type == model.JobTypeSimulation {
+			return nil, fmt.Errorf("%w: simulation jobs are not supported", invalid)
+		}
+		// 多轮对话的每一轮作为一个用例
+		if job.Type == model.JobTypeConversation {
+			var result model.ConversationResult
+			if err := job.Result.Decode(&result); err != nil {
+				return nil, fmt.Errorf("failed to decode job result: %w", err)
+			}
+			run.BatchResult = *conversationBatch(&result)
+			break
+		}
+		var req model.TestRequest
+		if err := job.Request.Decode(&req); err != nil {
+			return nil, fmt.Errorf("failed to decode job request: %w", err)
+		}
+		var result model.TestResult
+		if err := job.Result.Decode(&result); err != nil {
+			return nil, fmt.Errorf("failed to decode job result: %w", err)
+		}
+		run.Models = modelNames(req.Models)
+		run.Cases = []*model.BatchCaseResult{{
+			Case: model.TestCase{
+				System:    req.Prompts.System,
+				Prompt:    req.Prompts.User,
+				Variables: req.Variables,
+				Expected:  req.Expected,
+			},
+			Results: result.Results,
+		}}
+	default:
+		return nil, fmt.Errorf("%w: run_type must be %s or %s", invalid, model.RunTypeRecord, model.RunTypeJob)
+	}
+
+	if run.Cases == nil {
+		run.Cases = []*model.BatchCaseResult{}
+	}
+	run.Summary = summarizeBatch(&run.BatchResult)
+	return run, nil
+}
+
+// modelNames 提取请求中的模型名称
+func modelNames(models []model.ModelReq) []string {
+	names := make([]string, 0, len(models))
+	for _, m := range models {
+		names = append(names, m.Name)
+	}
+	return names
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/multi-agent-testing/backend/internal/judge"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/prompt"
+	"github.com/multi-agent-testing/backend/internal/simulation"
+	"github.com/multi-agent-testing/backend/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// defaultSimulationTurns 模拟用户对话的默认最大轮数
+const defaultSimulationTurns = 5
+
+// simulationPlan 渲染后的模拟用户评测配置
+type simulationPlan struct {
+	system     string // 被测模型的系统提示词
+	userSystem string // 模拟用户的系统提示词
+	opening    string
+	persona    simulation.Persona
+	simulator  model.ModelReq
+	judge      *model.JudgeRequest
+	maxTurns   int
+}
+
+// SubmitSimulation 提交模拟用户评测任务, 各模型并发与模拟用户对话, 结束后由评审模型评估
+func (s *JobService) SubmitSimulation(ctx context.Context, req *model.SimulationRequest) (*model.Job, error) {
+	plan, err := s.planSimulation(req)
+	if err != nil {
+		return nil, invalidJob(err)
+	}
+
+	request, err := model.NewJSONField(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	result := newSimulationResult(req.Models)
+
+	now := time.Now()
+	job := &model.Job{
+		ID:        uuid.NewString(),
+		Type:      model.JobTypeSimulation,
+		Status:    model.JobStatusPending,
+		Total:     len(req.Models),
+		Request:   request,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.store.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	if err := s.launch(job, func(ctx context.Context, job *model.Job) {
+		s.runSimulation(ctx, job, req.Models, plan, result)
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Simulation job submitted",
+		zap.String("job_id", job.ID),
+		zap.Int("max_turns", plan.maxTurns),
+		zap.Int("model_count", len(req.Models)),
+	)
+
+	return job, nil
+}
+
+// planSimulation 校验请求, 渲染提示词并解析模拟用户及评审模型
+// 模拟用户未指定模型时使用配置文件中的默认评审模型
+func (s *JobService) planSimulation(req *model.SimulationRequest) (*simulationPlan, error) {
+	if len(req.Models) == 0 {
+		return nil, errors.New("at least one model is required")
+	}
+	if err := validateModelNames(req.Models); err != nil {
+		return nil, err
+	}
+	if err := s.testService.ValidateModels(req.Models); err != nil {
+		return nil, err
+	}
+	if req.MaxTurns < 0 || req.MaxTurns > maxConversationTurns {
+		return nil, fmt.Errorf("max_turns must be between 1 and %d, or 0 for the default of %d", maxConversationTurns, defaultSimulationTurns)
+	}
+	if strings.TrimSpace(req.User.Persona) == "" || strings.TrimSpace(req.User.Goal) == "" {
+		return nil, errors.New("user persona and goal are required")
+	}
+
+	plan := &simulationPlan{
+		simulator: model.ModelReq{Provider: req.User.Provider, Name: req.User.Model},
+		judge:     req.Judge,
+		maxTurns:  req.MaxTurns,
+	}
+	if plan.maxTurns == 0 {
+		plan.maxTurns = defaultSimulationTurns
+	}
+	if plan.judge == nil {
+		plan.judge = &model.JudgeRequest{}
+	}
+	if err := s.testService.judge.ValidateSimulation(plan.judge); err != nil {
+		return nil, err
+	}
+	if plan.simulator.Provider == "" && plan.simulator.Name == "" {
+		cfg := s.testService.currentConfig().Judge
+		plan.simulator = model.ModelReq{Provider: cfg.Provider, Name: cfg.Model}
+	}
+	if plan.simulator.Provider == "" || plan.simulator.Name == "" {
+		return nil, errors.New("simulated user model is not configured, set user.provider and user.model")
+	}
+	if err := s.testService.ValidateModels([]model.ModelReq{plan.simulator}); err != nil {
+		return nil, fmt.Errorf("simulated user: %w", err)
+	}
+
+	fields := []struct {
+		name string
+		src  string
+		dst  *string
+	}{
+		{"system prompt", req.System, &plan.system},
+		{"persona", req.User.Persona, &plan.persona.Persona},
+		{"goal", req.User.Goal, &plan.persona.Goal},
+		{"stop condition", req.User.StopCondition, &plan.persona.StopCondition},
+		{"opening", req.User.Opening, &plan.opening},
+	}
+	for _, f := range fields {
+		// 没有变量时按原文使用, 设定中的{{、{%等代码或模板片段不会被当作模板解析
+		if len(req.Variables) == 0 {
+			*f.dst = f.src
+			continue
+		}
+		out, err := prompt.Render(f.src, req.Variables)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+		*f.dst = out
+	}
+
+	userSystem, err := simulation.BuildSystemPrompt(plan.persona)
+	if err != nil {
+		return nil, err
+	}
+	plan.userSystem = userSystem
+	return plan, nil
+}
+
+// runSimulation 执行模拟用户评测任务, 每个模型完成对话并评估后计为完成
+func (s *JobService) runSimulation(ctx context.Context, job *model.Job, models []model.ModelReq, plan *simulationPlan, result *model.SimulationResult) {
+	// 仅用于持久化, 不受任务取消影响
+	storeCtx := context.Background()
+
+	job.Status = model.JobStatusRunning
+	job.UpdatedAt = time.Now()
+	if err := s.store.Update(storeCtx, job); err != nil {
+		logger.Error("Failed to update job", zap.String("job_id", job.ID), zap.Error(err))
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	save := func(update func()) {
+		mu.Lock()
+		defer mu.Unlock()
+		update()
+		s.saveResult(storeCtx, job, result)
+	}
+	for _, modelReq := range models {
+		transcript := result.Transcripts[modelReq.Name]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !s.simulate(ctx, modelReq, plan, transcript, save) {
+				return
+			}
+			// 未产生任何对话时无需评估
+			var evaluation *model.SimulationEvaluation
+			if len(transcript.Turns) > 0 {
+				evaluation = s.testService.judge.EvaluateSimulation(ctx, plan.judge, simulationInput(plan, transcript))
+				if ctx.Err() != nil {
+					return
+				}
+			}
+			save(func() {
+				transcript.Evaluation = evaluation
+				finalizeSimulation(transcript)
+				job.Completed++
+			})
+		}()
+	}
+	wg.Wait()
+
+	s.saveResult(storeCtx, job, result)
+	if errors.Is(ctx.Err(), context.Canceled) {
+		s.finish(storeCtx, job, model.JobStatusCanceled, "")
+		return
+	}
+	s.finish(storeCtx, job, model.JobStatusSucceeded, "")
+}
+
+// simulate 模拟用户与单个模型对话直到结束, 被取消时返回false
+// save在持有锁时执行更新并保存进度
+func (s *JobService) simulate(ctx context.Context, modelReq model.ModelReq, plan *simulationPlan, transcript *model.SimulationTranscript, save func(func())) bool {
+	history := []model.Message{}
+	for i := 0; i < plan.maxTurns; i++ {
+		user := plan.opening
+		if i > 0 || user == "" {
+			out := s.testService.CallModel(ctx, model.PromptSet{
+				System:  plan.userSystem,
+				User:    simulation.Kickoff,
+				Message: simulation.Messages(history),
+			}, plan.simulator)
+			if ctx.Err() != nil {
+				return false
+			}
+
+			message, stop := simulation.ParseMessage(out.Content)
+			reason, errMsg := "", ""
+			switch {
+			case !out.Success:
+				reason, errMsg = model.StopReasonSimulatorError, out.Error
+			case stop:
+				reason = model.StopReasonUser
+			case message == "":
+				reason, errMsg = model.StopReasonSimulatorError, "simulated user returned an empty message"
+			}
+			save(func() {
+				transcript.SimulatorTokens += out.TokensUsed
+				transcript.SimulatorCost = addCost(transcript.SimulatorCost, out.Cost)
+				transcript.StopReason = reason
+				transcript.Error = errMsg
+			})
+			if reason != "" {
+				return true
+			}
+			user = message
+		}
+
+		history = append(history, model.Message{Role: "user", Content: user})
+		resp := s.testService.CallModel(ctx, model.PromptSet{
+			System:  plan.system,
+			User:    user,
+			Message: append([]model.Message{}, history...),
+		}, modelReq)
+		if ctx.Err() != nil {
+			return false
+		}
+		save(func() {
+			transcript.Turns = append(transcript.Turns, &model.TurnResult{Index: i, User: user, Response: resp})
+			if !resp.Success {
+				transcript.StopReason = model.StopReasonModelError
+			}
+		})
+		if !resp.Success {
+			return true
+		}
+		history = append(history, model.Message{Role: "assistant", Content: resp.Content})
+	}
+
+	save(func() {
+		transcript.StopReason = model.StopReasonMaxTurns
+	})
+	return true
+}
+
+// newSimulationResult 创建空的模拟对话记录
+func newSimulationResult(models []model.ModelReq) *model.SimulationResult {
+	result := &model.SimulationResult{
+		Models:      make([]string, 0, len(models)),
+		Transcripts: make(map[string]*model.SimulationTranscript, len(models)),
+	}
+	for _, modelReq := range models {
+		result.Models = append(result.Models, modelReq.Name)
+		result.Transcripts[modelReq.Name] = &model.SimulationTranscript{
+			Transcript: model.Transcript{
+				ModelName: modelReq.Name,
+				Provider:  modelReq.Provider,
+				Turns:     []*model.TurnResult{},
+			},
+		}
+	}
+	return result
+}
+
+// simulationInput 评审模型的输入
+func simulationInput(plan *simulationPlan, transcript *model.SimulationTranscript) judge.SimulationInput {
+	in := judge.SimulationInput{Persona: plan.persona.Persona, Goal: plan.persona.Goal}
+	for _, turn := range transcript.Turns {
+		in.Turns = append(in.Turns, judge.SimulationTurn{User: turn.User, Assistant: turn.Response.Content})
+	}
+	return in
+}
+
+// finalizeSimulation 汇总被测模型各轮的耗时、token及费用, 以评审结论作为总体结论
+// 模拟用户主动结束或达到最大轮数时视为完整的对话
+func finalizeSimulation(transcript *model.SimulationTranscript) {
+	finalizeTranscript(&transcript.Transcript, len(transcript.Turns))
+	transcript.Completed = transcript.StopReason == model.StopReasonUser || transcript.StopReason == model.StopReasonMaxTurns
+	transcript.Passed = nil
+	if transcript.Evaluation != nil {
+		transcript.Passed = transcript.Evaluation.GoalReached
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/repository"
+)
+
+func TestFinalizeSimulation(t *testing.T) {
+	turns := func(passed ...*bool) []*model.TurnResult {
+		out := make([]*model.TurnResult, len(passed))
+		for i, p := range passed {
+			out[i] = &model.TurnResult{Index: i, Response: &model.ModelResponse{ResponseTime: 100, TokensUsed: 10, Cost: ptr(0.5), Passed: p}}
+		}
+		return out
+	}
+	tests := []struct {
+		name          string
+		transcript    model.SimulationTranscript
+		wantCompleted bool
+		wantPassed    *bool
+	}{
+		{"user stopped", model.SimulationTranscript{StopReason: model.StopReasonUser}, true, nil},
+		{"max turns", model.SimulationTranscript{StopReason: model.StopReasonMaxTurns}, true, nil},
+		{"model error", model.SimulationTranscript{StopReason: model.StopReasonModelError}, false, nil},
+		{"simulator error", model.SimulationTranscript{StopReason: model.StopReasonSimulatorError}, false, nil},
+		{"cancelled", model.SimulationTranscript{}, false, nil},
+		{
+			name:          "goal reached",
+			transcript:    model.SimulationTranscript{StopReason: model.StopReasonUser, Evaluation: &model.SimulationEvaluation{GoalReached: ptr(true)}},
+			wantCompleted: true,
+			wantPassed:    ptr(true),
+		},
+		{
+			// 是否通过只取决于目标是否达成, 不受各轮断言影响
+			name: "goal missed",
+			transcript: model.SimulationTranscript{
+				Transcript: model.Transcript{Turns: turns(ptr(true), ptr(true))},
+				StopReason: model.StopReasonMaxTurns,
+				Evaluation: &model.SimulationEvaluation{GoalReached: ptr(false)},
+			},
+			wantCompleted: true,
+			wantPassed:    ptr(false),
+		},
+		{
+			name: "evaluation failed",
+			transcript: model.SimulationTranscript{
+				Transcript: model.Transcript{Turns: turns(ptr(true))},
+				StopReason: model.StopReasonUser,
+				Evaluation: &model.SimulationEvaluation{Error: "judge unavailable"},
+			},
+			wantCompleted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transcript := tt.transcript
+			finalizeSimulation(&transcript)
+			if transcript.Completed != tt.wantCompleted {
+				t.Fatalf("completed = %v, want %v", transcript.Completed, tt.wantCompleted)
+			}
+			if (transcript.Passed == nil) != (tt.wantPassed == nil) || (transcript.Passed != nil && *transcript.Passed != *tt.wantPassed) {
+				t.Fatalf("passed = %v, want %v", transcript.Passed, tt.wantPassed)
+			}
+		})
+	}
+
+	transcript := model.SimulationTranscript{Transcript: model.Transcript{Turns: turns(nil, nil, nil)}, StopReason: model.StopReasonUser}
+	finalizeSimulation(&transcript)
+	if transcript.ResponseTime != 300 || transcript.TokensUsed != 30 || transcript.Cost == nil || *transcript.Cost != 1.5 {
+		t.Fatalf("unexpected totals: time=%d tokens=%d cost=%v", transcript.ResponseTime, transcript.TokensUsed, transcript.Cost)
+	}
+}
+
+func TestSubmitSimulationRejectsDuplicateModelNames(t *testing.T) {
+	s := newBatchService(newFakeProvider(""), repository.NewMemoryJobStore())
+	req := &model.SimulationRequest{
+		User:   model.SimulatedUser{Persona: "a traveler", Goal: "rebook a flight"},
+		Models: []model.ModelReq{{Name: "x", Provider: "fake"}, {Name: "x", Provider: "openai"}},
+	}
+	if _, err := s.SubmitSimulation(context.Background(), req); !errors.Is(err, ErrInvalidJob) {
+		t.Fatalf("expected ErrInvalidJob, got %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/prompt"
+	"github.com/multi-agent-testing/backend/internal/repository"
+)
+
+var (
+	// ErrInvalidTemplate 模板内容或变量声明不合法
+	ErrInvalidTemplate = errors.New("invalid template")
+	// ErrRenderTemplate 模板渲染失败, 如缺少变量或类型不符
+	ErrRenderTemplate = errors.New("render template failed")
+)
+
+// TemplateService 提示词模板服务
+type TemplateService struct {
+	repo *repository.PromptTemplateRepository
+}
+
+// NewTemplateService 创建提示词模板服务
+func NewTemplateService(repo *repository.PromptTemplateRepository) *TemplateService {
+	return &TemplateService{
+		repo: repo,
+	}
+}
+
+// Create 校验并创建模板
+func (s *TemplateService) Create(ctx context.Context, req *model.SaveTemplateRequest) (*model.PromptTemplate, error) {
+	tpl, err := buildTemplate(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, tpl, req.Note); err != nil {
+		return nil, fmt.Errorf("failed to save template: %w", err)
+	}
+	return tpl, nil
+}
+
+// Update 校验并更新模板
+func (s *TemplateService) Update(ctx context.Context, id uint64, req *model.SaveTemplateRequest) (*model.PromptTemplate, error) {
+	tpl, err := buildTemplate(req)
+	if err != nil {
+		return nil, err
+	}
+	tpl.ID = id
+	if err := s.repo.Update(ctx, tpl, req.Note); err != nil {
+		return nil, err
+	}
+	return tpl, nil
+}
+
+// Get 获取模板
+func (s *TemplateService) Get(ctx context.Context, id uint64) (*model.PromptTemplate, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// List 分页查询模板
+func (s *TemplateService) List(ctx context.Context, name string, offset, limit int) ([]*model.PromptTemplate, int64, error) {
+	return s.repo.List(ctx, name, offset, limit)
+}
+
+// Delete 删除模板
+func (s *TemplateService) Delete(ctx context.Context, id uint64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// ListVersions 列出模板的全部版本
+func (s *TemplateService) ListVersions(ctx context.Context, id uint64) ([]*model.PromptTemplateVersion, error) {
+	versions, err := s.repo.ListVersions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return versions, nil
+}
+
+// GetVersion 获取模板的指定版本
+func (s *TemplateService) GetVersion(ctx context.Context, id uint64, version int) (*model.PromptTemplateVersion, error) {
+	return s.repo.GetVersion(ctx, id, version)
+}
+
+// Diff 比较模板两个版本, 返回发生变化的字段
+func (s *TemplateService) Diff(ctx context.Context, id uint64, from, to int) (*model.TemplateDiff, error) {
+	a, err := s.repo.GetVersion(ctx, id, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.repo.GetVersion(ctx, id, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &model.TemplateDiff{
+		TemplateID: id,
+		From:       from,
+		To:         to,
+		Changes:    []model.FieldChange{},
+	}
+	for _, field := range []struct {
+		name     string
+		from, to string
+	}{
+		{"name", a.Name, b.Name},
+		{"system_prompt", a.SystemPrompt, b.SystemPrompt},
+		{"user_prompt", a.UserPrompt, b.UserPrompt},
+		{"ai_prompt", a.AIPrompt, b.AIPrompt},
+		{"description", a.Description, b.Description},
+	} {
+		if field.from != field.to {
+			diff.Changes = append(diff.Changes, model.FieldChange{Field: field.name, From: field.from, To: field.to})
+		}
+	}
+
+	fromVars, err := json.Marshal(a.Variables)
+	if err != nil {
+		return nil, err
+	}
+	toVars, err := json.Marshal(b.Variables)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(fromVars, toVars) {
+		diff.Changes = append(diff.Changes, model.FieldChange{Field: "variables", From: a.Variables, To: b.Variables})
+	}
+	return diff, nil
+}
+
+// Rollback 以指定版本的内容生成模板的新版本, 历史版本保持不变
+func (s *TemplateService) Rollback(ctx context.Context, id uint64, version int, note string) (*model.PromptTemplate, error) {
+	if _, err := s.repo.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	v, err := s.repo.GetVersion(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if note == "" {
+		note = fmt.Sprintf("rollback to version %d", version)
+	}
+	tpl := v.Template()
+	if err := s.repo.Update(ctx, tpl, note); err != nil {
+		return nil, err
+	}
+	return tpl, nil
+}
+
+// Render 使用变量渲染模板, version为0时使用当前版本
+func (s *TemplateService) Render(ctx context.Context, id uint64, version int, values map[string]interface{}) (*model.PromptSet, error) {
+	tpl, err := s.load(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	return renderTemplate(tpl, values)
+}
+
+// ResolveTestRequest 请求引用了模板时, 渲染模板并填充请求中的提示词及实际使用的版本
+func (s *TemplateService) ResolveTestRequest(ctx context.Context, req *model.TestRequest) error {
+	if req.TemplateID == 0 {
+		if len(req.Variables) > 0 || req.TemplateVersion != 0 {
+			return fmt.Errorf("%w: variables and template_version require template_id", ErrRenderTemplate)
+		}
+		return nil
+	}
+
+	tpl, err := s.load(ctx, req.TemplateID, req.TemplateVersion)
+	if err != nil {
+		return err
+	}
+	prompts, err := renderTemplate(tpl, req.Variables)
+	if err != nil {
+		return err
+	}
+	// 已有的多轮消息保留, 模板仅提供提示词
+	prompts.Message = req.Prompts.Message
+	req.Prompts = *prompts
+	req.TemplateVersion = tpl.Version
+	return nil
+}
+
+// load 加载模板的当前内容或指定版本
+func (s *TemplateService) load(ctx context.Context, id uint64, version int) (*model.PromptTemplate, error) {
+	if version == 0 {
+		return s.repo.Get(ctx, id)
+	}
+	v, err := s.repo.GetVersion(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	return v.Template(), nil
+}
+
+// buildTemplate 校验保存请求并构建模板实体
+func buildTemplate(req *model.SaveTemplateRequest) (*model.PromptTemplate, error) {
+	referenced, err := prompt.ReferencedVariables(req.SystemPrompt, req.UserPrompt, req.AIPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	if err := prompt.ValidateDeclarations(req.Variables, referenced); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	tpl := &model.PromptTemplate{
+		Name:         req.Name,
+		SystemPrompt: req.SystemPrompt,
+		UserPrompt:   req.UserPrompt,
+		AIPrompt:     req.AIPrompt,
+		Description:  req.Description,
+	}
+	if len(req.Variables) > 0 {
+		if tpl.Variables, err = model.NewJSONArray(req.Variables); err != nil {
+			return nil, fmt.Errorf("failed to encode variables: %w", err)
+		}
+	}
+	return tpl, nil
+}
+
+// renderTemplate 校验变量后渲染模板的各段提示词
+func renderTemplate(tpl *model.PromptTemplate, values map[string]interface{}) (*model.PromptSet, error) {
+	decls, err := tpl.VariableDecls()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode template variables: %w", err)
+	}
+	referenced, err := prompt.ReferencedVariables(tpl.SystemPrompt, tpl.UserPrompt, tpl.AIPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRenderTemplate, err)
+	}
+	vars, err := prompt.ResolveVariables(decls, referenced, values)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRenderTemplate, err)
+	}
+
+	prompts := &model.PromptSet{}
+	for _, part := range []struct {
+		text string
+		out  *string
+	}{
+		{tpl.SystemPrompt, &prompts.System},
+		{tpl.UserPrompt, &prompts.User},
+		{tpl.AIPrompt, &prompts.AI},
+	} {
+		if *part.out, err = prompt.Render(part.text, vars); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrRenderTemplate, err)
+		}
+	}
+	return prompts, nil
+}
@@ -0,0 +1,79 @@
+package simulation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/prompt"
+)
+
+// StopToken 模拟用户结束对话时输出的标记
+const StopToken = "[END]"
+
+// Kickoff 模拟用户的第一条输入, 提示其发出第一条消息
+const Kickoff = "(The conversation starts now. Send your first message to the assistant.)"
+
+// defaultStopCondition 未指定结束条件时的默认条件
+const defaultStopCondition = "Your goal has been reached, or it is clear that the assistant cannot help you reach it."
+
+// userTemplate 模拟用户的系统提示词模板
+const userTemplate = `You are role-playing a user who is talking to an AI assistant. Stay in character and never reveal that you are simulated.
+
+[Persona]
+{{ persona }}
+
+[Goal]
+{{ goal }}
+
+[Stop Condition]
+{{ stop_condition }}
+
+Write only the next message the user would send, without quotes or explanations.
+When the stop condition is met, reply with ` + StopToken + ` and nothing else.`
+
+// Persona 渲染后的模拟用户设定
+type Persona struct {
+	Persona       string
+	Goal          string
+	StopCondition string
+}
+
+// BuildSystemPrompt 生成模拟用户的系统提示词
+func BuildSystemPrompt(p Persona) (string, error) {
+	stop := strings.TrimSpace(p.StopCondition)
+	if stop == "" {
+		stop = defaultStopCondition
+	}
+	out, err := prompt.Render(userTemplate, map[string]interface{}{
+		"persona":        p.Persona,
+		"goal":           p.Goal,
+		"stop_condition": stop,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render simulated user prompt: %w", err)
+	}
+	return out, nil
+}
+
+// Messages 将被测模型视角的历史转换为模拟用户视角, 交换user与assistant角色并在开头加入Kickoff
+func Messages(history []model.Message) []model.Message {
+	messages := make([]model.Message, 0, len(history)+1)
+	messages = append(messages, model.Message{Role: "user", Content: Kickoff})
+	for _, msg := range history {
+		role := "user"
+		if msg.Role == "user" {
+			role = "assistant"
+		}
+		messages = append(messages, model.Message{Role: role, Content: msg.Content})
+	}
+	return messages
+}
+
+// ParseMessage 解析模拟用户的输出, 包含StopToken时表示结束对话
+func ParseMessage(content string) (string, bool) {
+	if strings.Contains(content, StopToken) {
+		return strings.TrimSpace(strings.ReplaceAll(content, StopToken, "")), true
+	}
+	return strings.TrimSpace(content), false
+}
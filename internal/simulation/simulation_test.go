@@ -0,0 +1,90 @@
+package simulation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+)
+
+func TestBuildSystemPrompt(t *testing.T) {
+	out, err := BuildSystemPrompt(Persona{Persona: "a busy traveler", Goal: "rebook a flight"})
+	if err != nil {
+		t.Fatalf("BuildSystemPrompt: %v", err)
+	}
+	for _, want := range []string{"a busy traveler", "rebook a flight", defaultStopCondition, StopToken} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("prompt does not contain %q:\n%s", want, out)
+		}
+	}
+
+	out, err = BuildSystemPrompt(Persona{Persona: "p", Goal: "g", StopCondition: "You got a refund."})
+	if err != nil {
+		t.Fatalf("BuildSystemPrompt: %v", err)
+	}
+	if !strings.Contains(out, "You got a refund.") || strings.Contains(out, defaultStopCondition) {
+		t.Fatalf("custom stop condition not used:\n%s", out)
+	}
+}
+
+func TestMessages(t *testing.T) {
+	kickoff := model.Message{Role: "user", Content: Kickoff}
+	tests := []struct {
+		name    string
+		history []model.Message
+		want    []model.Message
+	}{
+		{"first turn", nil, []model.Message{kickoff}},
+		{
+			// 模拟用户发出的消息在其视角中是assistant, 被测模型的回复是user
+			name: "roles flipped",
+			history: []model.Message{
+				{Role: "user", Content: "I need to change my flight"},
+				{Role: "assistant", Content: "Which date?"},
+				{Role: "user", Content: "Friday"},
+				{Role: "assistant", Content: "Done"},
+			},
+			want: []model.Message{
+				kickoff,
+				{Role: "assistant", Content: "I need to change my flight"},
+				{Role: "user", Content: "Which date?"},
+				{Role: "assistant", Content: "Friday"},
+				{Role: "user", Content: "Done"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Messages(tt.history); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Messages = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		message string
+		stop    bool
+	}{
+		{"message", "  Can you help me?\n", "Can you help me?", false},
+		{"stop only", "[END]", "", true},
+		{"stop with whitespace", "\n [END] \n", "", true},
+		{"text before stop", "Thanks, that's all. [END]", "Thanks, that's all.", true},
+		{"text after stop", "[END] bye", "bye", true},
+		{"repeated stop", "[END][END]", "", true},
+		{"lowercase is not a stop", "[end]", "[end]", false},
+		{"empty", "   ", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, stop := ParseMessage(tt.content)
+			if message != tt.message || stop != tt.stop {
+				t.Fatalf("ParseMessage(%q) = (%q, %v), want (%q, %v)", tt.content, message, stop, tt.message, tt.stop)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package suite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/service"
+)
+
+// LocalBackend 在进程内使用与服务端相同的服务执行套件
+type LocalBackend struct {
+	jobs      *service.JobService
+	templates *service.TemplateService // 未启用数据库时为nil
+}
+
+// NewLocalBackend 创建进程内执行端, templates为nil时不支持引用模板
+func NewLocalBackend(jobs *service.JobService, templates *service.TemplateService) *LocalBackend {
+	return &LocalBackend{
+		jobs:      jobs,
+		templates: templates,
+	}
+}
+
+// RenderTemplate 使用数据库中的模板渲染提示词
+func (b *LocalBackend) RenderTemplate(ctx context.Context, id uint64, version int, variables map[string]interface{}) (*model.PromptSet, error) {
+	if b.templates == nil {
+		return nil, errors.New("templates require database to be enabled")
+	}
+	return b.templates.Render(ctx, id, version, variables)
+}
+
+// RunBatch 提交批量任务并等待结束
+func (b *LocalBackend) RunBatch(ctx context.Context, req *model.BatchRequest) (*model.BatchResult, error) {
+	job, err := b.jobs.SubmitBatch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// 任务在后台运行, 需显式取消
+			_, _ = b.jobs.Cancel(context.Background(), job.ID)
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		job, err = b.jobs.Get(ctx, job.ID)
+		if err != nil {
+			return nil, err
+		}
+		if job.IsFinished() {
+			return finishedResult(job)
+		}
+	}
+}
+
+// finishedResult 解码已结束任务的结果, 任务失败或被取消时返回错误
+func finishedResult(job *model.Job) (*model.BatchResult, error) {
+	switch job.Status {
+	case model.JobStatusFailed:
+		return nil, fmt.Errorf("job %s failed: %s", job.ID, job.Error)
+	case model.JobStatusCanceled:
+		return nil, fmt.Errorf("job %s was canceled", job.ID)
+	}
+	var result model.BatchResult
+	if err := job.Result.Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode job result: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,104 @@
+package suite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+)
+
+// RemoteBackend 通过HTTP API在运行中的服务端执行套件
+type RemoteBackend struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewRemoteBackend 创建服务端执行端, baseURL为服务地址, 如http://localhost:8080
+func NewRemoteBackend(baseURL string) *RemoteBackend {
+	return &RemoteBackend{
+		baseURL: strings.TrimRight(baseURL, "/") + "/api/v1",
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// RenderTemplate 调用服务端模板渲染接口
+func (b *RemoteBackend) RenderTemplate(ctx context.Context, id uint64, version int, variables map[string]interface{}) (*model.PromptSet, error) {
+	var prompts model.PromptSet
+	body := model.RenderTemplateRequest{Version: version, Variables: variables}
+	if err := b.call(ctx, http.MethodPost, fmt.Sprintf("/prompt/templates/%d/render", id), body, &prompts); err != nil {
+		return nil, err
+	}
+	return &prompts, nil
+}
+
+// RunBatch 提交批量任务并轮询直到结束
+func (b *RemoteBackend) RunBatch(ctx context.Context, req *model.BatchRequest) (*model.BatchResult, error) {
+	var job model.Job
+	if err := b.call(ctx, http.MethodPost, "/jobs/batch", req, &job); err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			_ = b.call(context.Background(), http.MethodDelete, "/jobs/"+job.ID, nil, nil)
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		if err := b.call(ctx, http.MethodGet, "/jobs/"+job.ID, nil, &job); err != nil {
+			return nil, err
+		}
+		if job.IsFinished() {
+			return finishedResult(&job)
+		}
+	}
+}
+
+// call 调用服务端接口并解码响应中的data字段, 非2xx响应返回服务端的错误信息
+func (b *RemoteBackend) call(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, b.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("%s %s: unexpected response (HTTP %d): %w", method, path, resp.StatusCode, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s: %s (HTTP %d)", method, path, envelope.Message, resp.StatusCode)
+	}
+	if out == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
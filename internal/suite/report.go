@@ -0,0 +1,238 @@
+package suite
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+	"github.com/multi-agent-testing/backend/internal/report"
+)
+
+// 用例结果状态
+const (
+	StatusPass  = "pass"  // 调用成功且断言及评审均通过
+	StatusFail  = "fail"  // 断言或评审未通过
+	StatusError = "error" // 模型调用失败或缺少结果
+)
+
+// Report 单个套件的执行报告
+type Report struct {
+	Suite    string     `json:"suite"`
+	Path     string     `json:"path"`
+	Duration int64      `json:"duration"` // 总耗时(毫秒)
+	Total    int        `json:"total"`
+	Passed   int        `json:"passed"`
+	Failed   int        `json:"failed"`
+	Errors   int        `json:"errors"`
+	Results  []*Outcome `json:"results"` // 按用例、模型顺序排列
+}
+
+// Outcome 单个用例在单个模型上的结果
+type Outcome struct {
+	CaseID       string   `json:"case_id"`
+	Model        string   `json:"model"`
+	Status       string   `json:"status"`
+	Message      string   `json:"message,omitempty"` // 未通过或出错的原因
+	ResponseTime int64    `json:"response_time"`     // 响应时间(毫秒)
+	Cost         *float64 `json:"cost,omitempty"`
+	Score        *float64 `json:"score,omitempty"` // 评审分数
+}
+
+// NewReport 由批量测试结果生成报告
+func NewReport(s *Suite, result *model.BatchResult, duration time.Duration) *Report {
+	report := &Report{
+		Suite:    s.Name,
+		Path:     s.Path,
+		Duration: duration.Milliseconds(),
+	}
+	for i, c := range result.Cases {
+		caseID := c.Case.ID
+		if caseID == "" {
+			caseID = fmt.Sprintf("case-%d", i+1)
+		}
+		for _, name := range result.Models {
+			outcome := newOutcome(caseID, name, c.Results[name])
+			switch outcome.Status {
+			case StatusPass:
+				report.Passed++
+			case StatusFail:
+				report.Failed++
+			default:
+				report.Errors++
+			}
+			report.Results = append(report.Results, outcome)
+		}
+	}
+	report.Total = len(report.Results)
+	return report
+}
+
+// newOutcome 判定单个模型响应的结果
+func newOutcome(caseID, modelName string, resp *model.ModelResponse) *Outcome {
+	outcome := &Outcome{CaseID: caseID, Model: modelName}
+	if resp == nil {
+		outcome.Status = StatusError
+		outcome.Message = "no result"
+		return outcome
+	}
+	outcome.ResponseTime = resp.ResponseTime
+	outcome.Cost = resp.Cost
+	if resp.Judge != nil {
+		outcome.Score = resp.Judge.Score
+	}
+
+	switch {
+	case !resp.Success:
+		outcome.Status = StatusError
+		outcome.Message = resp.Error
+	case resp.Passed != nil && !*resp.Passed:
+		outcome.Status = StatusFail
+		outcome.Message = report.FailureMessage(resp)
+	default:
+		outcome.Status = StatusPass
+	}
+	return outcome
+}
+
+// HasFailures 是否存在未通过或出错的用例
+func (r *Report) HasFailures() bool {
+	return r.Failed+r.Errors > 0
+}
+
+// WriteTable 以表格输出报告
+func WriteTable(w io.Writer, reports []*Report) error {
+	for i, r := range reports {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "Suite %s (%s)\n\n", r.Suite, r.Path)
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "CASE\tMODEL\tSTATUS\tTIME\tMESSAGE")
+		for _, o := range r.Results {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%dms\t%s\n",
+				o.CaseID, o.Model, strings.ToUpper(o.Status), o.ResponseTime, singleLine(o.Message))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "\n%d passed, %d failed, %d errors in %.1fs\n",
+			r.Passed, r.Failed, r.Errors, float64(r.Duration)/1000)
+	}
+	return nil
+}
+
+// singleLine 将换行替换为空格以免破坏表格
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// WriteJSON 以JSON数组输出报告
+func WriteJSON(w io.Writer, reports []*Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(reports)
+}
+
+// junitTestSuites JUnit XML根节点
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite 每个套件的每个模型对应一个testsuite
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit 以JUnit XML输出报告, 供CI展示测试结果
+func WriteJUnit(w io.Writer, reports []*Report) error {
+	root := junitTestSuites{Name: "mat"}
+	var total int64
+	for _, r := range reports {
+		total += r.Duration
+		root.Tests += r.Total
+		root.Failures += r.Failed
+		root.Errors += r.Errors
+
+		// 按模型分组, 保持模型首次出现的顺序
+		index := make(map[string]int)
+		var suites []junitTestSuite
+		var elapsed []int64
+		for _, o := range r.Results {
+			i, ok := index[o.Model]
+			if !ok {
+				i = len(suites)
+				index[o.Model] = i
+				suites = append(suites, junitTestSuite{Name: r.Suite + "/" + o.Model})
+				elapsed = append(elapsed, 0)
+			}
+			tc := junitTestCase{
+				Name:      o.CaseID,
+				ClassName: r.Suite + "." + o.Model,
+				Time:      seconds(o.ResponseTime),
+			}
+			switch o.Status {
+			case StatusFail:
+				tc.Failure = &junitMessage{Message: singleLine(o.Message), Type: "assertion", Text: o.Message}
+				suites[i].Failures++
+			case StatusError:
+				tc.Error = &junitMessage{Message: singleLine(o.Message), Type: "error", Text: o.Message}
+				suites[i].Errors++
+			}
+			suites[i].Tests++
+			suites[i].Cases = append(suites[i].Cases, tc)
+			elapsed[i] += o.ResponseTime
+		}
+		for i := range suites {
+			suites[i].Time = seconds(elapsed[i])
+		}
+		root.Suites = append(root.Suites, suites...)
+	}
+	root.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// seconds 将毫秒转换为JUnit使用的秒数
+func seconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
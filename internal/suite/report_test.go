@@ -0,0 +1,72 @@
+package suite
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	reports := []*Report{
+		{
+			Suite:    "capitals",
+			Duration: 2500,
+			Total:    4,
+			Passed:   2,
+			Failed:   1,
+			Errors:   1,
+			Results: []*Outcome{
+				{CaseID: "france", Model: "gpt-4.1", Status: StatusPass, ResponseTime: 1200},
+				{CaseID: "france", Model: "deepseek-chat", Status: StatusFail, Message: "contains: expected 巴黎\nlength: too long", ResponseTime: 800},
+				{CaseID: "japan", Model: "gpt-4.1", Status: StatusPass, ResponseTime: 300},
+				{CaseID: "japan", Model: "deepseek-chat", Status: StatusError, Message: "timeout & <retry>", ResponseTime: 0},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, reports); err != nil {
+		t.Fatalf("WriteJUnit: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Fatalf("missing xml header:\n%s", buf.String())
+	}
+
+	var root junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &root); err != nil {
+		t.Fatalf("output is not valid xml: %v\n%s", err, buf.String())
+	}
+	if root.Tests != 4 || root.Failures != 1 || root.Errors != 1 || root.Time != "2.500" {
+		t.Fatalf("unexpected totals: %+v", root)
+	}
+
+	// 每个模型一个testsuite, 按模型首次出现的顺序
+	var names []string
+	for _, s := range root.Suites {
+		names = append(names, s.Name)
+	}
+	if want := []string{"capitals/gpt-4.1", "capitals/deepseek-chat"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("testsuites = %v, want %v", names, want)
+	}
+
+	gpt, deepseek := root.Suites[0], root.Suites[1]
+	if gpt.Tests != 2 || gpt.Failures != 0 || gpt.Errors != 0 || gpt.Time != "1.500" {
+		t.Fatalf("unexpected gpt-4.1 suite: %+v", gpt)
+	}
+	if deepseek.Tests != 2 || deepseek.Failures != 1 || deepseek.Errors != 1 {
+		t.Fatalf("unexpected deepseek-chat suite: %+v", deepseek)
+	}
+
+	failure := deepseek.Cases[0].Failure
+	if failure == nil || failure.Type != "assertion" || failure.Message != "contains: expected 巴黎 length: too long" || failure.Text != reports[0].Results[1].Message {
+		t.Fatalf("unexpected failure: %+v", failure)
+	}
+	if e := deepseek.Cases[1].Error; e == nil || e.Type != "error" || e.Message != "timeout & <retry>" {
+		t.Fatalf("unexpected error: %+v", e)
+	}
+	if c := gpt.Cases[1]; c.Name != "japan" || c.ClassName != "capitals.gpt-4.1" || c.Failure != nil || c.Error != nil {
+		t.Fatalf("unexpected passing case: %+v", c)
+	}
+}
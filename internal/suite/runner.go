@@ -0,0 +1,34 @@
+package suite
+
+import (
+	"context"
+	"time"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+)
+
+// pollInterval 等待批量任务结束时查询任务状态的间隔
+const pollInterval = 500 * time.Millisecond
+
+// Backend 套件的执行端, 可以是运行中的服务端或进程内的服务
+type Backend interface {
+	// RenderTemplate 使用变量渲染服务端提示词模板, version为0时使用当前版本
+	RenderTemplate(ctx context.Context, id uint64, version int, variables map[string]interface{}) (*model.PromptSet, error)
+	// RunBatch 提交批量测试并等待结束, ctx取消时取消任务
+	RunBatch(ctx context.Context, req *model.BatchRequest) (*model.BatchResult, error)
+}
+
+// Run 执行测试套件
+func Run(ctx context.Context, s *Suite, backend Backend) (*Report, error) {
+	req, err := s.BatchRequest(ctx, backend)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	result, err := backend.RunBatch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return NewReport(s, result, time.Since(start)), nil
+}
@@ -0,0 +1,185 @@
+// Package suite 加载声明式YAML测试套件, 通过服务端或进程内服务执行并生成报告
+package suite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/multi-agent-testing/backend/internal/assertion"
+	"github.com/multi-agent-testing/backend/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidSuite 测试套件文件不合法
+var ErrInvalidSuite = errors.New("invalid test suite")
+
+// Suite 测试套件, 字段与批量测试请求一致, 另支持套件级的提示词、模板及共用变量
+type Suite struct {
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description,omitempty"`
+	Models         []model.ModelReq       `json:"models"`
+	System         string                 `json:"system,omitempty"`    // 默认系统提示词, 支持{{变量}}
+	Prompt         string                 `json:"prompt,omitempty"`    // 默认用户提示词, 用例未设置prompt时使用
+	Template       *TemplateRef           `json:"template,omitempty"`  // 服务端提示词模板, 与system、prompt互斥
+	Variables      map[string]interface{} `json:"variables,omitempty"` // 各用例共用的变量, 用例中的同名变量优先
+	DatasetID      uint64                 `json:"dataset_id,omitempty"`
+	DatasetVersion int                    `json:"dataset_version,omitempty"` // 为空时使用最新版本, CI中建议固定版本使结果可比较
+	Split          string                 `json:"split,omitempty"`           // 只运行指定划分的数据集用例
+	Tags           []string               `json:"tags,omitempty"`            // 只运行带有任一标签的数据集用例
+	Cases          []model.TestCase       `json:"cases,omitempty"`
+	Assertions     []model.Assertion      `json:"assertions,omitempty"` // 对每个用例的每个模型输出执行的断言
+	Judge          *model.JudgeRequest    `json:"judge,omitempty"`
+	Metrics        *model.MetricOptions   `json:"metrics,omitempty"`
+	Concurrency    int                    `json:"concurrency,omitempty"`
+
+	Path string `json:"-"` // 套件文件路径
+}
+
+// TemplateRef 引用的服务端提示词模板
+type TemplateRef struct {
+	ID      uint64 `json:"id"`
+	Version int    `json:"version,omitempty"` // 为空时使用当前版本
+}
+
+// Load 读取并校验测试套件文件, 未知字段视为错误以便发现拼写错误
+func Load(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先解析为通用结构再转为JSON, 以复用请求模型的json标签
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidSuite, path, err)
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidSuite, path, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.DisallowUnknownFields()
+	s := &Suite{}
+	if err := dec.Decode(s); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidSuite, path, err)
+	}
+
+	s.Path = path
+	if s.Name == "" {
+		s.Name = path
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Validate 校验套件结构, 模型及评审是否可用由执行端校验
+func (s *Suite) Validate() error {
+	if len(s.Models) == 0 {
+		return fmt.Errorf("%w: at least one model is required", ErrInvalidSuite)
+	}
+	for i, m := range s.Models {
+		if m.Name == "" || m.Provider == "" {
+			return fmt.Errorf("%w: models[%d] requires name and provider", ErrInvalidSuite, i)
+		}
+	}
+	if (s.DatasetID == 0) == (len(s.Cases) == 0) {
+		return fmt.Errorf("%w: exactly one of dataset_id and cases is required", ErrInvalidSuite)
+	}
+	switch {
+	case s.DatasetID == 0 && (s.DatasetVersion != 0 || s.Split != "" || len(s.Tags) > 0):
+		return fmt.Errorf("%w: dataset_version, split and tags require dataset_id", ErrInvalidSuite)
+	case s.DatasetID != 0 && (s.System != "" || s.Prompt != "" || len(s.Variables) > 0):
+		// 数据集用例自带提示词, 套件级的提示词和变量不会生效
+		return fmt.Errorf("%w: system, prompt and variables cannot be combined with dataset_id", ErrInvalidSuite)
+	case s.DatasetVersion < 0:
+		return fmt.Errorf("%w: dataset_version must be positive", ErrInvalidSuite)
+	case s.Split != "" && !slices.Contains(model.Splits, s.Split):
+		return fmt.Errorf("%w: unsupported split %s", ErrInvalidSuite, s.Split)
+	}
+	if s.Template != nil {
+		switch {
+		case s.Template.ID == 0:
+			return fmt.Errorf("%w: template.id is required", ErrInvalidSuite)
+		case s.System != "" || s.Prompt != "":
+			return fmt.Errorf("%w: template cannot be combined with system or prompt", ErrInvalidSuite)
+		case s.DatasetID != 0:
+			return fmt.Errorf("%w: template cannot be combined with dataset_id", ErrInvalidSuite)
+		}
+	}
+	if err := assertion.Validate(s.Assertions); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSuite, err)
+	}
+
+	seen := make(map[string]bool, len(s.Cases))
+	for i := range s.Cases {
+		tc := &s.Cases[i]
+		if tc.ID == "" {
+			tc.ID = fmt.Sprintf("case-%d", i+1)
+		}
+		if seen[tc.ID] {
+			return fmt.Errorf("%w: duplicate case id %s", ErrInvalidSuite, tc.ID)
+		}
+		seen[tc.ID] = true
+
+		if s.Template != nil {
+			if tc.Prompt != "" || tc.System != "" {
+				return fmt.Errorf("%w: case %s: prompt and system are rendered from the template", ErrInvalidSuite, tc.ID)
+			}
+		} else if tc.Prompt == "" && s.Prompt == "" {
+			return fmt.Errorf("%w: case %s: prompt is required", ErrInvalidSuite, tc.ID)
+		}
+		if err := assertion.Validate(tc.Assertions); err != nil {
+			return fmt.Errorf("%w: case %s: %v", ErrInvalidSuite, tc.ID, err)
+		}
+	}
+	return nil
+}
+
+// BatchRequest 将套件转换为批量测试请求, 引用模板时由执行端逐用例渲染提示词
+func (s *Suite) BatchRequest(ctx context.Context, backend Backend) (*model.BatchRequest, error) {
+	req := &model.BatchRequest{
+		DatasetID:      s.DatasetID,
+		DatasetVersion: s.DatasetVersion,
+		Split:          s.Split,
+		Tags:           s.Tags,
+		Models:         s.Models,
+		Concurrency:    s.Concurrency,
+		Assertions:     s.Assertions,
+		Judge:          s.Judge,
+		Metrics:        s.Metrics,
+	}
+	for _, tc := range s.Cases {
+		vars := make(map[string]interface{}, len(s.Variables)+len(tc.Variables))
+		for k, v := range s.Variables {
+			vars[k] = v
+		}
+		for k, v := range tc.Variables {
+			vars[k] = v
+		}
+
+		if s.Template != nil {
+			prompts, err := backend.RenderTemplate(ctx, s.Template.ID, s.Template.Version, vars)
+			if err != nil {
+				return nil, fmt.Errorf("case %s: %w", tc.ID, err)
+			}
+			tc.System, tc.Prompt, tc.Variables = prompts.System, prompts.User, nil
+		} else {
+			if tc.Prompt == "" {
+				tc.Prompt = s.Prompt
+			}
+			if tc.System == "" {
+				tc.System = s.System
+			}
+			tc.Variables = vars
+		}
+		req.Cases = append(req.Cases, tc)
+	}
+	return req, nil
+}
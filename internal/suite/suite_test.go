@@ -0,0 +1,173 @@
+package suite
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/multi-agent-testing/backend/internal/model"
+)
+
+// fakeBackend 记录模板渲染时收到的变量, 以变量拼接渲染结果
+type fakeBackend struct {
+	rendered []map[string]interface{}
+}
+
+func (b *fakeBackend) RenderTemplate(_ context.Context, id uint64, version int, variables map[string]interface{}) (*model.PromptSet, error) {
+	if id != 7 || version != 2 {
+		return nil, errors.New("unknown template")
+	}
+	b.rendered = append(b.rendered, variables)
+	return &model.PromptSet{System: "sys", User: "ask " + variables["country"].(string)}, nil
+}
+
+func (b *fakeBackend) RunBatch(context.Context, *model.BatchRequest) (*model.BatchResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+var testModels = []model.ModelReq{{Name: "gpt-4.1", Provider: "openai"}}
+
+func TestValidate(t *testing.T) {
+	cases := []model.TestCase{{Prompt: "hi"}}
+	tests := []struct {
+		name  string
+		suite Suite
+		ok    bool
+	}{
+		{"inline cases", Suite{Models: testModels, Cases: cases}, true},
+		{"suite prompt", Suite{Models: testModels, Prompt: "{{q}}", Cases: []model.TestCase{{Variables: map[string]interface{}{"q": "hi"}}}}, true},
+		{"dataset", Suite{Models: testModels, DatasetID: 3, DatasetVersion: 2, Split: "test", Tags: []string{"easy"}}, true},
+		{"template", Suite{Models: testModels, Template: &TemplateRef{ID: 7}, Cases: []model.TestCase{{ID: "a"}}}, true},
+		{"no models", Suite{Cases: cases}, false},
+		{"model without provider", Suite{Models: []model.ModelReq{{Name: "gpt-4.1"}}, Cases: cases}, false},
+		{"no cases or dataset", Suite{Models: testModels}, false},
+		{"cases and dataset", Suite{Models: testModels, DatasetID: 3, Cases: cases}, false},
+		{"split without dataset", Suite{Models: testModels, Split: "test", Cases: cases}, false},
+		{"negative dataset version", Suite{Models: testModels, DatasetID: 3, DatasetVersion: -1}, false},
+		{"unknown split", Suite{Models: testModels, DatasetID: 3, Split: "holdout"}, false},
+		{"dataset with system", Suite{Models: testModels, DatasetID: 3, System: "be brief"}, false},
+		{"dataset with prompt", Suite{Models: testModels, DatasetID: 3, Prompt: "{{q}}"}, false},
+		{"dataset with variables", Suite{Models: testModels, DatasetID: 3, Variables: map[string]interface{}{"lang": "en"}}, false},
+		{"template without id", Suite{Models: testModels, Template: &TemplateRef{}, Cases: []model.TestCase{{ID: "a"}}}, false},
+		{"template with prompt", Suite{Models: testModels, Template: &TemplateRef{ID: 7}, Prompt: "hi", Cases: []model.TestCase{{ID: "a"}}}, false},
+		{"template with dataset", Suite{Models: testModels, Template: &TemplateRef{ID: 7}, DatasetID: 3}, false},
+		{"template with case prompt", Suite{Models: testModels, Template: &TemplateRef{ID: 7}, Cases: cases}, false},
+		{"case without prompt", Suite{Models: testModels, Cases: []model.TestCase{{ID: "a"}}}, false},
+		{"duplicate case id", Suite{Models: testModels, Cases: []model.TestCase{{ID: "a", Prompt: "x"}, {ID: "a", Prompt: "y"}}}, false},
+		{"invalid assertion", Suite{Models: testModels, Cases: cases, Assertions: []model.Assertion{{Type: "nope"}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.suite.Validate()
+			if tt.ok && err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidSuite) {
+				t.Fatalf("expected ErrInvalidSuite, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateAssignsCaseIDs(t *testing.T) {
+	s := Suite{Models: testModels, Cases: []model.TestCase{{Prompt: "a"}, {ID: "named", Prompt: "b"}, {Prompt: "c"}}}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	var ids []string
+	for _, tc := range s.Cases {
+		ids = append(ids, tc.ID)
+	}
+	if want := []string{"case-1", "named", "case-3"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("case ids = %v, want %v", ids, want)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	s, err := Load(write("ok.yaml", "models:\n  - {name: gpt-4.1, provider: openai}\ncases:\n  - prompt: hi\n"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if s.Name != s.Path || s.Cases[0].ID != "case-1" {
+		t.Fatalf("expected defaults for name and case id, got %+v", s)
+	}
+
+	// 拼写错误的字段不应被静默忽略
+	if _, err := Load(write("typo.yaml", "models:\n  - {name: gpt-4.1, provider: openai}\ncase:\n  - prompt: hi\n")); !errors.Is(err, ErrInvalidSuite) {
+		t.Fatalf("expected ErrInvalidSuite for unknown field, got %v", err)
+	}
+}
+
+func TestBatchRequest(t *testing.T) {
+	s := &Suite{
+		Models:    testModels,
+		System:    "answer in {{lang}}",
+		Prompt:    "capital of {{country}}?",
+		Variables: map[string]interface{}{"lang": "en", "country": "France"},
+		Cases: []model.TestCase{
+			{ID: "default"},
+			{ID: "override", Variables: map[string]interface{}{"country": "Japan"}},
+			{ID: "own", System: "be brief", Prompt: "{{country}}"},
+		},
+	}
+	req, err := s.BatchRequest(context.Background(), &fakeBackend{})
+	if err != nil {
+		t.Fatalf("BatchRequest: %v", err)
+	}
+	want := []model.TestCase{
+		{ID: "default", System: "answer in {{lang}}", Prompt: "capital of {{country}}?", Variables: map[string]interface{}{"lang": "en", "country": "France"}},
+		{ID: "override", System: "answer in {{lang}}", Prompt: "capital of {{country}}?", Variables: map[string]interface{}{"lang": "en", "country": "Japan"}},
+		{ID: "own", System: "be brief", Prompt: "{{country}}", Variables: map[string]interface{}{"lang": "en", "country": "France"}},
+	}
+	if !reflect.DeepEqual(req.Cases, want) {
+		t.Fatalf("cases = %+v, want %+v", req.Cases, want)
+	}
+	// 合并变量时不应修改套件本身
+	if s.Cases[1].Variables["lang"] != nil || s.Variables["country"] != "France" {
+		t.Fatalf("suite variables were modified: %+v", s)
+	}
+}
+
+func TestBatchRequestTemplate(t *testing.T) {
+	s := &Suite{
+		Models:    testModels,
+		Template:  &TemplateRef{ID: 7, Version: 2},
+		Variables: map[string]interface{}{"country": "France", "lang": "en"},
+		Cases: []model.TestCase{
+			{ID: "a"},
+			{ID: "b", Variables: map[string]interface{}{"country": "Japan"}},
+		},
+	}
+	backend := &fakeBackend{}
+	req, err := s.BatchRequest(context.Background(), backend)
+	if err != nil {
+		t.Fatalf("BatchRequest: %v", err)
+	}
+	want := []model.TestCase{
+		{ID: "a", System: "sys", Prompt: "ask France"},
+		{ID: "b", System: "sys", Prompt: "ask Japan"},
+	}
+	if !reflect.DeepEqual(req.Cases, want) {
+		t.Fatalf("cases = %+v, want %+v", req.Cases, want)
+	}
+	if got := backend.rendered[1]; got["lang"] != "en" || got["country"] != "Japan" {
+		t.Fatalf("template rendered with %v", got)
+	}
+
+	s.Template.ID = 8
+	if _, err := s.BatchRequest(context.Background(), backend); err == nil {
+		t.Fatal("expected render error to be returned")
+	}
+}
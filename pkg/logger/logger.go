@@ -1,30 +1,39 @@
 package logger
 
 import (
+	"fmt"
 	"os"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-var globalLogger *zap.Logger
+var (
+	globalLogger *zap.Logger
+	globalLevel  = zap.NewAtomicLevel()
+)
 
-// Init 初始化日志系统
-func Init(level, format, output string) error {
-	// 解析日志级别
-	var zapLevel zapcore.Level
+// parseLevel 解析日志级别, 未知级别返回false
+func parseLevel(level string) (zapcore.Level, bool) {
 	switch level {
 	case "debug":
-		zapLevel = zapcore.DebugLevel
+		return zapcore.DebugLevel, true
 	case "info":
-		zapLevel = zapcore.InfoLevel
+		return zapcore.InfoLevel, true
 	case "warn":
-		zapLevel = zapcore.WarnLevel
+		return zapcore.WarnLevel, true
 	case "error":
-		zapLevel = zapcore.ErrorLevel
+		return zapcore.ErrorLevel, true
 	default:
-		zapLevel = zapcore.InfoLevel
+		return zapcore.InfoLevel, false
 	}
+}
+
+// Init 初始化日志系统
+func Init(level, format, output string) error {
+	// 解析日志级别
+	zapLevel, _ := parseLevel(level)
+	globalLevel.SetLevel(zapLevel)
 
 	// 配置编码器
 	var encoderConfig zapcore.EncoderConfig
@@ -46,18 +55,28 @@ func Init(level, format, output string) error {
 
 	// 配置输出位置
 	var writeSyncer zapcore.WriteSyncer
-	if output == "stdout" {
-		writeSyncer = zapcore.AddSync(os.Stdout)
+	if output == "stderr" {
+		writeSyncer = zapcore.AddSync(os.Stderr)
 	} else {
 		writeSyncer = zapcore.AddSync(os.Stdout)
 	}
 
-	core := zapcore.NewCore(encoder, writeSyncer, zapLevel)
+	core := zapcore.NewCore(encoder, writeSyncer, globalLevel)
 	globalLogger = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
 
 	return nil
 }
 
+// SetLevel 运行时修改日志级别
+func SetLevel(level string) error {
+	zapLevel, ok := parseLevel(level)
+	if !ok {
+		return fmt.Errorf("unknown log level: %s", level)
+	}
+	globalLevel.SetLevel(zapLevel)
+	return nil
+}
+
 // GetLogger 获取全局logger
 func GetLogger() *zap.Logger {
 	if globalLogger == nil {